@@ -0,0 +1,138 @@
+// Package callbacksig signs and verifies the status callbacks sent by the
+// payments service. Consumer services import it to authenticate callbacks.
+//
+// The signature header has the form
+//
+//	t=<unix timestamp>,v1=<hex hmac-sha256>[,v1=<hex hmac-sha256>]
+//
+// where each v1 is HMAC-SHA256(secret, "<timestamp>.<body>"). While a secret is
+// being rotated the header carries one v1 per active secret, so receivers can
+// switch to the new secret at their own pace.
+package callbacksig
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Header carries the signature on HTTP callbacks.
+	Header = "X-Payments-Signature"
+	// MetadataKey carries the signature on gRPC callbacks.
+	MetadataKey = "x-payments-signature"
+	// DefaultTolerance is the accepted clock skew between signing and verifying.
+	DefaultTolerance = 5 * time.Minute
+)
+
+var (
+	ErrMissingSignature  = errors.New("callback signature is missing")
+	ErrInvalidSignature  = errors.New("callback signature is invalid")
+	ErrSignatureExpired  = errors.New("callback signature timestamp is outside the tolerance")
+	ErrNoSecretsProvided = errors.New("no callback signing secrets provided")
+)
+
+// Sign returns the signature header value for body, with one v1 entry per
+// non-empty secret.
+func Sign(body []byte, timestamp time.Time, secrets ...string) string {
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+	parts := []string{"t=" + ts}
+	for _, secret := range secrets {
+		if strings.TrimSpace(secret) == "" {
+			continue
+		}
+		parts = append(parts, "v1="+computeSignature(body, ts, secret))
+	}
+	return strings.Join(parts, ",")
+}
+
+// Verify checks that header holds a valid signature of body for one of the
+// given secrets and that its timestamp is within tolerance of now. A
+// non-positive tolerance uses DefaultTolerance.
+func Verify(body []byte, header string, tolerance time.Duration, secrets ...string) error {
+	return verifyAt(body, header, tolerance, time.Now(), secrets...)
+}
+
+// VerifyRequest verifies an HTTP callback and returns its body. The request
+// body is restored so handlers can still bind it.
+func VerifyRequest(r *http.Request, tolerance time.Duration, secrets ...string) ([]byte, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	_ = r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	if err := Verify(body, r.Header.Get(Header), tolerance, secrets...); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+func verifyAt(body []byte, header string, tolerance time.Duration, now time.Time, secrets ...string) error {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return ErrMissingSignature
+	}
+	if tolerance <= 0 {
+		tolerance = DefaultTolerance
+	}
+
+	var ts string
+	signatures := make([]string, 0, 2)
+	for _, part := range strings.Split(header, ",") {
+		part = strings.TrimSpace(part)
+		if strings.HasPrefix(part, "t=") {
+			ts = strings.TrimPrefix(part, "t=")
+		}
+		if strings.HasPrefix(part, "v1=") {
+			signatures = append(signatures, strings.TrimPrefix(part, "v1="))
+		}
+	}
+	if ts == "" || len(signatures) == 0 {
+		return ErrInvalidSignature
+	}
+
+	tsUnix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	age := now.Sub(time.Unix(tsUnix, 0))
+	if age > tolerance || age < -tolerance {
+		return ErrSignatureExpired
+	}
+
+	checked := false
+	for _, secret := range secrets {
+		if strings.TrimSpace(secret) == "" {
+			continue
+		}
+		checked = true
+		expected := computeSignature(body, ts, secret)
+		for _, candidate := range signatures {
+			if hmac.Equal([]byte(expected), []byte(strings.ToLower(candidate))) {
+				return nil
+			}
+		}
+	}
+	if !checked {
+		return ErrNoSecretsProvided
+	}
+
+	return ErrInvalidSignature
+}
+
+func computeSignature(body []byte, ts string, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte(ts))
+	_, _ = mac.Write([]byte("."))
+	_, _ = mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,99 @@
+package callbacksig
+
+import (
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	body := []byte(`{"payment":{"id":1}}`)
+	now := time.Unix(1700000000, 0)
+	header := Sign(body, now, "whsec_new")
+
+	if !strings.HasPrefix(header, "t=1700000000,v1=") {
+		t.Fatalf("unexpected header %q", header)
+	}
+	if err := verifyAt(body, header, time.Minute, now, "whsec_new"); err != nil {
+		t.Fatalf("expected valid signature, got %v", err)
+	}
+	if err := verifyAt([]byte(`{"payment":{"id":2}}`), header, time.Minute, now, "whsec_new"); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expected ErrInvalidSignature for altered body, got %v", err)
+	}
+	if err := verifyAt(body, header, time.Minute, now, "whsec_other"); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expected ErrInvalidSignature for wrong secret, got %v", err)
+	}
+}
+
+func TestVerifyRejectsReplayOutsideTolerance(t *testing.T) {
+	body := []byte(`{}`)
+	signedAt := time.Unix(1700000000, 0)
+	header := Sign(body, signedAt, "whsec_new")
+
+	if err := verifyAt(body, header, time.Minute, signedAt.Add(2*time.Minute), "whsec_new"); !errors.Is(err, ErrSignatureExpired) {
+		t.Fatalf("expected ErrSignatureExpired, got %v", err)
+	}
+	if err := verifyAt(body, header, time.Minute, signedAt.Add(-2*time.Minute), "whsec_new"); !errors.Is(err, ErrSignatureExpired) {
+		t.Fatalf("expected ErrSignatureExpired for future timestamp, got %v", err)
+	}
+}
+
+func TestVerifyDuringRotation(t *testing.T) {
+	body := []byte(`{}`)
+	now := time.Unix(1700000000, 0)
+	header := Sign(body, now, "whsec_new", "whsec_old")
+
+	if strings.Count(header, "v1=") != 2 {
+		t.Fatalf("expected one signature per active secret, got %q", header)
+	}
+	if err := verifyAt(body, header, time.Minute, now, "whsec_old"); err != nil {
+		t.Fatalf("receiver on the old secret should verify, got %v", err)
+	}
+	if err := verifyAt(body, header, time.Minute, now, "whsec_new"); err != nil {
+		t.Fatalf("receiver on the new secret should verify, got %v", err)
+	}
+
+	// A receiver that already rotated keeps accepting both while we still sign
+	// with the old secret only.
+	oldOnly := Sign(body, now, "whsec_old")
+	if err := verifyAt(body, oldOnly, time.Minute, now, "whsec_new", "whsec_old"); err != nil {
+		t.Fatalf("expected receiver with both secrets to verify, got %v", err)
+	}
+}
+
+func TestVerifyRejectsMalformedHeaders(t *testing.T) {
+	body := []byte(`{}`)
+	if err := Verify(body, "", time.Minute, "whsec"); !errors.Is(err, ErrMissingSignature) {
+		t.Fatalf("expected ErrMissingSignature, got %v", err)
+	}
+	if err := Verify(body, "t=abc,v1=00", time.Minute, "whsec"); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expected ErrInvalidSignature, got %v", err)
+	}
+	if err := Verify(body, Sign(body, time.Now()), time.Minute, "whsec"); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expected ErrInvalidSignature for header without v1, got %v", err)
+	}
+	if err := Verify(body, Sign(body, time.Now(), "whsec"), time.Minute); !errors.Is(err, ErrNoSecretsProvided) {
+		t.Fatalf("expected ErrNoSecretsProvided, got %v", err)
+	}
+}
+
+func TestVerifyRequestRestoresBody(t *testing.T) {
+	body := `{"payment":{"id":1}}`
+	req := httptest.NewRequest("POST", "/callbacks", strings.NewReader(body))
+	req.Header.Set(Header, Sign([]byte(body), time.Now(), "whsec"))
+
+	got, err := VerifyRequest(req, 0, "whsec")
+	if err != nil {
+		t.Fatalf("verify request failed: %v", err)
+	}
+	if string(got) != body {
+		t.Fatalf("unexpected body %q", got)
+	}
+	rest, _ := io.ReadAll(req.Body)
+	if string(rest) != body {
+		t.Fatalf("expected request body to be restored, got %q", rest)
+	}
+}
@@ -42,8 +42,10 @@ func (c *PaymentController) CreatePayment(ctx echo.Context) error {
 		switch {
 		case errors.Is(err, service.ErrInvalidRequest), errors.Is(err, service.ErrInvalidStatus), errors.Is(err, service.ErrProviderUnsupported):
 			return c.writeError(ctx, http.StatusBadRequest, err.Error())
-		case errors.Is(err, service.ErrPaymentAlreadyExists):
+		case errors.Is(err, service.ErrPaymentAlreadyExists), errors.Is(err, service.ErrIdempotencyConflict):
 			return c.writeError(ctx, http.StatusConflict, err.Error())
+		case errors.Is(err, service.ErrForbidden):
+			return c.writeError(ctx, http.StatusForbidden, err.Error())
 		default:
 			c.logger.WithError(err).Error("Create payment failed")
 			return c.writeError(ctx, http.StatusInternalServerError, "internal server error")
@@ -74,6 +76,58 @@ func (c *PaymentController) GetPayment(ctx echo.Context) error {
 	return ctx.JSON(http.StatusOK, &types.PaymentEnvelopeResponse{Payment: mapper.PaymentToProto(item)})
 }
 
+func (c *PaymentController) GetPaymentByRequestID(ctx echo.Context) error {
+	req, err := types.NewGetPaymentByRequestIDRequestFromContext(ctx)
+	if err != nil {
+		return c.writeError(ctx, http.StatusBadRequest, "invalid request")
+	}
+	if err := req.Validate(); err != nil {
+		return c.writeError(ctx, http.StatusBadRequest, err.Error())
+	}
+
+	item, err := c.paymentService.GetPaymentByRequestID(ctx.Request().Context(), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrPaymentNotFound):
+			return c.writeError(ctx, http.StatusNotFound, "payment not found")
+		case errors.Is(err, service.ErrInvalidRequest):
+			return c.writeError(ctx, http.StatusBadRequest, err.Error())
+		case errors.Is(err, service.ErrForbidden):
+			return c.writeError(ctx, http.StatusForbidden, err.Error())
+		default:
+			c.logger.WithError(err).Error("Get payment by request ID failed")
+			return c.writeError(ctx, http.StatusInternalServerError, "internal server error")
+		}
+	}
+
+	return ctx.JSON(http.StatusOK, &types.PaymentEnvelopeResponse{Payment: mapper.PaymentToProto(item)})
+}
+
+func (c *PaymentController) GetPaymentByProviderRef(ctx echo.Context) error {
+	req, err := types.NewGetPaymentByProviderRefRequestFromContext(ctx)
+	if err != nil {
+		return c.writeError(ctx, http.StatusBadRequest, "invalid request")
+	}
+	if err := req.Validate(); err != nil {
+		return c.writeError(ctx, http.StatusBadRequest, err.Error())
+	}
+
+	item, err := c.paymentService.GetPaymentByProviderRef(ctx.Request().Context(), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrPaymentNotFound):
+			return c.writeError(ctx, http.StatusNotFound, "payment not found")
+		case errors.Is(err, service.ErrInvalidRequest), errors.Is(err, service.ErrProviderUnsupported):
+			return c.writeError(ctx, http.StatusBadRequest, err.Error())
+		default:
+			c.logger.WithError(err).Error("Get payment by provider ref failed")
+			return c.writeError(ctx, http.StatusInternalServerError, "internal server error")
+		}
+	}
+
+	return ctx.JSON(http.StatusOK, &types.PaymentEnvelopeResponse{Payment: mapper.PaymentToProto(item)})
+}
+
 func (c *PaymentController) ListPayments(ctx echo.Context) error {
 	req, err := types.NewListPaymentsRequestFromContext(ctx)
 	if err != nil {
@@ -83,13 +137,66 @@ func (c *PaymentController) ListPayments(ctx echo.Context) error {
 		return c.writeError(ctx, http.StatusBadRequest, err.Error())
 	}
 
-	items, err := c.paymentService.ListPayments(ctx.Request().Context(), req)
+	items, nextPageToken, err := c.paymentService.ListPayments(ctx.Request().Context(), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidRequest):
+			return c.writeError(ctx, http.StatusBadRequest, err.Error())
+		case errors.Is(err, service.ErrForbidden):
+			return c.writeError(ctx, http.StatusForbidden, err.Error())
+		default:
+			c.logger.WithError(err).Error("List payments failed")
+			return c.writeError(ctx, http.StatusInternalServerError, "internal server error")
+		}
+	}
+
+	return ctx.JSON(http.StatusOK, &types.ListPaymentsResponse{Payments: mapper.PaymentsToProto(items), NextPageToken: nextPageToken})
+}
+
+func (c *PaymentController) ListPaymentCharges(ctx echo.Context) error {
+	req, err := types.NewListPaymentChargesRequestFromContext(ctx)
+	if err != nil {
+		return c.writeError(ctx, http.StatusBadRequest, "invalid request")
+	}
+	if err := req.Validate(); err != nil {
+		return c.writeError(ctx, http.StatusBadRequest, err.Error())
+	}
+
+	items, err := c.paymentService.ListPaymentCharges(ctx.Request().Context(), req.GetId())
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrPaymentNotFound):
+			return c.writeError(ctx, http.StatusNotFound, "payment not found")
+		case errors.Is(err, service.ErrInvalidRequest):
+			return c.writeError(ctx, http.StatusBadRequest, err.Error())
+		default:
+			c.logger.WithError(err).Error("List payment charges failed")
+			return c.writeError(ctx, http.StatusInternalServerError, "internal server error")
+		}
+	}
+
+	return ctx.JSON(http.StatusOK, &types.ListPaymentChargesResponse{Charges: mapper.ChargesToProto(items)})
+}
+
+func (c *PaymentController) ListPaymentEvents(ctx echo.Context) error {
+	req, err := types.NewListPaymentEventsRequestFromContext(ctx)
+	if err != nil {
+		return c.writeError(ctx, http.StatusBadRequest, "invalid request")
+	}
+	if err := req.Validate(); err != nil {
+		return c.writeError(ctx, http.StatusBadRequest, err.Error())
+	}
+
+	items, nextCursor, err := c.paymentService.ListPaymentEvents(ctx.Request().Context(), req)
 	if err != nil {
-		c.logger.WithError(err).Error("List payments failed")
+		if errors.Is(err, service.ErrPaymentNotFound) {
+			return c.writeError(ctx, http.StatusNotFound, "payment not found")
+		}
+		c.logger.WithError(err).Error("List payment events failed")
 		return c.writeError(ctx, http.StatusInternalServerError, "internal server error")
 	}
 
-	return ctx.JSON(http.StatusOK, &types.ListPaymentsResponse{Payments: mapper.PaymentsToProto(items)})
+	return ctx.JSON(http.StatusOK, &types.ListPaymentEventsResponse{Events: mapper.PaymentEventsToProto(items), NextCursor: nextCursor})
 }
 
 func (c *PaymentController) CancelPayment(ctx echo.Context) error {
@@ -108,6 +215,11 @@ func (c *PaymentController) CancelPayment(ctx echo.Context) error {
 			return c.writeError(ctx, http.StatusNotFound, "payment not found")
 		case errors.Is(err, service.ErrInvalidStatus):
 			return c.writeError(ctx, http.StatusBadRequest, err.Error())
+		case errors.Is(err, service.ErrInvalidTransition), errors.Is(err, service.ErrConcurrentUpdate):
+			return c.writeError(ctx, http.StatusConflict, err.Error())
+		case errors.Is(err, service.ErrProviderCancelFailed):
+			c.logger.WithError(err).Warn("Provider cancellation failed")
+			return c.writeError(ctx, http.StatusBadGateway, service.ErrProviderCancelFailed.Error())
 		default:
 			c.logger.WithError(err).Error("Cancel payment failed")
 			return c.writeError(ctx, http.StatusInternalServerError, "internal server error")
@@ -117,22 +229,165 @@ func (c *PaymentController) CancelPayment(ctx echo.Context) error {
 	return ctx.JSON(http.StatusOK, &types.PaymentEnvelopeResponse{Payment: mapper.PaymentToProto(item)})
 }
 
+func (c *PaymentController) RefundPayment(ctx echo.Context) error {
+	req, err := types.NewRefundPaymentRequestFromContext(ctx)
+	if err != nil {
+		return c.writeError(ctx, http.StatusBadRequest, "invalid request")
+	}
+	if err := req.Validate(); err != nil {
+		return c.writeError(ctx, http.StatusBadRequest, err.Error())
+	}
+
+	item, refund, err := c.paymentService.RefundPayment(ctx.Request().Context(), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrPaymentNotFound):
+			return c.writeError(ctx, http.StatusNotFound, "payment not found")
+		case errors.Is(err, service.ErrInvalidRequest), errors.Is(err, service.ErrInvalidStatus), errors.Is(err, service.ErrRefundExceedsAmount), errors.Is(err, service.ErrProviderUnsupported):
+			return c.writeError(ctx, http.StatusBadRequest, err.Error())
+		case errors.Is(err, service.ErrInvalidTransition), errors.Is(err, service.ErrConcurrentUpdate):
+			return c.writeError(ctx, http.StatusConflict, err.Error())
+		default:
+			c.logger.WithError(err).Error("Refund payment failed")
+			return c.writeError(ctx, http.StatusInternalServerError, "internal server error")
+		}
+	}
+
+	return ctx.JSON(http.StatusCreated, &types.RefundPaymentResponse{
+		Payment: mapper.PaymentToProto(item),
+		Refund:  mapper.RefundToProto(refund),
+	})
+}
+
+func (c *PaymentController) PauseSubscription(ctx echo.Context) error {
+	req, err := types.NewPauseSubscriptionRequestFromContext(ctx)
+	if err != nil {
+		return c.writeError(ctx, http.StatusBadRequest, "invalid request")
+	}
+	if err := req.Validate(); err != nil {
+		return c.writeError(ctx, http.StatusBadRequest, err.Error())
+	}
+
+	item, err := c.paymentService.PauseSubscription(ctx.Request().Context(), req)
+	if err != nil {
+		return c.writeSubscriptionError(ctx, err, "Pause subscription failed")
+	}
+
+	return ctx.JSON(http.StatusOK, &types.PaymentEnvelopeResponse{Payment: mapper.PaymentToProto(item)})
+}
+
+func (c *PaymentController) ResumeSubscription(ctx echo.Context) error {
+	req, err := types.NewResumeSubscriptionRequestFromContext(ctx)
+	if err != nil {
+		return c.writeError(ctx, http.StatusBadRequest, "invalid request")
+	}
+	if err := req.Validate(); err != nil {
+		return c.writeError(ctx, http.StatusBadRequest, err.Error())
+	}
+
+	item, err := c.paymentService.ResumeSubscription(ctx.Request().Context(), req)
+	if err != nil {
+		return c.writeSubscriptionError(ctx, err, "Resume subscription failed")
+	}
+
+	return ctx.JSON(http.StatusOK, &types.PaymentEnvelopeResponse{Payment: mapper.PaymentToProto(item)})
+}
+
+func (c *PaymentController) CancelSubscription(ctx echo.Context) error {
+	req, err := types.NewCancelSubscriptionRequestFromContext(ctx)
+	if err != nil {
+		return c.writeError(ctx, http.StatusBadRequest, "invalid request")
+	}
+	if err := req.Validate(); err != nil {
+		return c.writeError(ctx, http.StatusBadRequest, err.Error())
+	}
+
+	item, err := c.paymentService.CancelSubscription(ctx.Request().Context(), req)
+	if err != nil {
+		return c.writeSubscriptionError(ctx, err, "Cancel subscription failed")
+	}
+
+	return ctx.JSON(http.StatusOK, &types.PaymentEnvelopeResponse{Payment: mapper.PaymentToProto(item)})
+}
+
+func (c *PaymentController) UpdateSubscription(ctx echo.Context) error {
+	req, err := types.NewUpdateSubscriptionRequestFromContext(ctx)
+	if err != nil {
+		return c.writeError(ctx, http.StatusBadRequest, "invalid request")
+	}
+	if err := req.Validate(); err != nil {
+		return c.writeError(ctx, http.StatusBadRequest, err.Error())
+	}
+
+	item, err := c.paymentService.UpdateSubscription(ctx.Request().Context(), req)
+	if err != nil {
+		return c.writeSubscriptionError(ctx, err, "Update subscription failed")
+	}
+
+	return ctx.JSON(http.StatusOK, &types.PaymentEnvelopeResponse{Payment: mapper.PaymentToProto(item)})
+}
+
+func (c *PaymentController) writeSubscriptionError(ctx echo.Context, err error, message string) error {
+	switch {
+	case errors.Is(err, service.ErrPaymentNotFound):
+		return c.writeError(ctx, http.StatusNotFound, "payment not found")
+	case errors.Is(err, service.ErrInvalidRequest), errors.Is(err, service.ErrInvalidStatus), errors.Is(err, service.ErrProviderUnsupported):
+		return c.writeError(ctx, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrInvalidTransition), errors.Is(err, service.ErrConcurrentUpdate):
+		return c.writeError(ctx, http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrProviderRequestFailed):
+		c.logger.WithError(err).Warn(message)
+		return c.writeError(ctx, http.StatusBadGateway, service.ErrProviderRequestFailed.Error())
+	default:
+		c.logger.WithError(err).Error(message)
+		return c.writeError(ctx, http.StatusInternalServerError, "internal server error")
+	}
+}
+
+// HandleProviderCallback serves the public webhook route. The body is taken
+// as raw bytes and the signature from the header the provider declares.
 func (c *PaymentController) HandleProviderCallback(ctx echo.Context) error {
-	req, err := types.NewHandleProviderCallbackRequestFromContext(ctx)
+	contract, err := c.paymentService.ProviderCallbackContract(ctx.Param("provider"))
+	if err != nil {
+		return c.writeError(ctx, http.StatusBadRequest, err.Error())
+	}
+	if !contract.RawBody {
+		return c.writeError(ctx, http.StatusBadRequest, "provider callbacks must be forwarded")
+	}
+
+	req, err := types.NewHandleProviderCallbackRequestFromContext(ctx, contract.SignatureHeader)
+	if err != nil {
+		return c.writeError(ctx, http.StatusBadRequest, "invalid request body")
+	}
+
+	return c.handleProviderCallback(ctx, req)
+}
+
+// ForwardProviderCallback serves the internal forwarding route, which accepts
+// webhooks relayed as a {"payload","signature"} envelope.
+func (c *PaymentController) ForwardProviderCallback(ctx echo.Context) error {
+	req, err := types.NewForwardedProviderCallbackRequestFromContext(ctx)
 	if err != nil {
 		return c.writeError(ctx, http.StatusBadRequest, "invalid request body")
 	}
+
+	return c.handleProviderCallback(ctx, req)
+}
+
+func (c *PaymentController) handleProviderCallback(ctx echo.Context, req *types.HandleProviderCallbackRequest) error {
 	if err := req.Validate(); err != nil {
 		return c.writeError(ctx, http.StatusBadRequest, err.Error())
 	}
 
-	_, err = c.paymentService.HandleProviderCallback(ctx.Request().Context(), req)
+	_, err := c.paymentService.HandleProviderCallback(ctx.Request().Context(), req)
 	if err != nil {
 		switch {
 		case errors.Is(err, service.ErrProviderUnsupported), errors.Is(err, service.ErrCallbackRejected), errors.Is(err, service.ErrInvalidRequest):
 			return c.writeError(ctx, http.StatusBadRequest, err.Error())
 		case errors.Is(err, service.ErrPaymentNotFound):
 			return c.writeError(ctx, http.StatusNotFound, "payment not found")
+		case errors.Is(err, service.ErrConcurrentUpdate):
+			return c.writeError(ctx, http.StatusConflict, err.Error())
 		default:
 			c.logger.WithError(err).Error("Handle provider callback failed")
 			return c.writeError(ctx, http.StatusInternalServerError, "internal server error")
@@ -142,6 +397,106 @@ func (c *PaymentController) HandleProviderCallback(ctx echo.Context) error {
 	return ctx.JSON(http.StatusOK, &types.MessageResponse{Message: "Provider callback processed"})
 }
 
+func (c *PaymentController) ListProviderCallbacks(ctx echo.Context) error {
+	req, err := types.NewListProviderCallbacksRequestFromContext(ctx)
+	if err != nil {
+		return c.writeError(ctx, http.StatusBadRequest, "invalid request")
+	}
+	if err := req.Validate(); err != nil {
+		return c.writeError(ctx, http.StatusBadRequest, err.Error())
+	}
+
+	items, err := c.paymentService.ListProviderCallbacks(ctx.Request().Context(), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidRequest):
+			return c.writeError(ctx, http.StatusBadRequest, err.Error())
+		case errors.Is(err, service.ErrForbidden):
+			return c.writeError(ctx, http.StatusForbidden, err.Error())
+		}
+		c.logger.WithError(err).Error("List provider callbacks failed")
+		return c.writeError(ctx, http.StatusInternalServerError, "internal server error")
+	}
+
+	return ctx.JSON(http.StatusOK, &types.ListProviderCallbacksResponse{Callbacks: mapper.ProviderCallbacksToProto(items)})
+}
+
+func (c *PaymentController) ReplayProviderCallback(ctx echo.Context) error {
+	req, err := types.NewReplayProviderCallbackRequestFromContext(ctx)
+	if err != nil {
+		return c.writeError(ctx, http.StatusBadRequest, "invalid request")
+	}
+	if err := req.Validate(); err != nil {
+		return c.writeError(ctx, http.StatusBadRequest, err.Error())
+	}
+
+	item, err := c.paymentService.ReplayProviderCallback(ctx.Request().Context(), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrCallbackNotFound):
+			return c.writeError(ctx, http.StatusNotFound, "callback not found")
+		case errors.Is(err, service.ErrProviderUnsupported), errors.Is(err, service.ErrCallbackRejected), errors.Is(err, service.ErrInvalidRequest):
+			return c.writeError(ctx, http.StatusBadRequest, err.Error())
+		case errors.Is(err, service.ErrPaymentNotFound):
+			return c.writeError(ctx, http.StatusNotFound, "payment not found")
+		case errors.Is(err, service.ErrConcurrentUpdate):
+			return c.writeError(ctx, http.StatusConflict, err.Error())
+		case errors.Is(err, service.ErrForbidden):
+			return c.writeError(ctx, http.StatusForbidden, err.Error())
+		default:
+			c.logger.WithError(err).Error("Replay provider callback failed")
+			return c.writeError(ctx, http.StatusInternalServerError, "internal server error")
+		}
+	}
+
+	return ctx.JSON(http.StatusOK, &types.PaymentEnvelopeResponse{Payment: mapper.PaymentToProto(item)})
+}
+
+func (c *PaymentController) ListDeadLetterCallbacks(ctx echo.Context) error {
+	req, err := types.NewListDeadLetterCallbacksRequestFromContext(ctx)
+	if err != nil {
+		return c.writeError(ctx, http.StatusBadRequest, "invalid request")
+	}
+	if err := req.Validate(); err != nil {
+		return c.writeError(ctx, http.StatusBadRequest, err.Error())
+	}
+
+	items, err := c.paymentService.ListDeadLetterCallbacks(ctx.Request().Context(), req)
+	if err != nil {
+		if errors.Is(err, service.ErrForbidden) {
+			return c.writeError(ctx, http.StatusForbidden, err.Error())
+		}
+		c.logger.WithError(err).Error("List dead-letter callbacks failed")
+		return c.writeError(ctx, http.StatusInternalServerError, "internal server error")
+	}
+
+	return ctx.JSON(http.StatusOK, &types.ListDeadLetterCallbacksResponse{Callbacks: mapper.CallbackDeliveriesToProto(items)})
+}
+
+func (c *PaymentController) RedeliverCallbacks(ctx echo.Context) error {
+	req, err := types.NewRedeliverCallbacksRequestFromContext(ctx)
+	if err != nil {
+		return c.writeError(ctx, http.StatusBadRequest, "invalid request body")
+	}
+	if err := req.Validate(); err != nil {
+		return c.writeError(ctx, http.StatusBadRequest, err.Error())
+	}
+
+	count, err := c.paymentService.RedeliverCallbacks(ctx.Request().Context(), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidRequest):
+			return c.writeError(ctx, http.StatusBadRequest, err.Error())
+		case errors.Is(err, service.ErrForbidden):
+			return c.writeError(ctx, http.StatusForbidden, err.Error())
+		}
+		c.logger.WithError(err).Error("Redeliver callbacks failed")
+		return c.writeError(ctx, http.StatusInternalServerError, "internal server error")
+	}
+
+	return ctx.JSON(http.StatusOK, &types.RedeliverCallbacksResponse{Redelivered: count})
+}
+
 func (c *PaymentController) writeError(ctx echo.Context, statusCode int, message string) error {
 	return ctx.JSON(statusCode, &types.ErrorResponse{Error: message})
 }
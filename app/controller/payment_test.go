@@ -15,20 +15,21 @@ import (
 	"github.com/vibast-solutions/ms-go-payments/app/provider"
 	"github.com/vibast-solutions/ms-go-payments/app/repository"
 	"github.com/vibast-solutions/ms-go-payments/app/service"
+	"github.com/vibast-solutions/ms-go-payments/app/sink"
 	"github.com/vibast-solutions/ms-go-payments/app/types"
 	"github.com/vibast-solutions/ms-go-payments/config"
 )
 
 type controllerPaymentRepo struct {
-	createFn                 func(ctx context.Context, payment *entity.Payment) error
-	updateFn                 func(ctx context.Context, payment *entity.Payment) error
-	findByIDFn               func(ctx context.Context, id uint64) (*entity.Payment, error)
-	findByCallerRequestIDFn  func(ctx context.Context, callerService, requestID string) (*entity.Payment, error)
-	findByCallbackHashFn     func(ctx context.Context, provider int32, callbackHash string) (*entity.Payment, error)
-	listFn                   func(ctx context.Context, filter repository.PaymentFilter) ([]*entity.Payment, error)
-	listDueCallbackDispatchFn func(ctx context.Context, now time.Time, limit int32) ([]*entity.Payment, error)
-	listExpiredPendingFn     func(ctx context.Context, cutoff time.Time, limit int32) ([]*entity.Payment, error)
-	listForReconcileFn       func(ctx context.Context, before time.Time, limit int32) ([]*entity.Payment, error)
+	createFn                func(ctx context.Context, payment *entity.Payment) error
+	updateFn                func(ctx context.Context, payment *entity.Payment) error
+	findByIDFn              func(ctx context.Context, id uint64) (*entity.Payment, error)
+	findByCallerRequestIDFn func(ctx context.Context, callerService, requestID string) (*entity.Payment, error)
+	findByCallbackHashFn    func(ctx context.Context, provider int32, callbackHash string) (*entity.Payment, error)
+	findByProviderRefFn     func(ctx context.Context, provider int32, providerRef string) (*entity.Payment, error)
+	listFn                  func(ctx context.Context, filter repository.PaymentFilter) ([]*entity.Payment, error)
+	listExpiredPendingFn    func(ctx context.Context, cutoff time.Time, limit int32) ([]*entity.Payment, error)
+	listForReconcileFn      func(ctx context.Context, before time.Time, limit int32) ([]*entity.Payment, error)
 }
 
 func (r *controllerPaymentRepo) Create(ctx context.Context, payment *entity.Payment) error {
@@ -66,16 +67,20 @@ func (r *controllerPaymentRepo) FindByCallbackHash(ctx context.Context, provider
 	return nil, nil
 }
 
-func (r *controllerPaymentRepo) List(ctx context.Context, filter repository.PaymentFilter) ([]*entity.Payment, error) {
-	if r.listFn != nil {
-		return r.listFn(ctx, filter)
+func (r *controllerPaymentRepo) FindByProviderSubscriptionID(context.Context, int32, string) (*entity.Payment, error) {
+	return nil, nil
+}
+
+func (r *controllerPaymentRepo) FindByProviderPaymentID(ctx context.Context, provider int32, providerPaymentID string) (*entity.Payment, error) {
+	if r.findByProviderRefFn != nil {
+		return r.findByProviderRefFn(ctx, provider, providerPaymentID)
 	}
-	return []*entity.Payment{}, nil
+	return nil, nil
 }
 
-func (r *controllerPaymentRepo) ListDueCallbackDispatch(ctx context.Context, now time.Time, limit int32) ([]*entity.Payment, error) {
-	if r.listDueCallbackDispatchFn != nil {
-		return r.listDueCallbackDispatchFn(ctx, now, limit)
+func (r *controllerPaymentRepo) List(ctx context.Context, filter repository.PaymentFilter) ([]*entity.Payment, error) {
+	if r.listFn != nil {
+		return r.listFn(ctx, filter)
 	}
 	return []*entity.Payment{}, nil
 }
@@ -94,18 +99,125 @@ func (r *controllerPaymentRepo) ListForReconcile(ctx context.Context, before tim
 	return []*entity.Payment{}, nil
 }
 
+func (r *controllerPaymentRepo) ListProvisional(context.Context, time.Time, int32) ([]*entity.Payment, error) {
+	return []*entity.Payment{}, nil
+}
+
 type controllerEventRepo struct{}
 
 func (r *controllerEventRepo) Create(context.Context, *entity.PaymentEvent) error {
 	return nil
 }
 
+func (r *controllerEventRepo) ListByPayment(context.Context, repository.PaymentEventFilter) ([]*entity.PaymentEvent, error) {
+	return nil, nil
+}
+
 type controllerCallbackRepo struct{}
 
 func (r *controllerCallbackRepo) Create(context.Context, *entity.PaymentCallback) error {
 	return nil
 }
 
+func (r *controllerCallbackRepo) FindByID(context.Context, uint64) (*entity.PaymentCallback, error) {
+	return nil, nil
+}
+
+func (r *controllerCallbackRepo) List(context.Context, repository.PaymentCallbackFilter) ([]*entity.PaymentCallback, error) {
+	return nil, nil
+}
+
+type controllerRefundRepo struct{}
+
+func (r *controllerRefundRepo) Create(context.Context, *entity.PaymentRefund) error {
+	return nil
+}
+
+func (r *controllerRefundRepo) Update(context.Context, *entity.PaymentRefund) error {
+	return nil
+}
+
+func (r *controllerRefundRepo) FindByPaymentRequestID(context.Context, uint64, string) (*entity.PaymentRefund, error) {
+	return nil, nil
+}
+
+func (r *controllerRefundRepo) FindByProviderRefundID(context.Context, uint64, string) (*entity.PaymentRefund, error) {
+	return nil, nil
+}
+
+func (r *controllerRefundRepo) ListByPaymentID(context.Context, uint64) ([]*entity.PaymentRefund, error) {
+	return []*entity.PaymentRefund{}, nil
+}
+
+type controllerChargeRepo struct{}
+
+func (r *controllerChargeRepo) Create(context.Context, *entity.PaymentCharge) error {
+	return nil
+}
+
+func (r *controllerChargeRepo) Update(context.Context, *entity.PaymentCharge) error {
+	return nil
+}
+
+func (r *controllerChargeRepo) FindByProviderInvoiceID(context.Context, uint64, string) (*entity.PaymentCharge, error) {
+	return nil, nil
+}
+
+func (r *controllerChargeRepo) ListByPaymentID(context.Context, uint64) ([]*entity.PaymentCharge, error) {
+	return []*entity.PaymentCharge{}, nil
+}
+
+type controllerProviderEventRepo struct{}
+
+func (r *controllerProviderEventRepo) Create(context.Context, *entity.PaymentProviderEvent) error {
+	return nil
+}
+
+func (r *controllerProviderEventRepo) FindByProviderEventID(context.Context, int32, string) (*entity.PaymentProviderEvent, error) {
+	return nil, nil
+}
+
+type controllerOutboxRepo struct {
+	listDeadLettersFn    func(ctx context.Context, filter repository.CallbackOutboxFilter) ([]*entity.PaymentCallbackOutbox, error)
+	requeueDeadLettersFn func(ctx context.Context, filter repository.CallbackOutboxFilter, now time.Time) (int64, error)
+}
+
+func (r *controllerOutboxRepo) Create(context.Context, *entity.PaymentCallbackOutbox) error {
+	return nil
+}
+
+func (r *controllerOutboxRepo) Update(context.Context, *entity.PaymentCallbackOutbox) error {
+	return nil
+}
+
+func (r *controllerOutboxRepo) ListDue(context.Context, time.Time, int32) ([]*entity.PaymentCallbackOutbox, error) {
+	return []*entity.PaymentCallbackOutbox{}, nil
+}
+
+func (r *controllerOutboxRepo) Claim(context.Context, *entity.PaymentCallbackOutbox, time.Time, time.Time) (bool, error) {
+	return true, nil
+}
+
+func (r *controllerOutboxRepo) ListDeadLetters(ctx context.Context, filter repository.CallbackOutboxFilter) ([]*entity.PaymentCallbackOutbox, error) {
+	if r.listDeadLettersFn != nil {
+		return r.listDeadLettersFn(ctx, filter)
+	}
+	return []*entity.PaymentCallbackOutbox{}, nil
+}
+
+func (r *controllerOutboxRepo) RequeueDeadLetters(ctx context.Context, filter repository.CallbackOutboxFilter, now time.Time) (int64, error) {
+	if r.requeueDeadLettersFn != nil {
+		return r.requeueDeadLettersFn(ctx, filter, now)
+	}
+	return 0, nil
+}
+
+type controllerUnitOfWork struct{}
+
+func (u *controllerUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
 type controllerProvider struct {
 	createOutput *provider.CreateOutput
 	createErr    error
@@ -134,6 +246,10 @@ func (p *controllerProvider) CreatePayment(context.Context, *provider.CreateInpu
 	}, nil
 }
 
+func (p *controllerProvider) CallbackContract() provider.CallbackContract {
+	return provider.CallbackContract{SignatureHeader: "Stripe-Signature", RawBody: true}
+}
+
 func (p *controllerProvider) VerifyAndParseCallback(context.Context, []byte, string) (*provider.CallbackEvent, error) {
 	if p.callbackErr != nil {
 		return nil, p.callbackErr
@@ -144,18 +260,62 @@ func (p *controllerProvider) VerifyAndParseCallback(context.Context, []byte, str
 	return &provider.CallbackEvent{EventType: "checkout.session.completed", NewStatus: int32(types.PaymentStatus_PAYMENT_STATUS_PAID)}, nil
 }
 
-func (p *controllerProvider) GetPaymentStatus(context.Context, string) (int32, error) {
+func (p *controllerProvider) ParseCallback(context.Context, []byte) (*provider.CallbackEvent, error) {
+	if p.callbackEvt != nil {
+		return p.callbackEvt, nil
+	}
+	return &provider.CallbackEvent{EventType: "checkout.session.completed", NewStatus: int32(types.PaymentStatus_PAYMENT_STATUS_PAID)}, nil
+}
+
+func (p *controllerProvider) GetPaymentStatus(context.Context, *provider.StatusInput) (int32, error) {
 	return 0, nil
 }
 
+func (p *controllerProvider) ListEvents(context.Context, *provider.ListEventsInput) (*provider.ListEventsOutput, error) {
+	return &provider.ListEventsOutput{}, nil
+}
+
+func (p *controllerProvider) Refund(context.Context, *provider.RefundInput) (*provider.RefundOutput, error) {
+	return &provider.RefundOutput{ProviderRefundID: "re_test_123", Status: int32(types.RefundStatus_REFUND_STATUS_SUCCEEDED)}, nil
+}
+
+func (p *controllerProvider) Cancel(context.Context, *provider.CancelInput) error {
+	return nil
+}
+
+func (p *controllerProvider) PauseSubscription(context.Context, *provider.SubscriptionInput) error {
+	return nil
+}
+
+func (p *controllerProvider) ResumeSubscription(context.Context, *provider.SubscriptionInput) error {
+	return nil
+}
+
+func (p *controllerProvider) CancelSubscription(context.Context, *provider.SubscriptionInput) error {
+	return nil
+}
+
+func (p *controllerProvider) UpdateSubscription(context.Context, *provider.UpdateSubscriptionInput) error {
+	return nil
+}
+
 func newControllerForTest(repo *controllerPaymentRepo, p provider.Provider) *PaymentController {
+	return newControllerWithOutboxForTest(repo, p, &controllerOutboxRepo{})
+}
+
+func newControllerWithOutboxForTest(repo *controllerPaymentRepo, p provider.Provider, outboxRepo *controllerOutboxRepo) *PaymentController {
 	paymentService := service.NewPaymentService(
 		repo,
 		&controllerEventRepo{},
 		&controllerCallbackRepo{},
+		&controllerRefundRepo{},
+		&controllerChargeRepo{},
+		&controllerProviderEventRepo{},
+		outboxRepo,
+		&controllerUnitOfWork{},
 		provider.NewRegistry(p),
+		sink.NewRegistry(sink.NewHTTPSink(nil, "")),
 		config.PaymentsConfig{CallbackMaxAttempts: 3, CallbackRetryInterval: time.Minute, PendingTimeout: time.Hour, ReconcileStaleAfter: time.Minute, JobBatchSize: 100},
-		"payments-app-key",
 	)
 	return NewPaymentController(paymentService)
 }
@@ -203,6 +363,106 @@ func TestCreatePaymentSuccess(t *testing.T) {
 	}
 }
 
+func TestCreatePaymentIdempotencyConflict(t *testing.T) {
+	repo := &controllerPaymentRepo{findByCallerRequestIDFn: func(context.Context, string, string) (*entity.Payment, error) {
+		return &entity.Payment{
+			ID:                 22,
+			RequestID:          "req-1",
+			CallerService:      "subscriptions-service",
+			ResourceType:       "subscription",
+			ResourceID:         "sub-1",
+			AmountCents:        500,
+			Currency:           "USD",
+			Status:             2,
+			PaymentMethod:      1,
+			PaymentType:        1,
+			Provider:           1,
+			RequestFingerprint: "fingerprint-of-the-first-request",
+		}, nil
+	}}
+	ctrl := newControllerForTest(repo, &controllerProvider{})
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/payments", bytes.NewBufferString(`{"request_id":"req-1","caller_service":"subscriptions-service","resource_type":"subscription","resource_id":"sub-1","amount_cents":1000,"currency":"USD","payment_method":1,"payment_type":1,"status_callback_url":"https://caller.example/callback"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(echo.HeaderXRequestID, "req-1")
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+
+	_ = ctrl.CreatePayment(ctx)
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d body=%s", rec.Code, rec.Body.String())
+	}
+	if !bytes.Contains(rec.Body.Bytes(), []byte("different amount_cents")) {
+		t.Fatalf("expected differing fields in the error, got %s", rec.Body.String())
+	}
+}
+
+func TestCreatePaymentForAnotherCallerIsForbidden(t *testing.T) {
+	ctrl := newControllerForTest(&controllerPaymentRepo{}, &controllerProvider{})
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/payments", bytes.NewBufferString(`{"request_id":"req-1","caller_service":"subscriptions-service","resource_type":"subscription","resource_id":"sub-1","amount_cents":1000,"currency":"USD","payment_method":1,"payment_type":1,"status_callback_url":"https://caller.example/callback"}`))
+	req = req.WithContext(service.WithCallerService(req.Context(), "orders-service"))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(echo.HeaderXRequestID, "req-1")
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+
+	_ = ctrl.CreatePayment(ctx)
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d body=%s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestGetPaymentOfAnotherCallerNotFound(t *testing.T) {
+	ctrl := newControllerForTest(&controllerPaymentRepo{findByIDFn: func(context.Context, uint64) (*entity.Payment, error) {
+		return &entity.Payment{ID: 9, CallerService: "subscriptions-service"}, nil
+	}}, &controllerProvider{})
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/payments/9", nil)
+	req = req.WithContext(service.WithCallerService(req.Context(), "orders-service"))
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("9")
+
+	_ = ctrl.GetPayment(ctx)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rec.Code)
+	}
+}
+
+func TestGetPaymentByRequestIDDefaultsToCallerService(t *testing.T) {
+	var lookedUp string
+	ctrl := newControllerForTest(&controllerPaymentRepo{findByCallerRequestIDFn: func(_ context.Context, callerService, requestID string) (*entity.Payment, error) {
+		lookedUp = callerService + "/" + requestID
+		return &entity.Payment{ID: 5, RequestID: requestID, CallerService: callerService, Metadata: map[string]string{}}, nil
+	}}, &controllerProvider{})
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/payments/by-request-id?request_id=req-5", nil)
+	req = req.WithContext(service.WithCallerService(req.Context(), "orders-service"))
+	rec := httptest.NewRecorder()
+
+	_ = ctrl.GetPaymentByRequestID(e.NewContext(req, rec))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d body=%s", rec.Code, rec.Body.String())
+	}
+	if lookedUp != "orders-service/req-5" {
+		t.Fatalf("expected lookup under the authenticated caller, got %q", lookedUp)
+	}
+}
+
+func TestGetPaymentByProviderRefRequiresOneRef(t *testing.T) {
+	ctrl := newControllerForTest(&controllerPaymentRepo{}, &controllerProvider{})
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/payments/by-provider-ref?provider=stripe&provider_payment_id=cs_1&provider_subscription_id=sub_1", nil)
+	rec := httptest.NewRecorder()
+
+	_ = ctrl.GetPaymentByProviderRef(e.NewContext(req, rec))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d body=%s", rec.Code, rec.Body.String())
+	}
+}
+
 func TestGetPaymentNotFound(t *testing.T) {
 	ctrl := newControllerForTest(&controllerPaymentRepo{findByIDFn: func(context.Context, uint64) (*entity.Payment, error) { return nil, nil }}, &controllerProvider{})
 	e := echo.New()
@@ -252,6 +512,52 @@ func TestListPaymentsSuccess(t *testing.T) {
 	}
 }
 
+func TestListPaymentsReturnsNextPageToken(t *testing.T) {
+	var filters []repository.PaymentFilter
+	ctrl := newControllerForTest(&controllerPaymentRepo{listFn: func(_ context.Context, filter repository.PaymentFilter) ([]*entity.Payment, error) {
+		filters = append(filters, filter)
+		items := make([]*entity.Payment, 0, filter.Limit)
+		for id := uint64(10); id > 0 && len(items) < int(filter.Limit); id-- {
+			if filter.BeforeID == 0 || id < filter.BeforeID {
+				items = append(items, &entity.Payment{ID: id, Metadata: map[string]string{}})
+			}
+		}
+		return items, nil
+	}}, &controllerProvider{})
+	e := echo.New()
+
+	req := httptest.NewRequest(http.MethodGet, "/payments?limit=3", nil)
+	rec := httptest.NewRecorder()
+	_ = ctrl.ListPayments(e.NewContext(req, rec))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d body=%s", rec.Code, rec.Body.String())
+	}
+	var page types.ListPaymentsResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &page); err != nil {
+		t.Fatalf("decode response failed: %v", err)
+	}
+	if len(page.GetPayments()) != 3 || page.GetNextPageToken() == "" {
+		t.Fatalf("expected a full page with a next token, got %s", rec.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/payments?limit=3&page_token="+page.GetNextPageToken(), nil)
+	rec = httptest.NewRecorder()
+	_ = ctrl.ListPayments(e.NewContext(req, rec))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d body=%s", rec.Code, rec.Body.String())
+	}
+	if len(filters) != 2 || filters[1].BeforeID != 8 {
+		t.Fatalf("expected second page to continue before payment 8, got %+v", filters)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/payments?page_token=garbage", nil)
+	rec = httptest.NewRecorder()
+	_ = ctrl.ListPayments(e.NewContext(req, rec))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a malformed page token, got %d", rec.Code)
+	}
+}
+
 func TestCancelPaymentNotFound(t *testing.T) {
 	ctrl := newControllerForTest(&controllerPaymentRepo{findByIDFn: func(context.Context, uint64) (*entity.Payment, error) { return nil, nil }}, &controllerProvider{})
 	e := echo.New()
@@ -285,3 +591,150 @@ func TestHandleProviderCallbackRejected(t *testing.T) {
 		t.Fatalf("expected 400, got %d", rec.Code)
 	}
 }
+
+func TestListDeadLetterCallbacksSuccess(t *testing.T) {
+	lastError := "callback endpoint returned status=500"
+	var gotFilter repository.CallbackOutboxFilter
+	outboxRepo := &controllerOutboxRepo{listDeadLettersFn: func(_ context.Context, filter repository.CallbackOutboxFilter) ([]*entity.PaymentCallbackOutbox, error) {
+		gotFilter = filter
+		return []*entity.PaymentCallbackOutbox{{
+			ID:            4,
+			PaymentID:     7,
+			CallerService: "subscriptions-service",
+			EventType:     "payment.status_changed",
+			Status:        entity.CallbackDeliveryFailed,
+			Attempts:      10,
+			LastError:     &lastError,
+		}}, nil
+	}}
+	ctrl := newControllerWithOutboxForTest(&controllerPaymentRepo{}, &controllerProvider{}, outboxRepo)
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/callbacks/dead-letters?caller_service=subscriptions-service&limit=20", nil)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+
+	_ = ctrl.ListDeadLetterCallbacks(ctx)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d body=%s", rec.Code, rec.Body.String())
+	}
+	if gotFilter.CallerService != "subscriptions-service" || gotFilter.Limit != 20 {
+		t.Fatalf("unexpected filter: %+v", gotFilter)
+	}
+
+	var payload types.ListDeadLetterCallbacksResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	if len(payload.GetCallbacks()) != 1 || payload.GetCallbacks()[0].GetLastError() != lastError {
+		t.Fatalf("unexpected dead letters: %+v", payload.GetCallbacks())
+	}
+}
+
+func TestRedeliverCallbacksRequiresFilterOrAll(t *testing.T) {
+	ctrl := newControllerForTest(&controllerPaymentRepo{}, &controllerProvider{})
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/callbacks/redeliver", bytes.NewBufferString(`{}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+
+	_ = ctrl.RedeliverCallbacks(ctx)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rec.Code)
+	}
+}
+
+func TestRedeliverCallbacksByPayment(t *testing.T) {
+	var gotFilter repository.CallbackOutboxFilter
+	outboxRepo := &controllerOutboxRepo{requeueDeadLettersFn: func(_ context.Context, filter repository.CallbackOutboxFilter, _ time.Time) (int64, error) {
+		gotFilter = filter
+		return 2, nil
+	}}
+	ctrl := newControllerWithOutboxForTest(&controllerPaymentRepo{}, &controllerProvider{}, outboxRepo)
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/callbacks/redeliver", bytes.NewBufferString(`{"payment_id":7}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+
+	_ = ctrl.RedeliverCallbacks(ctx)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d body=%s", rec.Code, rec.Body.String())
+	}
+	if gotFilter.PaymentID != 7 {
+		t.Fatalf("unexpected filter: %+v", gotFilter)
+	}
+
+	var payload types.RedeliverCallbacksResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	if payload.GetRedelivered() != 2 {
+		t.Fatalf("expected redelivered=2, got %d", payload.GetRedelivered())
+	}
+}
+
+func TestListPaymentEventsNotFound(t *testing.T) {
+	ctrl := newControllerForTest(&controllerPaymentRepo{}, &controllerProvider{})
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/payments/7/events", nil)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("7")
+
+	_ = ctrl.ListPaymentEvents(ctx)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rec.Code)
+	}
+}
+
+func TestReplayProviderCallbackNotFound(t *testing.T) {
+	ctrl := newControllerForTest(&controllerPaymentRepo{}, &controllerProvider{})
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/callbacks/inbox/5/replay", bytes.NewBufferString(`{"skip_signature":true}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("5")
+
+	_ = ctrl.ReplayProviderCallback(ctx)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rec.Code)
+	}
+}
+
+func TestForwardProviderCallbackRejectsRawBody(t *testing.T) {
+	ctrl := newControllerForTest(&controllerPaymentRepo{}, &controllerProvider{})
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/forwarded/stripe/hash-1", bytes.NewBufferString(`{"id":"evt_1"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(echo.HeaderXRequestID, "req-callback-1")
+	req.Header.Set("Stripe-Signature", "sig")
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetParamNames("provider", "hash")
+	ctx.SetParamValues("stripe", "hash-1")
+
+	_ = ctrl.ForwardProviderCallback(ctx)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rec.Code)
+	}
+}
+
+func TestHandleProviderCallbackUnsupportedProvider(t *testing.T) {
+	ctrl := newControllerForTest(&controllerPaymentRepo{}, &controllerProvider{})
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/providers/paypal/hash-1", bytes.NewBufferString(`{"id":"evt_1"}`))
+	req.Header.Set(echo.HeaderXRequestID, "req-callback-1")
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetParamNames("provider", "hash")
+	ctx.SetParamValues("paypal", "hash-1")
+
+	_ = ctrl.HandleProviderCallback(ctx)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rec.Code)
+	}
+}
@@ -29,6 +29,13 @@ type Payment struct {
 
 	RecurringInterval      *string
 	RecurringIntervalCount *int32
+	// SubscriptionAmountCents is the amount billed per cycle after a
+	// subscription update; nil means AmountCents. AmountCents itself stays
+	// the amount that was charged.
+	SubscriptionAmountCents *int64
+
+	SubscriptionPaused bool
+	CancelAtPeriodEnd  bool
 
 	ProviderPaymentID      *string
 	ProviderSubscriptionID *string
@@ -39,15 +46,22 @@ type Payment struct {
 
 	StatusCallbackURL string
 
+	// SuccessURL and CancelURL are kept so an interrupted create can be
+	// resumed with the exact same provider request.
+	SuccessURL string
+	CancelURL  string
+
+	// RequestFingerprint hashes the significant create fields so a reused
+	// request_id with different fields can be rejected.
+	RequestFingerprint string
+
 	RefundedCents   int64
 	RefundableCents int64
 
 	Metadata map[string]string
 
-	CallbackDeliveryStatus   int32
-	CallbackDeliveryAttempts int32
-	CallbackDeliveryNextAt   *time.Time
-	CallbackDeliveryLastErr  *string
+	// Version is bumped on every update and used for optimistic locking.
+	Version uint64
 
 	CreatedAt time.Time
 	UpdatedAt time.Time
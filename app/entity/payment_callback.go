@@ -7,12 +7,16 @@ type PaymentCallback struct {
 
 	PaymentID *uint64
 
-	Provider     string
-	CallbackHash string
-	Signature    string
-	PayloadJSON  string
-	Status       int32
-	Error        *string
+	Provider        string
+	CallbackHash    string
+	ProviderEventID *string
+	Signature       string
+	PayloadJSON     string
+	Status          int32
+	Error           *string
+	// SignatureVerified is set once the webhook signature was checked, so
+	// rows rejected before or without that check are never replayed unsigned.
+	SignatureVerified bool
 
 	CreatedAt time.Time
 	UpdatedAt time.Time
@@ -0,0 +1,28 @@
+package entity
+
+import "time"
+
+// PaymentCallbackOutbox is one queued status callback. Rows are written in the
+// same transaction as the change they announce and drained in id order per
+// payment. Status uses the CallbackDelivery* values.
+type PaymentCallbackOutbox struct {
+	ID uint64
+
+	PaymentID uint64
+	ChargeID  *uint64
+
+	CallerService string
+	EventType     string
+	RequestID     string
+	Destination   string
+	PayloadJSON   string
+
+	Status        int32
+	Attempts      int32
+	NextAttemptAt *time.Time
+	LastError     *string
+	DeliveredAt   *time.Time
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -0,0 +1,20 @@
+package entity
+
+import "time"
+
+type PaymentCharge struct {
+	ID uint64
+
+	PaymentID         uint64
+	ProviderInvoiceID string
+
+	AmountCents int64
+	Currency    string
+	Status      int32
+
+	PeriodStart *time.Time
+	PeriodEnd   *time.Time
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -0,0 +1,17 @@
+package entity
+
+import "time"
+
+// PaymentProviderEvent records a provider webhook event that has been applied,
+// so redelivered events can be recognised and skipped.
+type PaymentProviderEvent struct {
+	ID uint64
+
+	Provider        int32
+	ProviderEventID string
+
+	PaymentID uint64
+	EventType string
+
+	CreatedAt time.Time
+}
@@ -0,0 +1,20 @@
+package entity
+
+import "time"
+
+type PaymentRefund struct {
+	ID uint64
+
+	PaymentID uint64
+	RequestID string
+
+	AmountCents int64
+	Currency    string
+
+	Status           int32
+	Reason           *string
+	ProviderRefundID *string
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/sirupsen/logrus"
+	authmiddleware "github.com/vibast-solutions/lib-go-auth/middleware"
+	"github.com/vibast-solutions/ms-go-payments/app/service"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
@@ -31,6 +33,96 @@ func RequestIDInterceptor() grpc.UnaryServerInterceptor {
 	}
 }
 
+// CallerInterceptor carries the caller service resolved by the internal auth
+// interceptor into the service layer, which scopes payment access to it. It
+// must be chained after the auth interceptor.
+func CallerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if callerService, err := authmiddleware.CallerServiceFromGRPCContext(ctx); err == nil {
+			ctx = service.WithCallerService(ctx, callerService)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// contextServerStream replaces the context of a server stream.
+type contextServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func StreamRequestIDInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := ss.Context()
+		requestID := strings.TrimSpace(requestIDFromMetadata(ctx))
+		if requestID == "" {
+			return status.Error(codes.InvalidArgument, "x-request-id header is required")
+		}
+
+		_ = ss.SetHeader(metadata.Pairs(requestIDHeader, requestID))
+		return handler(srv, &contextServerStream{ServerStream: ss, ctx: context.WithValue(ctx, requestIDContextKey{}, requestID)})
+	}
+}
+
+// StreamCallerInterceptor is the streaming counterpart of CallerInterceptor.
+func StreamCallerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		callerService, err := authmiddleware.CallerServiceFromGRPCContext(ss.Context())
+		if err != nil {
+			return handler(srv, ss)
+		}
+		return handler(srv, &contextServerStream{ServerStream: ss, ctx: service.WithCallerService(ss.Context(), callerService)})
+	}
+}
+
+func StreamLoggingInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		latency := time.Since(start)
+
+		fields := logrus.Fields{
+			"method":     info.FullMethod,
+			"grpc_code":  status.Code(err).String(),
+			"latency":    latency.String(),
+			"latency_ns": latency.Nanoseconds(),
+		}
+
+		if requestID := RequestIDFromContext(ss.Context()); requestID != "" {
+			fields["request_id"] = requestID
+		}
+
+		entry := logrus.WithFields(fields)
+		if err != nil {
+			entry.WithError(err).Warn("grpc_stream")
+			return err
+		}
+		entry.Info("grpc_stream")
+		return nil
+	}
+}
+
+func StreamRecoveryInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				entry := logrus.WithField("method", info.FullMethod).WithField("panic", rec)
+				if requestID := RequestIDFromContext(ss.Context()); requestID != "" {
+					entry = entry.WithField("request_id", requestID)
+				}
+				entry.WithField("stack", string(debug.Stack())).Error("grpc_panic_recovered")
+				err = status.Error(codes.Internal, "internal server error")
+			}
+		}()
+
+		return handler(srv, ss)
+	}
+}
+
 func LoggingInterceptor() grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
 		start := time.Now()
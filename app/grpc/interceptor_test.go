@@ -65,3 +65,38 @@ func TestLoggingInterceptorPassThrough(t *testing.T) {
 		t.Fatalf("unexpected response: %v", resp)
 	}
 }
+
+type interceptorServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *interceptorServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *interceptorServerStream) SetHeader(metadata.MD) error {
+	return nil
+}
+
+func TestStreamRequestIDInterceptorCarriesHeader(t *testing.T) {
+	interceptor := StreamRequestIDInterceptor()
+
+	err := interceptor(nil, &interceptorServerStream{ctx: context.Background()}, &grpc.StreamServerInfo{}, func(interface{}, grpc.ServerStream) error {
+		return nil
+	})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected InvalidArgument for missing x-request-id, got %v", err)
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(requestIDHeader, "grpc-stream"))
+	err = interceptor(nil, &interceptorServerStream{ctx: ctx}, &grpc.StreamServerInfo{}, func(_ interface{}, ss grpc.ServerStream) error {
+		if got := RequestIDFromContext(ss.Context()); got != "grpc-stream" {
+			t.Fatalf("expected grpc-stream, got %q", got)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
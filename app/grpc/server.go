@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 
+	"github.com/vibast-solutions/ms-go-payments/app/entity"
 	"github.com/vibast-solutions/ms-go-payments/app/mapper"
 	"github.com/vibast-solutions/ms-go-payments/app/service"
 	"github.com/vibast-solutions/ms-go-payments/app/types"
@@ -36,8 +37,10 @@ func (s *Server) CreatePayment(ctx context.Context, req *types.CreatePaymentRequ
 		switch {
 		case errors.Is(err, service.ErrInvalidRequest), errors.Is(err, service.ErrInvalidStatus), errors.Is(err, service.ErrProviderUnsupported):
 			return nil, status.Error(codes.InvalidArgument, err.Error())
-		case errors.Is(err, service.ErrPaymentAlreadyExists):
+		case errors.Is(err, service.ErrPaymentAlreadyExists), errors.Is(err, service.ErrIdempotencyConflict):
 			return nil, status.Error(codes.AlreadyExists, err.Error())
+		case errors.Is(err, service.ErrForbidden):
+			return nil, status.Error(codes.PermissionDenied, err.Error())
 		default:
 			l.WithError(err).Error("Create payment failed")
 			return nil, status.Error(codes.Internal, "internal server error")
@@ -63,17 +66,127 @@ func (s *Server) GetPayment(ctx context.Context, req *types.GetPaymentRequest) (
 	return &types.PaymentEnvelopeResponse{Payment: mapper.PaymentToProto(item)}, nil
 }
 
+func (s *Server) GetPaymentByRequestID(ctx context.Context, req *types.GetPaymentByRequestIDRequest) (*types.PaymentEnvelopeResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	item, err := s.paymentService.GetPaymentByRequestID(ctx, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrPaymentNotFound):
+			return nil, status.Error(codes.NotFound, "payment not found")
+		case errors.Is(err, service.ErrInvalidRequest):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		case errors.Is(err, service.ErrForbidden):
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		default:
+			return nil, status.Error(codes.Internal, "internal server error")
+		}
+	}
+
+	return &types.PaymentEnvelopeResponse{Payment: mapper.PaymentToProto(item)}, nil
+}
+
+func (s *Server) GetPaymentByProviderRef(ctx context.Context, req *types.GetPaymentByProviderRefRequest) (*types.PaymentEnvelopeResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	item, err := s.paymentService.GetPaymentByProviderRef(ctx, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrPaymentNotFound):
+			return nil, status.Error(codes.NotFound, "payment not found")
+		case errors.Is(err, service.ErrInvalidRequest), errors.Is(err, service.ErrProviderUnsupported):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		default:
+			return nil, status.Error(codes.Internal, "internal server error")
+		}
+	}
+
+	return &types.PaymentEnvelopeResponse{Payment: mapper.PaymentToProto(item)}, nil
+}
+
+func (s *Server) WatchPayment(req *types.WatchPaymentRequest, stream types.PaymentsService_WatchPaymentServer) error {
+	if err := req.Validate(); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx := stream.Context()
+	err := s.paymentService.WatchPayment(ctx, req.GetId(), func(payment *entity.Payment, eventID uint64) error {
+		return stream.Send(&types.WatchPaymentResponse{Payment: mapper.PaymentToProto(payment), EventId: eventID})
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrPaymentNotFound):
+			return status.Error(codes.NotFound, "payment not found")
+		case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+			return status.FromContextError(err).Err()
+		case status.Code(err) != codes.Unknown:
+			return err
+		default:
+			loggerWithContext(ctx).WithError(err).Error("Watch payment failed")
+			return status.Error(codes.Internal, "internal server error")
+		}
+	}
+	return nil
+}
+
 func (s *Server) ListPayments(ctx context.Context, req *types.ListPaymentsRequest) (*types.ListPaymentsResponse, error) {
 	if err := req.Validate(); err != nil {
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
-	items, err := s.paymentService.ListPayments(ctx, req)
+	items, nextPageToken, err := s.paymentService.ListPayments(ctx, req)
 	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidRequest):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		case errors.Is(err, service.ErrForbidden):
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		default:
+			return nil, status.Error(codes.Internal, "internal server error")
+		}
+	}
+
+	return &types.ListPaymentsResponse{Payments: mapper.PaymentsToProto(items), NextPageToken: nextPageToken}, nil
+}
+
+func (s *Server) ListPaymentCharges(ctx context.Context, req *types.ListPaymentChargesRequest) (*types.ListPaymentChargesResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	items, err := s.paymentService.ListPaymentCharges(ctx, req.GetId())
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrPaymentNotFound):
+			return nil, status.Error(codes.NotFound, "payment not found")
+		case errors.Is(err, service.ErrInvalidRequest):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		default:
+			return nil, status.Error(codes.Internal, "internal server error")
+		}
+	}
+
+	return &types.ListPaymentChargesResponse{Charges: mapper.ChargesToProto(items)}, nil
+}
+
+func (s *Server) ListPaymentEvents(ctx context.Context, req *types.ListPaymentEventsRequest) (*types.ListPaymentEventsResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	items, nextCursor, err := s.paymentService.ListPaymentEvents(ctx, req)
+	if err != nil {
+		if errors.Is(err, service.ErrPaymentNotFound) {
+			return nil, status.Error(codes.NotFound, "payment not found")
+		}
 		return nil, status.Error(codes.Internal, "internal server error")
 	}
 
-	return &types.ListPaymentsResponse{Payments: mapper.PaymentsToProto(items)}, nil
+	return &types.ListPaymentEventsResponse{Events: mapper.PaymentEventsToProto(items), NextCursor: nextCursor}, nil
 }
 
 func (s *Server) CancelPayment(ctx context.Context, req *types.CancelPaymentRequest) (*types.PaymentEnvelopeResponse, error) {
@@ -88,6 +201,12 @@ func (s *Server) CancelPayment(ctx context.Context, req *types.CancelPaymentRequ
 			return nil, status.Error(codes.NotFound, "payment not found")
 		case errors.Is(err, service.ErrInvalidStatus):
 			return nil, status.Error(codes.InvalidArgument, err.Error())
+		case errors.Is(err, service.ErrInvalidTransition):
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		case errors.Is(err, service.ErrConcurrentUpdate):
+			return nil, status.Error(codes.Aborted, err.Error())
+		case errors.Is(err, service.ErrProviderCancelFailed):
+			return nil, status.Error(codes.Unavailable, service.ErrProviderCancelFailed.Error())
 		default:
 			return nil, status.Error(codes.Internal, "internal server error")
 		}
@@ -96,6 +215,106 @@ func (s *Server) CancelPayment(ctx context.Context, req *types.CancelPaymentRequ
 	return &types.PaymentEnvelopeResponse{Payment: mapper.PaymentToProto(item)}, nil
 }
 
+func (s *Server) RefundPayment(ctx context.Context, req *types.RefundPaymentRequest) (*types.RefundPaymentResponse, error) {
+	l := loggerWithContext(ctx)
+	if err := req.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	item, refund, err := s.paymentService.RefundPayment(ctx, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrPaymentNotFound):
+			return nil, status.Error(codes.NotFound, "payment not found")
+		case errors.Is(err, service.ErrInvalidRequest), errors.Is(err, service.ErrInvalidStatus), errors.Is(err, service.ErrRefundExceedsAmount), errors.Is(err, service.ErrProviderUnsupported):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		case errors.Is(err, service.ErrInvalidTransition):
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		case errors.Is(err, service.ErrConcurrentUpdate):
+			return nil, status.Error(codes.Aborted, err.Error())
+		default:
+			l.WithError(err).Error("Refund payment failed")
+			return nil, status.Error(codes.Internal, "internal server error")
+		}
+	}
+
+	return &types.RefundPaymentResponse{
+		Payment: mapper.PaymentToProto(item),
+		Refund:  mapper.RefundToProto(refund),
+	}, nil
+}
+
+func (s *Server) PauseSubscription(ctx context.Context, req *types.PauseSubscriptionRequest) (*types.PaymentEnvelopeResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	item, err := s.paymentService.PauseSubscription(ctx, req)
+	if err != nil {
+		return nil, subscriptionError(ctx, err, "Pause subscription failed")
+	}
+
+	return &types.PaymentEnvelopeResponse{Payment: mapper.PaymentToProto(item)}, nil
+}
+
+func (s *Server) ResumeSubscription(ctx context.Context, req *types.ResumeSubscriptionRequest) (*types.PaymentEnvelopeResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	item, err := s.paymentService.ResumeSubscription(ctx, req)
+	if err != nil {
+		return nil, subscriptionError(ctx, err, "Resume subscription failed")
+	}
+
+	return &types.PaymentEnvelopeResponse{Payment: mapper.PaymentToProto(item)}, nil
+}
+
+func (s *Server) CancelSubscription(ctx context.Context, req *types.CancelSubscriptionRequest) (*types.PaymentEnvelopeResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	item, err := s.paymentService.CancelSubscription(ctx, req)
+	if err != nil {
+		return nil, subscriptionError(ctx, err, "Cancel subscription failed")
+	}
+
+	return &types.PaymentEnvelopeResponse{Payment: mapper.PaymentToProto(item)}, nil
+}
+
+func (s *Server) UpdateSubscription(ctx context.Context, req *types.UpdateSubscriptionRequest) (*types.PaymentEnvelopeResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	item, err := s.paymentService.UpdateSubscription(ctx, req)
+	if err != nil {
+		return nil, subscriptionError(ctx, err, "Update subscription failed")
+	}
+
+	return &types.PaymentEnvelopeResponse{Payment: mapper.PaymentToProto(item)}, nil
+}
+
+func subscriptionError(ctx context.Context, err error, message string) error {
+	switch {
+	case errors.Is(err, service.ErrPaymentNotFound):
+		return status.Error(codes.NotFound, "payment not found")
+	case errors.Is(err, service.ErrInvalidRequest), errors.Is(err, service.ErrInvalidStatus), errors.Is(err, service.ErrProviderUnsupported):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, service.ErrInvalidTransition):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, service.ErrConcurrentUpdate):
+		return status.Error(codes.Aborted, err.Error())
+	case errors.Is(err, service.ErrProviderRequestFailed):
+		loggerWithContext(ctx).WithError(err).Warn(message)
+		return status.Error(codes.Unavailable, service.ErrProviderRequestFailed.Error())
+	default:
+		loggerWithContext(ctx).WithError(err).Error(message)
+		return status.Error(codes.Internal, "internal server error")
+	}
+}
+
 func (s *Server) HandleProviderCallback(ctx context.Context, req *types.HandleProviderCallbackRequest) (*types.MessageResponse, error) {
 	if err := req.Validate(); err != nil {
 		return nil, status.Error(codes.InvalidArgument, err.Error())
@@ -108,6 +327,8 @@ func (s *Server) HandleProviderCallback(ctx context.Context, req *types.HandlePr
 			return nil, status.Error(codes.InvalidArgument, err.Error())
 		case errors.Is(err, service.ErrPaymentNotFound):
 			return nil, status.Error(codes.NotFound, "payment not found")
+		case errors.Is(err, service.ErrConcurrentUpdate):
+			return nil, status.Error(codes.Aborted, err.Error())
 		default:
 			return nil, status.Error(codes.Internal, "internal server error")
 		}
@@ -115,3 +336,83 @@ func (s *Server) HandleProviderCallback(ctx context.Context, req *types.HandlePr
 
 	return &types.MessageResponse{Message: "Provider callback processed"}, nil
 }
+
+func (s *Server) ListProviderCallbacks(ctx context.Context, req *types.ListProviderCallbacksRequest) (*types.ListProviderCallbacksResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	items, err := s.paymentService.ListProviderCallbacks(ctx, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidRequest):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		case errors.Is(err, service.ErrForbidden):
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		}
+		return nil, status.Error(codes.Internal, "internal server error")
+	}
+
+	return &types.ListProviderCallbacksResponse{Callbacks: mapper.ProviderCallbacksToProto(items)}, nil
+}
+
+func (s *Server) ReplayProviderCallback(ctx context.Context, req *types.ReplayProviderCallbackRequest) (*types.PaymentEnvelopeResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	item, err := s.paymentService.ReplayProviderCallback(ctx, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrCallbackNotFound):
+			return nil, status.Error(codes.NotFound, "callback not found")
+		case errors.Is(err, service.ErrProviderUnsupported), errors.Is(err, service.ErrCallbackRejected), errors.Is(err, service.ErrInvalidRequest):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		case errors.Is(err, service.ErrPaymentNotFound):
+			return nil, status.Error(codes.NotFound, "payment not found")
+		case errors.Is(err, service.ErrConcurrentUpdate):
+			return nil, status.Error(codes.Aborted, err.Error())
+		case errors.Is(err, service.ErrForbidden):
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		default:
+			return nil, status.Error(codes.Internal, "internal server error")
+		}
+	}
+
+	return &types.PaymentEnvelopeResponse{Payment: mapper.PaymentToProto(item)}, nil
+}
+
+func (s *Server) ListDeadLetterCallbacks(ctx context.Context, req *types.ListDeadLetterCallbacksRequest) (*types.ListDeadLetterCallbacksResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	items, err := s.paymentService.ListDeadLetterCallbacks(ctx, req)
+	if err != nil {
+		if errors.Is(err, service.ErrForbidden) {
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		}
+		return nil, status.Error(codes.Internal, "internal server error")
+	}
+
+	return &types.ListDeadLetterCallbacksResponse{Callbacks: mapper.CallbackDeliveriesToProto(items)}, nil
+}
+
+func (s *Server) RedeliverCallbacks(ctx context.Context, req *types.RedeliverCallbacksRequest) (*types.RedeliverCallbacksResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	count, err := s.paymentService.RedeliverCallbacks(ctx, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidRequest):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		case errors.Is(err, service.ErrForbidden):
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		}
+		return nil, status.Error(codes.Internal, "internal server error")
+	}
+
+	return &types.RedeliverCallbacksResponse{Redelivered: count}, nil
+}
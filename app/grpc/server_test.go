@@ -3,6 +3,7 @@ package grpc
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -10,22 +11,24 @@ import (
 	"github.com/vibast-solutions/ms-go-payments/app/provider"
 	"github.com/vibast-solutions/ms-go-payments/app/repository"
 	"github.com/vibast-solutions/ms-go-payments/app/service"
+	"github.com/vibast-solutions/ms-go-payments/app/sink"
 	"github.com/vibast-solutions/ms-go-payments/app/types"
 	"github.com/vibast-solutions/ms-go-payments/config"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
 type grpcPaymentRepo struct {
-	createFn                 func(ctx context.Context, payment *entity.Payment) error
-	updateFn                 func(ctx context.Context, payment *entity.Payment) error
-	findByIDFn               func(ctx context.Context, id uint64) (*entity.Payment, error)
-	findByCallerRequestIDFn  func(ctx context.Context, callerService, requestID string) (*entity.Payment, error)
-	findByCallbackHashFn     func(ctx context.Context, provider int32, callbackHash string) (*entity.Payment, error)
-	listFn                   func(ctx context.Context, filter repository.PaymentFilter) ([]*entity.Payment, error)
-	listDueCallbackDispatchFn func(ctx context.Context, now time.Time, limit int32) ([]*entity.Payment, error)
-	listExpiredPendingFn     func(ctx context.Context, cutoff time.Time, limit int32) ([]*entity.Payment, error)
-	listForReconcileFn       func(ctx context.Context, before time.Time, limit int32) ([]*entity.Payment, error)
+	createFn                func(ctx context.Context, payment *entity.Payment) error
+	updateFn                func(ctx context.Context, payment *entity.Payment) error
+	findByIDFn              func(ctx context.Context, id uint64) (*entity.Payment, error)
+	findByCallerRequestIDFn func(ctx context.Context, callerService, requestID string) (*entity.Payment, error)
+	findByCallbackHashFn    func(ctx context.Context, provider int32, callbackHash string) (*entity.Payment, error)
+	findByProviderRefFn     func(ctx context.Context, provider int32, providerRef string) (*entity.Payment, error)
+	listFn                  func(ctx context.Context, filter repository.PaymentFilter) ([]*entity.Payment, error)
+	listExpiredPendingFn    func(ctx context.Context, cutoff time.Time, limit int32) ([]*entity.Payment, error)
+	listForReconcileFn      func(ctx context.Context, before time.Time, limit int32) ([]*entity.Payment, error)
 }
 
 func (r *grpcPaymentRepo) Create(ctx context.Context, payment *entity.Payment) error {
@@ -63,16 +66,20 @@ func (r *grpcPaymentRepo) FindByCallbackHash(ctx context.Context, provider int32
 	return nil, nil
 }
 
-func (r *grpcPaymentRepo) List(ctx context.Context, filter repository.PaymentFilter) ([]*entity.Payment, error) {
-	if r.listFn != nil {
-		return r.listFn(ctx, filter)
+func (r *grpcPaymentRepo) FindByProviderSubscriptionID(context.Context, int32, string) (*entity.Payment, error) {
+	return nil, nil
+}
+
+func (r *grpcPaymentRepo) FindByProviderPaymentID(ctx context.Context, provider int32, providerPaymentID string) (*entity.Payment, error) {
+	if r.findByProviderRefFn != nil {
+		return r.findByProviderRefFn(ctx, provider, providerPaymentID)
 	}
-	return []*entity.Payment{}, nil
+	return nil, nil
 }
 
-func (r *grpcPaymentRepo) ListDueCallbackDispatch(ctx context.Context, now time.Time, limit int32) ([]*entity.Payment, error) {
-	if r.listDueCallbackDispatchFn != nil {
-		return r.listDueCallbackDispatchFn(ctx, now, limit)
+func (r *grpcPaymentRepo) List(ctx context.Context, filter repository.PaymentFilter) ([]*entity.Payment, error) {
+	if r.listFn != nil {
+		return r.listFn(ctx, filter)
 	}
 	return []*entity.Payment{}, nil
 }
@@ -91,18 +98,125 @@ func (r *grpcPaymentRepo) ListForReconcile(ctx context.Context, before time.Time
 	return []*entity.Payment{}, nil
 }
 
+func (r *grpcPaymentRepo) ListProvisional(context.Context, time.Time, int32) ([]*entity.Payment, error) {
+	return []*entity.Payment{}, nil
+}
+
 type grpcEventRepo struct{}
 
 func (r *grpcEventRepo) Create(context.Context, *entity.PaymentEvent) error {
 	return nil
 }
 
+func (r *grpcEventRepo) ListByPayment(context.Context, repository.PaymentEventFilter) ([]*entity.PaymentEvent, error) {
+	return nil, nil
+}
+
 type grpcCallbackRepo struct{}
 
 func (r *grpcCallbackRepo) Create(context.Context, *entity.PaymentCallback) error {
 	return nil
 }
 
+func (r *grpcCallbackRepo) FindByID(context.Context, uint64) (*entity.PaymentCallback, error) {
+	return nil, nil
+}
+
+func (r *grpcCallbackRepo) List(context.Context, repository.PaymentCallbackFilter) ([]*entity.PaymentCallback, error) {
+	return nil, nil
+}
+
+type grpcRefundRepo struct{}
+
+func (r *grpcRefundRepo) Create(context.Context, *entity.PaymentRefund) error {
+	return nil
+}
+
+func (r *grpcRefundRepo) Update(context.Context, *entity.PaymentRefund) error {
+	return nil
+}
+
+func (r *grpcRefundRepo) FindByPaymentRequestID(context.Context, uint64, string) (*entity.PaymentRefund, error) {
+	return nil, nil
+}
+
+func (r *grpcRefundRepo) FindByProviderRefundID(context.Context, uint64, string) (*entity.PaymentRefund, error) {
+	return nil, nil
+}
+
+func (r *grpcRefundRepo) ListByPaymentID(context.Context, uint64) ([]*entity.PaymentRefund, error) {
+	return []*entity.PaymentRefund{}, nil
+}
+
+type grpcChargeRepo struct{}
+
+func (r *grpcChargeRepo) Create(context.Context, *entity.PaymentCharge) error {
+	return nil
+}
+
+func (r *grpcChargeRepo) Update(context.Context, *entity.PaymentCharge) error {
+	return nil
+}
+
+func (r *grpcChargeRepo) FindByProviderInvoiceID(context.Context, uint64, string) (*entity.PaymentCharge, error) {
+	return nil, nil
+}
+
+func (r *grpcChargeRepo) ListByPaymentID(context.Context, uint64) ([]*entity.PaymentCharge, error) {
+	return []*entity.PaymentCharge{}, nil
+}
+
+type grpcProviderEventRepo struct{}
+
+func (r *grpcProviderEventRepo) Create(context.Context, *entity.PaymentProviderEvent) error {
+	return nil
+}
+
+func (r *grpcProviderEventRepo) FindByProviderEventID(context.Context, int32, string) (*entity.PaymentProviderEvent, error) {
+	return nil, nil
+}
+
+type grpcOutboxRepo struct {
+	listDeadLettersFn    func(ctx context.Context, filter repository.CallbackOutboxFilter) ([]*entity.PaymentCallbackOutbox, error)
+	requeueDeadLettersFn func(ctx context.Context, filter repository.CallbackOutboxFilter, now time.Time) (int64, error)
+}
+
+func (r *grpcOutboxRepo) Create(context.Context, *entity.PaymentCallbackOutbox) error {
+	return nil
+}
+
+func (r *grpcOutboxRepo) Update(context.Context, *entity.PaymentCallbackOutbox) error {
+	return nil
+}
+
+func (r *grpcOutboxRepo) ListDue(context.Context, time.Time, int32) ([]*entity.PaymentCallbackOutbox, error) {
+	return []*entity.PaymentCallbackOutbox{}, nil
+}
+
+func (r *grpcOutboxRepo) Claim(context.Context, *entity.PaymentCallbackOutbox, time.Time, time.Time) (bool, error) {
+	return true, nil
+}
+
+func (r *grpcOutboxRepo) ListDeadLetters(ctx context.Context, filter repository.CallbackOutboxFilter) ([]*entity.PaymentCallbackOutbox, error) {
+	if r.listDeadLettersFn != nil {
+		return r.listDeadLettersFn(ctx, filter)
+	}
+	return []*entity.PaymentCallbackOutbox{}, nil
+}
+
+func (r *grpcOutboxRepo) RequeueDeadLetters(ctx context.Context, filter repository.CallbackOutboxFilter, now time.Time) (int64, error) {
+	if r.requeueDeadLettersFn != nil {
+		return r.requeueDeadLettersFn(ctx, filter, now)
+	}
+	return 0, nil
+}
+
+type grpcUnitOfWork struct{}
+
+func (u *grpcUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
 type grpcProvider struct {
 	createOutput *provider.CreateOutput
 	createErr    error
@@ -133,6 +247,10 @@ func (p *grpcProvider) CreatePayment(context.Context, *provider.CreateInput) (*p
 	}, nil
 }
 
+func (p *grpcProvider) CallbackContract() provider.CallbackContract {
+	return provider.CallbackContract{SignatureHeader: "Stripe-Signature", RawBody: true}
+}
+
 func (p *grpcProvider) VerifyAndParseCallback(context.Context, []byte, string) (*provider.CallbackEvent, error) {
 	if p.callbackErr != nil {
 		return nil, p.callbackErr
@@ -143,21 +261,65 @@ func (p *grpcProvider) VerifyAndParseCallback(context.Context, []byte, string) (
 	return &provider.CallbackEvent{EventType: "checkout.session.completed", NewStatus: int32(types.PaymentStatus_PAYMENT_STATUS_PAID)}, nil
 }
 
-func (p *grpcProvider) GetPaymentStatus(context.Context, string) (int32, error) {
+func (p *grpcProvider) ParseCallback(context.Context, []byte) (*provider.CallbackEvent, error) {
+	if p.callbackEvt != nil {
+		return p.callbackEvt, nil
+	}
+	return &provider.CallbackEvent{EventType: "checkout.session.completed", NewStatus: int32(types.PaymentStatus_PAYMENT_STATUS_PAID)}, nil
+}
+
+func (p *grpcProvider) GetPaymentStatus(context.Context, *provider.StatusInput) (int32, error) {
 	if p.statusErr != nil {
 		return 0, p.statusErr
 	}
 	return p.status, nil
 }
 
+func (p *grpcProvider) ListEvents(context.Context, *provider.ListEventsInput) (*provider.ListEventsOutput, error) {
+	return &provider.ListEventsOutput{}, nil
+}
+
+func (p *grpcProvider) Refund(context.Context, *provider.RefundInput) (*provider.RefundOutput, error) {
+	return &provider.RefundOutput{ProviderRefundID: "re_test_123", Status: int32(types.RefundStatus_REFUND_STATUS_SUCCEEDED)}, nil
+}
+
+func (p *grpcProvider) Cancel(context.Context, *provider.CancelInput) error {
+	return nil
+}
+
+func (p *grpcProvider) PauseSubscription(context.Context, *provider.SubscriptionInput) error {
+	return nil
+}
+
+func (p *grpcProvider) ResumeSubscription(context.Context, *provider.SubscriptionInput) error {
+	return nil
+}
+
+func (p *grpcProvider) CancelSubscription(context.Context, *provider.SubscriptionInput) error {
+	return nil
+}
+
+func (p *grpcProvider) UpdateSubscription(context.Context, *provider.UpdateSubscriptionInput) error {
+	return nil
+}
+
 func newGRPCServerForTest(repo *grpcPaymentRepo, p provider.Provider) *Server {
+	return newGRPCServerWithOutboxForTest(repo, p, &grpcOutboxRepo{})
+}
+
+func newGRPCServerWithOutboxForTest(repo *grpcPaymentRepo, p provider.Provider, outboxRepo *grpcOutboxRepo) *Server {
 	paymentService := service.NewPaymentService(
 		repo,
 		&grpcEventRepo{},
 		&grpcCallbackRepo{},
+		&grpcRefundRepo{},
+		&grpcChargeRepo{},
+		&grpcProviderEventRepo{},
+		outboxRepo,
+		&grpcUnitOfWork{},
 		provider.NewRegistry(p),
+		sink.NewRegistry(sink.NewHTTPSink(nil, "")),
 		config.PaymentsConfig{CallbackMaxAttempts: 3, CallbackRetryInterval: time.Minute, PendingTimeout: time.Hour, ReconcileStaleAfter: time.Minute, JobBatchSize: 100},
-		"payments-app-key",
 	)
 	return NewServer(paymentService)
 }
@@ -199,6 +361,127 @@ func TestCreatePaymentSuccess(t *testing.T) {
 	}
 }
 
+func TestCreatePaymentIdempotencyConflict(t *testing.T) {
+	repo := &grpcPaymentRepo{
+		findByCallerRequestIDFn: func(context.Context, string, string) (*entity.Payment, error) {
+			return &entity.Payment{
+				ID:                 77,
+				RequestID:          "req-1",
+				CallerService:      "subscriptions-service",
+				ResourceType:       "subscription",
+				ResourceID:         "sub-1",
+				AmountCents:        1999,
+				Currency:           "EUR",
+				Status:             2,
+				PaymentMethod:      1,
+				PaymentType:        1,
+				Provider:           1,
+				RequestFingerprint: "fingerprint-of-the-first-request",
+			}, nil
+		},
+	}
+	srv := newGRPCServerForTest(repo, &grpcProvider{})
+
+	_, err := srv.CreatePayment(context.Background(), &types.CreatePaymentRequest{
+		RequestId:         "req-1",
+		CallerService:     "subscriptions-service",
+		ResourceType:      "subscription",
+		ResourceId:        "sub-1",
+		AmountCents:       1999,
+		Currency:          "USD",
+		PaymentMethod:     types.PaymentMethod_PAYMENT_METHOD_HOSTED_CARD,
+		PaymentType:       types.PaymentType_PAYMENT_TYPE_ONE_TIME,
+		StatusCallbackUrl: "https://caller.example/callback",
+	})
+	if status.Code(err) != codes.AlreadyExists {
+		t.Fatalf("expected AlreadyExists, got %v", err)
+	}
+	if !strings.Contains(status.Convert(err).Message(), "different currency") {
+		t.Fatalf("expected differing fields in the message, got %q", status.Convert(err).Message())
+	}
+}
+
+func TestListPaymentsForAnotherCallerIsPermissionDenied(t *testing.T) {
+	srv := newGRPCServerForTest(&grpcPaymentRepo{}, &grpcProvider{})
+	ctx := service.WithCallerService(context.Background(), "orders-service")
+
+	_, err := srv.ListPayments(ctx, &types.ListPaymentsRequest{CallerService: "subscriptions-service"})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("expected PermissionDenied, got %v", err)
+	}
+}
+
+func TestGetPaymentByProviderRef(t *testing.T) {
+	sessionID := "cs_test_9"
+	repo := &grpcPaymentRepo{findByProviderRefFn: func(_ context.Context, provider int32, providerPaymentID string) (*entity.Payment, error) {
+		if provider != int32(types.ProviderType_PROVIDER_TYPE_STRIPE) || providerPaymentID != sessionID {
+			return nil, nil
+		}
+		return &entity.Payment{ID: 9, Provider: provider, ProviderPaymentID: &sessionID, Metadata: map[string]string{}}, nil
+	}}
+	srv := newGRPCServerForTest(repo, &grpcProvider{})
+
+	res, err := srv.GetPaymentByProviderRef(context.Background(), &types.GetPaymentByProviderRefRequest{
+		Provider:          types.ProviderType_PROVIDER_TYPE_STRIPE,
+		ProviderPaymentId: sessionID,
+	})
+	if err != nil || res.GetPayment().GetId() != 9 {
+		t.Fatalf("expected payment 9, got %+v err=%v", res, err)
+	}
+
+	_, err = srv.GetPaymentByProviderRef(context.Background(), &types.GetPaymentByProviderRefRequest{
+		Provider:          types.ProviderType_PROVIDER_TYPE_STRIPE,
+		ProviderPaymentId: "cs_unknown",
+	})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("expected NotFound, got %v", err)
+	}
+
+	_, err = srv.GetPaymentByProviderRef(context.Background(), &types.GetPaymentByProviderRefRequest{ProviderPaymentId: sessionID})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected InvalidArgument without provider, got %v", err)
+	}
+}
+
+type grpcWatchStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	sent []*types.WatchPaymentResponse
+}
+
+func (s *grpcWatchStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *grpcWatchStream) Send(resp *types.WatchPaymentResponse) error {
+	s.sent = append(s.sent, resp)
+	return nil
+}
+
+func TestWatchPaymentSendsTerminalPaymentAndEnds(t *testing.T) {
+	repo := &grpcPaymentRepo{findByIDFn: func(_ context.Context, id uint64) (*entity.Payment, error) {
+		return &entity.Payment{ID: id, Status: int32(types.PaymentStatus_PAYMENT_STATUS_PAID), Metadata: map[string]string{}}, nil
+	}}
+	srv := newGRPCServerForTest(repo, &grpcProvider{})
+	stream := &grpcWatchStream{ctx: context.Background()}
+
+	if err := srv.WatchPayment(&types.WatchPaymentRequest{Id: 4}, stream); err != nil {
+		t.Fatalf("watch payment failed: %v", err)
+	}
+	if len(stream.sent) != 1 || stream.sent[0].GetPayment().GetStatus() != types.PaymentStatus_PAYMENT_STATUS_PAID {
+		t.Fatalf("expected a single paid snapshot, got %+v", stream.sent)
+	}
+}
+
+func TestWatchPaymentNotFound(t *testing.T) {
+	srv := newGRPCServerForTest(&grpcPaymentRepo{}, &grpcProvider{})
+
+	err := srv.WatchPayment(&types.WatchPaymentRequest{Id: 4}, &grpcWatchStream{ctx: context.Background()})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("expected NotFound, got %v", err)
+	}
+}
+
 func TestGetPaymentNotFound(t *testing.T) {
 	repo := &grpcPaymentRepo{findByIDFn: func(context.Context, uint64) (*entity.Payment, error) { return nil, nil }}
 	srv := newGRPCServerForTest(repo, &grpcProvider{})
@@ -269,3 +552,41 @@ func TestListPaymentsSuccess(t *testing.T) {
 		t.Fatalf("unexpected payments response: %+v", resp)
 	}
 }
+
+func TestRedeliverCallbacksInvalidArgument(t *testing.T) {
+	srv := newGRPCServerForTest(&grpcPaymentRepo{}, &grpcProvider{})
+
+	_, err := srv.RedeliverCallbacks(context.Background(), &types.RedeliverCallbacksRequest{All: true, CallerService: "subscriptions-service"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected InvalidArgument, got %v", err)
+	}
+}
+
+func TestRedeliverCallbacksAll(t *testing.T) {
+	var gotFilter repository.CallbackOutboxFilter
+	outboxRepo := &grpcOutboxRepo{requeueDeadLettersFn: func(_ context.Context, filter repository.CallbackOutboxFilter, _ time.Time) (int64, error) {
+		gotFilter = filter
+		return 5, nil
+	}}
+	srv := newGRPCServerWithOutboxForTest(&grpcPaymentRepo{}, &grpcProvider{}, outboxRepo)
+
+	resp, err := srv.RedeliverCallbacks(context.Background(), &types.RedeliverCallbacksRequest{All: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.GetRedelivered() != 5 {
+		t.Fatalf("expected redelivered=5, got %d", resp.GetRedelivered())
+	}
+	if gotFilter != (repository.CallbackOutboxFilter{}) {
+		t.Fatalf("expected an empty filter for all, got %+v", gotFilter)
+	}
+}
+
+func TestListDeadLetterCallbacksInvalidLimit(t *testing.T) {
+	srv := newGRPCServerForTest(&grpcPaymentRepo{}, &grpcProvider{})
+
+	_, err := srv.ListDeadLetterCallbacks(context.Background(), &types.ListDeadLetterCallbacksRequest{Limit: 1000})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected InvalidArgument, got %v", err)
+	}
+}
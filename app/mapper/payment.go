@@ -27,6 +27,8 @@ func PaymentToProto(item *entity.Payment) *types.Payment {
 		Provider:                types.ProviderType(item.Provider),
 		RecurringInterval:       derefString(item.RecurringInterval),
 		RecurringIntervalCount:  derefInt32(item.RecurringIntervalCount),
+		SubscriptionPaused:      item.SubscriptionPaused,
+		CancelAtPeriodEnd:       item.CancelAtPeriodEnd,
 		ProviderPaymentId:       derefString(item.ProviderPaymentID),
 		ProviderSubscriptionId:  derefString(item.ProviderSubscriptionID),
 		CheckoutUrl:             derefString(item.CheckoutURL),
@@ -49,6 +51,149 @@ func PaymentsToProto(items []*entity.Payment) []*types.Payment {
 	return result
 }
 
+func RefundToProto(item *entity.PaymentRefund) *types.Refund {
+	if item == nil {
+		return nil
+	}
+
+	return &types.Refund{
+		Id:               item.ID,
+		PaymentId:        item.PaymentID,
+		RequestId:        item.RequestID,
+		AmountCents:      item.AmountCents,
+		Currency:         item.Currency,
+		Status:           types.RefundStatus(item.Status),
+		Reason:           derefString(item.Reason),
+		ProviderRefundId: derefString(item.ProviderRefundID),
+		CreatedAt:        item.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:        item.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+func ChargeToProto(item *entity.PaymentCharge) *types.Charge {
+	if item == nil {
+		return nil
+	}
+
+	return &types.Charge{
+		Id:                item.ID,
+		PaymentId:         item.PaymentID,
+		ProviderInvoiceId: item.ProviderInvoiceID,
+		AmountCents:       item.AmountCents,
+		Currency:          item.Currency,
+		Status:            types.PaymentStatus(item.Status),
+		PeriodStart:       formatOptionalTime(item.PeriodStart),
+		PeriodEnd:         formatOptionalTime(item.PeriodEnd),
+		CreatedAt:         item.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:         item.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+func ChargesToProto(items []*entity.PaymentCharge) []*types.Charge {
+	result := make([]*types.Charge, 0, len(items))
+	for _, item := range items {
+		result = append(result, ChargeToProto(item))
+	}
+	return result
+}
+
+func PaymentEventToProto(item *entity.PaymentEvent) *types.PaymentEvent {
+	if item == nil {
+		return nil
+	}
+
+	return &types.PaymentEvent{
+		Id:              item.ID,
+		PaymentId:       item.PaymentID,
+		EventType:       item.EventType,
+		OldStatus:       types.PaymentStatus(derefInt32(item.OldStatus)),
+		NewStatus:       types.PaymentStatus(item.NewStatus),
+		ProviderEventId: derefString(item.ProviderEventID),
+		PayloadJson:     derefString(item.PayloadJSON),
+		CreatedAt:       item.CreatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+func PaymentEventsToProto(items []*entity.PaymentEvent) []*types.PaymentEvent {
+	result := make([]*types.PaymentEvent, 0, len(items))
+	for _, item := range items {
+		result = append(result, PaymentEventToProto(item))
+	}
+	return result
+}
+
+func ProviderCallbackToProto(item *entity.PaymentCallback) *types.ProviderCallback {
+	if item == nil {
+		return nil
+	}
+
+	var paymentID uint64
+	if item.PaymentID != nil {
+		paymentID = *item.PaymentID
+	}
+
+	return &types.ProviderCallback{
+		Id:              item.ID,
+		PaymentId:       paymentID,
+		Provider:        item.Provider,
+		CallbackHash:    item.CallbackHash,
+		ProviderEventId: derefString(item.ProviderEventID),
+		Status:          types.ProviderCallbackStatus(item.Status),
+		Error:           derefString(item.Error),
+		Payload:         item.PayloadJSON,
+		CreatedAt:       item.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:       item.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+func ProviderCallbacksToProto(items []*entity.PaymentCallback) []*types.ProviderCallback {
+	result := make([]*types.ProviderCallback, 0, len(items))
+	for _, item := range items {
+		result = append(result, ProviderCallbackToProto(item))
+	}
+	return result
+}
+
+func CallbackDeliveryToProto(item *entity.PaymentCallbackOutbox) *types.CallbackDelivery {
+	if item == nil {
+		return nil
+	}
+
+	result := &types.CallbackDelivery{
+		Id:            item.ID,
+		PaymentId:     item.PaymentID,
+		CallerService: item.CallerService,
+		EventType:     item.EventType,
+		RequestId:     item.RequestID,
+		Destination:   item.Destination,
+		Attempts:      item.Attempts,
+		CreatedAt:     item.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:     item.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+	if item.ChargeID != nil {
+		result.ChargeId = *item.ChargeID
+	}
+	if item.LastError != nil {
+		result.LastError = *item.LastError
+	}
+	return result
+}
+
+func CallbackDeliveriesToProto(items []*entity.PaymentCallbackOutbox) []*types.CallbackDelivery {
+	result := make([]*types.CallbackDelivery, 0, len(items))
+	for _, item := range items {
+		result = append(result, CallbackDeliveryToProto(item))
+	}
+	return result
+}
+
+func formatOptionalTime(v *time.Time) string {
+	if v == nil {
+		return ""
+	}
+	return v.UTC().Format(time.RFC3339)
+}
+
 func derefString(v *string) string {
 	if v == nil {
 		return ""
@@ -1,16 +1,30 @@
 package provider
 
-import "context"
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// IdempotencyKey derives the base idempotency key of a provider mutation from
+// the caller service, the request ID and the step. Retries of the same step
+// yield the same key, so the provider returns the object it already created
+// instead of creating another one. Providers append their own sub-steps when
+// one operation takes several calls.
+func IdempotencyKey(callerService, requestID, step string) string {
+	return strings.TrimSpace(callerService) + ":" + strings.TrimSpace(requestID) + ":" + strings.TrimSpace(step)
+}
 
 type CreateInput struct {
-	RequestID     string
-	CallbackHash  string
-	ResourceType  string
-	ResourceID    string
-	AmountCents   int64
-	Currency      string
-	PaymentMethod int32
-	PaymentType   int32
+	IdempotencyKey string
+	RequestID      string
+	CallbackHash   string
+	ResourceType   string
+	ResourceID     string
+	AmountCents    int64
+	Currency       string
+	PaymentMethod  int32
+	PaymentType    int32
 
 	RecurringInterval      string
 	RecurringIntervalCount int32
@@ -30,17 +44,126 @@ type CreateOutput struct {
 	InitialStatus          int32
 }
 
+type RefundInput struct {
+	IdempotencyKey    string
+	RequestID         string
+	PaymentID         uint64
+	ProviderPaymentID string
+	PaymentMethod     int32
+	PaymentType       int32
+	AmountCents       int64
+	Reason            string
+}
+
+type RefundOutput struct {
+	ProviderRefundID string
+	Status           int32
+}
+
+type CancelInput struct {
+	IdempotencyKey         string
+	ProviderPaymentID      string
+	ProviderSubscriptionID string
+	PaymentMethod          int32
+	PaymentType            int32
+	Reason                 string
+}
+
+// StatusInput identifies a payment to reconcile. Providers pick the lookup
+// from the payment method and type: a recurring payment with a subscription
+// is resolved through the subscription rather than its checkout.
+type StatusInput struct {
+	ProviderPaymentID      string
+	ProviderSubscriptionID string
+	PaymentMethod          int32
+	PaymentType            int32
+}
+
+type SubscriptionInput struct {
+	IdempotencyKey         string
+	ProviderSubscriptionID string
+	AtPeriodEnd            bool
+	Reason                 string
+}
+
+type UpdateSubscriptionInput struct {
+	IdempotencyKey         string
+	ProviderSubscriptionID string
+	Currency               string
+	AmountCents            int64
+	RecurringInterval      string
+	RecurringIntervalCount int32
+}
+
+type CallbackRefund struct {
+	ProviderRefundID string
+	AmountCents      int64
+	Status           int32
+}
+
+type CallbackInvoice struct {
+	ProviderInvoiceID string
+	AmountCents       int64
+	Currency          string
+	Status            int32
+	PeriodStart       *time.Time
+	PeriodEnd         *time.Time
+}
+
 type CallbackEvent struct {
 	ProviderEventID        *string
 	ProviderPaymentID      *string
 	ProviderSubscriptionID *string
+	CallbackHash           *string
 	EventType              string
 	NewStatus              int32
+
+	Invoice            *CallbackInvoice
+	Refund             *CallbackRefund
+	TotalRefundedCents *int64
+}
+
+type ListEventsInput struct {
+	CreatedFrom   time.Time
+	StartingAfter string
+	Limit         int32
+}
+
+// ListedEvent is an event fetched from the provider API. Payload has the same
+// shape as a webhook body, so it can be passed to ParseCallback.
+type ListedEvent struct {
+	ID      string
+	Payload []byte
+}
+
+// ListEventsOutput holds one page of events, newest first. StartingAfter of
+// the next page is the ID of the last event.
+type ListEventsOutput struct {
+	Events  []ListedEvent
+	HasMore bool
+}
+
+// CallbackContract describes how a provider delivers webhooks. SignatureHeader
+// names the header that carries the signature. RawBody means the signature is
+// computed over the exact request body, which is then passed to
+// VerifyAndParseCallback unchanged.
+type CallbackContract struct {
+	SignatureHeader string
+	RawBody         bool
 }
 
 type Provider interface {
 	Code() int32
 	CreatePayment(ctx context.Context, input *CreateInput) (*CreateOutput, error)
+	CallbackContract() CallbackContract
 	VerifyAndParseCallback(ctx context.Context, payload []byte, signature string) (*CallbackEvent, error)
-	GetPaymentStatus(ctx context.Context, providerPaymentID string) (int32, error)
+	ParseCallback(ctx context.Context, payload []byte) (*CallbackEvent, error)
+	GetPaymentStatus(ctx context.Context, input *StatusInput) (int32, error)
+	ListEvents(ctx context.Context, input *ListEventsInput) (*ListEventsOutput, error)
+	Refund(ctx context.Context, input *RefundInput) (*RefundOutput, error)
+	Cancel(ctx context.Context, input *CancelInput) error
+	PauseSubscription(ctx context.Context, input *SubscriptionInput) error
+	ResumeSubscription(ctx context.Context, input *SubscriptionInput) error
+	CancelSubscription(ctx context.Context, input *SubscriptionInput) error
+	UpdateSubscription(ctx context.Context, input *UpdateSubscriptionInput) error
 }
@@ -4,6 +4,11 @@ import "errors"
 
 var ErrProviderNotSupported = errors.New("provider is not supported")
 
+// ErrRequestRejected matches provider errors for requests that fail the same
+// way however often they are repeated, such as invalid parameters. Any other
+// provider error may be transient and the request may be retried.
+var ErrRequestRejected = errors.New("provider rejected the request")
+
 type Registry struct {
 	providers map[int32]Provider
 }
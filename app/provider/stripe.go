@@ -19,12 +19,25 @@ import (
 	"github.com/vibast-solutions/ms-go-payments/app/types"
 )
 
+const (
+	defaultStripeBaseURL = "https://api.stripe.com"
+	maxStripeRetryAfter  = 30 * time.Second
+)
+
 type StripeConfig struct {
+	// BaseURL is the Stripe API root; it defaults to https://api.stripe.com
+	// and points at a fake server in tests.
+	BaseURL                   string
 	SecretKey                 string
 	WebhookSecret             string
 	ProviderCallbackBaseURL   string
 	SignatureToleranceSeconds int64
 	HTTPTimeout               time.Duration
+	// MaxNetworkRetries is how often a failed request is retried; 0 disables
+	// retries. RetryBaseDelay and RetryMaxDelay bound the backoff between them.
+	MaxNetworkRetries int
+	RetryBaseDelay    time.Duration
+	RetryMaxDelay     time.Duration
 }
 
 type StripeProvider struct {
@@ -42,6 +55,19 @@ func NewStripeProvider(cfg StripeConfig) *StripeProvider {
 		tolerance = 300
 	}
 	cfg.SignatureToleranceSeconds = tolerance
+	if cfg.MaxNetworkRetries < 0 {
+		cfg.MaxNetworkRetries = 0
+	}
+	if cfg.RetryBaseDelay <= 0 {
+		cfg.RetryBaseDelay = 500 * time.Millisecond
+	}
+	if cfg.RetryMaxDelay < cfg.RetryBaseDelay {
+		cfg.RetryMaxDelay = 8 * cfg.RetryBaseDelay
+	}
+	cfg.BaseURL = strings.TrimRight(strings.TrimSpace(cfg.BaseURL), "/")
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = defaultStripeBaseURL
+	}
 
 	return &StripeProvider{
 		cfg:    cfg,
@@ -69,59 +95,421 @@ func (p *StripeProvider) CreatePayment(ctx context.Context, input *CreateInput)
 	case int32(types.PaymentMethod_PAYMENT_METHOD_PAYMENT_LINK):
 		return p.createPaymentLink(ctx, input, callbackURL)
 	default:
-		return nil, errors.New("unsupported payment method for stripe")
+		return nil, fmt.Errorf("%w: unsupported payment method for stripe", ErrRequestRejected)
 	}
 }
 
-func (p *StripeProvider) GetPaymentStatus(ctx context.Context, providerPaymentID string) (int32, error) {
-	if strings.TrimSpace(providerPaymentID) == "" {
+func (p *StripeProvider) GetPaymentStatus(ctx context.Context, input *StatusInput) (int32, error) {
+	if input.PaymentType == int32(types.PaymentType_PAYMENT_TYPE_RECURRING) {
+		if subscriptionID := strings.TrimSpace(input.ProviderSubscriptionID); subscriptionID != "" {
+			return p.subscriptionStatus(ctx, subscriptionID)
+		}
+	}
+
+	providerPaymentID := strings.TrimSpace(input.ProviderPaymentID)
+	if providerPaymentID == "" {
 		return 0, nil
 	}
+	if input.PaymentMethod == int32(types.PaymentMethod_PAYMENT_METHOD_PAYMENT_LINK) && !isCheckoutSessionID(providerPaymentID) {
+		return p.paymentLinkStatus(ctx, providerPaymentID)
+	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.stripe.com/v1/checkout/sessions/"+url.PathEscape(providerPaymentID), nil)
+	body, err := p.getJSON(ctx, "/v1/checkout/sessions/"+url.PathEscape(providerPaymentID), nil)
 	if err != nil {
 		return 0, err
 	}
-	req.Header.Set("Authorization", "Bearer "+p.cfg.SecretKey)
 
-	resp, err := p.client.Do(req)
-	if err != nil {
+	var payload struct {
+		Status        string `json:"status"`
+		PaymentStatus string `json:"payment_status"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
 		return 0, err
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	return mapStripeCheckoutSessionStatus(payload.Status, payload.PaymentStatus), nil
+}
+
+// paymentLinkStatus looks at the checkout sessions created from a payment
+// link. The link itself stays open, so an expired session only means that
+// one attempt lapsed and is not reported.
+func (p *StripeProvider) paymentLinkStatus(ctx context.Context, paymentLinkID string) (int32, error) {
+	query := url.Values{}
+	query.Set("payment_link", paymentLinkID)
+	query.Set("limit", "100")
+	body, err := p.getJSON(ctx, "/v1/checkout/sessions", query)
 	if err != nil {
 		return 0, err
 	}
-	if resp.StatusCode >= 400 {
-		return 0, fmt.Errorf("stripe get checkout session failed: status=%d body=%s", resp.StatusCode, string(body))
+
+	var list struct {
+		Data []struct {
+			Status        string `json:"status"`
+			PaymentStatus string `json:"payment_status"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &list); err != nil {
+		return 0, err
+	}
+
+	var result int32
+	for _, session := range list.Data {
+		if session.Status == "expired" {
+			continue
+		}
+		switch mapStripeCheckoutSessionStatus(session.Status, session.PaymentStatus) {
+		case int32(types.PaymentStatus_PAYMENT_STATUS_PAID):
+			return int32(types.PaymentStatus_PAYMENT_STATUS_PAID), nil
+		case int32(types.PaymentStatus_PAYMENT_STATUS_PENDING):
+			result = int32(types.PaymentStatus_PAYMENT_STATUS_PENDING)
+		}
+	}
+
+	return result, nil
+}
+
+func (p *StripeProvider) subscriptionStatus(ctx context.Context, subscriptionID string) (int32, error) {
+	query := url.Values{}
+	query.Set("expand[]", "latest_invoice")
+	body, err := p.getJSON(ctx, "/v1/subscriptions/"+url.PathEscape(subscriptionID), query)
+	if err != nil {
+		return 0, err
 	}
 
 	var payload struct {
-		Status        string `json:"status"`
-		PaymentStatus string `json:"payment_status"`
+		Status        string          `json:"status"`
+		LatestInvoice json.RawMessage `json:"latest_invoice"`
 	}
 	if err := json.Unmarshal(body, &payload); err != nil {
 		return 0, err
 	}
 
-	switch payload.Status {
+	// latest_invoice is an ID unless expanded; an unexpanded value carries no
+	// status and is ignored.
+	var invoice struct {
+		Status string `json:"status"`
+	}
+	_ = json.Unmarshal(payload.LatestInvoice, &invoice)
+
+	return mapStripeSubscriptionStatus(payload.Status, invoice.Status), nil
+}
+
+// stripeCallbackEventTypes are the event types ParseCallback acts on. Event
+// listings are narrowed to them.
+var stripeCallbackEventTypes = []string{
+	"checkout.session.completed",
+	"checkout.session.async_payment_succeeded",
+	"checkout.session.async_payment_failed",
+	"checkout.session.expired",
+	"invoice.paid",
+	"invoice.payment_failed",
+	"customer.subscription.deleted",
+	"charge.refunded",
+	"refund.created",
+	"refund.updated",
+	"charge.refund.updated",
+}
+
+func (p *StripeProvider) ListEvents(ctx context.Context, input *ListEventsInput) (*ListEventsOutput, error) {
+	if strings.TrimSpace(p.cfg.SecretKey) == "" {
+		return nil, errors.New("stripe secret key is not configured")
+	}
+
+	limit := input.Limit
+	if limit <= 0 || limit > 100 {
+		limit = 100
+	}
+	query := url.Values{}
+	query.Set("limit", strconv.FormatInt(int64(limit), 10))
+	if !input.CreatedFrom.IsZero() {
+		query.Set("created[gte]", strconv.FormatInt(input.CreatedFrom.Unix(), 10))
+	}
+	if s := strings.TrimSpace(input.StartingAfter); s != "" {
+		query.Set("starting_after", s)
+	}
+	for _, eventType := range stripeCallbackEventTypes {
+		query.Add("types[]", eventType)
+	}
+
+	body, err := p.getJSON(ctx, "/v1/events", query)
+	if err != nil {
+		return nil, err
+	}
+
+	var list struct {
+		Data    []json.RawMessage `json:"data"`
+		HasMore bool              `json:"has_more"`
+	}
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, err
+	}
+
+	result := &ListEventsOutput{HasMore: list.HasMore}
+	for _, raw := range list.Data {
+		var event struct {
+			ID string `json:"id"`
+		}
+		if err := json.Unmarshal(raw, &event); err != nil {
+			return nil, err
+		}
+		result.Events = append(result.Events, ListedEvent{ID: strings.TrimSpace(event.ID), Payload: raw})
+	}
+
+	return result, nil
+}
+
+func (p *StripeProvider) Refund(ctx context.Context, input *RefundInput) (*RefundOutput, error) {
+	if strings.TrimSpace(p.cfg.SecretKey) == "" {
+		return nil, errors.New("stripe secret key is not configured")
+	}
+
+	paymentIntentID, err := p.resolvePaymentIntentID(ctx, input.PaymentMethod, input.ProviderPaymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	values := url.Values{}
+	values.Set("payment_intent", paymentIntentID)
+	values.Set("amount", strconv.FormatInt(input.AmountCents, 10))
+	if reason := strings.TrimSpace(input.Reason); reason != "" {
+		switch reason {
+		case "duplicate", "fraudulent", "requested_by_customer":
+			values.Set("reason", reason)
+		}
+		values.Set("metadata[reason]", reason)
+	}
+	values.Set("metadata[request_id]", input.RequestID)
+	values.Set("metadata[payment_id]", strconv.FormatUint(input.PaymentID, 10))
+
+	body, err := p.postForm(ctx, "/v1/refunds", values, stepKey(input.IdempotencyKey, "refund"))
+	if err != nil {
+		return nil, err
+	}
+
+	var refund struct {
+		ID     string `json:"id"`
+		Status string `json:"status"`
+	}
+	if err := json.Unmarshal(body, &refund); err != nil {
+		return nil, err
+	}
+	refundID := strings.TrimSpace(refund.ID)
+	if refundID == "" {
+		return nil, errors.New("stripe refund id missing")
+	}
+
+	return &RefundOutput{
+		ProviderRefundID: refundID,
+		Status:           mapStripeRefundStatus(refund.Status),
+	}, nil
+}
+
+// Cancel closes whatever the payment still has open at Stripe. Stripe rejects
+// closing a session or subscription twice, so a rejected call is checked
+// against the object's current state and counts as done when it is already
+// closed: a retried cancel, or one racing an expiry, then succeeds.
+func (p *StripeProvider) Cancel(ctx context.Context, input *CancelInput) error {
+	if strings.TrimSpace(p.cfg.SecretKey) == "" {
+		return errors.New("stripe secret key is not configured")
+	}
+
+	subscriptionID := strings.TrimSpace(input.ProviderSubscriptionID)
+	if input.PaymentType == int32(types.PaymentType_PAYMENT_TYPE_RECURRING) && subscriptionID != "" {
+		values := url.Values{}
+		if reason := strings.TrimSpace(input.Reason); reason != "" {
+			values.Set("cancellation_details[comment]", reason)
+		}
+		if err := p.deleteSubscription(ctx, subscriptionID, values); err != nil {
+			return err
+		}
+	}
+
+	providerPaymentID := strings.TrimSpace(input.ProviderPaymentID)
+	if providerPaymentID == "" {
+		return nil
+	}
+
+	switch input.PaymentMethod {
+	case int32(types.PaymentMethod_PAYMENT_METHOD_PAYMENT_LINK):
+		values := url.Values{}
+		values.Set("active", "false")
+		_, err := p.postForm(ctx, "/v1/payment_links/"+url.PathEscape(providerPaymentID), values, stepKey(input.IdempotencyKey, "payment_link_deactivate"))
+		return err
+	case int32(types.PaymentMethod_PAYMENT_METHOD_HOSTED_CARD):
+		if subscriptionID != "" {
+			return nil
+		}
+		_, err := p.postForm(ctx, "/v1/checkout/sessions/"+url.PathEscape(providerPaymentID)+"/expire", url.Values{}, stepKey(input.IdempotencyKey, "checkout_session_expire"))
+		if err == nil || !isStripeClientError(err) {
+			return err
+		}
+		return p.checkoutSessionClosed(ctx, providerPaymentID, err)
+	default:
+		return errors.New("unsupported payment method for stripe")
+	}
+}
+
+// checkoutSessionClosed resolves a rejected expire. An expired session is
+// what the caller asked for; a completed one was paid in the meantime and
+// must not be reported as canceled.
+func (p *StripeProvider) checkoutSessionClosed(ctx context.Context, sessionID string, expireErr error) error {
+	body, err := p.getJSON(ctx, "/v1/checkout/sessions/"+url.PathEscape(sessionID), nil)
+	if err != nil {
+		return expireErr
+	}
+	var session struct {
+		Status string `json:"status"`
+	}
+	if err := json.Unmarshal(body, &session); err != nil {
+		return expireErr
+	}
+	switch session.Status {
 	case "expired":
-		return int32(types.PaymentStatus_PAYMENT_STATUS_EXPIRED), nil
+		return nil
+	case "complete":
+		return fmt.Errorf("stripe checkout session %s is already complete: %w", sessionID, expireErr)
+	default:
+		return expireErr
 	}
+}
 
-	switch payload.PaymentStatus {
-	case "paid", "no_payment_required":
-		return int32(types.PaymentStatus_PAYMENT_STATUS_PAID), nil
-	case "unpaid":
-		return int32(types.PaymentStatus_PAYMENT_STATUS_PENDING), nil
+// deleteSubscription cancels a subscription immediately. Stripe rejects the
+// DELETE once the subscription is canceled, which counts as done.
+func (p *StripeProvider) deleteSubscription(ctx context.Context, subscriptionID string, values url.Values) error {
+	_, err := p.deleteForm(ctx, "/v1/subscriptions/"+url.PathEscape(subscriptionID), values)
+	if err == nil || !isStripeClientError(err) {
+		return err
+	}
+
+	body, getErr := p.getJSON(ctx, "/v1/subscriptions/"+url.PathEscape(subscriptionID), nil)
+	if getErr != nil {
+		return err
+	}
+	var subscription struct {
+		Status string `json:"status"`
+	}
+	if json.Unmarshal(body, &subscription) != nil {
+		return err
+	}
+	switch subscription.Status {
+	case "canceled", "incomplete_expired":
+		return nil
 	default:
-		return 0, nil
+		return err
+	}
+}
+
+func (p *StripeProvider) PauseSubscription(ctx context.Context, input *SubscriptionInput) error {
+	subscriptionID, err := p.subscriptionID(input.ProviderSubscriptionID)
+	if err != nil {
+		return err
+	}
+
+	values := url.Values{}
+	values.Set("pause_collection[behavior]", "void")
+	if reason := strings.TrimSpace(input.Reason); reason != "" {
+		values.Set("metadata[pause_reason]", reason)
+	}
+	_, err = p.postForm(ctx, "/v1/subscriptions/"+url.PathEscape(subscriptionID), values, stepKey(input.IdempotencyKey, "subscription"))
+	return err
+}
+
+func (p *StripeProvider) ResumeSubscription(ctx context.Context, input *SubscriptionInput) error {
+	subscriptionID, err := p.subscriptionID(input.ProviderSubscriptionID)
+	if err != nil {
+		return err
 	}
+
+	// An empty value unsets pause_collection on the Stripe side.
+	values := url.Values{}
+	values.Set("pause_collection", "")
+	values.Set("metadata[pause_reason]", "")
+	_, err = p.postForm(ctx, "/v1/subscriptions/"+url.PathEscape(subscriptionID), values, stepKey(input.IdempotencyKey, "subscription"))
+	return err
 }
 
-func (p *StripeProvider) VerifyAndParseCallback(_ context.Context, payload []byte, signature string) (*CallbackEvent, error) {
+func (p *StripeProvider) CancelSubscription(ctx context.Context, input *SubscriptionInput) error {
+	subscriptionID, err := p.subscriptionID(input.ProviderSubscriptionID)
+	if err != nil {
+		return err
+	}
+
+	values := url.Values{}
+	if reason := strings.TrimSpace(input.Reason); reason != "" {
+		values.Set("cancellation_details[comment]", reason)
+	}
+	if input.AtPeriodEnd {
+		values.Set("cancel_at_period_end", "true")
+		_, err = p.postForm(ctx, "/v1/subscriptions/"+url.PathEscape(subscriptionID), values, stepKey(input.IdempotencyKey, "subscription"))
+		return err
+	}
+	return p.deleteSubscription(ctx, subscriptionID, values)
+}
+
+func (p *StripeProvider) UpdateSubscription(ctx context.Context, input *UpdateSubscriptionInput) error {
+	subscriptionID, err := p.subscriptionID(input.ProviderSubscriptionID)
+	if err != nil {
+		return err
+	}
+
+	body, err := p.getJSON(ctx, "/v1/subscriptions/"+url.PathEscape(subscriptionID), nil)
+	if err != nil {
+		return err
+	}
+	var subscription struct {
+		Items struct {
+			Data []struct {
+				ID    string `json:"id"`
+				Price struct {
+					Product interface{} `json:"product"`
+				} `json:"price"`
+			} `json:"data"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(body, &subscription); err != nil {
+		return err
+	}
+	if len(subscription.Items.Data) == 0 {
+		return errors.New("stripe subscription has no items")
+	}
+	item := subscription.Items.Data[0]
+	productID := parseStringish(item.Price.Product)
+	if strings.TrimSpace(item.ID) == "" || productID == "" {
+		return errors.New("stripe subscription item is incomplete")
+	}
+
+	// The new price applies from the next billing period; the running period
+	// is not prorated.
+	values := url.Values{}
+	values.Set("items[0][id]", strings.TrimSpace(item.ID))
+	values.Set("items[0][price_data][currency]", strings.ToLower(input.Currency))
+	values.Set("items[0][price_data][product]", productID)
+	values.Set("items[0][price_data][unit_amount]", strconv.FormatInt(input.AmountCents, 10))
+	values.Set("items[0][price_data][recurring][interval]", input.RecurringInterval)
+	values.Set("items[0][price_data][recurring][interval_count]", strconv.FormatInt(int64(input.RecurringIntervalCount), 10))
+	values.Set("proration_behavior", "none")
+	_, err = p.postForm(ctx, "/v1/subscriptions/"+url.PathEscape(subscriptionID), values, stepKey(input.IdempotencyKey, "subscription"))
+	return err
+}
+
+func (p *StripeProvider) subscriptionID(providerSubscriptionID string) (string, error) {
+	if strings.TrimSpace(p.cfg.SecretKey) == "" {
+		return "", errors.New("stripe secret key is not configured")
+	}
+	subscriptionID := strings.TrimSpace(providerSubscriptionID)
+	if subscriptionID == "" {
+		return "", errors.New("provider subscription id is empty")
+	}
+	return subscriptionID, nil
+}
+
+// CallbackContract reports that Stripe signs the raw body and sends the
+// signature in the Stripe-Signature header.
+func (p *StripeProvider) CallbackContract() CallbackContract {
+	return CallbackContract{SignatureHeader: "Stripe-Signature", RawBody: true}
+}
+
+func (p *StripeProvider) VerifyAndParseCallback(ctx context.Context, payload []byte, signature string) (*CallbackEvent, error) {
 	if strings.TrimSpace(p.cfg.WebhookSecret) == "" {
 		return nil, errors.New("stripe webhook secret is not configured")
 	}
@@ -129,6 +517,12 @@ func (p *StripeProvider) VerifyAndParseCallback(_ context.Context, payload []byt
 		return nil, errors.New("invalid stripe signature")
 	}
 
+	return p.ParseCallback(ctx, payload)
+}
+
+// ParseCallback parses a Stripe event without checking its signature. It is
+// meant for payloads that were verified when they were first received.
+func (p *StripeProvider) ParseCallback(_ context.Context, payload []byte) (*CallbackEvent, error) {
 	var event struct {
 		ID   string `json:"id"`
 		Type string `json:"type"`
@@ -167,6 +561,12 @@ func (p *StripeProvider) VerifyAndParseCallback(_ context.Context, payload []byt
 	case "customer.subscription.deleted":
 		result.NewStatus = int32(types.PaymentStatus_PAYMENT_STATUS_CANCELED)
 		assignSubscriptionFields(result, event.Data.Object)
+	case "charge.refunded":
+		result.NewStatus = 0
+		assignChargeRefundFields(result, event.Data.Object)
+	case "refund.created", "refund.updated", "charge.refund.updated":
+		result.NewStatus = 0
+		assignRefundFields(result, event.Data.Object)
 	default:
 		result.NewStatus = 0
 	}
@@ -206,8 +606,11 @@ func (p *StripeProvider) createCheckoutSession(ctx context.Context, input *Creat
 	}
 	values.Set("metadata[request_id]", input.RequestID)
 	values.Set("metadata[callback_hash]", input.CallbackHash)
+	if input.PaymentType == int32(types.PaymentType_PAYMENT_TYPE_RECURRING) {
+		values.Set("subscription_data[metadata][callback_hash]", input.CallbackHash)
+	}
 
-	body, err := p.postForm(ctx, "/v1/checkout/sessions", values)
+	body, err := p.postForm(ctx, "/v1/checkout/sessions", values, stepKey(input.IdempotencyKey, "checkout_session"))
 	if err != nil {
 		return nil, err
 	}
@@ -241,7 +644,7 @@ func (p *StripeProvider) createCheckoutSession(ctx context.Context, input *Creat
 func (p *StripeProvider) createPaymentLink(ctx context.Context, input *CreateInput, callbackURL string) (*CreateOutput, error) {
 	productValues := url.Values{}
 	productValues.Set("name", buildProductName(input))
-	productResp, err := p.postForm(ctx, "/v1/products", productValues)
+	productResp, err := p.postForm(ctx, "/v1/products", productValues, stepKey(input.IdempotencyKey, "product"))
 	if err != nil {
 		return nil, err
 	}
@@ -264,7 +667,7 @@ func (p *StripeProvider) createPaymentLink(ctx context.Context, input *CreateInp
 		priceValues.Set("recurring[interval]", input.RecurringInterval)
 		priceValues.Set("recurring[interval_count]", strconv.FormatInt(int64(input.RecurringIntervalCount), 10))
 	}
-	priceResp, err := p.postForm(ctx, "/v1/prices", priceValues)
+	priceResp, err := p.postForm(ctx, "/v1/prices", priceValues, stepKey(input.IdempotencyKey, "price"))
 	if err != nil {
 		return nil, err
 	}
@@ -289,8 +692,11 @@ func (p *StripeProvider) createPaymentLink(ctx context.Context, input *CreateInp
 	}
 	linkValues.Set("metadata[request_id]", input.RequestID)
 	linkValues.Set("metadata[callback_hash]", input.CallbackHash)
+	if input.PaymentType == int32(types.PaymentType_PAYMENT_TYPE_RECURRING) {
+		linkValues.Set("subscription_data[metadata][callback_hash]", input.CallbackHash)
+	}
 
-	linkResp, err := p.postForm(ctx, "/v1/payment_links", linkValues)
+	linkResp, err := p.postForm(ctx, "/v1/payment_links", linkValues, stepKey(input.IdempotencyKey, "payment_link"))
 	if err != nil {
 		return nil, err
 	}
@@ -316,13 +722,149 @@ func (p *StripeProvider) createPaymentLink(ctx context.Context, input *CreateInp
 	return result, nil
 }
 
-func (p *StripeProvider) postForm(ctx context.Context, path string, values url.Values) ([]byte, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://api.stripe.com"+path, strings.NewReader(values.Encode()))
+func (p *StripeProvider) resolvePaymentIntentID(ctx context.Context, paymentMethod int32, providerPaymentID string) (string, error) {
+	providerPaymentID = strings.TrimSpace(providerPaymentID)
+	if providerPaymentID == "" {
+		return "", errors.New("provider payment id is empty")
+	}
+
+	var sessionBody []byte
+	var err error
+	if paymentMethod == int32(types.PaymentMethod_PAYMENT_METHOD_PAYMENT_LINK) && !isCheckoutSessionID(providerPaymentID) {
+		query := url.Values{}
+		query.Set("payment_link", providerPaymentID)
+		query.Set("status", "complete")
+		query.Set("limit", "1")
+		listBody, err := p.getJSON(ctx, "/v1/checkout/sessions", query)
+		if err != nil {
+			return "", err
+		}
+		var list struct {
+			Data []json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(listBody, &list); err != nil {
+			return "", err
+		}
+		if len(list.Data) == 0 {
+			return "", errors.New("stripe completed checkout session not found for payment link")
+		}
+		sessionBody = list.Data[0]
+	} else {
+		sessionBody, err = p.getJSON(ctx, "/v1/checkout/sessions/"+url.PathEscape(providerPaymentID), nil)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	var session struct {
+		PaymentIntent interface{} `json:"payment_intent"`
+		Invoice       interface{} `json:"invoice"`
+	}
+	if err := json.Unmarshal(sessionBody, &session); err != nil {
+		return "", err
+	}
+	if s := parseStringish(session.PaymentIntent); s != "" {
+		return s, nil
+	}
+
+	invoiceID := parseStringish(session.Invoice)
+	if invoiceID == "" {
+		return "", errors.New("stripe payment intent not found for payment")
+	}
+	invoiceBody, err := p.getJSON(ctx, "/v1/invoices/"+url.PathEscape(invoiceID), nil)
+	if err != nil {
+		return "", err
+	}
+	var invoice struct {
+		PaymentIntent interface{} `json:"payment_intent"`
+	}
+	if err := json.Unmarshal(invoiceBody, &invoice); err != nil {
+		return "", err
+	}
+	if s := parseStringish(invoice.PaymentIntent); s != "" {
+		return s, nil
+	}
+
+	return "", errors.New("stripe payment intent not found for payment")
+}
+
+// isCheckoutSessionID tells a checkout session ID from a payment link ID.
+// Payment-link payments normally keep their plink_ ID, but rows written before
+// that was the case hold the cs_ ID of the session that paid them.
+func isCheckoutSessionID(id string) bool {
+	return strings.HasPrefix(id, "cs_")
+}
+
+func (p *StripeProvider) getJSON(ctx context.Context, path string, query url.Values) ([]byte, error) {
+	return p.do(ctx, http.MethodGet, path, query, "")
+}
+
+func (p *StripeProvider) postForm(ctx context.Context, path string, values url.Values, idempotencyKey string) ([]byte, error) {
+	return p.do(ctx, http.MethodPost, path, values, idempotencyKey)
+}
+
+// deleteForm needs no idempotency key: Stripe treats DELETE as idempotent.
+func (p *StripeProvider) deleteForm(ctx context.Context, path string, values url.Values) ([]byte, error) {
+	return p.do(ctx, http.MethodDelete, path, values, "")
+}
+
+// do sends a Stripe request and retries network errors, 429 and 5xx responses
+// with exponential backoff, waiting for Retry-After when Stripe sends it.
+// A POST is only retried when it carries an idempotency key.
+func (p *StripeProvider) do(ctx context.Context, method, path string, values url.Values, idempotencyKey string) ([]byte, error) {
+	retryable := method != http.MethodPost || idempotencyKey != ""
+	for attempt := 0; ; attempt++ {
+		body, err := p.doOnce(ctx, method, path, values, idempotencyKey)
+		if err == nil {
+			return body, nil
+		}
+		if !retryable || attempt >= p.cfg.MaxNetworkRetries || ctx.Err() != nil {
+			return nil, err
+		}
+
+		delay := p.retryDelay(attempt)
+		var httpErr *stripeHTTPError
+		if errors.As(err, &httpErr) {
+			if !httpErr.retryable() {
+				return nil, err
+			}
+			if httpErr.retryAfter >= 0 {
+				delay = httpErr.retryAfter
+			}
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, err
+		case <-timer.C:
+		}
+	}
+}
+
+func (p *StripeProvider) doOnce(ctx context.Context, method, path string, values url.Values, idempotencyKey string) ([]byte, error) {
+	target := p.cfg.BaseURL + path
+	var body io.Reader
+	if method == http.MethodGet {
+		if len(values) > 0 {
+			target += "?" + values.Encode()
+		}
+	} else {
+		body = strings.NewReader(values.Encode())
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Set("Authorization", "Bearer "+p.cfg.SecretKey)
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
 
 	resp, err := p.client.Do(req)
 	if err != nil {
@@ -330,15 +872,118 @@ func (p *StripeProvider) postForm(ctx context.Context, path string, values url.V
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, err
 	}
 	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("stripe request failed: path=%s status=%d body=%s", path, resp.StatusCode, string(body))
+		return nil, &stripeHTTPError{
+			path:        path,
+			status:      resp.StatusCode,
+			body:        string(respBody),
+			retryAfter:  parseRetryAfter(resp.Header.Get("Retry-After")),
+			shouldRetry: resp.Header.Get("Stripe-Should-Retry"),
+		}
+	}
+
+	return respBody, nil
+}
+
+// retryDelay doubles RetryBaseDelay per attempt up to RetryMaxDelay.
+func (p *StripeProvider) retryDelay(attempt int) time.Duration {
+	delay := p.cfg.RetryBaseDelay
+	for i := 0; i < attempt && delay < p.cfg.RetryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.cfg.RetryMaxDelay {
+		delay = p.cfg.RetryMaxDelay
+	}
+	return delay
+}
+
+type stripeHTTPError struct {
+	path        string
+	status      int
+	body        string
+	retryAfter  time.Duration
+	shouldRetry string
+}
+
+// isStripeClientError reports whether Stripe answered with a 4xx, i.e. it
+// rejected the request rather than failing to handle it.
+func isStripeClientError(err error) bool {
+	var httpErr *stripeHTTPError
+	return errors.As(err, &httpErr) && httpErr.status >= 400 && httpErr.status < 500
+}
+
+func (e *stripeHTTPError) Error() string {
+	return fmt.Sprintf("stripe request failed: path=%s status=%d body=%s", e.path, e.status, e.body)
+}
+
+// Is matches ErrRequestRejected for the statuses Stripe uses for requests
+// that can never succeed: invalid parameters, a declined request and a
+// missing object. Authentication errors are left out because they go away
+// once the configuration is fixed.
+func (e *stripeHTTPError) Is(target error) bool {
+	if target != ErrRequestRejected {
+		return false
+	}
+	switch e.status {
+	case http.StatusBadRequest, http.StatusPaymentRequired, http.StatusNotFound:
+		return true
+	default:
+		return false
+	}
+}
+
+// retryable follows Stripe-Should-Retry when present, else retries rate
+// limits and server errors.
+func (e *stripeHTTPError) retryable() bool {
+	switch e.shouldRetry {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	return e.status == http.StatusTooManyRequests || e.status >= 500
+}
+
+// parseRetryAfter reads delay-seconds or an HTTP date; -1 means absent or
+// unreadable. Waits are capped at maxStripeRetryAfter.
+func parseRetryAfter(value string) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return -1
+	}
+	var delay time.Duration
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		delay = time.Duration(seconds) * time.Second
+	} else if at, err := http.ParseTime(value); err == nil {
+		delay = time.Until(at)
+	} else {
+		return -1
 	}
+	if delay < 0 {
+		delay = 0
+	}
+	if delay > maxStripeRetryAfter {
+		delay = maxStripeRetryAfter
+	}
+	return delay
+}
 
-	return body, nil
+// stepKey derives the key of one Stripe call from the operation's base key.
+// Stripe accepts keys of up to 255 characters; longer ones are hashed.
+func stepKey(baseKey, step string) string {
+	if strings.TrimSpace(baseKey) == "" {
+		return ""
+	}
+	key := baseKey + ":" + step
+	if len(key) > 255 {
+		sum := sha256.Sum256([]byte(key))
+		key = hex.EncodeToString(sum[:])
+	}
+	return key
 }
 
 func buildProductName(input *CreateInput) string {
@@ -410,12 +1055,14 @@ func verifyStripeSignature(payload []byte, signatureHeader string, webhookSecret
 
 func assignCheckoutSessionFields(event *CallbackEvent, payload json.RawMessage) {
 	var object struct {
-		ID           string      `json:"id"`
-		Subscription interface{} `json:"subscription"`
+		ID           string            `json:"id"`
+		Subscription interface{}       `json:"subscription"`
+		Metadata     map[string]string `json:"metadata"`
 	}
 	if json.Unmarshal(payload, &object) != nil {
 		return
 	}
+	assignCallbackHash(event, object.Metadata)
 	if s := strings.TrimSpace(object.ID); s != "" {
 		event.ProviderPaymentID = &s
 	}
@@ -425,33 +1072,178 @@ func assignCheckoutSessionFields(event *CallbackEvent, payload json.RawMessage)
 }
 
 func assignInvoiceFields(event *CallbackEvent, payload json.RawMessage) {
+	type stripePeriod struct {
+		Start int64 `json:"start"`
+		End   int64 `json:"end"`
+	}
 	var object struct {
 		ID           string      `json:"id"`
 		Subscription interface{} `json:"subscription"`
+		AmountPaid   int64       `json:"amount_paid"`
+		AmountDue    int64       `json:"amount_due"`
+		Currency     string      `json:"currency"`
+		PeriodStart  int64       `json:"period_start"`
+		PeriodEnd    int64       `json:"period_end"`
+		Details      struct {
+			Metadata map[string]string `json:"metadata"`
+		} `json:"subscription_details"`
+		Lines struct {
+			Data []struct {
+				Period stripePeriod `json:"period"`
+			} `json:"data"`
+		} `json:"lines"`
 	}
 	if json.Unmarshal(payload, &object) != nil {
 		return
 	}
-	if s := strings.TrimSpace(object.ID); s != "" {
-		event.ProviderPaymentID = &s
-	}
 	if s := parseStringish(object.Subscription); s != "" {
 		event.ProviderSubscriptionID = &s
 	}
+	assignCallbackHash(event, object.Details.Metadata)
+
+	invoiceID := strings.TrimSpace(object.ID)
+	if invoiceID == "" {
+		return
+	}
+
+	amount := object.AmountPaid
+	if event.NewStatus != int32(types.PaymentStatus_PAYMENT_STATUS_PAID) || amount == 0 {
+		amount = object.AmountDue
+	}
+
+	// The line period covers the billed subscription interval; the invoice
+	// level period_start/period_end only describe the usage window.
+	periodStart, periodEnd := object.PeriodStart, object.PeriodEnd
+	if len(object.Lines.Data) > 0 && object.Lines.Data[0].Period.Start > 0 {
+		periodStart = object.Lines.Data[0].Period.Start
+		periodEnd = object.Lines.Data[0].Period.End
+	}
+
+	event.Invoice = &CallbackInvoice{
+		ProviderInvoiceID: invoiceID,
+		AmountCents:       amount,
+		Currency:          strings.ToUpper(strings.TrimSpace(object.Currency)),
+		Status:            event.NewStatus,
+		PeriodStart:       unixTimePtr(periodStart),
+		PeriodEnd:         unixTimePtr(periodEnd),
+	}
+}
+
+func unixTimePtr(seconds int64) *time.Time {
+	if seconds <= 0 {
+		return nil
+	}
+	t := time.Unix(seconds, 0).UTC()
+	return &t
 }
 
 func assignSubscriptionFields(event *CallbackEvent, payload json.RawMessage) {
 	var object struct {
-		ID string `json:"id"`
+		ID       string            `json:"id"`
+		Metadata map[string]string `json:"metadata"`
 	}
 	if json.Unmarshal(payload, &object) != nil {
 		return
 	}
+	assignCallbackHash(event, object.Metadata)
 	if s := strings.TrimSpace(object.ID); s != "" {
 		event.ProviderSubscriptionID = &s
 	}
 }
 
+// assignCallbackHash picks up the callback hash stored in metadata when the
+// payment was created. Payment link metadata is copied to its checkout
+// sessions and subscription metadata to its invoices.
+func assignCallbackHash(event *CallbackEvent, metadata map[string]string) {
+	if s := strings.TrimSpace(metadata["callback_hash"]); s != "" {
+		event.CallbackHash = &s
+	}
+}
+
+func assignChargeRefundFields(event *CallbackEvent, payload json.RawMessage) {
+	var object struct {
+		AmountRefunded int64 `json:"amount_refunded"`
+	}
+	if json.Unmarshal(payload, &object) != nil {
+		return
+	}
+	total := object.AmountRefunded
+	event.TotalRefundedCents = &total
+}
+
+func assignRefundFields(event *CallbackEvent, payload json.RawMessage) {
+	var object struct {
+		ID     string `json:"id"`
+		Amount int64  `json:"amount"`
+		Status string `json:"status"`
+	}
+	if json.Unmarshal(payload, &object) != nil {
+		return
+	}
+	refundID := strings.TrimSpace(object.ID)
+	if refundID == "" {
+		return
+	}
+	event.Refund = &CallbackRefund{
+		ProviderRefundID: refundID,
+		AmountCents:      object.Amount,
+		Status:           mapStripeRefundStatus(object.Status),
+	}
+}
+
+func mapStripeCheckoutSessionStatus(status, paymentStatus string) int32 {
+	switch status {
+	case "expired":
+		return int32(types.PaymentStatus_PAYMENT_STATUS_EXPIRED)
+	}
+
+	switch paymentStatus {
+	case "paid", "no_payment_required":
+		return int32(types.PaymentStatus_PAYMENT_STATUS_PAID)
+	case "unpaid":
+		return int32(types.PaymentStatus_PAYMENT_STATUS_PENDING)
+	default:
+		return 0
+	}
+}
+
+// mapStripeSubscriptionStatus maps a subscription and the status of its
+// latest invoice to the status of the payment that started it.
+func mapStripeSubscriptionStatus(subscriptionStatus, invoiceStatus string) int32 {
+	switch subscriptionStatus {
+	case "canceled":
+		return int32(types.PaymentStatus_PAYMENT_STATUS_CANCELED)
+	case "incomplete_expired":
+		return int32(types.PaymentStatus_PAYMENT_STATUS_EXPIRED)
+	case "active", "trialing":
+		return int32(types.PaymentStatus_PAYMENT_STATUS_PAID)
+	}
+
+	switch invoiceStatus {
+	case "paid":
+		return int32(types.PaymentStatus_PAYMENT_STATUS_PAID)
+	case "uncollectible", "void":
+		return int32(types.PaymentStatus_PAYMENT_STATUS_FAILED)
+	case "open", "draft":
+		return int32(types.PaymentStatus_PAYMENT_STATUS_PENDING)
+	default:
+		return 0
+	}
+}
+
+func mapStripeRefundStatus(status string) int32 {
+	switch strings.TrimSpace(status) {
+	case "succeeded":
+		return int32(types.RefundStatus_REFUND_STATUS_SUCCEEDED)
+	case "failed":
+		return int32(types.RefundStatus_REFUND_STATUS_FAILED)
+	case "canceled":
+		return int32(types.RefundStatus_REFUND_STATUS_CANCELED)
+	default:
+		return int32(types.RefundStatus_REFUND_STATUS_PENDING)
+	}
+}
+
 func parseStringish(v interface{}) string {
 	switch t := v.(type) {
 	case string:
@@ -484,6 +1276,9 @@ func parseStringish(v interface{}) string {
 	return ""
 }
 
+// BuildForwardPayload wraps a received webhook into the envelope accepted by
+// the internal forwarding route, for proxies that relay webhooks to this
+// service instead of routing the provider to the webhook listener.
 func BuildForwardPayload(payload []byte, signature string) []byte {
 	message := map[string]string{
 		"payload":   string(payload),
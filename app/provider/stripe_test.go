@@ -1,12 +1,21 @@
 package provider
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/vibast-solutions/ms-go-payments/app/provider/stripefake"
+	"github.com/vibast-solutions/ms-go-payments/app/types"
 )
 
 func TestVerifyStripeSignature(t *testing.T) {
@@ -38,3 +47,578 @@ func TestJoinCallbackURL(t *testing.T) {
 		t.Fatal("expected empty callback URL when base URL is empty")
 	}
 }
+
+func TestVerifyAndParseCallbackRefundEvents(t *testing.T) {
+	secret := "whsec_test"
+	p := NewStripeProvider(StripeConfig{WebhookSecret: secret})
+
+	sign := func(payload []byte) string {
+		ts := time.Now().Unix()
+		mac := hmac.New(sha256.New, []byte(secret))
+		_, _ = mac.Write([]byte(fmt.Sprintf("%d.%s", ts, string(payload))))
+		return fmt.Sprintf("t=%d,v1=%s", ts, hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	chargePayload := []byte(`{"id":"evt_charge","type":"charge.refunded","data":{"object":{"id":"ch_1","amount":1000,"amount_refunded":400}}}`)
+	event, err := p.VerifyAndParseCallback(context.Background(), chargePayload, sign(chargePayload))
+	if err != nil {
+		t.Fatalf("parse charge.refunded failed: %v", err)
+	}
+	if event.NewStatus != 0 || event.TotalRefundedCents == nil || *event.TotalRefundedCents != 400 {
+		t.Fatalf("unexpected charge.refunded event: %+v", event)
+	}
+
+	refundPayload := []byte(`{"id":"evt_refund","type":"refund.updated","data":{"object":{"id":"re_1","amount":400,"status":"failed"}}}`)
+	event, err = p.VerifyAndParseCallback(context.Background(), refundPayload, sign(refundPayload))
+	if err != nil {
+		t.Fatalf("parse refund.updated failed: %v", err)
+	}
+	if event.Refund == nil || event.Refund.ProviderRefundID != "re_1" || event.Refund.AmountCents != 400 {
+		t.Fatalf("unexpected refund.updated event: %+v", event)
+	}
+	if event.Refund.Status != int32(types.RefundStatus_REFUND_STATUS_FAILED) {
+		t.Fatalf("expected failed refund status, got %d", event.Refund.Status)
+	}
+}
+
+func TestVerifyAndParseCallbackInvoicePaid(t *testing.T) {
+	secret := "whsec_test"
+	p := NewStripeProvider(StripeConfig{WebhookSecret: secret})
+
+	payload := []byte(`{"id":"evt_invoice","type":"invoice.paid","data":{"object":{"id":"in_1","subscription":"sub_1","amount_paid":1999,"amount_due":1999,"currency":"usd","period_start":1700000000,"period_end":1700000000,"lines":{"data":[{"period":{"start":1700000000,"end":1702592000}}]}}}}`)
+	ts := time.Now().Unix()
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte(fmt.Sprintf("%d.%s", ts, string(payload))))
+	signature := fmt.Sprintf("t=%d,v1=%s", ts, hex.EncodeToString(mac.Sum(nil)))
+
+	event, err := p.VerifyAndParseCallback(context.Background(), payload, signature)
+	if err != nil {
+		t.Fatalf("parse invoice.paid failed: %v", err)
+	}
+	if event.ProviderPaymentID != nil {
+		t.Fatalf("expected invoice id not to replace provider payment id, got %q", *event.ProviderPaymentID)
+	}
+	if event.ProviderSubscriptionID == nil || *event.ProviderSubscriptionID != "sub_1" {
+		t.Fatalf("unexpected subscription id: %+v", event.ProviderSubscriptionID)
+	}
+	invoice := event.Invoice
+	if invoice == nil || invoice.ProviderInvoiceID != "in_1" || invoice.AmountCents != 1999 || invoice.Currency != "USD" {
+		t.Fatalf("unexpected invoice: %+v", invoice)
+	}
+	if invoice.Status != int32(types.PaymentStatus_PAYMENT_STATUS_PAID) {
+		t.Fatalf("expected paid invoice status, got %d", invoice.Status)
+	}
+	if invoice.PeriodStart == nil || invoice.PeriodEnd == nil || invoice.PeriodEnd.Unix() != 1702592000 {
+		t.Fatalf("expected line item billing period, got start=%v end=%v", invoice.PeriodStart, invoice.PeriodEnd)
+	}
+}
+
+func TestMapStripeSubscriptionStatus(t *testing.T) {
+	cases := []struct {
+		subscription string
+		invoice      string
+		want         types.PaymentStatus
+	}{
+		{"active", "paid", types.PaymentStatus_PAYMENT_STATUS_PAID},
+		{"trialing", "", types.PaymentStatus_PAYMENT_STATUS_PAID},
+		{"incomplete", "open", types.PaymentStatus_PAYMENT_STATUS_PENDING},
+		{"incomplete", "paid", types.PaymentStatus_PAYMENT_STATUS_PAID},
+		{"incomplete_expired", "void", types.PaymentStatus_PAYMENT_STATUS_EXPIRED},
+		{"past_due", "uncollectible", types.PaymentStatus_PAYMENT_STATUS_FAILED},
+		{"canceled", "paid", types.PaymentStatus_PAYMENT_STATUS_CANCELED},
+		{"unknown", "", types.PaymentStatus_PAYMENT_STATUS_UNSPECIFIED},
+	}
+	for _, tc := range cases {
+		if got := mapStripeSubscriptionStatus(tc.subscription, tc.invoice); got != int32(tc.want) {
+			t.Fatalf("subscription=%s invoice=%s: expected %d, got %d", tc.subscription, tc.invoice, tc.want, got)
+		}
+	}
+}
+
+func TestGetPaymentStatusSkipsEmptyIDs(t *testing.T) {
+	p := NewStripeProvider(StripeConfig{})
+	status, err := p.GetPaymentStatus(context.Background(), &StatusInput{
+		PaymentMethod: int32(types.PaymentMethod_PAYMENT_METHOD_PAYMENT_LINK),
+		PaymentType:   int32(types.PaymentType_PAYMENT_TYPE_RECURRING),
+	})
+	if err != nil || status != 0 {
+		t.Fatalf("expected no lookup without provider ids, got status=%d err=%v", status, err)
+	}
+}
+
+func TestParseCallbackReadsCallbackHashFromMetadata(t *testing.T) {
+	p := NewStripeProvider(StripeConfig{})
+
+	session := []byte(`{"id":"evt_1","type":"checkout.session.completed","data":{"object":{"id":"cs_1","payment_link":"plink_1","metadata":{"callback_hash":"hash-1"}}}}`)
+	event, err := p.ParseCallback(context.Background(), session)
+	if err != nil {
+		t.Fatalf("parse checkout session failed: %v", err)
+	}
+	if event.CallbackHash == nil || *event.CallbackHash != "hash-1" {
+		t.Fatalf("expected callback hash from session metadata, got %v", event.CallbackHash)
+	}
+
+	invoice := []byte(`{"id":"evt_2","type":"invoice.paid","data":{"object":{"id":"in_1","subscription":"sub_1","subscription_details":{"metadata":{"callback_hash":"hash-2"}}}}}`)
+	event, err = p.ParseCallback(context.Background(), invoice)
+	if err != nil {
+		t.Fatalf("parse invoice failed: %v", err)
+	}
+	if event.CallbackHash == nil || *event.CallbackHash != "hash-2" {
+		t.Fatalf("expected callback hash from subscription details, got %v", event.CallbackHash)
+	}
+	if event.ProviderSubscriptionID == nil || *event.ProviderSubscriptionID != "sub_1" {
+		t.Fatalf("expected subscription id, got %v", event.ProviderSubscriptionID)
+	}
+}
+
+func newStripeAgainstFake(t *testing.T, webhookBaseURL string) (*StripeProvider, *stripefake.Server) {
+	t.Helper()
+	fake, err := stripefake.New(stripefake.Config{SecretKey: "sk_test_fake", WebhookSecret: "whsec_test", WebhookBaseURL: webhookBaseURL})
+	if err != nil {
+		t.Fatalf("start fake stripe: %v", err)
+	}
+	t.Cleanup(fake.Close)
+
+	return NewStripeProvider(StripeConfig{
+		BaseURL:                 fake.URL,
+		SecretKey:               "sk_test_fake",
+		WebhookSecret:           "whsec_test",
+		ProviderCallbackBaseURL: "https://gateway.example/webhooks/providers/stripe",
+	}), fake
+}
+
+func TestStripeCheckoutSessionLifecycleAgainstFake(t *testing.T) {
+	p, fake := newStripeAgainstFake(t, "")
+	ctx := context.Background()
+
+	out, err := p.CreatePayment(ctx, &CreateInput{
+		RequestID:     "req-1",
+		CallbackHash:  "hash-1",
+		ResourceType:  "order",
+		ResourceID:    "42",
+		AmountCents:   1500,
+		Currency:      "EUR",
+		PaymentMethod: int32(types.PaymentMethod_PAYMENT_METHOD_HOSTED_CARD),
+		PaymentType:   int32(types.PaymentType_PAYMENT_TYPE_ONE_TIME),
+	})
+	if err != nil {
+		t.Fatalf("create payment failed: %v", err)
+	}
+	if out.ProviderPaymentID == nil || out.CheckoutURL == nil {
+		t.Fatalf("expected session id and checkout url, got %+v", out)
+	}
+	if out.ProviderCallbackURL != "https://gateway.example/webhooks/providers/stripe/hash-1" {
+		t.Fatalf("unexpected callback url: %s", out.ProviderCallbackURL)
+	}
+
+	statusInput := &StatusInput{
+		ProviderPaymentID: *out.ProviderPaymentID,
+		PaymentMethod:     int32(types.PaymentMethod_PAYMENT_METHOD_HOSTED_CARD),
+		PaymentType:       int32(types.PaymentType_PAYMENT_TYPE_ONE_TIME),
+	}
+	status, err := p.GetPaymentStatus(ctx, statusInput)
+	if err != nil || status != int32(types.PaymentStatus_PAYMENT_STATUS_PENDING) {
+		t.Fatalf("expected pending before payment, got status=%d err=%v", status, err)
+	}
+
+	eventID, err := fake.CompleteCheckoutSession(*out.ProviderPaymentID)
+	if err != nil {
+		t.Fatalf("complete session failed: %v", err)
+	}
+	status, err = p.GetPaymentStatus(ctx, statusInput)
+	if err != nil || status != int32(types.PaymentStatus_PAYMENT_STATUS_PAID) {
+		t.Fatalf("expected paid after payment, got status=%d err=%v", status, err)
+	}
+
+	page, err := p.ListEvents(ctx, &ListEventsInput{CreatedFrom: time.Now().Add(-time.Minute)})
+	if err != nil {
+		t.Fatalf("list events failed: %v", err)
+	}
+	if len(page.Events) != 1 || page.Events[0].ID != eventID || page.HasMore {
+		t.Fatalf("unexpected events page: %+v", page)
+	}
+	event, err := p.ParseCallback(ctx, page.Events[0].Payload)
+	if err != nil {
+		t.Fatalf("parse listed event failed: %v", err)
+	}
+	if event.NewStatus != int32(types.PaymentStatus_PAYMENT_STATUS_PAID) || event.CallbackHash == nil || *event.CallbackHash != "hash-1" {
+		t.Fatalf("unexpected parsed event: %+v", event)
+	}
+}
+
+func TestStripePaymentLinkStatusAgainstFake(t *testing.T) {
+	p, fake := newStripeAgainstFake(t, "")
+	ctx := context.Background()
+
+	out, err := p.CreatePayment(ctx, &CreateInput{
+		RequestID:     "req-2",
+		CallbackHash:  "hash-2",
+		ResourceType:  "order",
+		ResourceID:    "43",
+		AmountCents:   900,
+		Currency:      "USD",
+		PaymentMethod: int32(types.PaymentMethod_PAYMENT_METHOD_PAYMENT_LINK),
+		PaymentType:   int32(types.PaymentType_PAYMENT_TYPE_ONE_TIME),
+	})
+	if err != nil {
+		t.Fatalf("create payment link failed: %v", err)
+	}
+	if out.ProviderPaymentID == nil || !strings.HasPrefix(*out.ProviderPaymentID, "plink_") {
+		t.Fatalf("expected payment link id, got %+v", out.ProviderPaymentID)
+	}
+
+	statusInput := &StatusInput{
+		ProviderPaymentID: *out.ProviderPaymentID,
+		PaymentMethod:     int32(types.PaymentMethod_PAYMENT_METHOD_PAYMENT_LINK),
+		PaymentType:       int32(types.PaymentType_PAYMENT_TYPE_ONE_TIME),
+	}
+	status, err := p.GetPaymentStatus(ctx, statusInput)
+	if err != nil || status != 0 {
+		t.Fatalf("expected no status before the link is used, got status=%d err=%v", status, err)
+	}
+
+	if _, _, err := fake.PayPaymentLink(*out.ProviderPaymentID); err != nil {
+		t.Fatalf("pay payment link failed: %v", err)
+	}
+	status, err = p.GetPaymentStatus(ctx, statusInput)
+	if err != nil || status != int32(types.PaymentStatus_PAYMENT_STATUS_PAID) {
+		t.Fatalf("expected paid after the link is used, got status=%d err=%v", status, err)
+	}
+}
+
+func TestStripeCancelCheckoutSessionIsIdempotentAgainstFake(t *testing.T) {
+	p, fake := newStripeAgainstFake(t, "")
+	ctx := context.Background()
+
+	create := func(requestID string) string {
+		out, err := p.CreatePayment(ctx, &CreateInput{
+			RequestID:     requestID,
+			CallbackHash:  "hash-" + requestID,
+			ResourceType:  "order",
+			ResourceID:    requestID,
+			AmountCents:   1500,
+			Currency:      "EUR",
+			PaymentMethod: int32(types.PaymentMethod_PAYMENT_METHOD_HOSTED_CARD),
+			PaymentType:   int32(types.PaymentType_PAYMENT_TYPE_ONE_TIME),
+		})
+		if err != nil {
+			t.Fatalf("create payment failed: %v", err)
+		}
+		return *out.ProviderPaymentID
+	}
+	cancel := func(sessionID, key string) error {
+		return p.Cancel(ctx, &CancelInput{
+			IdempotencyKey:    key,
+			ProviderPaymentID: sessionID,
+			PaymentMethod:     int32(types.PaymentMethod_PAYMENT_METHOD_HOSTED_CARD),
+			PaymentType:       int32(types.PaymentType_PAYMENT_TYPE_ONE_TIME),
+		})
+	}
+
+	sessionID := create("cancel-1")
+	if err := cancel(sessionID, "cancel-1-a"); err != nil {
+		t.Fatalf("cancel failed: %v", err)
+	}
+	if session, _ := fake.CheckoutSession(sessionID); session.Status != "expired" {
+		t.Fatalf("expected expired session, got %s", session.Status)
+	}
+	if err := cancel(sessionID, "cancel-1-b"); err != nil {
+		t.Fatalf("expected cancel of an expired session to succeed, got %v", err)
+	}
+
+	lapsedID := create("cancel-2")
+	if _, err := fake.ExpireCheckoutSession(lapsedID); err != nil {
+		t.Fatalf("expire session failed: %v", err)
+	}
+	if err := cancel(lapsedID, "cancel-2"); err != nil {
+		t.Fatalf("expected cancel after provider expiry to succeed, got %v", err)
+	}
+
+	paidID := create("cancel-3")
+	if _, err := fake.CompleteCheckoutSession(paidID); err != nil {
+		t.Fatalf("complete session failed: %v", err)
+	}
+	if err := cancel(paidID, "cancel-3"); err == nil {
+		t.Fatal("expected cancel of a completed session to fail")
+	}
+}
+
+func TestStripeCancelSubscriptionIsIdempotentAgainstFake(t *testing.T) {
+	p, fake := newStripeAgainstFake(t, "")
+	ctx := context.Background()
+
+	out, err := p.CreatePayment(ctx, &CreateInput{
+		RequestID:              "req-sub",
+		CallbackHash:           "hash-sub",
+		ResourceType:           "plan",
+		ResourceID:             "pro",
+		AmountCents:            900,
+		Currency:               "EUR",
+		PaymentMethod:          int32(types.PaymentMethod_PAYMENT_METHOD_HOSTED_CARD),
+		PaymentType:            int32(types.PaymentType_PAYMENT_TYPE_RECURRING),
+		RecurringInterval:      "month",
+		RecurringIntervalCount: 1,
+	})
+	if err != nil {
+		t.Fatalf("create subscription payment failed: %v", err)
+	}
+	if _, err := fake.CompleteCheckoutSession(*out.ProviderPaymentID); err != nil {
+		t.Fatalf("complete session failed: %v", err)
+	}
+	session, _ := fake.CheckoutSession(*out.ProviderPaymentID)
+	if session.Subscription == nil {
+		t.Fatal("expected completed session to carry a subscription")
+	}
+
+	input := &CancelInput{
+		IdempotencyKey:         "cancel-sub",
+		ProviderPaymentID:      session.ID,
+		ProviderSubscriptionID: *session.Subscription,
+		PaymentMethod:          int32(types.PaymentMethod_PAYMENT_METHOD_HOSTED_CARD),
+		PaymentType:            int32(types.PaymentType_PAYMENT_TYPE_RECURRING),
+	}
+	for attempt := 1; attempt <= 2; attempt++ {
+		if err := p.Cancel(ctx, input); err != nil {
+			t.Fatalf("cancel attempt %d failed: %v", attempt, err)
+		}
+	}
+	if subscription, _ := fake.Subscription(*session.Subscription); subscription.Status != "canceled" {
+		t.Fatalf("expected canceled subscription, got %s", subscription.Status)
+	}
+	if got := fake.RequestCount(http.MethodDelete, "/v1/subscriptions/"+*session.Subscription); got != 2 {
+		t.Fatalf("expected both cancels to reach stripe, got %d", got)
+	}
+
+	err = p.CancelSubscription(ctx, &SubscriptionInput{IdempotencyKey: "cancel-sub-again", ProviderSubscriptionID: *session.Subscription})
+	if err != nil {
+		t.Fatalf("expected cancel subscription of a canceled subscription to succeed, got %v", err)
+	}
+}
+
+func TestStripeCancelPaymentLinkAgainstFake(t *testing.T) {
+	p, fake := newStripeAgainstFake(t, "")
+	ctx := context.Background()
+
+	out, err := p.CreatePayment(ctx, &CreateInput{
+		RequestID:     "req-link-cancel",
+		CallbackHash:  "hash-link-cancel",
+		ResourceType:  "order",
+		ResourceID:    "44",
+		AmountCents:   700,
+		Currency:      "USD",
+		PaymentMethod: int32(types.PaymentMethod_PAYMENT_METHOD_PAYMENT_LINK),
+		PaymentType:   int32(types.PaymentType_PAYMENT_TYPE_ONE_TIME),
+	})
+	if err != nil {
+		t.Fatalf("create payment link failed: %v", err)
+	}
+
+	for _, key := range []string{"cancel-link-a", "cancel-link-b"} {
+		err := p.Cancel(ctx, &CancelInput{
+			IdempotencyKey:    key,
+			ProviderPaymentID: *out.ProviderPaymentID,
+			PaymentMethod:     int32(types.PaymentMethod_PAYMENT_METHOD_PAYMENT_LINK),
+			PaymentType:       int32(types.PaymentType_PAYMENT_TYPE_ONE_TIME),
+		})
+		if err != nil {
+			t.Fatalf("cancel payment link failed: %v", err)
+		}
+	}
+	if link, _ := fake.PaymentLink(*out.ProviderPaymentID); link.Active {
+		t.Fatal("expected payment link to be deactivated")
+	}
+}
+
+func TestStripeFakeSendsVerifiableWebhooks(t *testing.T) {
+	received := make(chan *CallbackEvent, 1)
+	var p *StripeProvider
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.URL.Path != "/webhooks/providers/stripe/hash-3" {
+			http.Error(w, "unexpected path "+r.URL.Path, http.StatusNotFound)
+			return
+		}
+		event, err := p.VerifyAndParseCallback(r.Context(), body, r.Header.Get(p.CallbackContract().SignatureHeader))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		received <- event
+	}))
+	defer receiver.Close()
+
+	p, fake := newStripeAgainstFake(t, receiver.URL+"/webhooks/providers/stripe")
+	ctx := context.Background()
+	out, err := p.CreatePayment(ctx, &CreateInput{
+		RequestID:     "req-3",
+		CallbackHash:  "hash-3",
+		AmountCents:   500,
+		Currency:      "EUR",
+		PaymentMethod: int32(types.PaymentMethod_PAYMENT_METHOD_HOSTED_CARD),
+		PaymentType:   int32(types.PaymentType_PAYMENT_TYPE_ONE_TIME),
+	})
+	if err != nil {
+		t.Fatalf("create payment failed: %v", err)
+	}
+	eventID, err := fake.ExpireCheckoutSession(*out.ProviderPaymentID)
+	if err != nil {
+		t.Fatalf("expire session failed: %v", err)
+	}
+	if err := fake.SendWebhook(ctx, eventID); err != nil {
+		t.Fatalf("send webhook failed: %v", err)
+	}
+
+	event := <-received
+	if event.NewStatus != int32(types.PaymentStatus_PAYMENT_STATUS_EXPIRED) {
+		t.Fatalf("expected expired event, got %+v", event)
+	}
+	if event.ProviderPaymentID == nil || *event.ProviderPaymentID != *out.ProviderPaymentID {
+		t.Fatalf("expected session id on event, got %v", event.ProviderPaymentID)
+	}
+}
+
+func TestStripeRequestsFailWithWrongKeyAgainstFake(t *testing.T) {
+	_, fake := newStripeAgainstFake(t, "")
+	p := NewStripeProvider(StripeConfig{BaseURL: fake.URL + "/", SecretKey: "sk_wrong", ProviderCallbackBaseURL: "https://gateway.example/cb"})
+
+	_, err := p.CreatePayment(context.Background(), &CreateInput{
+		CallbackHash:  "hash-4",
+		AmountCents:   500,
+		Currency:      "EUR",
+		PaymentMethod: int32(types.PaymentMethod_PAYMENT_METHOD_HOSTED_CARD),
+		PaymentType:   int32(types.PaymentType_PAYMENT_TYPE_ONE_TIME),
+	})
+	if err == nil || !strings.Contains(err.Error(), "status=401") {
+		t.Fatalf("expected 401 from fake stripe, got %v", err)
+	}
+}
+
+func TestStripeRetriesIdempotentCreateAgainstFake(t *testing.T) {
+	_, fake := newStripeAgainstFake(t, "")
+	p := NewStripeProvider(StripeConfig{
+		BaseURL:                 fake.URL,
+		SecretKey:               "sk_test_fake",
+		ProviderCallbackBaseURL: "https://gateway.example/cb",
+		MaxNetworkRetries:       2,
+		RetryBaseDelay:          time.Millisecond,
+	})
+	fake.FailNext(http.MethodPost, "/v1/checkout/sessions", http.StatusBadGateway, "")
+
+	out, err := p.CreatePayment(context.Background(), &CreateInput{
+		IdempotencyKey: IdempotencyKey("orders-service", "req-5", "create"),
+		CallbackHash:   "hash-5",
+		AmountCents:    700,
+		Currency:       "EUR",
+		PaymentMethod:  int32(types.PaymentMethod_PAYMENT_METHOD_HOSTED_CARD),
+		PaymentType:    int32(types.PaymentType_PAYMENT_TYPE_ONE_TIME),
+	})
+	if err != nil {
+		t.Fatalf("expected retried create to succeed, got %v", err)
+	}
+	if got := fake.RequestCount(http.MethodPost, "/v1/checkout/sessions"); got != 2 {
+		t.Fatalf("expected 2 create attempts, got %d", got)
+	}
+	sessions := fake.CheckoutSessions()
+	if len(sessions) != 1 || sessions[0].ID != *out.ProviderPaymentID {
+		t.Fatalf("expected the replayed session only, got %+v", sessions)
+	}
+}
+
+func TestStripeHonoursRetryAfterAgainstFake(t *testing.T) {
+	_, fake := newStripeAgainstFake(t, "")
+	p := NewStripeProvider(StripeConfig{
+		BaseURL:           fake.URL,
+		SecretKey:         "sk_test_fake",
+		MaxNetworkRetries: 1,
+		RetryBaseDelay:    time.Hour,
+	})
+	fake.FailNext(http.MethodGet, "/v1/events", http.StatusTooManyRequests, "0")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := p.ListEvents(ctx, &ListEventsInput{CreatedFrom: time.Now().Add(-time.Minute)}); err != nil {
+		t.Fatalf("expected retry after Retry-After, got %v", err)
+	}
+	if got := fake.RequestCount(http.MethodGet, "/v1/events"); got != 2 {
+		t.Fatalf("expected 2 list attempts, got %d", got)
+	}
+}
+
+func TestStripeDoesNotRetryPostWithoutIdempotencyKey(t *testing.T) {
+	_, fake := newStripeAgainstFake(t, "")
+	p := NewStripeProvider(StripeConfig{
+		BaseURL:                 fake.URL,
+		SecretKey:               "sk_test_fake",
+		ProviderCallbackBaseURL: "https://gateway.example/cb",
+		MaxNetworkRetries:       2,
+		RetryBaseDelay:          time.Millisecond,
+	})
+	fake.FailNext(http.MethodPost, "/v1/checkout/sessions", http.StatusServiceUnavailable, "")
+
+	_, err := p.CreatePayment(context.Background(), &CreateInput{
+		CallbackHash:  "hash-6",
+		AmountCents:   700,
+		Currency:      "EUR",
+		PaymentMethod: int32(types.PaymentMethod_PAYMENT_METHOD_HOSTED_CARD),
+		PaymentType:   int32(types.PaymentType_PAYMENT_TYPE_ONE_TIME),
+	})
+	if err == nil || !strings.Contains(err.Error(), "status=503") {
+		t.Fatalf("expected 503 without retry, got %v", err)
+	}
+	if got := fake.RequestCount(http.MethodPost, "/v1/checkout/sessions"); got != 1 {
+		t.Fatalf("expected a single attempt, got %d", got)
+	}
+}
+
+func TestStripeFakeRejectsReusedIdempotencyKeyWithOtherParams(t *testing.T) {
+	p, _ := newStripeAgainstFake(t, "")
+	input := &CreateInput{
+		IdempotencyKey: "orders-service:req-7:create",
+		CallbackHash:   "hash-7",
+		AmountCents:    700,
+		Currency:       "EUR",
+		PaymentMethod:  int32(types.PaymentMethod_PAYMENT_METHOD_HOSTED_CARD),
+		PaymentType:    int32(types.PaymentType_PAYMENT_TYPE_ONE_TIME),
+	}
+	if _, err := p.CreatePayment(context.Background(), input); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+
+	input.AmountCents = 900
+	_, err := p.CreatePayment(context.Background(), input)
+	if err == nil || !strings.Contains(err.Error(), "idempotency_error") {
+		t.Fatalf("expected idempotency_error, got %v", err)
+	}
+}
+
+func TestStripeHTTPErrorRejectedStatuses(t *testing.T) {
+	for status, rejected := range map[int]bool{
+		http.StatusBadRequest:          true,
+		http.StatusPaymentRequired:     true,
+		http.StatusNotFound:            true,
+		http.StatusUnauthorized:        false,
+		http.StatusConflict:            false,
+		http.StatusTooManyRequests:     false,
+		http.StatusInternalServerError: false,
+	} {
+		err := fmt.Errorf("create: %w", &stripeHTTPError{path: "/v1/checkout/sessions", status: status})
+		if errors.Is(err, ErrRequestRejected) != rejected {
+			t.Fatalf("status %d: expected rejected=%v", status, rejected)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if got := parseRetryAfter(""); got != -1 {
+		t.Fatalf("expected -1 without header, got %s", got)
+	}
+	if got := parseRetryAfter("3"); got != 3*time.Second {
+		t.Fatalf("expected 3s, got %s", got)
+	}
+	if got := parseRetryAfter("3600"); got != maxStripeRetryAfter {
+		t.Fatalf("expected cap %s, got %s", maxStripeRetryAfter, got)
+	}
+	if got := parseRetryAfter(time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat)); got != 0 {
+		t.Fatalf("expected 0 for past date, got %s", got)
+	}
+}
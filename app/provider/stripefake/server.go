@@ -0,0 +1,963 @@
+// Package stripefake is an in-memory stand-in for the parts of the Stripe API
+// used by the payments service: products, prices, payment links, checkout
+// sessions, subscriptions, refunds and events. Responses follow the shape of the real API closely
+// enough for StripeProvider to run unchanged against it.
+//
+// Tests drive payments forward with CompleteCheckoutSession, PayPaymentLink
+// and ExpireCheckoutSession, which record events like Stripe does, and deliver
+// them with SendWebhook, signed with the configured webhook secret.
+//
+// POST requests with an Idempotency-Key are replayed like Stripe does, and
+// FailNext makes a request fail after it was applied, as when the response is
+// lost on the way back, to exercise client retries.
+package stripefake
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vibast-solutions/ms-go-payments/app/callbacksig"
+)
+
+type Config struct {
+	// Addr makes the server listen on a fixed address, e.g. 0.0.0.0:38085 so
+	// that containers can reach it. Empty picks a free loopback port.
+	Addr string
+	// SecretKey is the API key requests must carry. Empty accepts any key.
+	SecretKey     string
+	WebhookSecret string
+	// WebhookBaseURL receives webhooks as <WebhookBaseURL>/<callback_hash>,
+	// taking the hash from the metadata of the event object.
+	WebhookBaseURL string
+}
+
+type Product struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Name    string `json:"name"`
+	Active  bool   `json:"active"`
+	Created int64  `json:"created"`
+}
+
+type Recurring struct {
+	Interval      string `json:"interval"`
+	IntervalCount int64  `json:"interval_count"`
+}
+
+type Price struct {
+	ID         string     `json:"id"`
+	Object     string     `json:"object"`
+	Product    string     `json:"product"`
+	Currency   string     `json:"currency"`
+	UnitAmount int64      `json:"unit_amount"`
+	Type       string     `json:"type"`
+	Recurring  *Recurring `json:"recurring"`
+	Active     bool       `json:"active"`
+	Created    int64      `json:"created"`
+}
+
+type PaymentLink struct {
+	ID       string            `json:"id"`
+	Object   string            `json:"object"`
+	URL      string            `json:"url"`
+	Active   bool              `json:"active"`
+	Metadata map[string]string `json:"metadata"`
+	Created  int64             `json:"created"`
+
+	price string
+}
+
+type CheckoutSession struct {
+	ID                string            `json:"id"`
+	Object            string            `json:"object"`
+	Mode              string            `json:"mode"`
+	Status            string            `json:"status"`
+	PaymentStatus     string            `json:"payment_status"`
+	URL               *string           `json:"url"`
+	AmountTotal       int64             `json:"amount_total"`
+	Currency          string            `json:"currency"`
+	ClientReferenceID *string           `json:"client_reference_id"`
+	SuccessURL        string            `json:"success_url"`
+	CancelURL         string            `json:"cancel_url"`
+	PaymentLink       *string           `json:"payment_link"`
+	PaymentIntent     *string           `json:"payment_intent"`
+	Subscription      *string           `json:"subscription"`
+	Metadata          map[string]string `json:"metadata"`
+	Created           int64             `json:"created"`
+	ExpiresAt         int64             `json:"expires_at"`
+}
+
+type Subscription struct {
+	ID         string            `json:"id"`
+	Object     string            `json:"object"`
+	Status     string            `json:"status"`
+	CanceledAt *int64            `json:"canceled_at"`
+	Metadata   map[string]string `json:"metadata"`
+	Created    int64             `json:"created"`
+}
+
+type Refund struct {
+	ID            string            `json:"id"`
+	Object        string            `json:"object"`
+	Amount        int64             `json:"amount"`
+	Currency      string            `json:"currency"`
+	PaymentIntent string            `json:"payment_intent"`
+	Status        string            `json:"status"`
+	Metadata      map[string]string `json:"metadata"`
+	Created       int64             `json:"created"`
+}
+
+type Event struct {
+	ID         string    `json:"id"`
+	Object     string    `json:"object"`
+	Type       string    `json:"type"`
+	APIVersion string    `json:"api_version"`
+	Created    int64     `json:"created"`
+	Livemode   bool      `json:"livemode"`
+	Data       EventData `json:"data"`
+}
+
+type EventData struct {
+	Object json.RawMessage `json:"object"`
+}
+
+type Server struct {
+	URL string
+
+	cfg    Config
+	srv    *httptest.Server
+	client *http.Client
+
+	mu       sync.Mutex
+	seq      int
+	products map[string]*Product
+	prices   map[string]*Price
+	links    map[string]*PaymentLink
+	sessions map[string]*CheckoutSession
+	subs     map[string]*Subscription
+	refunds  map[string]*Refund
+	events   []*Event
+
+	idempotent map[string]*idempotentResult
+	failures   []*injectedFailure
+	requests   map[string]int
+}
+
+type idempotentResult struct {
+	method string
+	path   string
+	params string
+	status int
+	body   []byte
+}
+
+type injectedFailure struct {
+	method     string
+	path       string
+	status     int
+	retryAfter string
+}
+
+// New starts a fake Stripe server. Close it when done.
+func New(cfg Config) (*Server, error) {
+	s := &Server{
+		cfg:      cfg,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		products: make(map[string]*Product),
+		prices:   make(map[string]*Price),
+		links:    make(map[string]*PaymentLink),
+		sessions: make(map[string]*CheckoutSession),
+		subs:     make(map[string]*Subscription),
+		refunds:  make(map[string]*Refund),
+
+		idempotent: make(map[string]*idempotentResult),
+		requests:   make(map[string]int),
+	}
+
+	s.srv = httptest.NewUnstartedServer(s.routes())
+	if cfg.Addr != "" {
+		listener, err := net.Listen("tcp", cfg.Addr)
+		if err != nil {
+			return nil, err
+		}
+		_ = s.srv.Listener.Close()
+		s.srv.Listener = listener
+	}
+	s.srv.Start()
+	s.URL = s.srv.URL
+
+	return s, nil
+}
+
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+func (s *Server) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/products", s.createProduct)
+	mux.HandleFunc("POST /v1/prices", s.createPrice)
+	mux.HandleFunc("POST /v1/payment_links", s.createPaymentLink)
+	mux.HandleFunc("GET /v1/payment_links/{id}", s.getPaymentLink)
+	mux.HandleFunc("POST /v1/payment_links/{id}", s.updatePaymentLink)
+	mux.HandleFunc("POST /v1/checkout/sessions", s.createCheckoutSession)
+	mux.HandleFunc("GET /v1/checkout/sessions", s.listCheckoutSessions)
+	mux.HandleFunc("GET /v1/checkout/sessions/{id}", s.getCheckoutSession)
+	mux.HandleFunc("POST /v1/checkout/sessions/{id}/expire", s.expireCheckoutSession)
+	mux.HandleFunc("GET /v1/subscriptions/{id}", s.getSubscription)
+	mux.HandleFunc("DELETE /v1/subscriptions/{id}", s.cancelSubscription)
+	mux.HandleFunc("POST /v1/refunds", s.createRefund)
+	mux.HandleFunc("GET /v1/events", s.listEvents)
+	mux.HandleFunc("GET /v1/events/{id}", s.getEvent)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, "Unrecognized request URL ("+r.Method+": "+r.URL.Path+").")
+	})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/v1/") {
+			mux.ServeHTTP(w, r)
+			return
+		}
+		key := strings.TrimSpace(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+		if key == "" || (s.cfg.SecretKey != "" && key != s.cfg.SecretKey) {
+			writeError(w, http.StatusUnauthorized, "Invalid API Key provided.")
+			return
+		}
+		if err := r.ParseForm(); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		s.serveAPI(mux, w, r)
+	})
+}
+
+func (s *Server) serveAPI(mux http.Handler, w http.ResponseWriter, r *http.Request) {
+	key := ""
+	if r.Method == http.MethodPost {
+		key = r.Header.Get("Idempotency-Key")
+	}
+
+	s.mu.Lock()
+	s.requests[r.Method+" "+r.URL.Path]++
+	cached := s.idempotent[key]
+	s.mu.Unlock()
+
+	if cached != nil {
+		if cached.method != r.Method || cached.path != r.URL.Path || cached.params != r.Form.Encode() {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"error": map[string]string{
+					"type":    "idempotency_error",
+					"message": "Keys for idempotent requests can only be used with the same parameters they were first used with.",
+				},
+			})
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Idempotent-Replayed", "true")
+		w.WriteHeader(cached.status)
+		_, _ = w.Write(cached.body)
+		return
+	}
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, r)
+
+	s.mu.Lock()
+	if key != "" && rec.Code < 400 {
+		s.idempotent[key] = &idempotentResult{
+			method: r.Method,
+			path:   r.URL.Path,
+			params: r.Form.Encode(),
+			status: rec.Code,
+			body:   rec.Body.Bytes(),
+		}
+	}
+	failure := s.takeFailure(r.Method, r.URL.Path)
+	s.mu.Unlock()
+
+	if failure != nil {
+		if failure.retryAfter != "" {
+			w.Header().Set("Retry-After", failure.retryAfter)
+		}
+		writeError(w, failure.status, "Injected failure.")
+		return
+	}
+	for k, v := range rec.Header() {
+		w.Header()[k] = v
+	}
+	w.WriteHeader(rec.Code)
+	_, _ = w.Write(rec.Body.Bytes())
+}
+
+// FailNext makes the next request to method and path answer with status and,
+// when set, a Retry-After header. The request itself is still applied.
+func (s *Server) FailNext(method, path string, status int, retryAfter string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, &injectedFailure{method: method, path: path, status: status, retryAfter: retryAfter})
+}
+
+// RequestCount reports how many requests reached method and path.
+func (s *Server) RequestCount(method, path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[method+" "+path]
+}
+
+// CheckoutSessions returns copies of all checkout sessions, oldest first.
+func (s *Server) CheckoutSessions() []CheckoutSession {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	items := make([]CheckoutSession, 0, len(s.sessions))
+	for _, session := range s.sessions {
+		items = append(items, *session)
+	}
+	sort.Slice(items, func(i, j int) bool { return idSeq(items[i].ID) < idSeq(items[j].ID) })
+	return items
+}
+
+func (s *Server) takeFailure(method, path string) *injectedFailure {
+	for i, failure := range s.failures {
+		if failure.method == method && failure.path == path {
+			s.failures = append(s.failures[:i], s.failures[i+1:]...)
+			return failure
+		}
+	}
+	return nil
+}
+
+func (s *Server) createProduct(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimSpace(r.Form.Get("name"))
+	if name == "" {
+		writeError(w, http.StatusBadRequest, "Missing required param: name.")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	product := &Product{ID: s.nextID("prod"), Object: "product", Name: name, Active: true, Created: now()}
+	s.products[product.ID] = product
+	writeJSON(w, product)
+}
+
+func (s *Server) createPrice(w http.ResponseWriter, r *http.Request) {
+	unitAmount, err := strconv.ParseInt(r.Form.Get("unit_amount"), 10, 64)
+	if err != nil || unitAmount < 0 {
+		writeError(w, http.StatusBadRequest, "Invalid integer: unit_amount.")
+		return
+	}
+	currency := strings.ToLower(strings.TrimSpace(r.Form.Get("currency")))
+	if len(currency) != 3 {
+		writeError(w, http.StatusBadRequest, "Missing required param: currency.")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	productID := r.Form.Get("product")
+	if _, ok := s.products[productID]; !ok {
+		writeError(w, http.StatusBadRequest, "No such product: '"+productID+"'")
+		return
+	}
+
+	price := &Price{
+		ID:         s.nextID("price"),
+		Object:     "price",
+		Product:    productID,
+		Currency:   currency,
+		UnitAmount: unitAmount,
+		Type:       "one_time",
+		Active:     true,
+		Created:    now(),
+	}
+	if recurring := parseRecurring(r.Form, "recurring"); recurring != nil {
+		price.Type = "recurring"
+		price.Recurring = recurring
+	}
+	s.prices[price.ID] = price
+	writeJSON(w, price)
+}
+
+func (s *Server) createPaymentLink(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	priceID := r.Form.Get("line_items[0][price]")
+	if _, ok := s.prices[priceID]; !ok {
+		writeError(w, http.StatusBadRequest, "No such price: '"+priceID+"'")
+		return
+	}
+
+	id := s.nextID("plink")
+	link := &PaymentLink{
+		ID:       id,
+		Object:   "payment_link",
+		URL:      s.URL + "/pay/" + id,
+		Active:   true,
+		Metadata: parseMetadata(r.Form, "metadata"),
+		Created:  now(),
+		price:    priceID,
+	}
+	s.links[link.ID] = link
+	writeJSON(w, link)
+}
+
+func (s *Server) getPaymentLink(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	link, ok := s.links[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "No such payment_link: '"+r.PathValue("id")+"'")
+		return
+	}
+	writeJSON(w, link)
+}
+
+// updatePaymentLink only supports toggling active, which is what deactivating
+// a link on cancel needs.
+func (s *Server) updatePaymentLink(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	link, ok := s.links[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "No such payment_link: '"+r.PathValue("id")+"'")
+		return
+	}
+	if v := r.Form.Get("active"); v != "" {
+		active, err := strconv.ParseBool(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid boolean: active.")
+			return
+		}
+		link.Active = active
+	}
+	writeJSON(w, link)
+}
+
+func (s *Server) createCheckoutSession(w http.ResponseWriter, r *http.Request) {
+	mode := r.Form.Get("mode")
+	if mode != "payment" && mode != "subscription" {
+		writeError(w, http.StatusBadRequest, "Invalid mode: must be one of payment, setup, or subscription.")
+		return
+	}
+	unitAmount, err := strconv.ParseInt(r.Form.Get("line_items[0][price_data][unit_amount]"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid integer: line_items[0][price_data][unit_amount].")
+		return
+	}
+	if mode == "subscription" && parseRecurring(r.Form, "line_items[0][price_data][recurring]") == nil {
+		writeError(w, http.StatusBadRequest, "You must provide at least one recurring price in `subscription` mode.")
+		return
+	}
+	if strings.TrimSpace(r.Form.Get("success_url")) == "" {
+		writeError(w, http.StatusBadRequest, "Missing required param: success_url.")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session := s.newSession(mode, unitAmount, r.Form.Get("line_items[0][price_data][currency]"))
+	session.SuccessURL = r.Form.Get("success_url")
+	session.CancelURL = r.Form.Get("cancel_url")
+	session.Metadata = parseMetadata(r.Form, "metadata")
+	if ref := strings.TrimSpace(r.Form.Get("client_reference_id")); ref != "" {
+		session.ClientReferenceID = &ref
+	}
+	writeJSON(w, session)
+}
+
+func (s *Server) getCheckoutSession(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, ok := s.sessions[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "No such checkout.session: '"+r.PathValue("id")+"'")
+		return
+	}
+	writeJSON(w, session)
+}
+
+// expireCheckoutSession answers like Stripe: only open sessions can be
+// expired, anything else is a 400.
+func (s *Server) expireCheckoutSession(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, ok := s.sessions[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "No such checkout.session: '"+r.PathValue("id")+"'")
+		return
+	}
+	if _, err := s.expireSession(session); err != nil {
+		writeError(w, http.StatusBadRequest, "Only Checkout Sessions with a status in [\"open\"] can be expired.")
+		return
+	}
+	writeJSON(w, session)
+}
+
+func (s *Server) getSubscription(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	subscription, ok := s.subs[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "No such subscription: '"+r.PathValue("id")+"'")
+		return
+	}
+	writeJSON(w, subscription)
+}
+
+// cancelSubscription answers like Stripe: a subscription that is already
+// canceled can't be canceled again.
+func (s *Server) cancelSubscription(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	subscription, ok := s.subs[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "No such subscription: '"+r.PathValue("id")+"'")
+		return
+	}
+	if subscription.Status == "canceled" {
+		writeError(w, http.StatusBadRequest, "A canceled subscription can only update its cancellation_details and metadata.")
+		return
+	}
+	canceledAt := now()
+	subscription.Status = "canceled"
+	subscription.CanceledAt = &canceledAt
+	if _, err := s.recordEvent("customer.subscription.deleted", subscription); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, subscription)
+}
+
+func (s *Server) listCheckoutSessions(w http.ResponseWriter, r *http.Request) {
+	paymentLink := r.Form.Get("payment_link")
+	status := r.Form.Get("status")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	items := make([]*CheckoutSession, 0)
+	for _, session := range s.sessions {
+		if paymentLink != "" && (session.PaymentLink == nil || *session.PaymentLink != paymentLink) {
+			continue
+		}
+		if status != "" && session.Status != status {
+			continue
+		}
+		items = append(items, session)
+	}
+	sort.Slice(items, func(i, j int) bool { return idSeq(items[i].ID) > idSeq(items[j].ID) })
+
+	page, hasMore := paginate(items, r.Form, func(item *CheckoutSession) string { return item.ID })
+	writeList(w, "/v1/checkout/sessions", page, hasMore)
+}
+
+// createRefund refunds the payment intent of a completed checkout session.
+func (s *Server) createRefund(w http.ResponseWriter, r *http.Request) {
+	paymentIntent := strings.TrimSpace(r.Form.Get("payment_intent"))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var session *CheckoutSession
+	for _, item := range s.sessions {
+		if item.PaymentIntent != nil && *item.PaymentIntent == paymentIntent {
+			session = item
+			break
+		}
+	}
+	if session == nil {
+		writeError(w, http.StatusBadRequest, "No such payment_intent: '"+paymentIntent+"'")
+		return
+	}
+
+	var refunded int64
+	for _, refund := range s.refunds {
+		if refund.PaymentIntent == paymentIntent {
+			refunded += refund.Amount
+		}
+	}
+	amount := session.AmountTotal - refunded
+	if v := r.Form.Get("amount"); v != "" {
+		parsed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || parsed <= 0 {
+			writeError(w, http.StatusBadRequest, "Invalid integer: amount.")
+			return
+		}
+		amount = parsed
+	}
+	if amount <= 0 || refunded+amount > session.AmountTotal {
+		writeError(w, http.StatusBadRequest, "Refund amount is greater than unrefunded amount on charge.")
+		return
+	}
+
+	refund := &Refund{
+		ID:            s.nextID("re"),
+		Object:        "refund",
+		Amount:        amount,
+		Currency:      session.Currency,
+		PaymentIntent: paymentIntent,
+		Status:        "succeeded",
+		Metadata:      parseMetadata(r.Form, "metadata"),
+		Created:       now(),
+	}
+	s.refunds[refund.ID] = refund
+	writeJSON(w, refund)
+}
+
+func (s *Server) listEvents(w http.ResponseWriter, r *http.Request) {
+	var createdFrom int64
+	if v := r.Form.Get("created[gte]"); v != "" {
+		parsed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid integer: created[gte].")
+			return
+		}
+		createdFrom = parsed
+	}
+	types := make(map[string]bool)
+	for _, eventType := range r.Form["types[]"] {
+		types[eventType] = true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	items := make([]*Event, 0, len(s.events))
+	for i := len(s.events) - 1; i >= 0; i-- {
+		event := s.events[i]
+		if event.Created < createdFrom {
+			continue
+		}
+		if len(types) > 0 && !types[event.Type] {
+			continue
+		}
+		items = append(items, event)
+	}
+
+	page, hasMore := paginate(items, r.Form, func(item *Event) string { return item.ID })
+	writeList(w, "/v1/events", page, hasMore)
+}
+
+func (s *Server) getEvent(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	event := s.findEvent(r.PathValue("id"))
+	if event == nil {
+		writeError(w, http.StatusNotFound, "No such event: '"+r.PathValue("id")+"'")
+		return
+	}
+	writeJSON(w, event)
+}
+
+// CheckoutSession returns a copy of a stored checkout session.
+func (s *Server) CheckoutSession(id string) (CheckoutSession, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, ok := s.sessions[id]
+	if !ok {
+		return CheckoutSession{}, false
+	}
+	return *session, true
+}
+
+// CompleteCheckoutSession marks an open session as paid and records a
+// checkout.session.completed event. Subscription sessions get a subscription.
+func (s *Server) CompleteCheckoutSession(id string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, ok := s.sessions[id]
+	if !ok {
+		return "", fmt.Errorf("checkout session %s not found", id)
+	}
+	return s.completeSession(session)
+}
+
+// ExpireCheckoutSession expires an open session and records a
+// checkout.session.expired event.
+func (s *Server) ExpireCheckoutSession(id string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, ok := s.sessions[id]
+	if !ok {
+		return "", fmt.Errorf("checkout session %s not found", id)
+	}
+	return s.expireSession(session)
+}
+
+// PaymentLink returns a copy of a stored payment link.
+func (s *Server) PaymentLink(id string) (PaymentLink, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	link, ok := s.links[id]
+	if !ok {
+		return PaymentLink{}, false
+	}
+	return *link, true
+}
+
+// Subscription returns a copy of a stored subscription.
+func (s *Server) Subscription(id string) (Subscription, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	subscription, ok := s.subs[id]
+	if !ok {
+		return Subscription{}, false
+	}
+	return *subscription, true
+}
+
+// PayPaymentLink opens a checkout session from a payment link, copying the
+// link metadata like Stripe does, and completes it. It returns the session and
+// event IDs.
+func (s *Server) PayPaymentLink(id string) (string, string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	link, ok := s.links[id]
+	if !ok {
+		return "", "", fmt.Errorf("payment link %s not found", id)
+	}
+	price := s.prices[link.price]
+
+	mode := "payment"
+	if price.Recurring != nil {
+		mode = "subscription"
+	}
+	session := s.newSession(mode, price.UnitAmount, price.Currency)
+	session.PaymentLink = &link.ID
+	session.Metadata = copyMetadata(link.Metadata)
+
+	eventID, err := s.completeSession(session)
+	if err != nil {
+		return "", "", err
+	}
+	return session.ID, eventID, nil
+}
+
+// SendWebhook delivers a recorded event to WebhookBaseURL with a
+// Stripe-Signature header.
+func (s *Server) SendWebhook(ctx context.Context, eventID string) error {
+	s.mu.Lock()
+	event := s.findEvent(eventID)
+	s.mu.Unlock()
+	if event == nil {
+		return fmt.Errorf("event %s not found", eventID)
+	}
+	if strings.TrimSpace(s.cfg.WebhookBaseURL) == "" {
+		return errors.New("webhook base url is not configured")
+	}
+
+	var object struct {
+		Metadata map[string]string `json:"metadata"`
+	}
+	_ = json.Unmarshal(event.Data.Object, &object)
+	callbackHash := strings.TrimSpace(object.Metadata["callback_hash"])
+	if callbackHash == "" {
+		return fmt.Errorf("event %s carries no callback_hash metadata", eventID)
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	target := strings.TrimRight(s.cfg.WebhookBaseURL, "/") + "/" + url.PathEscape(callbackHash)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Stripe-Signature", callbacksig.Sign(payload, time.Now(), s.cfg.WebhookSecret))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook %s rejected: status=%d", eventID, resp.StatusCode)
+	}
+	return nil
+}
+
+func (s *Server) newSession(mode string, amount int64, currency string) *CheckoutSession {
+	id := s.nextID("cs_test")
+	checkoutURL := s.URL + "/checkout/" + id
+	session := &CheckoutSession{
+		ID:            id,
+		Object:        "checkout.session",
+		Mode:          mode,
+		Status:        "open",
+		PaymentStatus: "unpaid",
+		URL:           &checkoutURL,
+		AmountTotal:   amount,
+		Currency:      strings.ToLower(currency),
+		Metadata:      map[string]string{},
+		Created:       now(),
+		ExpiresAt:     now() + int64((24 * time.Hour).Seconds()),
+	}
+	s.sessions[session.ID] = session
+	return session
+}
+
+func (s *Server) completeSession(session *CheckoutSession) (string, error) {
+	if session.Status != "open" {
+		return "", fmt.Errorf("checkout session %s is %s", session.ID, session.Status)
+	}
+	session.Status = "complete"
+	session.PaymentStatus = "paid"
+	session.URL = nil
+	if session.Mode == "subscription" {
+		subscription := &Subscription{
+			ID:       s.nextID("sub"),
+			Object:   "subscription",
+			Status:   "active",
+			Metadata: copyMetadata(session.Metadata),
+			Created:  now(),
+		}
+		s.subs[subscription.ID] = subscription
+		session.Subscription = &subscription.ID
+	} else {
+		paymentIntentID := s.nextID("pi")
+		session.PaymentIntent = &paymentIntentID
+	}
+	return s.recordEvent("checkout.session.completed", session)
+}
+
+func (s *Server) expireSession(session *CheckoutSession) (string, error) {
+	if session.Status != "open" {
+		return "", fmt.Errorf("checkout session %s is %s", session.ID, session.Status)
+	}
+	session.Status = "expired"
+	session.URL = nil
+	return s.recordEvent("checkout.session.expired", session)
+}
+
+func (s *Server) recordEvent(eventType string, object interface{}) (string, error) {
+	raw, err := json.Marshal(object)
+	if err != nil {
+		return "", err
+	}
+	event := &Event{
+		ID:         s.nextID("evt"),
+		Object:     "event",
+		Type:       eventType,
+		APIVersion: "2024-06-20",
+		Created:    now(),
+		Data:       EventData{Object: raw},
+	}
+	s.events = append(s.events, event)
+	return event.ID, nil
+}
+
+func (s *Server) findEvent(id string) *Event {
+	for _, event := range s.events {
+		if event.ID == id {
+			return event
+		}
+	}
+	return nil
+}
+
+func (s *Server) nextID(prefix string) string {
+	s.seq++
+	return fmt.Sprintf("%s_fake%d", prefix, s.seq)
+}
+
+// idSeq orders IDs by creation; they all end in the server-wide sequence.
+func idSeq(id string) int {
+	n, _ := strconv.Atoi(id[strings.LastIndex(id, "fake")+len("fake"):])
+	return n
+}
+
+func paginate[T any](items []T, form url.Values, id func(T) string) ([]T, bool) {
+	if startingAfter := form.Get("starting_after"); startingAfter != "" {
+		for i, item := range items {
+			if id(item) == startingAfter {
+				items = items[i+1:]
+				break
+			}
+		}
+	}
+	limit := 10
+	if v, err := strconv.Atoi(form.Get("limit")); err == nil && v > 0 && v <= 100 {
+		limit = v
+	}
+	if len(items) > limit {
+		return items[:limit], true
+	}
+	return items, false
+}
+
+func parseRecurring(form url.Values, prefix string) *Recurring {
+	interval := form.Get(prefix + "[interval]")
+	if interval == "" {
+		return nil
+	}
+	count, err := strconv.ParseInt(form.Get(prefix+"[interval_count]"), 10, 64)
+	if err != nil || count <= 0 {
+		count = 1
+	}
+	return &Recurring{Interval: interval, IntervalCount: count}
+}
+
+func parseMetadata(form url.Values, prefix string) map[string]string {
+	result := make(map[string]string)
+	for key, values := range form {
+		if !strings.HasPrefix(key, prefix+"[") || !strings.HasSuffix(key, "]") || len(values) == 0 {
+			continue
+		}
+		name := strings.TrimSuffix(strings.TrimPrefix(key, prefix+"["), "]")
+		if name == "" || strings.ContainsAny(name, "[]") {
+			continue
+		}
+		result[name] = values[0]
+	}
+	return result
+}
+
+func copyMetadata(metadata map[string]string) map[string]string {
+	result := make(map[string]string, len(metadata))
+	for k, v := range metadata {
+		result[k] = v
+	}
+	return result
+}
+
+func now() int64 {
+	return time.Now().Unix()
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeList[T any](w http.ResponseWriter, path string, data []T, hasMore bool) {
+	writeJSON(w, map[string]interface{}{
+		"object":   "list",
+		"url":      path,
+		"has_more": hasMore,
+		"data":     data,
+	})
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"error": map[string]string{
+			"type":    "invalid_request_error",
+			"message": message,
+		},
+	})
+}
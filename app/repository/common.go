@@ -4,10 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
-	"errors"
 	"time"
-
-	mysqlDriver "github.com/go-sql-driver/mysql"
 )
 
 type DBTX interface {
@@ -16,11 +13,6 @@ type DBTX interface {
 	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
 }
 
-func isDuplicateEntryError(err error) bool {
-	var mysqlErr *mysqlDriver.MySQLError
-	return errors.As(err, &mysqlErr) && mysqlErr.Number == 1062
-}
-
 func nullableStringValue(v *string) interface{} {
 	if v == nil {
 		return nil
@@ -35,6 +27,20 @@ func nullableInt32Value(v *int32) interface{} {
 	return *v
 }
 
+func nullableInt64Value(v *int64) interface{} {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+func nullableUint64Value(v *uint64) interface{} {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
 func nullableTimeValue(v *time.Time) interface{} {
 	if v == nil {
 		return nil
@@ -58,6 +64,22 @@ func int32PtrFromNull(v sql.NullInt32) *int32 {
 	return &n
 }
 
+func int64PtrFromNull(v sql.NullInt64) *int64 {
+	if !v.Valid {
+		return nil
+	}
+	n := v.Int64
+	return &n
+}
+
+func uint64PtrFromNull(v sql.NullInt64) *uint64 {
+	if !v.Valid {
+		return nil
+	}
+	n := uint64(v.Int64)
+	return &n
+}
+
 func timePtrFromNull(v sql.NullTime) *time.Time {
 	if !v.Valid {
 		return nil
@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	mysqlDriver "github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	DriverMySQL    = "mysql"
+	DriverPostgres = "postgres"
+)
+
+// Dialect holds the SQL that differs between the supported databases.
+// Repositories write their queries with ? placeholders and MySQL-compatible
+// syntax; anything else goes through the dialect.
+type Dialect interface {
+	// DriverName is the database/sql driver to open connections with.
+	DriverName() string
+	// Rebind rewrites ? placeholders into the driver's bind syntax.
+	Rebind(query string) string
+	IsUniqueViolation(err error) bool
+	// MetadataMatch returns a condition on payments.metadata_json that holds
+	// when key is set to value.
+	MetadataMatch(key, value string) (string, []interface{})
+	// InsertReturningID runs an INSERT into a table with an id column and
+	// returns the generated id.
+	InsertReturningID(ctx context.Context, db DBTX, query string, args ...interface{}) (uint64, error)
+}
+
+// NewDialect returns the dialect for a configured database driver.
+func NewDialect(driver string) (Dialect, error) {
+	switch driver {
+	case DriverMySQL:
+		return mysqlDialect{}, nil
+	case DriverPostgres:
+		return postgresDialect{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", driver)
+	}
+}
+
+type mysqlDialect struct{}
+
+func (mysqlDialect) DriverName() string { return "mysql" }
+
+func (mysqlDialect) Rebind(query string) string { return query }
+
+func (mysqlDialect) IsUniqueViolation(err error) bool {
+	var mysqlErr *mysqlDriver.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == 1062
+}
+
+// MetadataMatch builds the expected pair with JSON_OBJECT rather than a JSON
+// path, so keys are bound as plain values and need no escaping.
+func (mysqlDialect) MetadataMatch(key, value string) (string, []interface{}) {
+	return "JSON_CONTAINS(metadata_json, JSON_OBJECT(?, ?))", []interface{}{key, value}
+}
+
+func (mysqlDialect) InsertReturningID(ctx context.Context, db DBTX, query string, args ...interface{}) (uint64, error) {
+	result, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return uint64(id), nil
+}
+
+// postgresDialect targets the pgx database/sql driver.
+type postgresDialect struct{}
+
+func (postgresDialect) DriverName() string { return "pgx" }
+
+// Rebind numbers the placeholders $1, $2, ... and leaves question marks inside
+// string literals alone.
+func (postgresDialect) Rebind(query string) string {
+	var b strings.Builder
+	b.Grow(len(query) + 16)
+	n := 0
+	inLiteral := false
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == '\'':
+			inLiteral = !inLiteral
+		case c == '?' && !inLiteral:
+			n++
+			b.WriteByte('$')
+			b.WriteString(strconv.Itoa(n))
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+func (postgresDialect) IsUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// MetadataMatch uses JSONB containment so the GIN index on metadata_json
+// applies.
+func (postgresDialect) MetadataMatch(key, value string) (string, []interface{}) {
+	payload, _ := json.Marshal(map[string]string{key: value})
+	return "metadata_json @> ?::jsonb", []interface{}{string(payload)}
+}
+
+// InsertReturningID wraps inserts inside a transaction in a savepoint: unlike
+// MySQL, Postgres aborts the whole transaction on a unique violation, and the
+// services treat duplicates as a normal outcome and carry on.
+func (postgresDialect) InsertReturningID(ctx context.Context, db DBTX, query string, args ...interface{}) (uint64, error) {
+	query = strings.TrimSpace(query) + " RETURNING id"
+	if _, ok := ctx.Value(txContextKey{}).(*sql.Tx); !ok {
+		var id uint64
+		err := db.QueryRowContext(ctx, query, args...).Scan(&id)
+		return id, err
+	}
+
+	if _, err := db.ExecContext(ctx, "SAVEPOINT repository_insert"); err != nil {
+		return 0, err
+	}
+	var id uint64
+	if err := db.QueryRowContext(ctx, query, args...).Scan(&id); err != nil {
+		if _, rollbackErr := db.ExecContext(ctx, "ROLLBACK TO SAVEPOINT repository_insert"); rollbackErr != nil {
+			return 0, errors.Join(err, rollbackErr)
+		}
+		return 0, err
+	}
+	if _, err := db.ExecContext(ctx, "RELEASE SAVEPOINT repository_insert"); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// boundDB rebinds every query for its dialect before it reaches the driver.
+type boundDB struct {
+	db      DBTX
+	dialect Dialect
+}
+
+func (b boundDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return b.db.ExecContext(ctx, b.dialect.Rebind(query), args...)
+}
+
+func (b boundDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return b.db.QueryContext(ctx, b.dialect.Rebind(query), args...)
+}
+
+func (b boundDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return b.db.QueryRowContext(ctx, b.dialect.Rebind(query), args...)
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	mysqlDriver "github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestPostgresRebindNumbersPlaceholders(t *testing.T) {
+	dialect, err := NewDialect(DriverPostgres)
+	if err != nil {
+		t.Fatalf("expected postgres dialect, got %v", err)
+	}
+
+	got := dialect.Rebind("SELECT id FROM payments WHERE status IN (?, ?) AND resource_id <> '?' AND id < ? LIMIT ?")
+	want := "SELECT id FROM payments WHERE status IN ($1, $2) AND resource_id <> '?' AND id < $3 LIMIT $4"
+	if got != want {
+		t.Fatalf("unexpected rebind:\n got %s\nwant %s", got, want)
+	}
+
+	mysql, _ := NewDialect(DriverMySQL)
+	if query := "SELECT 1 FROM payments WHERE id = ?"; mysql.Rebind(query) != query {
+		t.Fatalf("expected mysql to keep ? placeholders, got %s", mysql.Rebind(query))
+	}
+}
+
+func TestDialectUniqueViolation(t *testing.T) {
+	mysql, _ := NewDialect(DriverMySQL)
+	postgres, _ := NewDialect(DriverPostgres)
+
+	mysqlDuplicate := fmt.Errorf("insert: %w", &mysqlDriver.MySQLError{Number: 1062})
+	pgDuplicate := fmt.Errorf("insert: %w", &pgconn.PgError{Code: "23505"})
+
+	if !mysql.IsUniqueViolation(mysqlDuplicate) || mysql.IsUniqueViolation(pgDuplicate) {
+		t.Fatal("mysql dialect should only match error 1062")
+	}
+	if !postgres.IsUniqueViolation(pgDuplicate) || postgres.IsUniqueViolation(mysqlDuplicate) {
+		t.Fatal("postgres dialect should only match SQLSTATE 23505")
+	}
+	if postgres.IsUniqueViolation(&pgconn.PgError{Code: "23503"}) || postgres.IsUniqueViolation(errors.New("boom")) {
+		t.Fatal("postgres dialect matched a non-unique error")
+	}
+}
+
+func TestDialectMetadataMatch(t *testing.T) {
+	mysql, _ := NewDialect(DriverMySQL)
+	condition, args := mysql.MetadataMatch(`or"der\`, "42")
+	if condition != "JSON_CONTAINS(metadata_json, JSON_OBJECT(?, ?))" || len(args) != 2 || args[0] != `or"der\` || args[1] != "42" {
+		t.Fatalf("unexpected mysql metadata match: %s %v", condition, args)
+	}
+
+	postgres, _ := NewDialect(DriverPostgres)
+	condition, args = postgres.MetadataMatch("order", `4"2`)
+	if condition != "metadata_json @> ?::jsonb" || len(args) != 1 || args[0] != `{"order":"4\"2"}` {
+		t.Fatalf("unexpected postgres metadata match: %s %v", condition, args)
+	}
+}
+
+// recordingDB captures the last query instead of running it.
+type recordingDB struct {
+	query string
+	args  []interface{}
+}
+
+var errRecorded = errors.New("recorded")
+
+func (db *recordingDB) ExecContext(_ context.Context, query string, args ...interface{}) (sql.Result, error) {
+	db.query, db.args = query, args
+	return nil, errRecorded
+}
+
+func (db *recordingDB) QueryContext(_ context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	db.query, db.args = query, args
+	return nil, errRecorded
+}
+
+func (db *recordingDB) QueryRowContext(_ context.Context, query string, args ...interface{}) *sql.Row {
+	db.query, db.args = query, args
+	return nil
+}
+
+func TestPaymentListBindsQuotedMetadataKeys(t *testing.T) {
+	key := `pl"an\tier`
+	cases := map[string]struct {
+		condition string
+		args      []interface{}
+	}{
+		DriverMySQL:    {"JSON_CONTAINS(metadata_json, JSON_OBJECT(?, ?))", []interface{}{key, "pro"}},
+		DriverPostgres: {"metadata_json @> $1::jsonb", []interface{}{`{"pl\"an\\tier":"pro"}`}},
+	}
+	for driver, want := range cases {
+		dialect, _ := NewDialect(driver)
+		db := &recordingDB{}
+		_, err := NewPaymentRepository(db, dialect).List(context.Background(), PaymentFilter{Metadata: map[string]string{key: "pro"}, Limit: 10})
+		if !errors.Is(err, errRecorded) {
+			t.Fatalf("%s: expected the recorded query, got %v", driver, err)
+		}
+		if !strings.Contains(db.query, want.condition) || strings.Contains(db.query, key) {
+			t.Fatalf("%s: expected the key to be bound, got %s", driver, db.query)
+		}
+		if len(db.args) != len(want.args)+2 {
+			t.Fatalf("%s: unexpected args %v", driver, db.args)
+		}
+		for i, arg := range want.args {
+			if db.args[i] != arg {
+				t.Fatalf("%s: unexpected metadata arg %d: %v", driver, i, db.args[i])
+			}
+		}
+	}
+}
+
+func TestNewDialectRejectsUnknownDriver(t *testing.T) {
+	if _, err := NewDialect("sqlite"); err == nil {
+		t.Fatal("expected error for unsupported driver")
+	}
+}
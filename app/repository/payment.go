@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"sort"
 	"strings"
 	"time"
 
@@ -11,8 +12,9 @@ import (
 )
 
 var (
-	ErrPaymentNotFound      = errors.New("payment not found")
-	ErrPaymentAlreadyExists = errors.New("payment already exists")
+	ErrPaymentNotFound        = errors.New("payment not found")
+	ErrPaymentAlreadyExists   = errors.New("payment already exists")
+	ErrPaymentVersionConflict = errors.New("payment version conflict")
 )
 
 type PaymentFilter struct {
@@ -22,20 +24,41 @@ type PaymentFilter struct {
 	ResourceID    string
 	HasStatus     bool
 	Status        int32
-	Provider      int32
-	Limit         int32
-	Offset        int32
+	// Statuses matches any of the listed statuses.
+	Statuses               []int32
+	Provider               int32
+	CustomerRef            string
+	Currency               string
+	MinAmountCents         int64
+	MaxAmountCents         int64
+	ProviderPaymentID      string
+	ProviderSubscriptionID string
+	CreatedFrom            *time.Time
+	CreatedTo              *time.Time
+	UpdatedFrom            *time.Time
+	UpdatedTo              *time.Time
+	// Metadata matches payments whose metadata has every listed key/value.
+	Metadata map[string]string
+	// BeforeID continues a keyset page: only payments with a lower ID match.
+	BeforeID uint64
+	Limit    int32
+	Offset   int32
 }
 
 type PaymentRepository struct {
-	db DBTX
+	db      DBTX
+	dialect Dialect
 }
 
-func NewPaymentRepository(db DBTX) *PaymentRepository {
-	return &PaymentRepository{db: db}
+func NewPaymentRepository(db DBTX, dialect Dialect) *PaymentRepository {
+	return &PaymentRepository{db: db, dialect: dialect}
 }
 
 func (r *PaymentRepository) Create(ctx context.Context, payment *entity.Payment) error {
+	if payment.Version == 0 {
+		payment.Version = 1
+	}
+
 	metadataJSON, err := serializeMetadata(payment.Metadata)
 	if err != nil {
 		return err
@@ -45,17 +68,16 @@ func (r *PaymentRepository) Create(ctx context.Context, payment *entity.Payment)
 		INSERT INTO payments (
 			request_id, caller_service, resource_type, resource_id, customer_ref,
 			amount_cents, currency, status, payment_method, payment_type, provider,
-			recurring_interval, recurring_interval_count,
+			recurring_interval, recurring_interval_count, subscription_amount_cents, subscription_paused, cancel_at_period_end,
 			provider_payment_id, provider_subscription_id, checkout_url,
-			provider_callback_hash, provider_callback_url, status_callback_url,
+			provider_callback_hash, provider_callback_url, status_callback_url, success_url, cancel_url, request_fingerprint,
 			refunded_cents, refundable_cents, metadata_json,
-			callback_delivery_status, callback_delivery_attempts, callback_delivery_next_at, callback_delivery_last_error,
-			created_at, updated_at
+			version, created_at, updated_at
 		)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
-	result, err := r.db.ExecContext(ctx, query,
+	id, err := r.dialect.InsertReturningID(ctx, executor(ctx, r.db, r.dialect), query,
 		payment.RequestID,
 		payment.CallerService,
 		payment.ResourceType,
@@ -69,34 +91,32 @@ func (r *PaymentRepository) Create(ctx context.Context, payment *entity.Payment)
 		payment.Provider,
 		nullableStringValue(payment.RecurringInterval),
 		nullableInt32Value(payment.RecurringIntervalCount),
+		nullableInt64Value(payment.SubscriptionAmountCents),
+		payment.SubscriptionPaused,
+		payment.CancelAtPeriodEnd,
 		nullableStringValue(payment.ProviderPaymentID),
 		nullableStringValue(payment.ProviderSubscriptionID),
 		nullableStringValue(payment.CheckoutURL),
 		payment.ProviderCallbackHash,
 		payment.ProviderCallbackURL,
 		payment.StatusCallbackURL,
+		payment.SuccessURL,
+		payment.CancelURL,
+		payment.RequestFingerprint,
 		payment.RefundedCents,
 		payment.RefundableCents,
 		metadataJSON,
-		payment.CallbackDeliveryStatus,
-		payment.CallbackDeliveryAttempts,
-		nullableTimeValue(payment.CallbackDeliveryNextAt),
-		nullableStringValue(payment.CallbackDeliveryLastErr),
+		payment.Version,
 		payment.CreatedAt,
 		payment.UpdatedAt,
 	)
 	if err != nil {
-		if isDuplicateEntryError(err) {
+		if r.dialect.IsUniqueViolation(err) {
 			return ErrPaymentAlreadyExists
 		}
 		return err
 	}
-
-	id, err := result.LastInsertId()
-	if err != nil {
-		return err
-	}
-	payment.ID = uint64(id)
+	payment.ID = id
 	return nil
 }
 
@@ -119,6 +139,9 @@ func (r *PaymentRepository) Update(ctx context.Context, payment *entity.Payment)
 			provider = ?,
 			recurring_interval = ?,
 			recurring_interval_count = ?,
+			subscription_amount_cents = ?,
+			subscription_paused = ?,
+			cancel_at_period_end = ?,
 			provider_payment_id = ?,
 			provider_subscription_id = ?,
 			checkout_url = ?,
@@ -127,15 +150,12 @@ func (r *PaymentRepository) Update(ctx context.Context, payment *entity.Payment)
 			refunded_cents = ?,
 			refundable_cents = ?,
 			metadata_json = ?,
-			callback_delivery_status = ?,
-			callback_delivery_attempts = ?,
-			callback_delivery_next_at = ?,
-			callback_delivery_last_error = ?,
+			version = version + 1,
 			updated_at = ?
-		WHERE id = ?
+		WHERE id = ? AND version = ?
 	`
 
-	result, err := r.db.ExecContext(ctx, query,
+	result, err := executor(ctx, r.db, r.dialect).ExecContext(ctx, query,
 		payment.ResourceType,
 		payment.ResourceID,
 		nullableStringValue(payment.CustomerRef),
@@ -147,6 +167,9 @@ func (r *PaymentRepository) Update(ctx context.Context, payment *entity.Payment)
 		payment.Provider,
 		nullableStringValue(payment.RecurringInterval),
 		nullableInt32Value(payment.RecurringIntervalCount),
+		nullableInt64Value(payment.SubscriptionAmountCents),
+		payment.SubscriptionPaused,
+		payment.CancelAtPeriodEnd,
 		nullableStringValue(payment.ProviderPaymentID),
 		nullableStringValue(payment.ProviderSubscriptionID),
 		nullableStringValue(payment.CheckoutURL),
@@ -155,12 +178,9 @@ func (r *PaymentRepository) Update(ctx context.Context, payment *entity.Payment)
 		payment.RefundedCents,
 		payment.RefundableCents,
 		metadataJSON,
-		payment.CallbackDeliveryStatus,
-		payment.CallbackDeliveryAttempts,
-		nullableTimeValue(payment.CallbackDeliveryNextAt),
-		nullableStringValue(payment.CallbackDeliveryLastErr),
 		payment.UpdatedAt,
 		payment.ID,
+		payment.Version,
 	)
 	if err != nil {
 		return err
@@ -171,8 +191,17 @@ func (r *PaymentRepository) Update(ctx context.Context, payment *entity.Payment)
 		return err
 	}
 	if affected == 0 {
-		return ErrPaymentNotFound
+		var exists int
+		err := executor(ctx, r.db, r.dialect).QueryRowContext(ctx, "SELECT 1 FROM payments WHERE id = ?", payment.ID).Scan(&exists)
+		if err == sql.ErrNoRows {
+			return ErrPaymentNotFound
+		}
+		if err != nil {
+			return err
+		}
+		return ErrPaymentVersionConflict
 	}
+	payment.Version++
 
 	return nil
 }
@@ -181,18 +210,17 @@ func (r *PaymentRepository) FindByID(ctx context.Context, id uint64) (*entity.Pa
 	query := `
 		SELECT id, request_id, caller_service, resource_type, resource_id, customer_ref,
 			amount_cents, currency, status, payment_method, payment_type, provider,
-			recurring_interval, recurring_interval_count,
+			recurring_interval, recurring_interval_count, subscription_amount_cents, subscription_paused, cancel_at_period_end,
 			provider_payment_id, provider_subscription_id, checkout_url,
-			provider_callback_hash, provider_callback_url, status_callback_url,
+			provider_callback_hash, provider_callback_url, status_callback_url, success_url, cancel_url, request_fingerprint,
 			refunded_cents, refundable_cents, metadata_json,
-			callback_delivery_status, callback_delivery_attempts, callback_delivery_next_at, callback_delivery_last_error,
-			created_at, updated_at
+			version, created_at, updated_at
 		FROM payments
 		WHERE id = ?
 	`
 
 	payment := &entity.Payment{}
-	if err := scanPayment(r.db.QueryRowContext(ctx, query, id), payment); err == sql.ErrNoRows {
+	if err := scanPayment(executor(ctx, r.db, r.dialect).QueryRowContext(ctx, query, id), payment); err == sql.ErrNoRows {
 		return nil, nil
 	} else if err != nil {
 		return nil, err
@@ -205,19 +233,18 @@ func (r *PaymentRepository) FindByCallerRequestID(ctx context.Context, callerSer
 	query := `
 		SELECT id, request_id, caller_service, resource_type, resource_id, customer_ref,
 			amount_cents, currency, status, payment_method, payment_type, provider,
-			recurring_interval, recurring_interval_count,
+			recurring_interval, recurring_interval_count, subscription_amount_cents, subscription_paused, cancel_at_period_end,
 			provider_payment_id, provider_subscription_id, checkout_url,
-			provider_callback_hash, provider_callback_url, status_callback_url,
+			provider_callback_hash, provider_callback_url, status_callback_url, success_url, cancel_url, request_fingerprint,
 			refunded_cents, refundable_cents, metadata_json,
-			callback_delivery_status, callback_delivery_attempts, callback_delivery_next_at, callback_delivery_last_error,
-			created_at, updated_at
+			version, created_at, updated_at
 		FROM payments
 		WHERE caller_service = ? AND request_id = ?
 		LIMIT 1
 	`
 
 	payment := &entity.Payment{}
-	if err := scanPayment(r.db.QueryRowContext(ctx, query, callerService, requestID), payment); err == sql.ErrNoRows {
+	if err := scanPayment(executor(ctx, r.db, r.dialect).QueryRowContext(ctx, query, callerService, requestID), payment); err == sql.ErrNoRows {
 		return nil, nil
 	} else if err != nil {
 		return nil, err
@@ -230,19 +257,68 @@ func (r *PaymentRepository) FindByCallbackHash(ctx context.Context, provider int
 	query := `
 		SELECT id, request_id, caller_service, resource_type, resource_id, customer_ref,
 			amount_cents, currency, status, payment_method, payment_type, provider,
-			recurring_interval, recurring_interval_count,
+			recurring_interval, recurring_interval_count, subscription_amount_cents, subscription_paused, cancel_at_period_end,
 			provider_payment_id, provider_subscription_id, checkout_url,
-			provider_callback_hash, provider_callback_url, status_callback_url,
+			provider_callback_hash, provider_callback_url, status_callback_url, success_url, cancel_url, request_fingerprint,
 			refunded_cents, refundable_cents, metadata_json,
-			callback_delivery_status, callback_delivery_attempts, callback_delivery_next_at, callback_delivery_last_error,
-			created_at, updated_at
+			version, created_at, updated_at
 		FROM payments
 		WHERE provider = ? AND provider_callback_hash = ?
 		LIMIT 1
 	`
 
 	payment := &entity.Payment{}
-	if err := scanPayment(r.db.QueryRowContext(ctx, query, provider, callbackHash), payment); err == sql.ErrNoRows {
+	if err := scanPayment(executor(ctx, r.db, r.dialect).QueryRowContext(ctx, query, provider, callbackHash), payment); err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return payment, nil
+}
+
+func (r *PaymentRepository) FindByProviderSubscriptionID(ctx context.Context, provider int32, providerSubscriptionID string) (*entity.Payment, error) {
+	query := `
+		SELECT id, request_id, caller_service, resource_type, resource_id, customer_ref,
+			amount_cents, currency, status, payment_method, payment_type, provider,
+			recurring_interval, recurring_interval_count, subscription_amount_cents, subscription_paused, cancel_at_period_end,
+			provider_payment_id, provider_subscription_id, checkout_url,
+			provider_callback_hash, provider_callback_url, status_callback_url, success_url, cancel_url, request_fingerprint,
+			refunded_cents, refundable_cents, metadata_json,
+			version, created_at, updated_at
+		FROM payments
+		WHERE provider = ? AND provider_subscription_id = ?
+		ORDER BY id ASC
+		LIMIT 1
+	`
+
+	payment := &entity.Payment{}
+	if err := scanPayment(executor(ctx, r.db, r.dialect).QueryRowContext(ctx, query, provider, providerSubscriptionID), payment); err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return payment, nil
+}
+
+func (r *PaymentRepository) FindByProviderPaymentID(ctx context.Context, provider int32, providerPaymentID string) (*entity.Payment, error) {
+	query := `
+		SELECT id, request_id, caller_service, resource_type, resource_id, customer_ref,
+			amount_cents, currency, status, payment_method, payment_type, provider,
+			recurring_interval, recurring_interval_count, subscription_amount_cents, subscription_paused, cancel_at_period_end,
+			provider_payment_id, provider_subscription_id, checkout_url,
+			provider_callback_hash, provider_callback_url, status_callback_url, success_url, cancel_url, request_fingerprint,
+			refunded_cents, refundable_cents, metadata_json,
+			version, created_at, updated_at
+		FROM payments
+		WHERE provider = ? AND provider_payment_id = ?
+		ORDER BY id ASC
+		LIMIT 1
+	`
+
+	payment := &entity.Payment{}
+	if err := scanPayment(executor(ctx, r.db, r.dialect).QueryRowContext(ctx, query, provider, providerPaymentID), payment); err == sql.ErrNoRows {
 		return nil, nil
 	} else if err != nil {
 		return nil, err
@@ -255,17 +331,16 @@ func (r *PaymentRepository) List(ctx context.Context, filter PaymentFilter) ([]*
 	query := `
 		SELECT id, request_id, caller_service, resource_type, resource_id, customer_ref,
 			amount_cents, currency, status, payment_method, payment_type, provider,
-			recurring_interval, recurring_interval_count,
+			recurring_interval, recurring_interval_count, subscription_amount_cents, subscription_paused, cancel_at_period_end,
 			provider_payment_id, provider_subscription_id, checkout_url,
-			provider_callback_hash, provider_callback_url, status_callback_url,
+			provider_callback_hash, provider_callback_url, status_callback_url, success_url, cancel_url, request_fingerprint,
 			refunded_cents, refundable_cents, metadata_json,
-			callback_delivery_status, callback_delivery_attempts, callback_delivery_next_at, callback_delivery_last_error,
-			created_at, updated_at
+			version, created_at, updated_at
 		FROM payments
 	`
 
-	conditions := make([]string, 0, 6)
-	args := make([]interface{}, 0, 8)
+	conditions := make([]string, 0, 16)
+	args := make([]interface{}, 0, 20)
 
 	if strings.TrimSpace(filter.RequestID) != "" {
 		conditions = append(conditions, "request_id = ?")
@@ -287,10 +362,72 @@ func (r *PaymentRepository) List(ctx context.Context, filter PaymentFilter) ([]*
 		conditions = append(conditions, "status = ?")
 		args = append(args, filter.Status)
 	}
+	if len(filter.Statuses) > 0 {
+		placeholders := make([]string, 0, len(filter.Statuses))
+		for _, status := range filter.Statuses {
+			placeholders = append(placeholders, "?")
+			args = append(args, status)
+		}
+		conditions = append(conditions, "status IN ("+strings.Join(placeholders, ", ")+")")
+	}
 	if filter.Provider > 0 {
 		conditions = append(conditions, "provider = ?")
 		args = append(args, filter.Provider)
 	}
+	if strings.TrimSpace(filter.CustomerRef) != "" {
+		conditions = append(conditions, "customer_ref = ?")
+		args = append(args, filter.CustomerRef)
+	}
+	if strings.TrimSpace(filter.Currency) != "" {
+		conditions = append(conditions, "currency = ?")
+		args = append(args, filter.Currency)
+	}
+	if filter.MinAmountCents > 0 {
+		conditions = append(conditions, "amount_cents >= ?")
+		args = append(args, filter.MinAmountCents)
+	}
+	if filter.MaxAmountCents > 0 {
+		conditions = append(conditions, "amount_cents <= ?")
+		args = append(args, filter.MaxAmountCents)
+	}
+	if strings.TrimSpace(filter.ProviderPaymentID) != "" {
+		conditions = append(conditions, "provider_payment_id = ?")
+		args = append(args, filter.ProviderPaymentID)
+	}
+	if strings.TrimSpace(filter.ProviderSubscriptionID) != "" {
+		conditions = append(conditions, "provider_subscription_id = ?")
+		args = append(args, filter.ProviderSubscriptionID)
+	}
+	if filter.CreatedFrom != nil {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		conditions = append(conditions, "created_at <= ?")
+		args = append(args, *filter.CreatedTo)
+	}
+	if filter.UpdatedFrom != nil {
+		conditions = append(conditions, "updated_at >= ?")
+		args = append(args, *filter.UpdatedFrom)
+	}
+	if filter.UpdatedTo != nil {
+		conditions = append(conditions, "updated_at <= ?")
+		args = append(args, *filter.UpdatedTo)
+	}
+	metadataKeys := make([]string, 0, len(filter.Metadata))
+	for key := range filter.Metadata {
+		metadataKeys = append(metadataKeys, key)
+	}
+	sort.Strings(metadataKeys)
+	for _, key := range metadataKeys {
+		condition, conditionArgs := r.dialect.MetadataMatch(key, filter.Metadata[key])
+		conditions = append(conditions, condition)
+		args = append(args, conditionArgs...)
+	}
+	if filter.BeforeID > 0 {
+		conditions = append(conditions, "id < ?")
+		args = append(args, filter.BeforeID)
+	}
 
 	if len(conditions) > 0 {
 		query += " WHERE " + strings.Join(conditions, " AND ")
@@ -299,7 +436,7 @@ func (r *PaymentRepository) List(ctx context.Context, filter PaymentFilter) ([]*
 	query += " ORDER BY id DESC LIMIT ? OFFSET ?"
 	args = append(args, filter.Limit, filter.Offset)
 
-	rows, err := r.db.QueryContext(ctx, query, args...)
+	rows, err := executor(ctx, r.db, r.dialect).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -320,25 +457,23 @@ func (r *PaymentRepository) List(ctx context.Context, filter PaymentFilter) ([]*
 	return payments, nil
 }
 
-func (r *PaymentRepository) ListDueCallbackDispatch(ctx context.Context, now time.Time, limit int32) ([]*entity.Payment, error) {
+func (r *PaymentRepository) ListExpiredPending(ctx context.Context, cutoff time.Time, limit int32) ([]*entity.Payment, error) {
 	query := `
 		SELECT id, request_id, caller_service, resource_type, resource_id, customer_ref,
 			amount_cents, currency, status, payment_method, payment_type, provider,
-			recurring_interval, recurring_interval_count,
+			recurring_interval, recurring_interval_count, subscription_amount_cents, subscription_paused, cancel_at_period_end,
 			provider_payment_id, provider_subscription_id, checkout_url,
-			provider_callback_hash, provider_callback_url, status_callback_url,
+			provider_callback_hash, provider_callback_url, status_callback_url, success_url, cancel_url, request_fingerprint,
 			refunded_cents, refundable_cents, metadata_json,
-			callback_delivery_status, callback_delivery_attempts, callback_delivery_next_at, callback_delivery_last_error,
-			created_at, updated_at
+			version, created_at, updated_at
 		FROM payments
-		WHERE callback_delivery_status = ?
-		  AND callback_delivery_next_at IS NOT NULL
-		  AND callback_delivery_next_at <= ?
-		ORDER BY callback_delivery_next_at ASC
+		WHERE status IN (?, ?)
+		  AND created_at <= ?
+		ORDER BY created_at ASC
 		LIMIT ?
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, entity.CallbackDeliveryPending, now, limit)
+	rows, err := executor(ctx, r.db, r.dialect).QueryContext(ctx, query, 2, 3, cutoff, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -359,24 +494,24 @@ func (r *PaymentRepository) ListDueCallbackDispatch(ctx context.Context, now tim
 	return payments, nil
 }
 
-func (r *PaymentRepository) ListExpiredPending(ctx context.Context, cutoff time.Time, limit int32) ([]*entity.Payment, error) {
+func (r *PaymentRepository) ListForReconcile(ctx context.Context, before time.Time, limit int32) ([]*entity.Payment, error) {
 	query := `
 		SELECT id, request_id, caller_service, resource_type, resource_id, customer_ref,
 			amount_cents, currency, status, payment_method, payment_type, provider,
-			recurring_interval, recurring_interval_count,
+			recurring_interval, recurring_interval_count, subscription_amount_cents, subscription_paused, cancel_at_period_end,
 			provider_payment_id, provider_subscription_id, checkout_url,
-			provider_callback_hash, provider_callback_url, status_callback_url,
+			provider_callback_hash, provider_callback_url, status_callback_url, success_url, cancel_url, request_fingerprint,
 			refunded_cents, refundable_cents, metadata_json,
-			callback_delivery_status, callback_delivery_attempts, callback_delivery_next_at, callback_delivery_last_error,
-			created_at, updated_at
+			version, created_at, updated_at
 		FROM payments
 		WHERE status IN (?, ?)
-		  AND created_at <= ?
-		ORDER BY created_at ASC
+		  AND (provider_payment_id IS NOT NULL OR provider_subscription_id IS NOT NULL)
+		  AND updated_at <= ?
+		ORDER BY updated_at ASC
 		LIMIT ?
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, 2, 3, cutoff, limit)
+	rows, err := executor(ctx, r.db, r.dialect).QueryContext(ctx, query, 2, 3, before, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -397,25 +532,27 @@ func (r *PaymentRepository) ListExpiredPending(ctx context.Context, cutoff time.
 	return payments, nil
 }
 
-func (r *PaymentRepository) ListForReconcile(ctx context.Context, before time.Time, limit int32) ([]*entity.Payment, error) {
+// ListProvisional returns payments that were stored by CreatePayment but never
+// got a provider ID, created at or before cutoff, oldest first.
+func (r *PaymentRepository) ListProvisional(ctx context.Context, cutoff time.Time, limit int32) ([]*entity.Payment, error) {
 	query := `
 		SELECT id, request_id, caller_service, resource_type, resource_id, customer_ref,
 			amount_cents, currency, status, payment_method, payment_type, provider,
-			recurring_interval, recurring_interval_count,
+			recurring_interval, recurring_interval_count, subscription_amount_cents, subscription_paused, cancel_at_period_end,
 			provider_payment_id, provider_subscription_id, checkout_url,
-			provider_callback_hash, provider_callback_url, status_callback_url,
+			provider_callback_hash, provider_callback_url, status_callback_url, success_url, cancel_url, request_fingerprint,
 			refunded_cents, refundable_cents, metadata_json,
-			callback_delivery_status, callback_delivery_attempts, callback_delivery_next_at, callback_delivery_last_error,
-			created_at, updated_at
+			version, created_at, updated_at
 		FROM payments
-		WHERE status IN (?, ?)
-		  AND provider_payment_id IS NOT NULL
-		  AND updated_at <= ?
-		ORDER BY updated_at ASC
+		WHERE status = ?
+		  AND provider_payment_id IS NULL
+		  AND provider_subscription_id IS NULL
+		  AND created_at <= ?
+		ORDER BY created_at ASC
 		LIMIT ?
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, 2, 3, before, limit)
+	rows, err := executor(ctx, r.db, r.dialect).QueryContext(ctx, query, 1, cutoff, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -444,12 +581,11 @@ func scanPayment(scan rowScanner, payment *entity.Payment) error {
 	var customerRef sql.NullString
 	var recurringInterval sql.NullString
 	var recurringIntervalCount sql.NullInt32
+	var subscriptionAmountCents sql.NullInt64
 	var providerPaymentID sql.NullString
 	var providerSubscriptionID sql.NullString
 	var checkoutURL sql.NullString
 	var metadataJSON string
-	var callbackNextAt sql.NullTime
-	var callbackLastErr sql.NullString
 
 	err := scan.Scan(
 		&payment.ID,
@@ -466,19 +602,22 @@ func scanPayment(scan rowScanner, payment *entity.Payment) error {
 		&payment.Provider,
 		&recurringInterval,
 		&recurringIntervalCount,
+		&subscriptionAmountCents,
+		&payment.SubscriptionPaused,
+		&payment.CancelAtPeriodEnd,
 		&providerPaymentID,
 		&providerSubscriptionID,
 		&checkoutURL,
 		&payment.ProviderCallbackHash,
 		&payment.ProviderCallbackURL,
 		&payment.StatusCallbackURL,
+		&payment.SuccessURL,
+		&payment.CancelURL,
+		&payment.RequestFingerprint,
 		&payment.RefundedCents,
 		&payment.RefundableCents,
 		&metadataJSON,
-		&payment.CallbackDeliveryStatus,
-		&payment.CallbackDeliveryAttempts,
-		&callbackNextAt,
-		&callbackLastErr,
+		&payment.Version,
 		&payment.CreatedAt,
 		&payment.UpdatedAt,
 	)
@@ -489,11 +628,10 @@ func scanPayment(scan rowScanner, payment *entity.Payment) error {
 	payment.CustomerRef = stringPtrFromNull(customerRef)
 	payment.RecurringInterval = stringPtrFromNull(recurringInterval)
 	payment.RecurringIntervalCount = int32PtrFromNull(recurringIntervalCount)
+	payment.SubscriptionAmountCents = int64PtrFromNull(subscriptionAmountCents)
 	payment.ProviderPaymentID = stringPtrFromNull(providerPaymentID)
 	payment.ProviderSubscriptionID = stringPtrFromNull(providerSubscriptionID)
 	payment.CheckoutURL = stringPtrFromNull(checkoutURL)
-	payment.CallbackDeliveryNextAt = timePtrFromNull(callbackNextAt)
-	payment.CallbackDeliveryLastErr = stringPtrFromNull(callbackLastErr)
 
 	metadata, err := parseMetadata(metadataJSON)
 	if err != nil {
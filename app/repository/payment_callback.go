@@ -2,46 +2,176 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"strings"
+	"time"
 
 	"github.com/vibast-solutions/ms-go-payments/app/entity"
 )
 
+// PaymentCallbackFilter searches the inbound webhook inbox. Zero values are
+// ignored; the time range is inclusive.
+type PaymentCallbackFilter struct {
+	Provider       string
+	CallbackHash   string
+	HasStatus      bool
+	Status         int32
+	CreatedFrom    *time.Time
+	CreatedTo      *time.Time
+	Limit          int32
+	Offset         int32
+	IncludePayload bool
+}
+
 type PaymentCallbackRepository struct {
-	db DBTX
+	db      DBTX
+	dialect Dialect
 }
 
-func NewPaymentCallbackRepository(db DBTX) *PaymentCallbackRepository {
-	return &PaymentCallbackRepository{db: db}
+func NewPaymentCallbackRepository(db DBTX, dialect Dialect) *PaymentCallbackRepository {
+	return &PaymentCallbackRepository{db: db, dialect: dialect}
 }
 
 func (r *PaymentCallbackRepository) Create(ctx context.Context, callback *entity.PaymentCallback) error {
 	query := `
 		INSERT INTO payment_callbacks (
-			payment_id, provider, callback_hash, signature, payload_json, status, error, created_at, updated_at
+			payment_id, provider, callback_hash, provider_event_id, signature, payload_json, status, error,
+			signature_verified, created_at, updated_at
 		)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
-	result, err := r.db.ExecContext(ctx, query,
+	id, err := r.dialect.InsertReturningID(ctx, executor(ctx, r.db, r.dialect), query,
 		callback.PaymentID,
 		callback.Provider,
 		callback.CallbackHash,
+		nullableStringValue(callback.ProviderEventID),
 		callback.Signature,
 		callback.PayloadJSON,
 		callback.Status,
 		nullableStringValue(callback.Error),
+		callback.SignatureVerified,
 		callback.CreatedAt,
 		callback.UpdatedAt,
 	)
 	if err != nil {
 		return err
 	}
+	callback.ID = id
+
+	return nil
+}
+
+func (r *PaymentCallbackRepository) FindByID(ctx context.Context, id uint64) (*entity.PaymentCallback, error) {
+	query := `
+		SELECT id, payment_id, provider, callback_hash, provider_event_id, signature, payload_json, status, error,
+			signature_verified, created_at, updated_at
+		FROM payment_callbacks
+		WHERE id = ?
+		LIMIT 1
+	`
+
+	callback := &entity.PaymentCallback{}
+	if err := scanPaymentCallback(executor(ctx, r.db, r.dialect).QueryRowContext(ctx, query, id), callback); err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return callback, nil
+}
+
+func (r *PaymentCallbackRepository) List(ctx context.Context, filter PaymentCallbackFilter) ([]*entity.PaymentCallback, error) {
+	// Payloads can be large, so they are only read when asked for.
+	payloadColumn := "''"
+	if filter.IncludePayload {
+		payloadColumn = "payload_json"
+	}
+
+	query := `
+		SELECT id, payment_id, provider, callback_hash, provider_event_id, signature, ` + payloadColumn + `, status, error,
+			signature_verified, created_at, updated_at
+		FROM payment_callbacks
+	`
+
+	conditions := make([]string, 0, 5)
+	args := make([]interface{}, 0, 7)
+
+	if strings.TrimSpace(filter.Provider) != "" {
+		conditions = append(conditions, "provider = ?")
+		args = append(args, filter.Provider)
+	}
+	if strings.TrimSpace(filter.CallbackHash) != "" {
+		conditions = append(conditions, "callback_hash = ?")
+		args = append(args, filter.CallbackHash)
+	}
+	if filter.HasStatus {
+		conditions = append(conditions, "status = ?")
+		args = append(args, filter.Status)
+	}
+	if filter.CreatedFrom != nil {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		conditions = append(conditions, "created_at <= ?")
+		args = append(args, *filter.CreatedTo)
+	}
 
-	id, err := result.LastInsertId()
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	query += " ORDER BY id DESC LIMIT ? OFFSET ?"
+	args = append(args, filter.Limit, filter.Offset)
+
+	rows, err := executor(ctx, r.db, r.dialect).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	callbacks := make([]*entity.PaymentCallback, 0)
+	for rows.Next() {
+		item := &entity.PaymentCallback{}
+		if err := scanPaymentCallback(rows, item); err != nil {
+			return nil, err
+		}
+		callbacks = append(callbacks, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return callbacks, nil
+}
+
+func scanPaymentCallback(scan rowScanner, callback *entity.PaymentCallback) error {
+	var paymentID sql.NullInt64
+	var providerEventID sql.NullString
+	var callbackErr sql.NullString
+
+	err := scan.Scan(
+		&callback.ID,
+		&paymentID,
+		&callback.Provider,
+		&callback.CallbackHash,
+		&providerEventID,
+		&callback.Signature,
+		&callback.PayloadJSON,
+		&callback.Status,
+		&callbackErr,
+		&callback.SignatureVerified,
+		&callback.CreatedAt,
+		&callback.UpdatedAt,
+	)
 	if err != nil {
 		return err
 	}
-	callback.ID = uint64(id)
+
+	callback.PaymentID = uint64PtrFromNull(paymentID)
+	callback.ProviderEventID = stringPtrFromNull(providerEventID)
+	callback.Error = stringPtrFromNull(callbackErr)
 
 	return nil
 }
@@ -0,0 +1,272 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/vibast-solutions/ms-go-payments/app/entity"
+)
+
+// CallbackOutboxFilter narrows dead-letter listing and redelivery. Empty
+// fields match every callback.
+type CallbackOutboxFilter struct {
+	PaymentID     uint64
+	CallerService string
+	EventType     string
+	Limit         int32
+	Offset        int32
+}
+
+type PaymentCallbackOutboxRepository struct {
+	db      DBTX
+	dialect Dialect
+}
+
+func NewPaymentCallbackOutboxRepository(db DBTX, dialect Dialect) *PaymentCallbackOutboxRepository {
+	return &PaymentCallbackOutboxRepository{db: db, dialect: dialect}
+}
+
+func (r *PaymentCallbackOutboxRepository) Create(ctx context.Context, item *entity.PaymentCallbackOutbox) error {
+	query := `
+		INSERT INTO payment_callback_outbox (
+			payment_id, charge_id, caller_service, event_type, request_id, destination, payload_json,
+			status, attempts, next_attempt_at, last_error, delivered_at, created_at, updated_at
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	id, err := r.dialect.InsertReturningID(ctx, executor(ctx, r.db, r.dialect), query,
+		item.PaymentID,
+		nullableUint64Value(item.ChargeID),
+		item.CallerService,
+		item.EventType,
+		item.RequestID,
+		item.Destination,
+		item.PayloadJSON,
+		item.Status,
+		item.Attempts,
+		nullableTimeValue(item.NextAttemptAt),
+		nullableStringValue(item.LastError),
+		nullableTimeValue(item.DeliveredAt),
+		item.CreatedAt,
+		item.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	item.ID = id
+
+	return nil
+}
+
+func (r *PaymentCallbackOutboxRepository) Update(ctx context.Context, item *entity.PaymentCallbackOutbox) error {
+	query := `
+		UPDATE payment_callback_outbox SET
+			status = ?,
+			attempts = ?,
+			next_attempt_at = ?,
+			last_error = ?,
+			delivered_at = ?,
+			updated_at = ?
+		WHERE id = ?
+	`
+
+	_, err := executor(ctx, r.db, r.dialect).ExecContext(ctx, query,
+		item.Status,
+		item.Attempts,
+		nullableTimeValue(item.NextAttemptAt),
+		nullableStringValue(item.LastError),
+		nullableTimeValue(item.DeliveredAt),
+		item.UpdatedAt,
+		item.ID,
+	)
+	return err
+}
+
+// ListDue returns pending callbacks whose next attempt is due. Only the oldest
+// undelivered callback of each payment is eligible, so callbacks for one
+// payment are delivered in the order they were queued; a dead-lettered one
+// holds back the rest until it is requeued.
+func (r *PaymentCallbackOutboxRepository) ListDue(ctx context.Context, now time.Time, limit int32) ([]*entity.PaymentCallbackOutbox, error) {
+	query := `
+		SELECT o.id, o.payment_id, o.charge_id, o.caller_service, o.event_type, o.request_id, o.destination, o.payload_json,
+			o.status, o.attempts, o.next_attempt_at, o.last_error, o.delivered_at, o.created_at, o.updated_at
+		FROM payment_callback_outbox o
+		WHERE o.status = ?
+		  AND o.next_attempt_at IS NOT NULL
+		  AND o.next_attempt_at <= ?
+		  AND NOT EXISTS (
+			SELECT 1 FROM payment_callback_outbox earlier
+			WHERE earlier.payment_id = o.payment_id
+			  AND earlier.status IN (?, ?)
+			  AND earlier.id < o.id
+		  )
+		ORDER BY o.id ASC
+		LIMIT ?
+	`
+
+	rows, err := executor(ctx, r.db, r.dialect).QueryContext(ctx, query,
+		entity.CallbackDeliveryPending, now, entity.CallbackDeliveryPending, entity.CallbackDeliveryFailed, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]*entity.PaymentCallbackOutbox, 0)
+	for rows.Next() {
+		item := &entity.PaymentCallbackOutbox{}
+		if err := scanCallbackOutbox(rows, item); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// Claim takes a callback listed by ListDue for delivery by moving its next
+// attempt to until. It reports false when another dispatcher claimed or
+// delivered the callback first. A claim that is never settled, e.g. because
+// the dispatcher crashed, lapses at until and the callback is due again.
+func (r *PaymentCallbackOutboxRepository) Claim(ctx context.Context, item *entity.PaymentCallbackOutbox, now, until time.Time) (bool, error) {
+	query := `
+		UPDATE payment_callback_outbox SET
+			next_attempt_at = ?,
+			updated_at = ?
+		WHERE id = ?
+		  AND status = ?
+		  AND next_attempt_at IS NOT NULL
+		  AND next_attempt_at <= ?
+	`
+
+	result, err := executor(ctx, r.db, r.dialect).ExecContext(ctx, query, until, now, item.ID, entity.CallbackDeliveryPending, now)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if affected == 0 {
+		return false, nil
+	}
+
+	item.NextAttemptAt = &until
+	item.UpdatedAt = now
+	return true, nil
+}
+
+// ListDeadLetters returns callbacks that exhausted their retry budget, newest
+// first.
+func (r *PaymentCallbackOutboxRepository) ListDeadLetters(ctx context.Context, filter CallbackOutboxFilter) ([]*entity.PaymentCallbackOutbox, error) {
+	conditions, args := deadLetterConditions(filter)
+	query := `
+		SELECT id, payment_id, charge_id, caller_service, event_type, request_id, destination, payload_json,
+			status, attempts, next_attempt_at, last_error, delivered_at, created_at, updated_at
+		FROM payment_callback_outbox
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY id DESC
+		LIMIT ? OFFSET ?
+	`
+	args = append(args, filter.Limit, filter.Offset)
+
+	rows, err := executor(ctx, r.db, r.dialect).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]*entity.PaymentCallbackOutbox, 0)
+	for rows.Next() {
+		item := &entity.PaymentCallbackOutbox{}
+		if err := scanCallbackOutbox(rows, item); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// RequeueDeadLetters moves the matching dead-lettered callbacks back to
+// pending with a fresh retry budget and returns how many were reset.
+func (r *PaymentCallbackOutboxRepository) RequeueDeadLetters(ctx context.Context, filter CallbackOutboxFilter, now time.Time) (int64, error) {
+	conditions, args := deadLetterConditions(filter)
+	query := `
+		UPDATE payment_callback_outbox SET
+			status = ?,
+			attempts = 0,
+			next_attempt_at = ?,
+			updated_at = ?
+		WHERE ` + strings.Join(conditions, " AND ")
+	args = append([]interface{}{entity.CallbackDeliveryPending, now, now}, args...)
+
+	result, err := executor(ctx, r.db, r.dialect).ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func deadLetterConditions(filter CallbackOutboxFilter) ([]string, []interface{}) {
+	conditions := []string{"status = ?"}
+	args := []interface{}{entity.CallbackDeliveryFailed}
+
+	if filter.PaymentID > 0 {
+		conditions = append(conditions, "payment_id = ?")
+		args = append(args, filter.PaymentID)
+	}
+	if strings.TrimSpace(filter.CallerService) != "" {
+		conditions = append(conditions, "caller_service = ?")
+		args = append(args, filter.CallerService)
+	}
+	if strings.TrimSpace(filter.EventType) != "" {
+		conditions = append(conditions, "event_type = ?")
+		args = append(args, filter.EventType)
+	}
+
+	return conditions, args
+}
+
+func scanCallbackOutbox(scan rowScanner, item *entity.PaymentCallbackOutbox) error {
+	var chargeID sql.NullInt64
+	var nextAttemptAt sql.NullTime
+	var lastError sql.NullString
+	var deliveredAt sql.NullTime
+
+	err := scan.Scan(
+		&item.ID,
+		&item.PaymentID,
+		&chargeID,
+		&item.CallerService,
+		&item.EventType,
+		&item.RequestID,
+		&item.Destination,
+		&item.PayloadJSON,
+		&item.Status,
+		&item.Attempts,
+		&nextAttemptAt,
+		&lastError,
+		&deliveredAt,
+		&item.CreatedAt,
+		&item.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	item.ChargeID = uint64PtrFromNull(chargeID)
+	item.NextAttemptAt = timePtrFromNull(nextAttemptAt)
+	item.LastError = stringPtrFromNull(lastError)
+	item.DeliveredAt = timePtrFromNull(deliveredAt)
+
+	return nil
+}
@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/vibast-solutions/ms-go-payments/app/entity"
+)
+
+var (
+	ErrChargeNotFound      = errors.New("charge not found")
+	ErrChargeAlreadyExists = errors.New("charge already exists")
+)
+
+type PaymentChargeRepository struct {
+	db      DBTX
+	dialect Dialect
+}
+
+func NewPaymentChargeRepository(db DBTX, dialect Dialect) *PaymentChargeRepository {
+	return &PaymentChargeRepository{db: db, dialect: dialect}
+}
+
+func (r *PaymentChargeRepository) Create(ctx context.Context, charge *entity.PaymentCharge) error {
+	query := `
+		INSERT INTO payment_charges (
+			payment_id, provider_invoice_id, amount_cents, currency, status, period_start, period_end,
+			created_at, updated_at
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	id, err := r.dialect.InsertReturningID(ctx, executor(ctx, r.db, r.dialect), query,
+		charge.PaymentID,
+		charge.ProviderInvoiceID,
+		charge.AmountCents,
+		charge.Currency,
+		charge.Status,
+		nullableTimeValue(charge.PeriodStart),
+		nullableTimeValue(charge.PeriodEnd),
+		charge.CreatedAt,
+		charge.UpdatedAt,
+	)
+	if err != nil {
+		if r.dialect.IsUniqueViolation(err) {
+			return ErrChargeAlreadyExists
+		}
+		return err
+	}
+	charge.ID = id
+
+	return nil
+}
+
+func (r *PaymentChargeRepository) Update(ctx context.Context, charge *entity.PaymentCharge) error {
+	query := `
+		UPDATE payment_charges SET
+			amount_cents = ?,
+			currency = ?,
+			status = ?,
+			period_start = ?,
+			period_end = ?,
+			updated_at = ?
+		WHERE id = ?
+	`
+
+	result, err := executor(ctx, r.db, r.dialect).ExecContext(ctx, query,
+		charge.AmountCents,
+		charge.Currency,
+		charge.Status,
+		nullableTimeValue(charge.PeriodStart),
+		nullableTimeValue(charge.PeriodEnd),
+		charge.UpdatedAt,
+		charge.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrChargeNotFound
+	}
+
+	return nil
+}
+
+func (r *PaymentChargeRepository) FindByProviderInvoiceID(ctx context.Context, paymentID uint64, providerInvoiceID string) (*entity.PaymentCharge, error) {
+	query := `
+		SELECT id, payment_id, provider_invoice_id, amount_cents, currency, status, period_start, period_end,
+			created_at, updated_at
+		FROM payment_charges
+		WHERE payment_id = ? AND provider_invoice_id = ?
+		LIMIT 1
+	`
+
+	charge := &entity.PaymentCharge{}
+	if err := scanCharge(executor(ctx, r.db, r.dialect).QueryRowContext(ctx, query, paymentID, providerInvoiceID), charge); err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return charge, nil
+}
+
+func (r *PaymentChargeRepository) ListByPaymentID(ctx context.Context, paymentID uint64) ([]*entity.PaymentCharge, error) {
+	query := `
+		SELECT id, payment_id, provider_invoice_id, amount_cents, currency, status, period_start, period_end,
+			created_at, updated_at
+		FROM payment_charges
+		WHERE payment_id = ?
+		ORDER BY id DESC
+	`
+
+	rows, err := executor(ctx, r.db, r.dialect).QueryContext(ctx, query, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanChargesFromRows(rows)
+}
+
+func scanCharge(scan rowScanner, charge *entity.PaymentCharge) error {
+	var periodStart sql.NullTime
+	var periodEnd sql.NullTime
+
+	err := scan.Scan(
+		&charge.ID,
+		&charge.PaymentID,
+		&charge.ProviderInvoiceID,
+		&charge.AmountCents,
+		&charge.Currency,
+		&charge.Status,
+		&periodStart,
+		&periodEnd,
+		&charge.CreatedAt,
+		&charge.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	charge.PeriodStart = timePtrFromNull(periodStart)
+	charge.PeriodEnd = timePtrFromNull(periodEnd)
+
+	return nil
+}
+
+func scanChargesFromRows(rows *sql.Rows) ([]*entity.PaymentCharge, error) {
+	charges := make([]*entity.PaymentCharge, 0)
+	for rows.Next() {
+		item := &entity.PaymentCharge{}
+		if err := scanCharge(rows, item); err != nil {
+			return nil, err
+		}
+		charges = append(charges, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return charges, nil
+}
@@ -2,16 +2,29 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"strings"
 
 	"github.com/vibast-solutions/ms-go-payments/app/entity"
 )
 
+// PaymentEventFilter selects one page of a payment's history. AfterID is the
+// keyset cursor: only events with a larger id are returned.
+type PaymentEventFilter struct {
+	PaymentID      uint64
+	EventType      string
+	AfterID        uint64
+	Limit          int32
+	IncludePayload bool
+}
+
 type PaymentEventRepository struct {
-	db DBTX
+	db      DBTX
+	dialect Dialect
 }
 
-func NewPaymentEventRepository(db DBTX) *PaymentEventRepository {
-	return &PaymentEventRepository{db: db}
+func NewPaymentEventRepository(db DBTX, dialect Dialect) *PaymentEventRepository {
+	return &PaymentEventRepository{db: db, dialect: dialect}
 }
 
 func (r *PaymentEventRepository) Create(ctx context.Context, event *entity.PaymentEvent) error {
@@ -22,7 +35,7 @@ func (r *PaymentEventRepository) Create(ctx context.Context, event *entity.Payme
 		VALUES (?, ?, ?, ?, ?, ?, ?)
 	`
 
-	result, err := r.db.ExecContext(ctx, query,
+	id, err := r.dialect.InsertReturningID(ctx, executor(ctx, r.db, r.dialect), query,
 		event.PaymentID,
 		event.EventType,
 		nullableInt32Value(event.OldStatus),
@@ -34,12 +47,76 @@ func (r *PaymentEventRepository) Create(ctx context.Context, event *entity.Payme
 	if err != nil {
 		return err
 	}
+	event.ID = id
+
+	return nil
+}
+
+func (r *PaymentEventRepository) ListByPayment(ctx context.Context, filter PaymentEventFilter) ([]*entity.PaymentEvent, error) {
+	// Payloads can be large, so they are only read when asked for.
+	payloadColumn := "NULL"
+	if filter.IncludePayload {
+		payloadColumn = "payload_json"
+	}
+
+	query := `
+		SELECT id, payment_id, event_type, old_status, new_status, provider_event_id, ` + payloadColumn + `, created_at
+		FROM payment_events
+		WHERE payment_id = ? AND id > ?
+	`
+	args := []interface{}{filter.PaymentID, filter.AfterID}
+	if strings.TrimSpace(filter.EventType) != "" {
+		query += " AND event_type = ?"
+		args = append(args, filter.EventType)
+	}
+	query += " ORDER BY id ASC"
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+	}
 
-	id, err := result.LastInsertId()
+	rows, err := executor(ctx, r.db, r.dialect).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := make([]*entity.PaymentEvent, 0)
+	for rows.Next() {
+		item := &entity.PaymentEvent{}
+		if err := scanPaymentEvent(rows, item); err != nil {
+			return nil, err
+		}
+		events = append(events, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func scanPaymentEvent(scan rowScanner, event *entity.PaymentEvent) error {
+	var oldStatus sql.NullInt32
+	var providerEventID sql.NullString
+	var payloadJSON sql.NullString
+
+	err := scan.Scan(
+		&event.ID,
+		&event.PaymentID,
+		&event.EventType,
+		&oldStatus,
+		&event.NewStatus,
+		&providerEventID,
+		&payloadJSON,
+		&event.CreatedAt,
+	)
 	if err != nil {
 		return err
 	}
-	event.ID = uint64(id)
+
+	event.OldStatus = int32PtrFromNull(oldStatus)
+	event.ProviderEventID = stringPtrFromNull(providerEventID)
+	event.PayloadJSON = stringPtrFromNull(payloadJSON)
 
 	return nil
 }
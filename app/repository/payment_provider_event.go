@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/vibast-solutions/ms-go-payments/app/entity"
+)
+
+var ErrProviderEventAlreadyExists = errors.New("provider event already exists")
+
+type PaymentProviderEventRepository struct {
+	db      DBTX
+	dialect Dialect
+}
+
+func NewPaymentProviderEventRepository(db DBTX, dialect Dialect) *PaymentProviderEventRepository {
+	return &PaymentProviderEventRepository{db: db, dialect: dialect}
+}
+
+func (r *PaymentProviderEventRepository) Create(ctx context.Context, event *entity.PaymentProviderEvent) error {
+	query := `
+		INSERT INTO payment_provider_events (
+			provider, provider_event_id, payment_id, event_type, created_at
+		)
+		VALUES (?, ?, ?, ?, ?)
+	`
+
+	id, err := r.dialect.InsertReturningID(ctx, executor(ctx, r.db, r.dialect), query,
+		event.Provider,
+		event.ProviderEventID,
+		event.PaymentID,
+		event.EventType,
+		event.CreatedAt,
+	)
+	if err != nil {
+		if r.dialect.IsUniqueViolation(err) {
+			return ErrProviderEventAlreadyExists
+		}
+		return err
+	}
+	event.ID = id
+
+	return nil
+}
+
+func (r *PaymentProviderEventRepository) FindByProviderEventID(ctx context.Context, provider int32, providerEventID string) (*entity.PaymentProviderEvent, error) {
+	query := `
+		SELECT id, provider, provider_event_id, payment_id, event_type, created_at
+		FROM payment_provider_events
+		WHERE provider = ? AND provider_event_id = ?
+		LIMIT 1
+	`
+
+	event := &entity.PaymentProviderEvent{}
+	err := executor(ctx, r.db, r.dialect).QueryRowContext(ctx, query, provider, providerEventID).Scan(
+		&event.ID,
+		&event.Provider,
+		&event.ProviderEventID,
+		&event.PaymentID,
+		&event.EventType,
+		&event.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return event, nil
+}
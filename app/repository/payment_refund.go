@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/vibast-solutions/ms-go-payments/app/entity"
+)
+
+var (
+	ErrRefundNotFound      = errors.New("refund not found")
+	ErrRefundAlreadyExists = errors.New("refund already exists")
+)
+
+type PaymentRefundRepository struct {
+	db      DBTX
+	dialect Dialect
+}
+
+func NewPaymentRefundRepository(db DBTX, dialect Dialect) *PaymentRefundRepository {
+	return &PaymentRefundRepository{db: db, dialect: dialect}
+}
+
+func (r *PaymentRefundRepository) Create(ctx context.Context, refund *entity.PaymentRefund) error {
+	query := `
+		INSERT INTO payment_refunds (
+			payment_id, request_id, amount_cents, currency, status, reason, provider_refund_id, created_at, updated_at
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	id, err := r.dialect.InsertReturningID(ctx, executor(ctx, r.db, r.dialect), query,
+		refund.PaymentID,
+		refund.RequestID,
+		refund.AmountCents,
+		refund.Currency,
+		refund.Status,
+		nullableStringValue(refund.Reason),
+		nullableStringValue(refund.ProviderRefundID),
+		refund.CreatedAt,
+		refund.UpdatedAt,
+	)
+	if err != nil {
+		if r.dialect.IsUniqueViolation(err) {
+			return ErrRefundAlreadyExists
+		}
+		return err
+	}
+	refund.ID = id
+
+	return nil
+}
+
+func (r *PaymentRefundRepository) Update(ctx context.Context, refund *entity.PaymentRefund) error {
+	query := `
+		UPDATE payment_refunds SET
+			request_id = ?,
+			amount_cents = ?,
+			status = ?,
+			reason = ?,
+			provider_refund_id = ?,
+			updated_at = ?
+		WHERE id = ?
+	`
+
+	result, err := executor(ctx, r.db, r.dialect).ExecContext(ctx, query,
+		refund.RequestID,
+		refund.AmountCents,
+		refund.Status,
+		nullableStringValue(refund.Reason),
+		nullableStringValue(refund.ProviderRefundID),
+		refund.UpdatedAt,
+		refund.ID,
+	)
+	if err != nil {
+		if r.dialect.IsUniqueViolation(err) {
+			return ErrRefundAlreadyExists
+		}
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrRefundNotFound
+	}
+
+	return nil
+}
+
+func (r *PaymentRefundRepository) FindByPaymentRequestID(ctx context.Context, paymentID uint64, requestID string) (*entity.PaymentRefund, error) {
+	query := `
+		SELECT id, payment_id, request_id, amount_cents, currency, status, reason, provider_refund_id, created_at, updated_at
+		FROM payment_refunds
+		WHERE payment_id = ? AND request_id = ?
+		LIMIT 1
+	`
+
+	refund := &entity.PaymentRefund{}
+	if err := scanRefund(executor(ctx, r.db, r.dialect).QueryRowContext(ctx, query, paymentID, requestID), refund); err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return refund, nil
+}
+
+func (r *PaymentRefundRepository) FindByProviderRefundID(ctx context.Context, paymentID uint64, providerRefundID string) (*entity.PaymentRefund, error) {
+	query := `
+		SELECT id, payment_id, request_id, amount_cents, currency, status, reason, provider_refund_id, created_at, updated_at
+		FROM payment_refunds
+		WHERE payment_id = ? AND provider_refund_id = ?
+		LIMIT 1
+	`
+
+	refund := &entity.PaymentRefund{}
+	if err := scanRefund(executor(ctx, r.db, r.dialect).QueryRowContext(ctx, query, paymentID, providerRefundID), refund); err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return refund, nil
+}
+
+func (r *PaymentRefundRepository) ListByPaymentID(ctx context.Context, paymentID uint64) ([]*entity.PaymentRefund, error) {
+	query := `
+		SELECT id, payment_id, request_id, amount_cents, currency, status, reason, provider_refund_id, created_at, updated_at
+		FROM payment_refunds
+		WHERE payment_id = ?
+		ORDER BY id ASC
+	`
+
+	rows, err := executor(ctx, r.db, r.dialect).QueryContext(ctx, query, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	refunds := make([]*entity.PaymentRefund, 0)
+	for rows.Next() {
+		item := &entity.PaymentRefund{}
+		if err := scanRefund(rows, item); err != nil {
+			return nil, err
+		}
+		refunds = append(refunds, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return refunds, nil
+}
+
+func scanRefund(scan rowScanner, refund *entity.PaymentRefund) error {
+	var reason sql.NullString
+	var providerRefundID sql.NullString
+
+	err := scan.Scan(
+		&refund.ID,
+		&refund.PaymentID,
+		&refund.RequestID,
+		&refund.AmountCents,
+		&refund.Currency,
+		&refund.Status,
+		&reason,
+		&providerRefundID,
+		&refund.CreatedAt,
+		&refund.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	refund.Reason = stringPtrFromNull(reason)
+	refund.ProviderRefundID = stringPtrFromNull(providerRefundID)
+
+	return nil
+}
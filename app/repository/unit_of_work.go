@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+type txContextKey struct{}
+
+// UnitOfWork runs a group of repository calls inside a single database
+// transaction. The transaction travels in the context, so every repository
+// method called with that context writes through it instead of the pool.
+type UnitOfWork struct {
+	db *sql.DB
+}
+
+func NewUnitOfWork(db *sql.DB) *UnitOfWork {
+	return &UnitOfWork{db: db}
+}
+
+// Do commits when fn returns nil and rolls back otherwise. Nested calls join
+// the transaction that is already open on the context.
+func (u *UnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txContextKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(context.WithValue(ctx, txContextKey{}, tx)); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && !errors.Is(rollbackErr, sql.ErrTxDone) {
+			return errors.Join(err, rollbackErr)
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// executor returns the transaction bound to ctx, falling back to db, with
+// queries rebound for dialect.
+func executor(ctx context.Context, db DBTX, dialect Dialect) DBTX {
+	if tx, ok := ctx.Value(txContextKey{}).(*sql.Tx); ok {
+		db = tx
+	}
+	return boundDB{db: db, dialect: dialect}
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/vibast-solutions/ms-go-payments/app/entity"
+)
+
+type callerServiceContextKey struct{}
+
+// WithCallerService returns a context carrying the internal service that was
+// authenticated for the request. Payment reads and mutations made with it are
+// limited to that service's payments unless it is an admin service.
+func WithCallerService(ctx context.Context, callerService string) context.Context {
+	return context.WithValue(ctx, callerServiceContextKey{}, strings.TrimSpace(callerService))
+}
+
+// CallerServiceFromContext returns the authenticated caller service, if any.
+func CallerServiceFromContext(ctx context.Context) (string, bool) {
+	callerService, ok := ctx.Value(callerServiceContextKey{}).(string)
+	return callerService, ok && callerService != ""
+}
+
+// callerScope returns the caller service the request is limited to, or "" when
+// it may act on every caller's payments: admin services, and requests without
+// an authenticated caller such as CLI jobs.
+func (s *PaymentService) callerScope(ctx context.Context) string {
+	callerService, ok := CallerServiceFromContext(ctx)
+	if !ok || s.adminServices[callerService] {
+		return ""
+	}
+	return callerService
+}
+
+// scopeCallerService applies the caller scope to a caller_service filter or
+// field: it defaults to the caller and must not name another service.
+func (s *PaymentService) scopeCallerService(ctx context.Context, requested string) (string, error) {
+	scope := s.callerScope(ctx)
+	if scope == "" {
+		return requested, nil
+	}
+	if requested != "" && requested != scope {
+		return "", fmt.Errorf("%w: caller %s cannot act for caller_service %s", ErrForbidden, scope, requested)
+	}
+	return scope, nil
+}
+
+// requireAdmin rejects callers that are limited to their own payments.
+func (s *PaymentService) requireAdmin(ctx context.Context) error {
+	if scope := s.callerScope(ctx); scope != "" {
+		return fmt.Errorf("%w: caller %s is not an admin service", ErrForbidden, scope)
+	}
+	return nil
+}
+
+// findPaymentForCaller loads a payment the caller may see. Payments of other
+// callers are reported as not found so their IDs cannot be probed.
+func (s *PaymentService) findPaymentForCaller(ctx context.Context, id uint64) (*entity.Payment, error) {
+	payment, err := s.paymentRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.visibleToCaller(ctx, payment)
+}
+
+// visibleToCaller turns a looked-up payment into ErrPaymentNotFound when it is
+// missing or belongs to another caller.
+func (s *PaymentService) visibleToCaller(ctx context.Context, payment *entity.Payment) (*entity.Payment, error) {
+	if payment == nil {
+		return nil, ErrPaymentNotFound
+	}
+	if scope := s.callerScope(ctx); scope != "" && payment.CallerService != scope {
+		return nil, ErrPaymentNotFound
+	}
+	return payment, nil
+}
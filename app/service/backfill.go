@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/vibast-solutions/ms-go-payments/app/entity"
+	"github.com/vibast-solutions/ms-go-payments/app/provider"
+	"github.com/vibast-solutions/ms-go-payments/app/types"
+)
+
+// EventBackfillChange is a payment whose status moved while applying a
+// backfilled provider event.
+type EventBackfillChange struct {
+	ProviderEventID string
+	EventType       string
+	PaymentID       uint64
+	OldStatus       int32
+	NewStatus       int32
+}
+
+// EventBackfillSummary counts what happened to the events of a backfill.
+// Unmatched events could not be tied to a payment; Failed events were
+// rejected by the callback pipeline.
+type EventBackfillSummary struct {
+	Fetched          int
+	Applied          int
+	AlreadyProcessed int
+	Unmatched        int
+	Failed           int
+	Changes          []EventBackfillChange
+}
+
+// BackfillProviderEvents fetches the provider's events created since the
+// given time and applies the ones not yet processed through the provider
+// callback pipeline, oldest first. Signatures are not checked: the events
+// come from an authenticated API call. Failures of single events are counted
+// and the first one is returned next to the summary.
+func (s *PaymentService) BackfillProviderEvents(ctx context.Context, providerRaw string, since time.Time) (*EventBackfillSummary, error) {
+	providerCode, err := parseProviderCode(providerRaw)
+	if err != nil {
+		return nil, ErrProviderUnsupported
+	}
+	providerClient, err := s.providerReg.Get(providerCode)
+	if err != nil {
+		return nil, ErrProviderUnsupported
+	}
+
+	events, err := s.listProviderEvents(ctx, providerClient, since)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &EventBackfillSummary{Fetched: len(events)}
+	var firstErr error
+	for i := len(events) - 1; i >= 0; i-- {
+		listed := events[i]
+
+		parsedEvent, err := providerClient.ParseCallback(ctx, listed.Payload)
+		if err != nil {
+			summary.Failed++
+			firstErr = keepFirstErr(firstErr, err)
+			continue
+		}
+
+		eventID := strings.TrimSpace(derefString(parsedEvent.ProviderEventID))
+		if eventID == "" {
+			eventID = listed.ID
+		}
+		if eventID != "" {
+			processed, err := s.providerEventRepo.FindByProviderEventID(ctx, providerCode, eventID)
+			if err != nil {
+				summary.Failed++
+				firstErr = keepFirstErr(firstErr, err)
+				continue
+			}
+			if processed != nil {
+				summary.AlreadyProcessed++
+				continue
+			}
+		}
+
+		payment, err := s.findPaymentForProviderEvent(ctx, providerCode, parsedEvent)
+		if err != nil {
+			summary.Failed++
+			firstErr = keepFirstErr(firstErr, err)
+			continue
+		}
+		if payment == nil {
+			summary.Unmatched++
+			continue
+		}
+
+		oldStatus := payment.Status
+		updated, err := s.handleProviderCallback(ctx, &types.HandleProviderCallbackRequest{
+			Provider:     strings.ToLower(strings.TrimSpace(providerRaw)),
+			CallbackHash: payment.ProviderCallbackHash,
+			Payload:      string(listed.Payload),
+		}, false, eventID)
+		if err != nil {
+			summary.Failed++
+			firstErr = keepFirstErr(firstErr, err)
+			continue
+		}
+
+		summary.Applied++
+		if updated != nil && updated.Status != oldStatus {
+			summary.Changes = append(summary.Changes, EventBackfillChange{
+				ProviderEventID: eventID,
+				EventType:       parsedEvent.EventType,
+				PaymentID:       updated.ID,
+				OldStatus:       oldStatus,
+				NewStatus:       updated.Status,
+			})
+		}
+	}
+
+	return summary, firstErr
+}
+
+func (s *PaymentService) listProviderEvents(ctx context.Context, providerClient provider.Provider, since time.Time) ([]provider.ListedEvent, error) {
+	events := make([]provider.ListedEvent, 0)
+	startingAfter := ""
+	for {
+		page, err := providerClient.ListEvents(ctx, &provider.ListEventsInput{
+			CreatedFrom:   since,
+			StartingAfter: startingAfter,
+		})
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, page.Events...)
+		if !page.HasMore || len(page.Events) == 0 {
+			return events, nil
+		}
+
+		startingAfter = page.Events[len(page.Events)-1].ID
+		if startingAfter == "" {
+			return nil, errors.New("provider event page ended with an event without id")
+		}
+	}
+}
+
+// findPaymentForProviderEvent ties an event that did not arrive on a callback
+// URL to its payment: by the callback hash carried in the event metadata, else
+// by the subscription it belongs to.
+func (s *PaymentService) findPaymentForProviderEvent(ctx context.Context, providerCode int32, event *provider.CallbackEvent) (*entity.Payment, error) {
+	if callbackHash := strings.TrimSpace(derefString(event.CallbackHash)); callbackHash != "" {
+		payment, err := s.paymentRepo.FindByCallbackHash(ctx, providerCode, callbackHash)
+		if err != nil || payment != nil {
+			return payment, err
+		}
+	}
+	if subscriptionID := strings.TrimSpace(derefString(event.ProviderSubscriptionID)); subscriptionID != "" {
+		return s.paymentRepo.FindByProviderSubscriptionID(ctx, providerCode, subscriptionID)
+	}
+	return nil, nil
+}
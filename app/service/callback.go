@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
@@ -16,6 +17,7 @@ import (
 const (
 	paymentCallbackStatusProcessed int32 = 10
 	paymentCallbackStatusRejected  int32 = 20
+	paymentCallbackStatusDuplicate int32 = 30
 )
 
 type handleProviderCallbackRequest interface {
@@ -25,7 +27,113 @@ type handleProviderCallbackRequest interface {
 	GetPayload() string
 }
 
+type listProviderCallbacksRequest interface {
+	GetProvider() string
+	GetCallbackHash() string
+	GetHasStatus() bool
+	GetStatus() types.ProviderCallbackStatus
+	GetCreatedFrom() string
+	GetCreatedTo() string
+	GetLimit() int32
+	GetOffset() int32
+	GetIncludePayload() bool
+}
+
+type replayProviderCallbackRequest interface {
+	GetId() uint64
+	GetSkipSignature() bool
+}
+
+// ProviderCallbackContract returns how the named provider delivers webhooks.
+func (s *PaymentService) ProviderCallbackContract(providerRaw string) (provider.CallbackContract, error) {
+	providerCode, err := parseProviderCode(providerRaw)
+	if err != nil {
+		return provider.CallbackContract{}, ErrProviderUnsupported
+	}
+	providerClient, err := s.providerReg.Get(providerCode)
+	if err != nil {
+		return provider.CallbackContract{}, ErrProviderUnsupported
+	}
+	return providerClient.CallbackContract(), nil
+}
+
 func (s *PaymentService) HandleProviderCallback(ctx context.Context, req handleProviderCallbackRequest) (*entity.Payment, error) {
+	return s.handleProviderCallback(ctx, req, true, "")
+}
+
+// ListProviderCallbacks searches the inbound webhook inbox, newest first.
+func (s *PaymentService) ListProviderCallbacks(ctx context.Context, req listProviderCallbacksRequest) ([]*entity.PaymentCallback, error) {
+	if err := s.requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	limit := req.GetLimit()
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+
+	filter := repository.PaymentCallbackFilter{
+		Provider:       strings.ToLower(strings.TrimSpace(req.GetProvider())),
+		CallbackHash:   strings.TrimSpace(req.GetCallbackHash()),
+		HasStatus:      req.GetHasStatus(),
+		Status:         int32(req.GetStatus()),
+		Limit:          limit,
+		Offset:         req.GetOffset(),
+		IncludePayload: req.GetIncludePayload(),
+	}
+
+	var err error
+	if filter.CreatedFrom, err = parseOptionalTime(req.GetCreatedFrom()); err != nil {
+		return nil, fmt.Errorf("%w: created_from must be an RFC3339 timestamp", ErrInvalidRequest)
+	}
+	if filter.CreatedTo, err = parseOptionalTime(req.GetCreatedTo()); err != nil {
+		return nil, fmt.Errorf("%w: created_to must be an RFC3339 timestamp", ErrInvalidRequest)
+	}
+
+	return s.callbackRepo.List(ctx, filter)
+}
+
+// ReplayProviderCallback runs a stored webhook through the callback handling
+// again. The replay is recorded as a new inbox entry; events that were already
+// applied are deduplicated by their provider event ID as usual. skip_signature
+// is only honoured for webhooks whose signature was verified when they first
+// arrived, so a forged webhook that was rejected can never be applied.
+func (s *PaymentService) ReplayProviderCallback(ctx context.Context, req replayProviderCallbackRequest) (*entity.Payment, error) {
+	if err := s.requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	stored, err := s.callbackRepo.FindByID(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	if stored == nil {
+		return nil, ErrCallbackNotFound
+	}
+	if req.GetSkipSignature() && !signatureWasVerified(stored) {
+		return nil, fmt.Errorf("%w: skip_signature is only allowed for callbacks whose signature was verified", ErrInvalidRequest)
+	}
+
+	return s.handleProviderCallback(ctx, &types.HandleProviderCallbackRequest{
+		Provider:     stored.Provider,
+		CallbackHash: stored.CallbackHash,
+		Signature:    stored.Signature,
+		Payload:      stored.PayloadJSON,
+	}, !req.GetSkipSignature(), "")
+}
+
+// signatureWasVerified also trusts processed and duplicate rows written before
+// signature_verified existed: both only happen after verification.
+func signatureWasVerified(callback *entity.PaymentCallback) bool {
+	return callback.SignatureVerified ||
+		callback.Status == paymentCallbackStatusProcessed ||
+		callback.Status == paymentCallbackStatusDuplicate
+}
+
+// handleProviderCallback applies a webhook payload. fallbackEventID is used as
+// the provider event ID when the payload does not carry one, so that events
+// listed from the provider API are deduplicated by the ID they were listed with.
+func (s *PaymentService) handleProviderCallback(ctx context.Context, req handleProviderCallbackRequest, verifySignature bool, fallbackEventID string) (*entity.Payment, error) {
 	providerCode, err := parseProviderCode(req.GetProvider())
 	if err != nil {
 		if errors.Is(err, provider.ErrProviderNotSupported) {
@@ -43,55 +151,149 @@ func (s *PaymentService) HandleProviderCallback(ctx context.Context, req handleP
 	}
 
 	payload := []byte(req.GetPayload())
-	signature := strings.TrimSpace(req.GetSignature())
-	parsedEvent, err := providerClient.VerifyAndParseCallback(ctx, payload, signature)
+	var parsedEvent *provider.CallbackEvent
+	if verifySignature {
+		parsedEvent, err = providerClient.VerifyAndParseCallback(ctx, payload, strings.TrimSpace(req.GetSignature()))
+	} else {
+		parsedEvent, err = providerClient.ParseCallback(ctx, payload)
+	}
 	if err != nil {
-		s.persistRejectedCallback(ctx, nil, req, fmt.Sprintf("provider callback validation failed: %v", err))
+		if writeErr := s.persistRejectedCallback(ctx, nil, req, false, fmt.Sprintf("provider callback validation failed: %v", err)); writeErr != nil {
+			return nil, writeErr
+		}
 		return nil, ErrCallbackRejected
 	}
 	if parsedEvent == nil {
-		s.persistRejectedCallback(ctx, nil, req, "provider callback payload could not be parsed")
+		if err := s.persistRejectedCallback(ctx, nil, req, true, "provider callback payload could not be parsed"); err != nil {
+			return nil, err
+		}
 		return nil, ErrCallbackRejected
 	}
+	if strings.TrimSpace(derefString(parsedEvent.ProviderEventID)) == "" && fallbackEventID != "" {
+		parsedEvent.ProviderEventID = &fallbackEventID
+	}
 
 	callbackHash := strings.TrimSpace(req.GetCallbackHash())
 	payment, err := s.paymentRepo.FindByCallbackHash(ctx, providerCode, callbackHash)
 	if err != nil {
+		s.persistFailedCallback(ctx, nil, req, err)
 		return nil, err
 	}
 	if payment == nil {
-		s.persistRejectedCallback(ctx, nil, req, "payment not found for callback hash")
+		if err := s.persistRejectedCallback(ctx, nil, req, true, "payment not found for callback hash"); err != nil {
+			return nil, err
+		}
 		return nil, ErrPaymentNotFound
 	}
 
+	// Providers redeliver events they consider unacknowledged; an event that has
+	// already been applied is acknowledged again without touching the payment.
+	providerEventID := strings.TrimSpace(derefString(parsedEvent.ProviderEventID))
+	if providerEventID != "" {
+		processed, err := s.providerEventRepo.FindByProviderEventID(ctx, providerCode, providerEventID)
+		if err != nil {
+			s.persistFailedCallback(ctx, &payment.ID, req, err)
+			return nil, err
+		}
+		if processed != nil {
+			if err := s.persistDuplicateCallback(ctx, payment.ID, req, providerEventID); err != nil {
+				return nil, err
+			}
+			return payment, nil
+		}
+	}
+
+	eventType := strings.TrimSpace(parsedEvent.EventType)
+	if eventType == "" {
+		eventType = "provider_callback"
+	}
+
 	now := time.Now().UTC()
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		// Claiming the provider event first makes a concurrent delivery of the
+		// same event block on the unique key and then fall into the duplicate path.
+		if providerEventID != "" {
+			err := s.providerEventRepo.Create(ctx, &entity.PaymentProviderEvent{
+				Provider:        providerCode,
+				ProviderEventID: providerEventID,
+				PaymentID:       payment.ID,
+				EventType:       eventType,
+				CreatedAt:       now,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return s.applyProviderCallback(ctx, payment, parsedEvent, eventType, req, now)
+	})
+	if err != nil {
+		if !errors.Is(err, repository.ErrProviderEventAlreadyExists) {
+			s.persistFailedCallback(ctx, &payment.ID, req, err)
+			return nil, mapUpdateError(err)
+		}
+		if err := s.persistDuplicateCallback(ctx, payment.ID, req, providerEventID); err != nil {
+			return nil, err
+		}
+		return s.GetPayment(ctx, payment.ID)
+	}
+
+	return payment, nil
+}
+
+func (s *PaymentService) applyProviderCallback(
+	ctx context.Context,
+	payment *entity.Payment,
+	parsedEvent *provider.CallbackEvent,
+	eventType string,
+	req handleProviderCallbackRequest,
+	now time.Time,
+) error {
 	oldStatus := payment.Status
 
-	if parsedEvent.ProviderPaymentID != nil {
+	// Payment-link payments keep the plink_ ID they were created with: status
+	// checks, cancels and refunds look the payment up through the link, while
+	// the event only carries the ID of the checkout session opened from it.
+	if parsedEvent.ProviderPaymentID != nil && !keepsProviderPaymentID(payment) {
 		payment.ProviderPaymentID = parsedEvent.ProviderPaymentID
 	}
 	if parsedEvent.ProviderSubscriptionID != nil {
 		payment.ProviderSubscriptionID = parsedEvent.ProviderSubscriptionID
 	}
-	if parsedEvent.NewStatus > 0 {
-		payment.Status = parsedEvent.NewStatus
+	// Renewal invoices are tracked as charges; they only move the parent until
+	// the subscription has been paid for the first time. Out-of-order events
+	// that would make an illegal move (e.g. a late expiry after PAID) are kept
+	// for audit but leave the status untouched.
+	var transitionErr error
+	if parsedEvent.NewStatus > 0 && (parsedEvent.Invoice == nil || oldStatus != int32(types.PaymentStatus_PAYMENT_STATUS_PAID)) {
+		_, transitionErr = transitionStatus(payment, parsedEvent.NewStatus)
 	}
 
-	if payment.Status != oldStatus && terminalStatus(payment.Status) {
-		s.markForCallbackDelivery(payment, now)
+	oldRefundedCents := payment.RefundedCents
+	charge, err := s.applyInvoiceCallback(ctx, payment, parsedEvent, now)
+	if err != nil {
+		return err
+	}
+	if err := s.applyRefundCallback(ctx, payment, parsedEvent, now); err != nil {
+		return err
 	}
 
 	payment.UpdatedAt = now
 	if err := s.paymentRepo.Update(ctx, payment); err != nil {
-		if errors.Is(err, repository.ErrPaymentNotFound) {
-			return nil, ErrPaymentNotFound
-		}
-		return nil, err
+		return err
 	}
 
-	eventType := strings.TrimSpace(parsedEvent.EventType)
-	if eventType == "" {
-		eventType = "provider_callback"
+	if err := s.queueStatusCallback(ctx, payment, oldStatus, now); err != nil {
+		return err
+	}
+	if charge != nil {
+		if err := s.queueCallback(ctx, payment, charge, callbackEventChargeStatus, now); err != nil {
+			return err
+		}
+	}
+	if payment.RefundedCents != oldRefundedCents {
+		if err := s.queueCallback(ctx, payment, nil, callbackEventPaymentRefund, now); err != nil {
+			return err
+		}
 	}
 
 	oldStatusPtr := &oldStatus
@@ -99,60 +301,128 @@ func (s *PaymentService) HandleProviderCallback(ctx context.Context, req handleP
 		oldStatusPtr = nil
 	}
 
-	payloadJSON := string(payload)
-	_ = s.eventRepo.Create(ctx, &entity.PaymentEvent{
-		PaymentID:        payment.ID,
-		EventType:        eventType,
-		OldStatus:        oldStatusPtr,
-		NewStatus:        payment.Status,
-		ProviderEventID:  parsedEvent.ProviderEventID,
-		PayloadJSON:      &payloadJSON,
-		CreatedAt:        now,
+	payloadJSON := req.GetPayload()
+	err = s.eventRepo.Create(ctx, &entity.PaymentEvent{
+		PaymentID:       payment.ID,
+		EventType:       eventType,
+		OldStatus:       oldStatusPtr,
+		NewStatus:       payment.Status,
+		ProviderEventID: parsedEvent.ProviderEventID,
+		PayloadJSON:     &payloadJSON,
+		CreatedAt:       now,
 	})
+	if err != nil {
+		return err
+	}
+	if transitionErr != nil {
+		rejectedJSON, _ := json.Marshal(map[string]string{"error": transitionErr.Error(), "event_type": eventType})
+		rejected := string(rejectedJSON)
+		err := s.eventRepo.Create(ctx, &entity.PaymentEvent{
+			PaymentID:       payment.ID,
+			EventType:       "status_transition_rejected",
+			NewStatus:       payment.Status,
+			ProviderEventID: parsedEvent.ProviderEventID,
+			PayloadJSON:     &rejected,
+			CreatedAt:       now,
+		})
+		if err != nil {
+			return err
+		}
+	}
 
 	paymentID := payment.ID
-	callbackErr := s.callbackRepo.Create(ctx, &entity.PaymentCallback{
-		PaymentID:     &paymentID,
-		Provider:      strings.ToLower(strings.TrimSpace(req.GetProvider())),
-		CallbackHash:  callbackHash,
-		Signature:     signature,
-		PayloadJSON:   string(payload),
-		Status:        paymentCallbackStatusProcessed,
-		CreatedAt:     now,
-		UpdatedAt:     now,
+	return s.callbackRepo.Create(ctx, &entity.PaymentCallback{
+		PaymentID:         &paymentID,
+		Provider:          strings.ToLower(strings.TrimSpace(req.GetProvider())),
+		CallbackHash:      strings.TrimSpace(req.GetCallbackHash()),
+		ProviderEventID:   parsedEvent.ProviderEventID,
+		Signature:         strings.TrimSpace(req.GetSignature()),
+		PayloadJSON:       req.GetPayload(),
+		Status:            paymentCallbackStatusProcessed,
+		SignatureVerified: true,
+		CreatedAt:         now,
+		UpdatedAt:         now,
 	})
-	if callbackErr != nil {
-		return nil, callbackErr
-	}
+}
 
-	return payment, nil
+func keepsProviderPaymentID(payment *entity.Payment) bool {
+	return payment.PaymentMethod == int32(types.PaymentMethod_PAYMENT_METHOD_PAYMENT_LINK) &&
+		payment.ProviderPaymentID != nil && strings.TrimSpace(*payment.ProviderPaymentID) != ""
+}
+
+func (s *PaymentService) persistDuplicateCallback(
+	ctx context.Context,
+	paymentID uint64,
+	req handleProviderCallbackRequest,
+	providerEventID string,
+) error {
+	now := time.Now().UTC()
+	return s.callbackRepo.Create(ctx, &entity.PaymentCallback{
+		PaymentID:         &paymentID,
+		Provider:          strings.ToLower(strings.TrimSpace(req.GetProvider())),
+		CallbackHash:      strings.TrimSpace(req.GetCallbackHash()),
+		ProviderEventID:   &providerEventID,
+		Signature:         strings.TrimSpace(req.GetSignature()),
+		PayloadJSON:       req.GetPayload(),
+		Status:            paymentCallbackStatusDuplicate,
+		SignatureVerified: true,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	})
 }
 
 func (s *PaymentService) persistRejectedCallback(
 	ctx context.Context,
 	paymentID *uint64,
 	req handleProviderCallbackRequest,
+	signatureVerified bool,
 	reason string,
-) {
+) error {
 	now := time.Now().UTC()
 	reason = strings.TrimSpace(reason)
 	if reason == "" {
 		reason = "callback rejected"
 	}
 	trimmedErr := truncate(reason, 1024)
-	_ = s.callbackRepo.Create(ctx, &entity.PaymentCallback{
-		PaymentID:    paymentID,
-		Provider:     strings.ToLower(strings.TrimSpace(req.GetProvider())),
-		CallbackHash: strings.TrimSpace(req.GetCallbackHash()),
-		Signature:    strings.TrimSpace(req.GetSignature()),
-		PayloadJSON:  req.GetPayload(),
-		Status:       paymentCallbackStatusRejected,
-		Error:        &trimmedErr,
-		CreatedAt:    now,
-		UpdatedAt:    now,
+	return s.callbackRepo.Create(ctx, &entity.PaymentCallback{
+		PaymentID:         paymentID,
+		Provider:          strings.ToLower(strings.TrimSpace(req.GetProvider())),
+		CallbackHash:      strings.TrimSpace(req.GetCallbackHash()),
+		Signature:         strings.TrimSpace(req.GetSignature()),
+		PayloadJSON:       req.GetPayload(),
+		Status:            paymentCallbackStatusRejected,
+		Error:             &trimmedErr,
+		SignatureVerified: signatureVerified,
+		CreatedAt:         now,
+		UpdatedAt:         now,
 	})
 }
 
+// persistFailedCallback keeps a webhook that could not be applied because of an
+// internal error, so it can be replayed from the inbox. It is only called after
+// the signature step. It is best effort: the database may well be what failed.
+func (s *PaymentService) persistFailedCallback(
+	ctx context.Context,
+	paymentID *uint64,
+	req handleProviderCallbackRequest,
+	cause error,
+) {
+	_ = s.persistRejectedCallback(ctx, paymentID, req, true, fmt.Sprintf("provider callback processing failed: %v", cause))
+}
+
+func parseOptionalTime(raw string) (*time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	value, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, err
+	}
+	value = value.UTC()
+	return &value, nil
+}
+
 func parseProviderCode(providerRaw string) (int32, error) {
 	switch strings.ToLower(strings.TrimSpace(providerRaw)) {
 	case "stripe", "1":
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/vibast-solutions/ms-go-payments/app/entity"
+	"github.com/vibast-solutions/ms-go-payments/app/provider"
+	"github.com/vibast-solutions/ms-go-payments/app/repository"
+	"github.com/vibast-solutions/ms-go-payments/app/types"
+)
+
+func (s *PaymentService) ListPaymentCharges(ctx context.Context, id uint64) ([]*entity.PaymentCharge, error) {
+	payment, err := s.findPaymentForCaller(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if payment.PaymentType != int32(types.PaymentType_PAYMENT_TYPE_RECURRING) {
+		return nil, fmt.Errorf("%w: payment is not recurring", ErrInvalidRequest)
+	}
+
+	return s.chargeRepo.ListByPaymentID(ctx, payment.ID)
+}
+
+// applyInvoiceCallback records a provider invoice as a charge of the parent
+// payment. It returns the charge when it has just reached a terminal status:
+// every such charge gets its own status callback, while the parent keeps
+// describing the subscription as a whole.
+func (s *PaymentService) applyInvoiceCallback(ctx context.Context, payment *entity.Payment, event *provider.CallbackEvent, now time.Time) (*entity.PaymentCharge, error) {
+	invoice := event.Invoice
+	if invoice == nil || strings.TrimSpace(invoice.ProviderInvoiceID) == "" {
+		return nil, nil
+	}
+
+	charge, err := s.chargeRepo.FindByProviderInvoiceID(ctx, payment.ID, invoice.ProviderInvoiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	currency := invoice.Currency
+	if currency == "" {
+		currency = payment.Currency
+	}
+
+	if charge == nil {
+		charge = &entity.PaymentCharge{
+			PaymentID:         payment.ID,
+			ProviderInvoiceID: invoice.ProviderInvoiceID,
+			AmountCents:       invoice.AmountCents,
+			Currency:          currency,
+			Status:            invoice.Status,
+			PeriodStart:       invoice.PeriodStart,
+			PeriodEnd:         invoice.PeriodEnd,
+			CreatedAt:         now,
+			UpdatedAt:         now,
+		}
+		if err := s.chargeRepo.Create(ctx, charge); err != nil {
+			if errors.Is(err, repository.ErrChargeAlreadyExists) {
+				return nil, nil
+			}
+			return nil, err
+		}
+	} else {
+		if charge.Status == invoice.Status {
+			return nil, nil
+		}
+		charge.Status = invoice.Status
+		if invoice.AmountCents > 0 {
+			charge.AmountCents = invoice.AmountCents
+		}
+		charge.Currency = currency
+		if invoice.PeriodStart != nil {
+			charge.PeriodStart = invoice.PeriodStart
+		}
+		if invoice.PeriodEnd != nil {
+			charge.PeriodEnd = invoice.PeriodEnd
+		}
+		charge.UpdatedAt = now
+		if err := s.chargeRepo.Update(ctx, charge); err != nil {
+			return nil, err
+		}
+	}
+
+	if !terminalStatus(charge.Status) {
+		return nil, nil
+	}
+	return charge, nil
+}
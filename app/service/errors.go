@@ -3,11 +3,19 @@ package service
 import "errors"
 
 var (
-	ErrInvalidRequest       = errors.New("invalid request")
-	ErrPaymentNotFound      = errors.New("payment not found")
-	ErrPaymentAlreadyExists = errors.New("payment already exists")
-	ErrInvalidStatus        = errors.New("invalid status")
-	ErrProviderUnsupported  = errors.New("provider is not supported")
-	ErrInvalidProvider      = errors.New("invalid provider")
-	ErrCallbackRejected     = errors.New("callback rejected")
+	ErrInvalidRequest        = errors.New("invalid request")
+	ErrPaymentNotFound       = errors.New("payment not found")
+	ErrPaymentAlreadyExists  = errors.New("payment already exists")
+	ErrInvalidStatus         = errors.New("invalid status")
+	ErrProviderUnsupported   = errors.New("provider is not supported")
+	ErrInvalidProvider       = errors.New("invalid provider")
+	ErrCallbackRejected      = errors.New("callback rejected")
+	ErrCallbackNotFound      = errors.New("callback not found")
+	ErrRefundExceedsAmount   = errors.New("refund amount exceeds refundable amount")
+	ErrProviderCancelFailed  = errors.New("provider cancellation failed")
+	ErrProviderRequestFailed = errors.New("provider request failed")
+	ErrInvalidTransition     = errors.New("invalid status transition")
+	ErrConcurrentUpdate      = errors.New("payment was modified concurrently")
+	ErrIdempotencyConflict   = errors.New("idempotency conflict")
+	ErrForbidden             = errors.New("forbidden")
 )
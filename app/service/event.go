@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/vibast-solutions/ms-go-payments/app/entity"
+	"github.com/vibast-solutions/ms-go-payments/app/repository"
+)
+
+type listPaymentEventsRequest interface {
+	GetId() uint64
+	GetEventType() string
+	GetCursor() uint64
+	GetLimit() int32
+	GetIncludePayload() bool
+}
+
+// ListPaymentEvents returns one page of the payment's event history, oldest
+// first, and the cursor of the next page (0 when this is the last one).
+func (s *PaymentService) ListPaymentEvents(ctx context.Context, req listPaymentEventsRequest) ([]*entity.PaymentEvent, uint64, error) {
+	payment, err := s.findPaymentForCaller(ctx, req.GetId())
+	if err != nil {
+		return nil, 0, err
+	}
+
+	limit := req.GetLimit()
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+
+	// One extra row tells whether another page follows.
+	events, err := s.eventRepo.ListByPayment(ctx, repository.PaymentEventFilter{
+		PaymentID:      payment.ID,
+		EventType:      strings.TrimSpace(req.GetEventType()),
+		AfterID:        req.GetCursor(),
+		Limit:          limit + 1,
+		IncludePayload: req.GetIncludePayload(),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var nextCursor uint64
+	if len(events) > int(limit) {
+		events = events[:limit]
+		nextCursor = events[len(events)-1].ID
+	}
+
+	return events, nextCursor, nil
+}
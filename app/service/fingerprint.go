@@ -0,0 +1,74 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/vibast-solutions/ms-go-payments/app/entity"
+)
+
+type fingerprintField struct {
+	name  string
+	value string
+}
+
+// fingerprintFields lists the CreatePayment fields that identify what is being
+// paid for. Reusing a request_id with any of them changed is a conflict.
+func fingerprintFields(payment *entity.Payment) []fingerprintField {
+	return []fingerprintField{
+		{name: "resource_type", value: payment.ResourceType},
+		{name: "resource_id", value: payment.ResourceID},
+		{name: "customer_ref", value: derefString(payment.CustomerRef)},
+		{name: "amount_cents", value: strconv.FormatInt(payment.AmountCents, 10)},
+		{name: "currency", value: payment.Currency},
+		{name: "payment_method", value: strconv.FormatInt(int64(payment.PaymentMethod), 10)},
+		{name: "payment_type", value: strconv.FormatInt(int64(payment.PaymentType), 10)},
+		{name: "provider", value: strconv.FormatInt(int64(payment.Provider), 10)},
+		{name: "recurring_interval", value: derefString(payment.RecurringInterval)},
+		{name: "recurring_interval_count", value: strconv.FormatInt(int64(derefInt32(payment.RecurringIntervalCount)), 10)},
+	}
+}
+
+// requestFingerprint hashes the significant fields of a normalized create
+// request.
+func requestFingerprint(payment *entity.Payment) string {
+	var b strings.Builder
+	for _, field := range fingerprintFields(payment) {
+		b.WriteString(field.name)
+		b.WriteByte('=')
+		b.WriteString(strconv.Quote(field.value))
+		b.WriteByte('\n')
+	}
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+// checkRequestFingerprint fails with ErrIdempotencyConflict, naming the fields
+// that differ, when a request_id is reused for a different payment. Payments
+// stored before fingerprints existed are not checked.
+func checkRequestFingerprint(existing, requested *entity.Payment) error {
+	if existing.RequestFingerprint == "" || existing.RequestFingerprint == requested.RequestFingerprint {
+		return nil
+	}
+
+	requestedFields := fingerprintFields(requested)
+	differing := make([]string, 0, len(requestedFields))
+	for i, field := range fingerprintFields(existing) {
+		if field.value != requestedFields[i].value {
+			differing = append(differing, field.name)
+		}
+	}
+	if len(differing) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf(
+		"%w: request_id %s was already used with different %s",
+		ErrIdempotencyConflict,
+		existing.RequestID,
+		strings.Join(differing, ", "),
+	)
+}
@@ -1,16 +1,14 @@
 package service
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
-	"fmt"
-	"net/http"
+	"errors"
 	"strings"
 	"time"
 
 	"github.com/vibast-solutions/ms-go-payments/app/entity"
-	"github.com/vibast-solutions/ms-go-payments/app/mapper"
+	"github.com/vibast-solutions/ms-go-payments/app/provider"
+	"github.com/vibast-solutions/ms-go-payments/app/repository"
 	"github.com/vibast-solutions/ms-go-payments/app/types"
 )
 
@@ -24,7 +22,16 @@ func (s *PaymentService) RunReconcileBatch(ctx context.Context) error {
 
 	var firstErr error
 	for _, payment := range items {
-		if payment == nil || payment.ProviderPaymentID == nil || strings.TrimSpace(*payment.ProviderPaymentID) == "" {
+		if payment == nil {
+			continue
+		}
+		input := &provider.StatusInput{
+			ProviderPaymentID:      strings.TrimSpace(derefString(payment.ProviderPaymentID)),
+			ProviderSubscriptionID: strings.TrimSpace(derefString(payment.ProviderSubscriptionID)),
+			PaymentMethod:          payment.PaymentMethod,
+			PaymentType:            payment.PaymentType,
+		}
+		if input.ProviderPaymentID == "" && input.ProviderSubscriptionID == "" {
 			continue
 		}
 
@@ -34,52 +41,71 @@ func (s *PaymentService) RunReconcileBatch(ctx context.Context) error {
 			continue
 		}
 
-		newStatus, err := providerClient.GetPaymentStatus(ctx, strings.TrimSpace(*payment.ProviderPaymentID))
+		newStatus, err := providerClient.GetPaymentStatus(ctx, input)
 		if err != nil {
 			firstErr = keepFirstErr(firstErr, err)
 			continue
 		}
-		if newStatus == 0 || newStatus == payment.Status {
+		if newStatus == 0 {
 			continue
 		}
 
 		oldStatus := payment.Status
-		payment.Status = newStatus
-		if terminalStatus(newStatus) {
-			s.markForCallbackDelivery(payment, now)
+		if changed, err := transitionStatus(payment, newStatus); err != nil || !changed {
+			continue
 		}
 		payment.UpdatedAt = now
 
-		if err := s.paymentRepo.Update(ctx, payment); err != nil {
+		err = s.uow.Do(ctx, func(ctx context.Context) error {
+			if err := s.paymentRepo.Update(ctx, payment); err != nil {
+				return err
+			}
+			err := s.eventRepo.Create(ctx, &entity.PaymentEvent{
+				PaymentID: payment.ID,
+				EventType: "payment_reconciled",
+				OldStatus: &oldStatus,
+				NewStatus: newStatus,
+				CreatedAt: now,
+			})
+			if err != nil {
+				return err
+			}
+			return s.queueStatusCallback(ctx, payment, oldStatus, now)
+		})
+		if err != nil && !errors.Is(err, repository.ErrPaymentVersionConflict) {
 			firstErr = keepFirstErr(firstErr, err)
-			continue
 		}
-
-		_ = s.eventRepo.Create(ctx, &entity.PaymentEvent{
-			PaymentID: payment.ID,
-			EventType: "payment_reconciled",
-			OldStatus: &oldStatus,
-			NewStatus: newStatus,
-			CreatedAt: now,
-		})
 	}
 
 	return firstErr
 }
 
+// RunDispatchCallbacksBatch drains due callbacks from the outbox. A callback
+// that is waiting for a retry or dead-lettered holds back the later callbacks
+// of its payment. Each callback is claimed before delivery so that concurrent
+// dispatchers don't deliver it twice.
 func (s *PaymentService) RunDispatchCallbacksBatch(ctx context.Context) error {
 	now := time.Now().UTC()
-	items, err := s.paymentRepo.ListDueCallbackDispatch(ctx, now, s.batchSize())
+	items, err := s.outboxRepo.ListDue(ctx, now, s.batchSize())
 	if err != nil {
 		return err
 	}
 
 	var firstErr error
-	for _, payment := range items {
-		if payment == nil {
+	for _, item := range items {
+		if item == nil {
+			continue
+		}
+		claimedAt := time.Now().UTC()
+		claimed, err := s.outboxRepo.Claim(ctx, item, claimedAt, claimedAt.Add(s.callbackClaimLease()))
+		if err != nil {
+			firstErr = keepFirstErr(firstErr, err)
+			continue
+		}
+		if !claimed {
 			continue
 		}
-		if err := s.dispatchCallback(ctx, payment, now); err != nil {
+		if err := s.dispatchCallback(ctx, item, claimedAt); err != nil {
 			firstErr = keepFirstErr(firstErr, err)
 		}
 	}
@@ -100,123 +126,93 @@ func (s *PaymentService) RunExpirePendingBatch(ctx context.Context) error {
 		if payment == nil {
 			continue
 		}
-		if payment.Status == int32(types.PaymentStatus_PAYMENT_STATUS_EXPIRED) {
-			continue
-		}
 
 		oldStatus := payment.Status
-		payment.Status = int32(types.PaymentStatus_PAYMENT_STATUS_EXPIRED)
-		s.markForCallbackDelivery(payment, now)
-		payment.UpdatedAt = now
-
-		if err := s.paymentRepo.Update(ctx, payment); err != nil {
-			firstErr = keepFirstErr(firstErr, err)
+		if changed, err := transitionStatus(payment, int32(types.PaymentStatus_PAYMENT_STATUS_EXPIRED)); err != nil || !changed {
 			continue
 		}
+		payment.UpdatedAt = now
 
-		_ = s.eventRepo.Create(ctx, &entity.PaymentEvent{
-			PaymentID: payment.ID,
-			EventType: "payment_expired",
-			OldStatus: &oldStatus,
-			NewStatus: payment.Status,
-			CreatedAt: now,
+		// A conflict means a webhook or another worker updated the payment
+		// after it was selected; its status wins over the expiry.
+		err = s.uow.Do(ctx, func(ctx context.Context) error {
+			if err := s.paymentRepo.Update(ctx, payment); err != nil {
+				return err
+			}
+			err := s.eventRepo.Create(ctx, &entity.PaymentEvent{
+				PaymentID: payment.ID,
+				EventType: "payment_expired",
+				OldStatus: &oldStatus,
+				NewStatus: payment.Status,
+				CreatedAt: now,
+			})
+			if err != nil {
+				return err
+			}
+			return s.queueStatusCallback(ctx, payment, oldStatus, now)
 		})
+		if err != nil && !errors.Is(err, repository.ErrPaymentVersionConflict) {
+			firstErr = keepFirstErr(firstErr, err)
+		}
 	}
 
 	return firstErr
 }
 
-func (s *PaymentService) dispatchCallback(ctx context.Context, payment *entity.Payment, now time.Time) error {
-	if strings.TrimSpace(payment.StatusCallbackURL) == "" {
-		errMsg := "status_callback_url is empty"
-		payment.CallbackDeliveryStatus = entity.CallbackDeliveryFailed
-		payment.CallbackDeliveryNextAt = nil
-		payment.CallbackDeliveryLastErr = &errMsg
-		payment.UpdatedAt = now
-		return s.paymentRepo.Update(ctx, payment)
-	}
-
-	payload := &types.PaymentEnvelopeResponse{Payment: mapper.PaymentToProto(payment)}
-	body, err := json.Marshal(payload)
-	if err != nil {
-		return err
-	}
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, payment.StatusCallbackURL, bytes.NewReader(body))
-	if err != nil {
-		return s.recordDispatchFailure(ctx, payment, now, err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-Request-ID", payment.RequestID)
-	if s.appAPIKey != "" {
-		req.Header.Set("X-API-Key", s.appAPIKey)
-	}
-
-	resp, err := s.callbackHTTP.Do(req)
+// RunSweepProvisionalBatch finishes creates that were interrupted between
+// storing the payment and recording the provider result. Recent ones are
+// resumed, and failed if the provider rejects them; ones older than
+// ProvisionalAbandonAfter are marked failed.
+func (s *PaymentService) RunSweepProvisionalBatch(ctx context.Context) error {
+	now := time.Now().UTC()
+	cutoff := now.Add(-s.paymentsCfg.ProvisionalStaleAfter)
+	abandonBefore := now.Add(-s.paymentsCfg.ProvisionalAbandonAfter)
+	items, err := s.paymentRepo.ListProvisional(ctx, cutoff, s.batchSize())
 	if err != nil {
-		return s.recordDispatchFailure(ctx, payment, now, err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return s.recordDispatchFailure(ctx, payment, now, fmt.Errorf("callback endpoint returned status=%d", resp.StatusCode))
-	}
-
-	payment.CallbackDeliveryStatus = entity.CallbackDeliverySuccess
-	payment.CallbackDeliveryNextAt = nil
-	payment.CallbackDeliveryLastErr = nil
-	payment.UpdatedAt = now
-
-	if err := s.paymentRepo.Update(ctx, payment); err != nil {
 		return err
 	}
 
-	_ = s.eventRepo.Create(ctx, &entity.PaymentEvent{
-		PaymentID: payment.ID,
-		EventType: "callback_dispatched",
-		NewStatus: payment.Status,
-		CreatedAt: now,
-	})
-
-	return nil
-}
-
-func (s *PaymentService) recordDispatchFailure(ctx context.Context, payment *entity.Payment, now time.Time, dispatchErr error) error {
-	payment.CallbackDeliveryAttempts++
-	trimmed := truncate(dispatchErr.Error(), 1024)
-	payment.CallbackDeliveryLastErr = &trimmed
-
-	maxAttempts := s.paymentsCfg.CallbackMaxAttempts
-	if maxAttempts <= 0 {
-		maxAttempts = 1
-	}
+	var firstErr error
+	for _, payment := range items {
+		if payment == nil {
+			continue
+		}
+		if payment.CreatedAt.After(abandonBefore) {
+			_, err := s.completeProvisionalPayment(ctx, payment, true)
+			if err != nil && !errors.Is(err, provider.ErrRequestRejected) {
+				firstErr = keepFirstErr(firstErr, err)
+			}
+			continue
+		}
 
-	if payment.CallbackDeliveryAttempts >= maxAttempts {
-		payment.CallbackDeliveryStatus = entity.CallbackDeliveryFailed
-		payment.CallbackDeliveryNextAt = nil
-	} else {
-		retryInterval := s.paymentsCfg.CallbackRetryInterval
-		if retryInterval <= 0 {
-			retryInterval = 5 * time.Minute
-		}
-		next := now.Add(retryInterval)
-		payment.CallbackDeliveryStatus = entity.CallbackDeliveryPending
-		payment.CallbackDeliveryNextAt = &next
-	}
-	payment.UpdatedAt = now
+		oldStatus := payment.Status
+		if changed, err := transitionStatus(payment, int32(types.PaymentStatus_PAYMENT_STATUS_FAILED)); err != nil || !changed {
+			continue
+		}
+		payment.UpdatedAt = now
 
-	if err := s.paymentRepo.Update(ctx, payment); err != nil {
-		return err
+		err = s.uow.Do(ctx, func(ctx context.Context) error {
+			if err := s.paymentRepo.Update(ctx, payment); err != nil {
+				return err
+			}
+			err := s.eventRepo.Create(ctx, &entity.PaymentEvent{
+				PaymentID: payment.ID,
+				EventType: "payment_create_abandoned",
+				OldStatus: &oldStatus,
+				NewStatus: payment.Status,
+				CreatedAt: now,
+			})
+			if err != nil {
+				return err
+			}
+			return s.queueStatusCallback(ctx, payment, oldStatus, now)
+		})
+		if err != nil && !errors.Is(err, repository.ErrPaymentVersionConflict) {
+			firstErr = keepFirstErr(firstErr, err)
+		}
 	}
 
-	_ = s.eventRepo.Create(ctx, &entity.PaymentEvent{
-		PaymentID: payment.ID,
-		EventType: "callback_dispatch_failed",
-		NewStatus: payment.Status,
-		CreatedAt: now,
-	})
-
-	return dispatchErr
+	return firstErr
 }
 
 func keepFirstErr(current error, candidate error) error {
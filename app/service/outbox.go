@@ -0,0 +1,282 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/vibast-solutions/ms-go-payments/app/callbacksig"
+	"github.com/vibast-solutions/ms-go-payments/app/entity"
+	"github.com/vibast-solutions/ms-go-payments/app/mapper"
+	"github.com/vibast-solutions/ms-go-payments/app/repository"
+	"github.com/vibast-solutions/ms-go-payments/app/sink"
+	"github.com/vibast-solutions/ms-go-payments/app/types"
+)
+
+const (
+	callbackEventPaymentStatus = "payment.status_changed"
+	callbackEventPaymentRefund = "payment.refund_updated"
+	callbackEventChargeStatus  = "charge.status_changed"
+)
+
+type listDeadLetterCallbacksRequest interface {
+	GetPaymentId() uint64
+	GetCallerService() string
+	GetEventType() string
+	GetLimit() int32
+	GetOffset() int32
+}
+
+type redeliverCallbacksRequest interface {
+	GetPaymentId() uint64
+	GetCallerService() string
+	GetEventType() string
+	GetAll() bool
+}
+
+// ListDeadLetterCallbacks returns callbacks that exhausted their retry budget.
+func (s *PaymentService) ListDeadLetterCallbacks(ctx context.Context, req listDeadLetterCallbacksRequest) ([]*entity.PaymentCallbackOutbox, error) {
+	limit := req.GetLimit()
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	callerService, err := s.scopeCallerService(ctx, strings.TrimSpace(req.GetCallerService()))
+	if err != nil {
+		return nil, err
+	}
+
+	return s.outboxRepo.ListDeadLetters(ctx, repository.CallbackOutboxFilter{
+		PaymentID:     req.GetPaymentId(),
+		CallerService: callerService,
+		EventType:     strings.TrimSpace(req.GetEventType()),
+		Limit:         limit,
+		Offset:        req.GetOffset(),
+	})
+}
+
+// RedeliverCallbacks resets dead-lettered callbacks to pending with a fresh
+// retry budget. Without a filter it only runs when all is set, so an empty
+// request cannot requeue every dead letter by accident.
+func (s *PaymentService) RedeliverCallbacks(ctx context.Context, req redeliverCallbacksRequest) (int64, error) {
+	filter := repository.CallbackOutboxFilter{
+		PaymentID:     req.GetPaymentId(),
+		CallerService: strings.TrimSpace(req.GetCallerService()),
+		EventType:     strings.TrimSpace(req.GetEventType()),
+	}
+	hasFilter := filter.PaymentID > 0 || filter.CallerService != "" || filter.EventType != ""
+	if hasFilter == req.GetAll() {
+		return 0, fmt.Errorf("%w: set either a filter or all", ErrInvalidRequest)
+	}
+	callerService, err := s.scopeCallerService(ctx, filter.CallerService)
+	if err != nil {
+		return 0, err
+	}
+	filter.CallerService = callerService
+
+	return s.outboxRepo.RequeueDeadLetters(ctx, filter, time.Now().UTC())
+}
+
+// queueStatusCallback queues the payment status callback when a change moved
+// the payment into a terminal status.
+func (s *PaymentService) queueStatusCallback(ctx context.Context, payment *entity.Payment, oldStatus int32, now time.Time) error {
+	if oldStatus == payment.Status || !terminalStatus(payment.Status) {
+		return nil
+	}
+	return s.queueCallback(ctx, payment, nil, callbackEventPaymentStatus, now)
+}
+
+// queueCallback writes a callback to the outbox. It must run in the same unit
+// of work as the change it announces; the body is snapshotted here so later
+// changes cannot alter what this callback reports.
+func (s *PaymentService) queueCallback(ctx context.Context, payment *entity.Payment, charge *entity.PaymentCharge, eventType string, now time.Time) error {
+	requestID := payment.RequestID
+	var chargeID *uint64
+	var body []byte
+	var err error
+	if charge != nil {
+		id := charge.ID
+		chargeID = &id
+		requestID = payment.RequestID + ":" + charge.ProviderInvoiceID
+		body, err = json.Marshal(&types.ChargeEnvelopeResponse{
+			Payment: mapper.PaymentToProto(payment),
+			Charge:  mapper.ChargeToProto(charge),
+		})
+	} else {
+		body, err = json.Marshal(&types.PaymentEnvelopeResponse{Payment: mapper.PaymentToProto(payment)})
+	}
+	if err != nil {
+		return err
+	}
+
+	return s.outboxRepo.Create(ctx, &entity.PaymentCallbackOutbox{
+		PaymentID:     payment.ID,
+		ChargeID:      chargeID,
+		CallerService: payment.CallerService,
+		EventType:     eventType,
+		RequestID:     requestID,
+		Destination:   strings.TrimSpace(payment.StatusCallbackURL),
+		PayloadJSON:   string(body),
+		Status:        entity.CallbackDeliveryPending,
+		NextAttemptAt: &now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	})
+}
+
+// dispatchCallback delivers one claimed callback. now stamps the outbox row;
+// the signature takes its own timestamp so that it is never older than the
+// delivery itself, however long the batch has been running.
+func (s *PaymentService) dispatchCallback(ctx context.Context, item *entity.PaymentCallbackOutbox, now time.Time) error {
+	if item.Destination == "" {
+		errMsg := "status_callback_url is empty"
+		item.Status = entity.CallbackDeliveryFailed
+		item.NextAttemptAt = nil
+		item.LastError = &errMsg
+		item.UpdatedAt = now
+		return s.outboxRepo.Update(ctx, item)
+	}
+
+	body := []byte(item.PayloadJSON)
+	err := s.sinks.Deliver(ctx, &sink.Message{
+		ID:          item.ID,
+		PaymentID:   item.PaymentID,
+		EventType:   item.EventType,
+		RequestID:   item.RequestID,
+		Destination: item.Destination,
+		Body:        body,
+		Signature:   s.signCallback(item.CallerService, body, time.Now().UTC()),
+	})
+	if err != nil {
+		return s.recordDispatchFailure(ctx, item, now, err)
+	}
+
+	item.Status = entity.CallbackDeliverySuccess
+	item.NextAttemptAt = nil
+	item.LastError = nil
+	item.DeliveredAt = &now
+	item.UpdatedAt = now
+
+	return s.uow.Do(ctx, func(ctx context.Context) error {
+		if err := s.outboxRepo.Update(ctx, item); err != nil {
+			return err
+		}
+		return s.createDispatchEvent(ctx, item, "callback_dispatched", now)
+	})
+}
+
+func (s *PaymentService) recordDispatchFailure(ctx context.Context, item *entity.PaymentCallbackOutbox, now time.Time, dispatchErr error) error {
+	item.Attempts++
+	trimmed := truncate(dispatchErr.Error(), 1024)
+	item.LastError = &trimmed
+
+	maxAttempts := s.paymentsCfg.CallbackMaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 1
+	}
+
+	if item.Attempts >= maxAttempts {
+		// Dead-lettered: only RedeliverCallbacks moves it back to pending.
+		item.Status = entity.CallbackDeliveryFailed
+		item.NextAttemptAt = nil
+	} else {
+		next := now.Add(s.callbackRetryDelay(item.Attempts))
+		item.Status = entity.CallbackDeliveryPending
+		item.NextAttemptAt = &next
+	}
+	item.UpdatedAt = now
+
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		if err := s.outboxRepo.Update(ctx, item); err != nil {
+			return err
+		}
+		return s.createDispatchEvent(ctx, item, "callback_dispatch_failed", now)
+	})
+	if err != nil {
+		return err
+	}
+
+	return dispatchErr
+}
+
+// callbackRetryDelay doubles the base interval with every failed attempt up to
+// the configured cap, then spreads retries by up to the jitter percentage so
+// callbacks that failed together do not all retry at the same instant.
+func (s *PaymentService) callbackRetryDelay(attempts int32) time.Duration {
+	base := s.paymentsCfg.CallbackRetryInterval
+	if base <= 0 {
+		base = 5 * time.Minute
+	}
+	maxDelay := s.paymentsCfg.CallbackRetryMaxInterval
+	if maxDelay < base {
+		maxDelay = base
+	}
+
+	delay := base
+	for i := int32(1); i < attempts && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+
+	jitterPercent := s.paymentsCfg.CallbackRetryJitterPercent
+	if jitterPercent > 0 && s.jitter != nil {
+		if jitterPercent > 100 {
+			jitterPercent = 100
+		}
+		spread := float64(delay) * float64(jitterPercent) / 100
+		delay = time.Duration(float64(delay) - spread + 2*spread*s.jitter())
+	}
+
+	return delay
+}
+
+// callbackClaimLease is how long a claimed callback is kept from other
+// dispatchers. It outlasts one delivery attempt so that only a dispatcher that
+// died mid-delivery loses its claim: every sink bounds a delivery by
+// CallbackHTTPTimeout.
+func (s *PaymentService) callbackClaimLease() time.Duration {
+	return s.paymentsCfg.CallbackHTTPTimeout + time.Minute
+}
+
+func (s *PaymentService) createDispatchEvent(ctx context.Context, item *entity.PaymentCallbackOutbox, eventType string, now time.Time) error {
+	payment, err := s.paymentRepo.FindByID(ctx, item.PaymentID)
+	if err != nil {
+		return err
+	}
+	if payment == nil {
+		return nil
+	}
+
+	payload := map[string]interface{}{
+		"callback_id": item.ID,
+		"event_type":  item.EventType,
+		"attempts":    item.Attempts,
+	}
+	if item.LastError != nil {
+		payload["error"] = *item.LastError
+	}
+	payloadJSON, _ := json.Marshal(payload)
+	encoded := string(payloadJSON)
+
+	return s.eventRepo.Create(ctx, &entity.PaymentEvent{
+		PaymentID:   payment.ID,
+		EventType:   eventType,
+		NewStatus:   payment.Status,
+		PayloadJSON: &encoded,
+		CreatedAt:   now,
+	})
+}
+
+// signCallback signs the body with every active secret of the caller service,
+// so receivers can verify with either secret while one is being rotated. The
+// timestamp is taken per attempt, which lets receivers reject replays.
+func (s *PaymentService) signCallback(callerService string, body []byte, now time.Time) string {
+	secrets := s.paymentsCfg.CallbackSigningSecrets[callerService]
+	if len(secrets) == 0 {
+		return ""
+	}
+	return callbacksig.Sign(body, now, secrets...)
+}
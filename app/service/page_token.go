@@ -0,0 +1,36 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// paymentPageToken is the keyset position of a ListPayments page. It is
+// handed to callers base64-encoded so they treat it as opaque.
+type paymentPageToken struct {
+	BeforeID uint64 `json:"before_id"`
+}
+
+func encodePaymentPageToken(beforeID uint64) string {
+	data, _ := json.Marshal(paymentPageToken{BeforeID: beforeID})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodePaymentPageToken(raw string) (uint64, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return 0, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid page_token", ErrInvalidRequest)
+	}
+	var token paymentPageToken
+	if err := json.Unmarshal(data, &token); err != nil || token.BeforeID == 0 {
+		return 0, fmt.Errorf("%w: invalid page_token", ErrInvalidRequest)
+	}
+	return token.BeforeID, nil
+}
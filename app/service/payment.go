@@ -2,9 +2,10 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
-	"net/http"
+	"math/rand/v2"
 	"strings"
 	"time"
 
@@ -12,6 +13,7 @@ import (
 	"github.com/vibast-solutions/ms-go-payments/app/entity"
 	"github.com/vibast-solutions/ms-go-payments/app/provider"
 	"github.com/vibast-solutions/ms-go-payments/app/repository"
+	"github.com/vibast-solutions/ms-go-payments/app/sink"
 	"github.com/vibast-solutions/ms-go-payments/app/types"
 	"github.com/vibast-solutions/ms-go-payments/config"
 )
@@ -40,6 +42,17 @@ type createPaymentRequest interface {
 	GetMetadata() map[string]string
 }
 
+type getPaymentByRequestIDRequest interface {
+	GetCallerService() string
+	GetRequestId() string
+}
+
+type getPaymentByProviderRefRequest interface {
+	GetProvider() types.ProviderType
+	GetProviderPaymentId() string
+	GetProviderSubscriptionId() string
+}
+
 type listPaymentsRequest interface {
 	GetRequestId() string
 	GetCallerService() string
@@ -50,6 +63,19 @@ type listPaymentsRequest interface {
 	GetProvider() types.ProviderType
 	GetLimit() int32
 	GetOffset() int32
+	GetPageToken() string
+	GetCreatedFrom() string
+	GetCreatedTo() string
+	GetUpdatedFrom() string
+	GetUpdatedTo() string
+	GetMinAmountCents() int64
+	GetMaxAmountCents() int64
+	GetCurrency() string
+	GetCustomerRef() string
+	GetStatuses() []types.PaymentStatus
+	GetProviderPaymentId() string
+	GetProviderSubscriptionId() string
+	GetMetadata() map[string]string
 }
 
 type cancelPaymentRequest interface {
@@ -63,67 +89,128 @@ type paymentRepository interface {
 	FindByID(ctx context.Context, id uint64) (*entity.Payment, error)
 	FindByCallerRequestID(ctx context.Context, callerService, requestID string) (*entity.Payment, error)
 	FindByCallbackHash(ctx context.Context, provider int32, callbackHash string) (*entity.Payment, error)
+	FindByProviderSubscriptionID(ctx context.Context, provider int32, providerSubscriptionID string) (*entity.Payment, error)
+	FindByProviderPaymentID(ctx context.Context, provider int32, providerPaymentID string) (*entity.Payment, error)
 	List(ctx context.Context, filter repository.PaymentFilter) ([]*entity.Payment, error)
-	ListDueCallbackDispatch(ctx context.Context, now time.Time, limit int32) ([]*entity.Payment, error)
 	ListExpiredPending(ctx context.Context, cutoff time.Time, limit int32) ([]*entity.Payment, error)
 	ListForReconcile(ctx context.Context, before time.Time, limit int32) ([]*entity.Payment, error)
+	ListProvisional(ctx context.Context, cutoff time.Time, limit int32) ([]*entity.Payment, error)
 }
 
 type paymentEventRepository interface {
 	Create(ctx context.Context, event *entity.PaymentEvent) error
+	ListByPayment(ctx context.Context, filter repository.PaymentEventFilter) ([]*entity.PaymentEvent, error)
 }
 
 type paymentCallbackRepository interface {
 	Create(ctx context.Context, callback *entity.PaymentCallback) error
+	FindByID(ctx context.Context, id uint64) (*entity.PaymentCallback, error)
+	List(ctx context.Context, filter repository.PaymentCallbackFilter) ([]*entity.PaymentCallback, error)
+}
+
+type paymentRefundRepository interface {
+	Create(ctx context.Context, refund *entity.PaymentRefund) error
+	Update(ctx context.Context, refund *entity.PaymentRefund) error
+	FindByPaymentRequestID(ctx context.Context, paymentID uint64, requestID string) (*entity.PaymentRefund, error)
+	FindByProviderRefundID(ctx context.Context, paymentID uint64, providerRefundID string) (*entity.PaymentRefund, error)
+	ListByPaymentID(ctx context.Context, paymentID uint64) ([]*entity.PaymentRefund, error)
+}
+
+type paymentChargeRepository interface {
+	Create(ctx context.Context, charge *entity.PaymentCharge) error
+	Update(ctx context.Context, charge *entity.PaymentCharge) error
+	FindByProviderInvoiceID(ctx context.Context, paymentID uint64, providerInvoiceID string) (*entity.PaymentCharge, error)
+	ListByPaymentID(ctx context.Context, paymentID uint64) ([]*entity.PaymentCharge, error)
+}
+
+// unitOfWork runs fn in a transaction; repository calls made with the context
+// passed to fn are committed or rolled back together.
+type unitOfWork interface {
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type paymentCallbackOutboxRepository interface {
+	Create(ctx context.Context, item *entity.PaymentCallbackOutbox) error
+	Update(ctx context.Context, item *entity.PaymentCallbackOutbox) error
+	ListDue(ctx context.Context, now time.Time, limit int32) ([]*entity.PaymentCallbackOutbox, error)
+	Claim(ctx context.Context, item *entity.PaymentCallbackOutbox, now, until time.Time) (bool, error)
+	ListDeadLetters(ctx context.Context, filter repository.CallbackOutboxFilter) ([]*entity.PaymentCallbackOutbox, error)
+	RequeueDeadLetters(ctx context.Context, filter repository.CallbackOutboxFilter, now time.Time) (int64, error)
+}
+
+type paymentProviderEventRepository interface {
+	Create(ctx context.Context, event *entity.PaymentProviderEvent) error
+	FindByProviderEventID(ctx context.Context, provider int32, providerEventID string) (*entity.PaymentProviderEvent, error)
 }
 
 type PaymentService struct {
-	paymentRepo  paymentRepository
-	eventRepo    paymentEventRepository
-	callbackRepo paymentCallbackRepository
-	providerReg  *provider.Registry
-	paymentsCfg  config.PaymentsConfig
-	appAPIKey    string
-	callbackHTTP *http.Client
+	paymentRepo       paymentRepository
+	eventRepo         paymentEventRepository
+	callbackRepo      paymentCallbackRepository
+	refundRepo        paymentRefundRepository
+	chargeRepo        paymentChargeRepository
+	providerEventRepo paymentProviderEventRepository
+	outboxRepo        paymentCallbackOutboxRepository
+	uow               unitOfWork
+	providerReg       *provider.Registry
+	sinks             *sink.Registry
+	paymentsCfg       config.PaymentsConfig
+	adminServices     map[string]bool
+	// jitter returns a value in [0, 1); tests replace it to get fixed delays.
+	jitter func() float64
 }
 
 func NewPaymentService(
 	paymentRepo paymentRepository,
 	eventRepo paymentEventRepository,
 	callbackRepo paymentCallbackRepository,
+	refundRepo paymentRefundRepository,
+	chargeRepo paymentChargeRepository,
+	providerEventRepo paymentProviderEventRepository,
+	outboxRepo paymentCallbackOutboxRepository,
+	uow unitOfWork,
 	providerReg *provider.Registry,
+	sinks *sink.Registry,
 	paymentsCfg config.PaymentsConfig,
-	appAPIKey string,
 ) *PaymentService {
-	timeout := paymentsCfg.CallbackHTTPTimeout
-	if timeout <= 0 {
-		timeout = 10 * time.Second
+	adminServices := make(map[string]bool, len(paymentsCfg.AdminServices))
+	for _, name := range paymentsCfg.AdminServices {
+		adminServices[name] = true
 	}
 
 	return &PaymentService{
-		paymentRepo:  paymentRepo,
-		eventRepo:    eventRepo,
-		callbackRepo: callbackRepo,
-		providerReg:  providerReg,
-		paymentsCfg:  paymentsCfg,
-		appAPIKey:    strings.TrimSpace(appAPIKey),
-		callbackHTTP: &http.Client{Timeout: timeout},
+		paymentRepo:       paymentRepo,
+		eventRepo:         eventRepo,
+		callbackRepo:      callbackRepo,
+		refundRepo:        refundRepo,
+		chargeRepo:        chargeRepo,
+		providerEventRepo: providerEventRepo,
+		outboxRepo:        outboxRepo,
+		uow:               uow,
+		providerReg:       providerReg,
+		sinks:             sinks,
+		paymentsCfg:       paymentsCfg,
+		adminServices:     adminServices,
+		jitter:            rand.Float64,
 	}
 }
 
+// CreatePayment stores the payment as provisional (CREATED, no provider ID)
+// before calling the provider, so a crash or a failed write after the provider
+// call never leaves a provider payment we do not know about. A retry with the
+// same request_id, or the provisional sweeper, resumes the create with the
+// stored callback hash and the same idempotency key.
 func (s *PaymentService) CreatePayment(ctx context.Context, req createPaymentRequest) (*entity.Payment, error) {
 	requestID := strings.TrimSpace(req.GetRequestId())
 	callerService := strings.TrimSpace(req.GetCallerService())
 	if requestID == "" || callerService == "" {
 		return nil, ErrInvalidRequest
 	}
-
-	existing, err := s.paymentRepo.FindByCallerRequestID(ctx, callerService, requestID)
-	if err != nil {
+	if _, err := s.scopeCallerService(ctx, callerService); err != nil {
 		return nil, err
 	}
-	if existing != nil {
-		return existing, nil
+	if err := s.sinks.Validate(req.GetStatusCallbackUrl()); err != nil {
+		return nil, fmt.Errorf("%w: status_callback_url: %v", ErrInvalidRequest, err)
 	}
 
 	providerCode := req.GetProvider()
@@ -131,162 +218,420 @@ func (s *PaymentService) CreatePayment(ctx context.Context, req createPaymentReq
 		providerCode = types.ProviderType_PROVIDER_TYPE_STRIPE
 	}
 
-	providerClient, err := s.providerReg.Get(int32(providerCode))
-	if err != nil {
-		if errors.Is(err, provider.ErrProviderNotSupported) {
-			return nil, ErrProviderUnsupported
-		}
-		return nil, err
-	}
-
-	callbackHash := uuid.NewString()
-	customerRef := normalizeOptionalString(req.GetCustomerRef())
-	metadata := cloneMetadata(req.GetMetadata())
-
-	providerOutput, err := providerClient.CreatePayment(ctx, &provider.CreateInput{
-		RequestID:              requestID,
-		CallbackHash:           callbackHash,
-		ResourceType:           strings.TrimSpace(req.GetResourceType()),
-		ResourceID:             strings.TrimSpace(req.GetResourceId()),
-		AmountCents:            req.GetAmountCents(),
-		Currency:               strings.ToUpper(strings.TrimSpace(req.GetCurrency())),
-		PaymentMethod:          int32(req.GetPaymentMethod()),
-		PaymentType:            int32(req.GetPaymentType()),
-		RecurringInterval:      strings.ToLower(strings.TrimSpace(req.GetRecurringInterval())),
-		RecurringIntervalCount: req.GetRecurringIntervalCount(),
-		CustomerRef:            customerRef,
-		Metadata:               metadata,
-		SuccessURL:             strings.TrimSpace(req.GetSuccessUrl()),
-		CancelURL:              strings.TrimSpace(req.GetCancelUrl()),
-	})
-	if err != nil {
-		return nil, err
-	}
-
 	now := time.Now().UTC()
 	payment := &entity.Payment{
 		RequestID:              requestID,
 		CallerService:          callerService,
 		ResourceType:           strings.TrimSpace(req.GetResourceType()),
 		ResourceID:             strings.TrimSpace(req.GetResourceId()),
-		CustomerRef:            customerRef,
+		CustomerRef:            normalizeOptionalString(req.GetCustomerRef()),
 		AmountCents:            req.GetAmountCents(),
 		Currency:               strings.ToUpper(strings.TrimSpace(req.GetCurrency())),
-		Status:                 providerOutput.InitialStatus,
+		Status:                 int32(types.PaymentStatus_PAYMENT_STATUS_CREATED),
 		PaymentMethod:          int32(req.GetPaymentMethod()),
 		PaymentType:            int32(req.GetPaymentType()),
 		Provider:               int32(providerCode),
 		RecurringInterval:      normalizeOptionalString(strings.ToLower(strings.TrimSpace(req.GetRecurringInterval()))),
 		RecurringIntervalCount: normalizeOptionalInt32(req.GetRecurringIntervalCount()),
-		ProviderPaymentID:      providerOutput.ProviderPaymentID,
-		ProviderSubscriptionID: providerOutput.ProviderSubscriptionID,
-		CheckoutURL:            providerOutput.CheckoutURL,
-		ProviderCallbackHash:   callbackHash,
-		ProviderCallbackURL:    providerOutput.ProviderCallbackURL,
+		ProviderCallbackHash:   uuid.NewString(),
 		StatusCallbackURL:      strings.TrimSpace(req.GetStatusCallbackUrl()),
+		SuccessURL:             strings.TrimSpace(req.GetSuccessUrl()),
+		CancelURL:              strings.TrimSpace(req.GetCancelUrl()),
 		RefundedCents:          0,
 		RefundableCents:        req.GetAmountCents(),
-		Metadata:               metadata,
-		CallbackDeliveryStatus: entity.CallbackDeliveryNone,
+		Metadata:               cloneMetadata(req.GetMetadata()),
 		CreatedAt:              now,
 		UpdatedAt:              now,
 	}
+	payment.RequestFingerprint = requestFingerprint(payment)
 
-	if terminalStatus(payment.Status) {
-		s.markForCallbackDelivery(payment, now)
+	existing, err := s.paymentRepo.FindByCallerRequestID(ctx, callerService, requestID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return s.continueExistingPayment(ctx, existing, payment)
+	}
+
+	if _, err := s.providerReg.Get(int32(providerCode)); err != nil {
+		if errors.Is(err, provider.ErrProviderNotSupported) {
+			return nil, ErrProviderUnsupported
+		}
+		return nil, err
 	}
 
 	if err := s.paymentRepo.Create(ctx, payment); err != nil {
-		if errors.Is(err, repository.ErrPaymentAlreadyExists) {
+		if !errors.Is(err, repository.ErrPaymentAlreadyExists) {
+			return nil, err
+		}
+		// A concurrent request with the same request_id won the insert; the
+		// unique index leaves a single row, which this request continues with.
+		existing, err = s.paymentRepo.FindByCallerRequestID(ctx, callerService, requestID)
+		if err != nil {
+			return nil, err
+		}
+		if existing == nil {
 			return nil, ErrPaymentAlreadyExists
 		}
+		return s.continueExistingPayment(ctx, existing, payment)
+	}
+
+	return s.completeProvisionalPayment(ctx, payment, false)
+}
+
+// continueExistingPayment answers a create whose request_id is already stored:
+// the same request gets the stored payment, resumed if it is still
+// provisional; a request with different fields is an idempotency conflict.
+func (s *PaymentService) continueExistingPayment(ctx context.Context, existing, requested *entity.Payment) (*entity.Payment, error) {
+	if err := checkRequestFingerprint(existing, requested); err != nil {
+		return nil, err
+	}
+	if !isProvisional(existing) {
+		return existing, nil
+	}
+	return s.completeProvisionalPayment(ctx, existing, false)
+}
+
+// completeProvisionalPayment creates the payment at the provider and records
+// the result. The provider request is built from the stored row only, so
+// resuming repeats the first attempt exactly and the provider replays it.
+// A transient provider error leaves the payment provisional to be resumed; a
+// rejected request fails it, queueing a status callback only when notify is
+// set, i.e. when no caller is waiting for the answer.
+func (s *PaymentService) completeProvisionalPayment(ctx context.Context, payment *entity.Payment, notify bool) (*entity.Payment, error) {
+	providerClient, err := s.providerReg.Get(payment.Provider)
+	if err != nil {
+		if errors.Is(err, provider.ErrProviderNotSupported) {
+			return nil, ErrProviderUnsupported
+		}
 		return nil, err
 	}
 
-	_ = s.eventRepo.Create(ctx, &entity.PaymentEvent{
-		PaymentID: payment.ID,
-		EventType: "payment_created",
-		NewStatus: payment.Status,
-		CreatedAt: now,
+	providerOutput, err := providerClient.CreatePayment(ctx, &provider.CreateInput{
+		IdempotencyKey:         provider.IdempotencyKey(payment.CallerService, payment.RequestID, "create"),
+		RequestID:              payment.RequestID,
+		CallbackHash:           payment.ProviderCallbackHash,
+		ResourceType:           payment.ResourceType,
+		ResourceID:             payment.ResourceID,
+		AmountCents:            payment.AmountCents,
+		Currency:               payment.Currency,
+		PaymentMethod:          payment.PaymentMethod,
+		PaymentType:            payment.PaymentType,
+		RecurringInterval:      derefString(payment.RecurringInterval),
+		RecurringIntervalCount: derefInt32(payment.RecurringIntervalCount),
+		CustomerRef:            payment.CustomerRef,
+		Metadata:               payment.Metadata,
+		SuccessURL:             payment.SuccessURL,
+		CancelURL:              payment.CancelURL,
+	})
+	if errors.Is(err, provider.ErrRequestRejected) {
+		return nil, s.failRejectedPayment(ctx, payment, err, notify)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	oldStatus := payment.Status
+	if _, err := transitionStatus(payment, providerOutput.InitialStatus); err != nil {
+		return nil, err
+	}
+	payment.ProviderPaymentID = providerOutput.ProviderPaymentID
+	payment.ProviderSubscriptionID = providerOutput.ProviderSubscriptionID
+	payment.CheckoutURL = providerOutput.CheckoutURL
+	payment.ProviderCallbackURL = providerOutput.ProviderCallbackURL
+	payment.UpdatedAt = now
+
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		if err := s.paymentRepo.Update(ctx, payment); err != nil {
+			return err
+		}
+		err := s.eventRepo.Create(ctx, &entity.PaymentEvent{
+			PaymentID: payment.ID,
+			EventType: "payment_created",
+			NewStatus: payment.Status,
+			CreatedAt: now,
+		})
+		if err != nil {
+			return err
+		}
+		return s.queueStatusCallback(ctx, payment, oldStatus, now)
 	})
+	if errors.Is(err, repository.ErrPaymentVersionConflict) {
+		// A concurrent request completed the same payment first.
+		return s.GetPayment(ctx, payment.ID)
+	}
+	if err != nil {
+		return nil, err
+	}
 
 	return payment, nil
 }
 
+// failRejectedPayment marks a provisional payment failed after the provider
+// rejected its create, so that neither a retry nor the sweeper repeats it.
+func (s *PaymentService) failRejectedPayment(ctx context.Context, payment *entity.Payment, rejectErr error, notify bool) error {
+	failure := fmt.Errorf("%w: %w", ErrInvalidRequest, rejectErr)
+
+	now := time.Now().UTC()
+	oldStatus := payment.Status
+	if changed, err := transitionStatus(payment, int32(types.PaymentStatus_PAYMENT_STATUS_FAILED)); err != nil || !changed {
+		return failure
+	}
+	payment.UpdatedAt = now
+
+	payloadJSON, _ := json.Marshal(map[string]string{"error": truncate(rejectErr.Error(), 1024)})
+	payload := string(payloadJSON)
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		if err := s.paymentRepo.Update(ctx, payment); err != nil {
+			return err
+		}
+		err := s.eventRepo.Create(ctx, &entity.PaymentEvent{
+			PaymentID:   payment.ID,
+			EventType:   "payment_create_rejected",
+			OldStatus:   &oldStatus,
+			NewStatus:   payment.Status,
+			PayloadJSON: &payload,
+			CreatedAt:   now,
+		})
+		if err != nil || !notify {
+			return err
+		}
+		return s.queueStatusCallback(ctx, payment, oldStatus, now)
+	})
+	// A conflict means a concurrent request finished the payment first. Any
+	// other error leaves the payment provisional for the sweeper.
+	if err != nil && !errors.Is(err, repository.ErrPaymentVersionConflict) {
+		return err
+	}
+
+	return failure
+}
+
+// isProvisional reports whether the payment was stored but its provider
+// create has not been recorded yet.
+func isProvisional(payment *entity.Payment) bool {
+	return payment.Status == int32(types.PaymentStatus_PAYMENT_STATUS_CREATED) &&
+		payment.ProviderPaymentID == nil &&
+		payment.ProviderSubscriptionID == nil
+}
+
 func (s *PaymentService) GetPayment(ctx context.Context, id uint64) (*entity.Payment, error) {
-	payment, err := s.paymentRepo.FindByID(ctx, id)
+	return s.findPaymentForCaller(ctx, id)
+}
+
+// GetPaymentByRequestID looks a payment up by the caller's own request ID.
+// caller_service defaults to the authenticated caller.
+func (s *PaymentService) GetPaymentByRequestID(ctx context.Context, req getPaymentByRequestIDRequest) (*entity.Payment, error) {
+	callerService, err := s.scopeCallerService(ctx, strings.TrimSpace(req.GetCallerService()))
 	if err != nil {
 		return nil, err
 	}
-	if payment == nil {
-		return nil, ErrPaymentNotFound
+	requestID := strings.TrimSpace(req.GetRequestId())
+	if callerService == "" || requestID == "" {
+		return nil, fmt.Errorf("%w: caller_service and request_id are required", ErrInvalidRequest)
 	}
-	return payment, nil
+
+	payment, err := s.paymentRepo.FindByCallerRequestID(ctx, callerService, requestID)
+	if err != nil {
+		return nil, err
+	}
+	return s.visibleToCaller(ctx, payment)
 }
 
-func (s *PaymentService) ListPayments(ctx context.Context, req listPaymentsRequest) ([]*entity.Payment, error) {
+// GetPaymentByProviderRef looks a payment up by the provider's checkout
+// session / payment link ID or by its subscription ID.
+func (s *PaymentService) GetPaymentByProviderRef(ctx context.Context, req getPaymentByProviderRefRequest) (*entity.Payment, error) {
+	providerCode := int32(req.GetProvider())
+	if _, err := s.providerReg.Get(providerCode); err != nil {
+		return nil, ErrProviderUnsupported
+	}
+
+	providerPaymentID := strings.TrimSpace(req.GetProviderPaymentId())
+	providerSubscriptionID := strings.TrimSpace(req.GetProviderSubscriptionId())
+
+	var payment *entity.Payment
+	var err error
+	switch {
+	case providerPaymentID != "" && providerSubscriptionID == "":
+		payment, err = s.paymentRepo.FindByProviderPaymentID(ctx, providerCode, providerPaymentID)
+	case providerSubscriptionID != "" && providerPaymentID == "":
+		payment, err = s.paymentRepo.FindByProviderSubscriptionID(ctx, providerCode, providerSubscriptionID)
+	default:
+		return nil, fmt.Errorf("%w: exactly one of provider_payment_id or provider_subscription_id is required", ErrInvalidRequest)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.visibleToCaller(ctx, payment)
+}
+
+// ListPayments returns one page of payments, newest first, and the token of
+// the next page ("" when this is the last one). Pages are keyset based, so
+// payments created while paging do not shift later pages.
+func (s *PaymentService) ListPayments(ctx context.Context, req listPaymentsRequest) ([]*entity.Payment, string, error) {
 	limit := req.GetLimit()
 	if limit <= 0 {
 		limit = defaultListLimit
 	}
 
+	callerService, err := s.scopeCallerService(ctx, strings.TrimSpace(req.GetCallerService()))
+	if err != nil {
+		return nil, "", err
+	}
+	beforeID, err := decodePaymentPageToken(req.GetPageToken())
+	if err != nil {
+		return nil, "", err
+	}
+
+	// One extra row tells whether another page follows.
 	filter := repository.PaymentFilter{
-		RequestID:     strings.TrimSpace(req.GetRequestId()),
-		CallerService: strings.TrimSpace(req.GetCallerService()),
-		ResourceType:  strings.TrimSpace(req.GetResourceType()),
-		ResourceID:    strings.TrimSpace(req.GetResourceId()),
-		HasStatus:     req.GetHasStatus(),
-		Status:        int32(req.GetStatus()),
-		Provider:      int32(req.GetProvider()),
-		Limit:         limit,
-		Offset:        req.GetOffset(),
+		RequestID:              strings.TrimSpace(req.GetRequestId()),
+		CallerService:          callerService,
+		ResourceType:           strings.TrimSpace(req.GetResourceType()),
+		ResourceID:             strings.TrimSpace(req.GetResourceId()),
+		HasStatus:              req.GetHasStatus(),
+		Status:                 int32(req.GetStatus()),
+		Provider:               int32(req.GetProvider()),
+		CustomerRef:            strings.TrimSpace(req.GetCustomerRef()),
+		Currency:               strings.ToUpper(strings.TrimSpace(req.GetCurrency())),
+		MinAmountCents:         req.GetMinAmountCents(),
+		MaxAmountCents:         req.GetMaxAmountCents(),
+		ProviderPaymentID:      strings.TrimSpace(req.GetProviderPaymentId()),
+		ProviderSubscriptionID: strings.TrimSpace(req.GetProviderSubscriptionId()),
+		Metadata:               req.GetMetadata(),
+		BeforeID:               beforeID,
+		Limit:                  limit + 1,
+		Offset:                 req.GetOffset(),
+	}
+	for _, status := range req.GetStatuses() {
+		filter.Statuses = append(filter.Statuses, int32(status))
+	}
+	if filter.CreatedFrom, err = parseOptionalTime(req.GetCreatedFrom()); err != nil {
+		return nil, "", fmt.Errorf("%w: created_from must be an RFC3339 timestamp", ErrInvalidRequest)
+	}
+	if filter.CreatedTo, err = parseOptionalTime(req.GetCreatedTo()); err != nil {
+		return nil, "", fmt.Errorf("%w: created_to must be an RFC3339 timestamp", ErrInvalidRequest)
+	}
+	if filter.UpdatedFrom, err = parseOptionalTime(req.GetUpdatedFrom()); err != nil {
+		return nil, "", fmt.Errorf("%w: updated_from must be an RFC3339 timestamp", ErrInvalidRequest)
+	}
+	if filter.UpdatedTo, err = parseOptionalTime(req.GetUpdatedTo()); err != nil {
+		return nil, "", fmt.Errorf("%w: updated_to must be an RFC3339 timestamp", ErrInvalidRequest)
+	}
+
+	payments, err := s.paymentRepo.List(ctx, filter)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var nextPageToken string
+	if len(payments) > int(limit) {
+		payments = payments[:limit]
+		nextPageToken = encodePaymentPageToken(payments[len(payments)-1].ID)
 	}
 
-	return s.paymentRepo.List(ctx, filter)
+	return payments, nextPageToken, nil
 }
 
 func (s *PaymentService) CancelPayment(ctx context.Context, req cancelPaymentRequest) (*entity.Payment, error) {
-	payment, err := s.paymentRepo.FindByID(ctx, req.GetId())
+	payment, err := s.findPaymentForCaller(ctx, req.GetId())
 	if err != nil {
 		return nil, err
 	}
-	if payment == nil {
-		return nil, ErrPaymentNotFound
-	}
 
 	if payment.Status == int32(types.PaymentStatus_PAYMENT_STATUS_PAID) {
 		return nil, fmt.Errorf("%w: paid payments cannot be canceled", ErrInvalidStatus)
 	}
+	if payment.Status == int32(types.PaymentStatus_PAYMENT_STATUS_CANCELED) {
+		return payment, nil
+	}
+	if !canTransition(payment.Status, int32(types.PaymentStatus_PAYMENT_STATUS_CANCELED)) {
+		return nil, fmt.Errorf("%w: %s payments cannot be canceled", ErrInvalidTransition, types.PaymentStatus(payment.Status).String())
+	}
+
+	if err := s.cancelAtProvider(ctx, payment, strings.TrimSpace(req.GetReason())); err != nil {
+		return nil, err
+	}
 
 	now := time.Now().UTC()
 	oldStatus := payment.Status
-	payment.Status = int32(types.PaymentStatus_PAYMENT_STATUS_CANCELED)
-	s.markForCallbackDelivery(payment, now)
+	if _, err := transitionStatus(payment, int32(types.PaymentStatus_PAYMENT_STATUS_CANCELED)); err != nil {
+		return nil, err
+	}
 	payment.UpdatedAt = now
 
-	if err := s.paymentRepo.Update(ctx, payment); err != nil {
-		if errors.Is(err, repository.ErrPaymentNotFound) {
-			return nil, ErrPaymentNotFound
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		if err := s.paymentRepo.Update(ctx, payment); err != nil {
+			return mapUpdateError(err)
 		}
+		err := s.eventRepo.Create(ctx, &entity.PaymentEvent{
+			PaymentID: payment.ID,
+			EventType: "payment_canceled",
+			OldStatus: &oldStatus,
+			NewStatus: payment.Status,
+			CreatedAt: now,
+		})
+		if err != nil {
+			return err
+		}
+		return s.queueStatusCallback(ctx, payment, oldStatus, now)
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	_ = s.eventRepo.Create(ctx, &entity.PaymentEvent{
-		PaymentID: payment.ID,
-		EventType: "payment_canceled",
-		OldStatus: &oldStatus,
-		NewStatus: payment.Status,
-		CreatedAt: now,
-	})
-
 	return payment, nil
 }
 
-func (s *PaymentService) markForCallbackDelivery(payment *entity.Payment, now time.Time) {
-	payment.CallbackDeliveryStatus = entity.CallbackDeliveryPending
-	payment.CallbackDeliveryAttempts = 0
-	payment.CallbackDeliveryNextAt = &now
-	payment.CallbackDeliveryLastErr = nil
+// cancelAtProvider closes whatever the provider still has open for the payment
+// (checkout session, payment link or subscription). A provider failure is
+// recorded as a payment event and the local status is left untouched.
+func (s *PaymentService) cancelAtProvider(ctx context.Context, payment *entity.Payment, reason string) error {
+	if payment.Status == int32(types.PaymentStatus_PAYMENT_STATUS_CANCELED) || payment.Status == int32(types.PaymentStatus_PAYMENT_STATUS_EXPIRED) {
+		return nil
+	}
+	providerPaymentID := strings.TrimSpace(derefString(payment.ProviderPaymentID))
+	providerSubscriptionID := strings.TrimSpace(derefString(payment.ProviderSubscriptionID))
+	if providerPaymentID == "" && providerSubscriptionID == "" {
+		return nil
+	}
+
+	providerClient, err := s.providerReg.Get(payment.Provider)
+	if err != nil {
+		if errors.Is(err, provider.ErrProviderNotSupported) {
+			return ErrProviderUnsupported
+		}
+		return err
+	}
+
+	cancelErr := providerClient.Cancel(ctx, &provider.CancelInput{
+		IdempotencyKey:         lifecycleIdempotencyKey(payment, "cancel"),
+		ProviderPaymentID:      providerPaymentID,
+		ProviderSubscriptionID: providerSubscriptionID,
+		PaymentMethod:          payment.PaymentMethod,
+		PaymentType:            payment.PaymentType,
+		Reason:                 reason,
+	})
+	if cancelErr == nil {
+		return nil
+	}
+
+	payloadJSON, _ := json.Marshal(map[string]string{"error": truncate(cancelErr.Error(), 1024)})
+	payload := string(payloadJSON)
+	failure := fmt.Errorf("%w: %v", ErrProviderCancelFailed, cancelErr)
+	err = s.eventRepo.Create(ctx, &entity.PaymentEvent{
+		PaymentID:   payment.ID,
+		EventType:   "payment_cancel_failed",
+		NewStatus:   payment.Status,
+		PayloadJSON: &payload,
+		CreatedAt:   time.Now().UTC(),
+	})
+	if err != nil {
+		return errors.Join(failure, err)
+	}
+
+	return failure
 }
 
 func (s *PaymentService) batchSize() int32 {
@@ -316,6 +661,21 @@ func normalizeOptionalString(v string) *string {
 	return &trimmed
 }
 
+// lifecycleIdempotencyKey keys a provider call that changes an existing
+// payment. Those requests carry no request ID of their own, so the payment
+// version stands in: a retry before the change is saved reuses the key, while
+// the same action after a later change gets a new one.
+func lifecycleIdempotencyKey(payment *entity.Payment, step string) string {
+	return provider.IdempotencyKey(payment.CallerService, payment.RequestID, fmt.Sprintf("%s:v%d", step, payment.Version))
+}
+
+func derefString(v *string) string {
+	if v == nil {
+		return ""
+	}
+	return *v
+}
+
 func normalizeOptionalInt32(v int32) *int32 {
 	if v <= 0 {
 		return nil
@@ -2,16 +2,23 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"sort"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/vibast-solutions/ms-go-payments/app/callbacksig"
 	"github.com/vibast-solutions/ms-go-payments/app/entity"
 	"github.com/vibast-solutions/ms-go-payments/app/provider"
+	"github.com/vibast-solutions/ms-go-payments/app/provider/stripefake"
 	"github.com/vibast-solutions/ms-go-payments/app/repository"
+	"github.com/vibast-solutions/ms-go-payments/app/sink"
 	"github.com/vibast-solutions/ms-go-payments/app/types"
 	"github.com/vibast-solutions/ms-go-payments/config"
 )
@@ -44,9 +51,14 @@ func (r *servicePaymentRepo) Create(_ context.Context, payment *entity.Payment)
 }
 
 func (r *servicePaymentRepo) Update(_ context.Context, payment *entity.Payment) error {
-	if _, ok := r.payments[payment.ID]; !ok {
+	stored, ok := r.payments[payment.ID]
+	if !ok {
 		return repository.ErrPaymentNotFound
 	}
+	if stored.Version != payment.Version {
+		return repository.ErrPaymentVersionConflict
+	}
+	payment.Version++
 	copyItem := *payment
 	r.payments[payment.ID] = &copyItem
 	return nil
@@ -81,6 +93,26 @@ func (r *servicePaymentRepo) FindByCallbackHash(_ context.Context, providerCode
 	return nil, nil
 }
 
+func (r *servicePaymentRepo) FindByProviderSubscriptionID(_ context.Context, providerCode int32, providerSubscriptionID string) (*entity.Payment, error) {
+	for _, item := range r.payments {
+		if item.Provider == providerCode && item.ProviderSubscriptionID != nil && *item.ProviderSubscriptionID == providerSubscriptionID {
+			copyItem := *item
+			return &copyItem, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *servicePaymentRepo) FindByProviderPaymentID(_ context.Context, providerCode int32, providerPaymentID string) (*entity.Payment, error) {
+	for _, item := range r.payments {
+		if item.Provider == providerCode && item.ProviderPaymentID != nil && *item.ProviderPaymentID == providerPaymentID {
+			copyItem := *item
+			return &copyItem, nil
+		}
+	}
+	return nil, nil
+}
+
 func (r *servicePaymentRepo) List(_ context.Context, filter repository.PaymentFilter) ([]*entity.Payment, error) {
 	items := make([]*entity.Payment, 0)
 	for _, item := range r.payments {
@@ -102,6 +134,27 @@ func (r *servicePaymentRepo) List(_ context.Context, filter repository.PaymentFi
 		if filter.Provider > 0 && item.Provider != filter.Provider {
 			continue
 		}
+		if len(filter.Statuses) > 0 && !containsStatus(filter.Statuses, item.Status) {
+			continue
+		}
+		if filter.Currency != "" && item.Currency != filter.Currency {
+			continue
+		}
+		if filter.MinAmountCents > 0 && item.AmountCents < filter.MinAmountCents {
+			continue
+		}
+		if filter.MaxAmountCents > 0 && item.AmountCents > filter.MaxAmountCents {
+			continue
+		}
+		if filter.CreatedFrom != nil && item.CreatedAt.Before(*filter.CreatedFrom) {
+			continue
+		}
+		if !matchesMetadata(item.Metadata, filter.Metadata) {
+			continue
+		}
+		if filter.BeforeID > 0 && item.ID >= filter.BeforeID {
+			continue
+		}
 		copyItem := *item
 		items = append(items, &copyItem)
 	}
@@ -121,10 +174,28 @@ func (r *servicePaymentRepo) List(_ context.Context, filter repository.PaymentFi
 	return items[start:end], nil
 }
 
-func (r *servicePaymentRepo) ListDueCallbackDispatch(_ context.Context, now time.Time, limit int32) ([]*entity.Payment, error) {
+func containsStatus(statuses []int32, status int32) bool {
+	for _, candidate := range statuses {
+		if candidate == status {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesMetadata(metadata, filter map[string]string) bool {
+	for key, value := range filter {
+		if metadata[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *servicePaymentRepo) ListExpiredPending(_ context.Context, cutoff time.Time, limit int32) ([]*entity.Payment, error) {
 	items := make([]*entity.Payment, 0)
 	for _, item := range r.payments {
-		if item.CallbackDeliveryStatus == entity.CallbackDeliveryPending && item.CallbackDeliveryNextAt != nil && !item.CallbackDeliveryNextAt.After(now) {
+		if (item.Status == int32(types.PaymentStatus_PAYMENT_STATUS_PENDING) || item.Status == int32(types.PaymentStatus_PAYMENT_STATUS_PROCESSING)) && !item.CreatedAt.After(cutoff) {
 			copyItem := *item
 			items = append(items, &copyItem)
 		}
@@ -132,10 +203,10 @@ func (r *servicePaymentRepo) ListDueCallbackDispatch(_ context.Context, now time
 	return limitItems(items, limit), nil
 }
 
-func (r *servicePaymentRepo) ListExpiredPending(_ context.Context, cutoff time.Time, limit int32) ([]*entity.Payment, error) {
+func (r *servicePaymentRepo) ListForReconcile(_ context.Context, before time.Time, limit int32) ([]*entity.Payment, error) {
 	items := make([]*entity.Payment, 0)
 	for _, item := range r.payments {
-		if (item.Status == int32(types.PaymentStatus_PAYMENT_STATUS_PENDING) || item.Status == int32(types.PaymentStatus_PAYMENT_STATUS_PROCESSING)) && !item.CreatedAt.After(cutoff) {
+		if (item.Status == int32(types.PaymentStatus_PAYMENT_STATUS_PENDING) || item.Status == int32(types.PaymentStatus_PAYMENT_STATUS_PROCESSING)) && (item.ProviderPaymentID != nil || item.ProviderSubscriptionID != nil) && !item.UpdatedAt.After(before) {
 			copyItem := *item
 			items = append(items, &copyItem)
 		}
@@ -143,10 +214,10 @@ func (r *servicePaymentRepo) ListExpiredPending(_ context.Context, cutoff time.T
 	return limitItems(items, limit), nil
 }
 
-func (r *servicePaymentRepo) ListForReconcile(_ context.Context, before time.Time, limit int32) ([]*entity.Payment, error) {
+func (r *servicePaymentRepo) ListProvisional(_ context.Context, cutoff time.Time, limit int32) ([]*entity.Payment, error) {
 	items := make([]*entity.Payment, 0)
 	for _, item := range r.payments {
-		if (item.Status == int32(types.PaymentStatus_PAYMENT_STATUS_PENDING) || item.Status == int32(types.PaymentStatus_PAYMENT_STATUS_PROCESSING)) && item.ProviderPaymentID != nil && !item.UpdatedAt.After(before) {
+		if item.Status == int32(types.PaymentStatus_PAYMENT_STATUS_CREATED) && item.ProviderPaymentID == nil && item.ProviderSubscriptionID == nil && !item.CreatedAt.After(cutoff) {
 			copyItem := *item
 			items = append(items, &copyItem)
 		}
@@ -162,39 +233,372 @@ func limitItems(items []*entity.Payment, limit int32) []*entity.Payment {
 }
 
 type serviceEventRepo struct {
-	events []*entity.PaymentEvent
+	events    []*entity.PaymentEvent
+	createErr error
 }
 
 func (r *serviceEventRepo) Create(_ context.Context, event *entity.PaymentEvent) error {
+	if r.createErr != nil {
+		return r.createErr
+	}
+	event.ID = uint64(len(r.events) + 1)
 	copyItem := *event
 	r.events = append(r.events, &copyItem)
 	return nil
 }
 
+func (r *serviceEventRepo) ListByPayment(_ context.Context, filter repository.PaymentEventFilter) ([]*entity.PaymentEvent, error) {
+	items := make([]*entity.PaymentEvent, 0)
+	for _, event := range r.events {
+		if event.PaymentID != filter.PaymentID || event.ID <= filter.AfterID {
+			continue
+		}
+		if filter.EventType != "" && event.EventType != filter.EventType {
+			continue
+		}
+		copyItem := *event
+		if !filter.IncludePayload {
+			copyItem.PayloadJSON = nil
+		}
+		items = append(items, &copyItem)
+		if filter.Limit > 0 && len(items) == int(filter.Limit) {
+			break
+		}
+	}
+	return items, nil
+}
+
 type serviceCallbackRepo struct {
 	callbacks []*entity.PaymentCallback
 }
 
 func (r *serviceCallbackRepo) Create(_ context.Context, callback *entity.PaymentCallback) error {
+	callback.ID = uint64(len(r.callbacks) + 1)
 	copyItem := *callback
 	r.callbacks = append(r.callbacks, &copyItem)
 	return nil
 }
 
+func (r *serviceCallbackRepo) FindByID(_ context.Context, id uint64) (*entity.PaymentCallback, error) {
+	for _, callback := range r.callbacks {
+		if callback.ID == id {
+			copyItem := *callback
+			return &copyItem, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *serviceCallbackRepo) List(_ context.Context, filter repository.PaymentCallbackFilter) ([]*entity.PaymentCallback, error) {
+	items := make([]*entity.PaymentCallback, 0)
+	for idx := len(r.callbacks) - 1; idx >= 0; idx-- {
+		callback := r.callbacks[idx]
+		if filter.Provider != "" && callback.Provider != filter.Provider {
+			continue
+		}
+		if filter.CallbackHash != "" && callback.CallbackHash != filter.CallbackHash {
+			continue
+		}
+		if filter.HasStatus && callback.Status != filter.Status {
+			continue
+		}
+		if filter.CreatedFrom != nil && callback.CreatedAt.Before(*filter.CreatedFrom) {
+			continue
+		}
+		if filter.CreatedTo != nil && callback.CreatedAt.After(*filter.CreatedTo) {
+			continue
+		}
+		copyItem := *callback
+		if !filter.IncludePayload {
+			copyItem.PayloadJSON = ""
+		}
+		items = append(items, &copyItem)
+	}
+	return items, nil
+}
+
+type serviceRefundRepo struct {
+	refunds []*entity.PaymentRefund
+}
+
+func (r *serviceRefundRepo) Create(_ context.Context, refund *entity.PaymentRefund) error {
+	for _, item := range r.refunds {
+		if item.PaymentID != refund.PaymentID {
+			continue
+		}
+		if item.RequestID == refund.RequestID || sameProviderRefundID(item, refund) {
+			return repository.ErrRefundAlreadyExists
+		}
+	}
+	refund.ID = uint64(len(r.refunds) + 1)
+	copyItem := *refund
+	r.refunds = append(r.refunds, &copyItem)
+	return nil
+}
+
+func (r *serviceRefundRepo) Update(_ context.Context, refund *entity.PaymentRefund) error {
+	for i, item := range r.refunds {
+		if item.ID == refund.ID {
+			copyItem := *refund
+			r.refunds[i] = &copyItem
+			return nil
+		}
+	}
+	return repository.ErrRefundNotFound
+}
+
+func sameProviderRefundID(a, b *entity.PaymentRefund) bool {
+	return a.ProviderRefundID != nil && b.ProviderRefundID != nil && *a.ProviderRefundID == *b.ProviderRefundID
+}
+
+func (r *serviceRefundRepo) FindByPaymentRequestID(_ context.Context, paymentID uint64, requestID string) (*entity.PaymentRefund, error) {
+	for _, item := range r.refunds {
+		if item.PaymentID == paymentID && item.RequestID == requestID {
+			copyItem := *item
+			return &copyItem, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *serviceRefundRepo) FindByProviderRefundID(_ context.Context, paymentID uint64, providerRefundID string) (*entity.PaymentRefund, error) {
+	for _, item := range r.refunds {
+		if item.PaymentID == paymentID && item.ProviderRefundID != nil && *item.ProviderRefundID == providerRefundID {
+			copyItem := *item
+			return &copyItem, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *serviceRefundRepo) ListByPaymentID(_ context.Context, paymentID uint64) ([]*entity.PaymentRefund, error) {
+	items := make([]*entity.PaymentRefund, 0)
+	for _, item := range r.refunds {
+		if item.PaymentID == paymentID {
+			copyItem := *item
+			items = append(items, &copyItem)
+		}
+	}
+	return items, nil
+}
+
+type serviceChargeRepo struct {
+	charges []*entity.PaymentCharge
+}
+
+func (r *serviceChargeRepo) Create(_ context.Context, charge *entity.PaymentCharge) error {
+	for _, item := range r.charges {
+		if item.PaymentID == charge.PaymentID && item.ProviderInvoiceID == charge.ProviderInvoiceID {
+			return repository.ErrChargeAlreadyExists
+		}
+	}
+	charge.ID = uint64(len(r.charges) + 1)
+	copyItem := *charge
+	r.charges = append(r.charges, &copyItem)
+	return nil
+}
+
+func (r *serviceChargeRepo) Update(_ context.Context, charge *entity.PaymentCharge) error {
+	for i, item := range r.charges {
+		if item.ID == charge.ID {
+			copyItem := *charge
+			r.charges[i] = &copyItem
+			return nil
+		}
+	}
+	return repository.ErrChargeNotFound
+}
+
+func (r *serviceChargeRepo) FindByProviderInvoiceID(_ context.Context, paymentID uint64, providerInvoiceID string) (*entity.PaymentCharge, error) {
+	for _, item := range r.charges {
+		if item.PaymentID == paymentID && item.ProviderInvoiceID == providerInvoiceID {
+			copyItem := *item
+			return &copyItem, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *serviceChargeRepo) ListByPaymentID(_ context.Context, paymentID uint64) ([]*entity.PaymentCharge, error) {
+	items := make([]*entity.PaymentCharge, 0)
+	for _, item := range r.charges {
+		if item.PaymentID == paymentID {
+			copyItem := *item
+			items = append(items, &copyItem)
+		}
+	}
+	return items, nil
+}
+
+type serviceProviderEventRepo struct {
+	events []*entity.PaymentProviderEvent
+}
+
+func (r *serviceProviderEventRepo) Create(_ context.Context, event *entity.PaymentProviderEvent) error {
+	for _, item := range r.events {
+		if item.Provider == event.Provider && item.ProviderEventID == event.ProviderEventID {
+			return repository.ErrProviderEventAlreadyExists
+		}
+	}
+	event.ID = uint64(len(r.events) + 1)
+	copyItem := *event
+	r.events = append(r.events, &copyItem)
+	return nil
+}
+
+func (r *serviceProviderEventRepo) FindByProviderEventID(_ context.Context, providerCode int32, providerEventID string) (*entity.PaymentProviderEvent, error) {
+	for _, item := range r.events {
+		if item.Provider == providerCode && item.ProviderEventID == providerEventID {
+			copyItem := *item
+			return &copyItem, nil
+		}
+	}
+	return nil, nil
+}
+
+type serviceOutboxRepo struct {
+	items []*entity.PaymentCallbackOutbox
+}
+
+func (r *serviceOutboxRepo) Create(_ context.Context, item *entity.PaymentCallbackOutbox) error {
+	item.ID = uint64(len(r.items) + 1)
+	copyItem := *item
+	r.items = append(r.items, &copyItem)
+	return nil
+}
+
+func (r *serviceOutboxRepo) Update(_ context.Context, item *entity.PaymentCallbackOutbox) error {
+	for idx, existing := range r.items {
+		if existing.ID == item.ID {
+			copyItem := *item
+			r.items[idx] = &copyItem
+			return nil
+		}
+	}
+	return nil
+}
+
+func (r *serviceOutboxRepo) ListDue(_ context.Context, now time.Time, limit int32) ([]*entity.PaymentCallbackOutbox, error) {
+	items := make([]*entity.PaymentCallbackOutbox, 0)
+	blocked := map[uint64]bool{}
+	for _, item := range r.items {
+		if item.Status == entity.CallbackDeliveryFailed {
+			blocked[item.PaymentID] = true
+		}
+		if item.Status != entity.CallbackDeliveryPending || blocked[item.PaymentID] {
+			continue
+		}
+		blocked[item.PaymentID] = true
+		if item.NextAttemptAt != nil && !item.NextAttemptAt.After(now) {
+			copyItem := *item
+			items = append(items, &copyItem)
+		}
+	}
+	if limit > 0 && int(limit) < len(items) {
+		items = items[:limit]
+	}
+	return items, nil
+}
+
+func (r *serviceOutboxRepo) Claim(_ context.Context, item *entity.PaymentCallbackOutbox, now, until time.Time) (bool, error) {
+	for _, existing := range r.items {
+		if existing.ID != item.ID {
+			continue
+		}
+		if existing.Status != entity.CallbackDeliveryPending || existing.NextAttemptAt == nil || existing.NextAttemptAt.After(now) {
+			return false, nil
+		}
+		existing.NextAttemptAt = &until
+		item.NextAttemptAt = &until
+		return true, nil
+	}
+	return false, nil
+}
+
+func (r *serviceOutboxRepo) matchesDeadLetter(item *entity.PaymentCallbackOutbox, filter repository.CallbackOutboxFilter) bool {
+	if item.Status != entity.CallbackDeliveryFailed {
+		return false
+	}
+	if filter.PaymentID > 0 && item.PaymentID != filter.PaymentID {
+		return false
+	}
+	if filter.CallerService != "" && item.CallerService != filter.CallerService {
+		return false
+	}
+	return filter.EventType == "" || item.EventType == filter.EventType
+}
+
+func (r *serviceOutboxRepo) ListDeadLetters(_ context.Context, filter repository.CallbackOutboxFilter) ([]*entity.PaymentCallbackOutbox, error) {
+	items := make([]*entity.PaymentCallbackOutbox, 0)
+	for idx := len(r.items) - 1; idx >= 0; idx-- {
+		if r.matchesDeadLetter(r.items[idx], filter) {
+			copyItem := *r.items[idx]
+			items = append(items, &copyItem)
+		}
+	}
+	return items, nil
+}
+
+func (r *serviceOutboxRepo) RequeueDeadLetters(_ context.Context, filter repository.CallbackOutboxFilter, now time.Time) (int64, error) {
+	var requeued int64
+	for _, item := range r.items {
+		if !r.matchesDeadLetter(item, filter) {
+			continue
+		}
+		nextAt := now
+		item.Status = entity.CallbackDeliveryPending
+		item.Attempts = 0
+		item.NextAttemptAt = &nextAt
+		item.UpdatedAt = now
+		requeued++
+	}
+	return requeued, nil
+}
+
+type serviceUnitOfWork struct {
+	commits   int
+	rollbacks int
+}
+
+func (u *serviceUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := fn(ctx); err != nil {
+		u.rollbacks++
+		return err
+	}
+	u.commits++
+	return nil
+}
+
 type serviceProvider struct {
 	createOutput *provider.CreateOutput
 	createErr    error
+	createInputs []*provider.CreateInput
 	callbackEvt  *provider.CallbackEvent
 	callbackErr  error
 	reconcile    int32
 	reconcileErr error
+	statusInputs []*provider.StatusInput
+	events       []provider.ListedEvent
+	eventsPage   int
+	parsed       map[string]*provider.CallbackEvent
+	refundOutput *provider.RefundOutput
+	refundErr    error
+	refundInputs []*provider.RefundInput
+	cancelErr    error
+	cancelInputs []*provider.CancelInput
+
+	subscriptionErr    error
+	subscriptionCalls  []string
+	subscriptionInputs []*provider.SubscriptionInput
+	updateInputs       []*provider.UpdateSubscriptionInput
 }
 
 func (p *serviceProvider) Code() int32 {
 	return int32(types.ProviderType_PROVIDER_TYPE_STRIPE)
 }
 
-func (p *serviceProvider) CreatePayment(context.Context, *provider.CreateInput) (*provider.CreateOutput, error) {
+func (p *serviceProvider) CreatePayment(_ context.Context, input *provider.CreateInput) (*provider.CreateOutput, error) {
+	p.createInputs = append(p.createInputs, input)
 	if p.createErr != nil {
 		return nil, p.createErr
 	}
@@ -211,6 +615,10 @@ func (p *serviceProvider) CreatePayment(context.Context, *provider.CreateInput)
 	}, nil
 }
 
+func (p *serviceProvider) CallbackContract() provider.CallbackContract {
+	return provider.CallbackContract{SignatureHeader: "Stripe-Signature", RawBody: true}
+}
+
 func (p *serviceProvider) VerifyAndParseCallback(context.Context, []byte, string) (*provider.CallbackEvent, error) {
 	if p.callbackErr != nil {
 		return nil, p.callbackErr
@@ -221,19 +629,116 @@ func (p *serviceProvider) VerifyAndParseCallback(context.Context, []byte, string
 	return &provider.CallbackEvent{EventType: "checkout.session.completed", NewStatus: int32(types.PaymentStatus_PAYMENT_STATUS_PAID)}, nil
 }
 
-func (p *serviceProvider) GetPaymentStatus(context.Context, string) (int32, error) {
+func (p *serviceProvider) ParseCallback(_ context.Context, payload []byte) (*provider.CallbackEvent, error) {
+	if evt, ok := p.parsed[string(payload)]; ok {
+		return evt, nil
+	}
+	if p.callbackEvt != nil {
+		return p.callbackEvt, nil
+	}
+	return &provider.CallbackEvent{EventType: "checkout.session.completed", NewStatus: int32(types.PaymentStatus_PAYMENT_STATUS_PAID)}, nil
+}
+
+func (p *serviceProvider) GetPaymentStatus(_ context.Context, input *provider.StatusInput) (int32, error) {
+	p.statusInputs = append(p.statusInputs, input)
 	if p.reconcileErr != nil {
 		return 0, p.reconcileErr
 	}
 	return p.reconcile, nil
 }
 
+// ListEvents pages through p.events, which are kept newest first like a
+// provider listing.
+func (p *serviceProvider) ListEvents(_ context.Context, input *provider.ListEventsInput) (*provider.ListEventsOutput, error) {
+	start := 0
+	if input.StartingAfter != "" {
+		for i, event := range p.events {
+			if event.ID == input.StartingAfter {
+				start = i + 1
+			}
+		}
+	}
+	end := len(p.events)
+	if p.eventsPage > 0 && start+p.eventsPage < end {
+		end = start + p.eventsPage
+	}
+	return &provider.ListEventsOutput{Events: p.events[start:end], HasMore: end < len(p.events)}, nil
+}
+
+func (p *serviceProvider) Refund(_ context.Context, input *provider.RefundInput) (*provider.RefundOutput, error) {
+	p.refundInputs = append(p.refundInputs, input)
+	if p.refundErr != nil {
+		return nil, p.refundErr
+	}
+	if p.refundOutput != nil {
+		return p.refundOutput, nil
+	}
+	return &provider.RefundOutput{
+		ProviderRefundID: "re_test_123",
+		Status:           int32(types.RefundStatus_REFUND_STATUS_SUCCEEDED),
+	}, nil
+}
+
+func (p *serviceProvider) Cancel(_ context.Context, input *provider.CancelInput) error {
+	p.cancelInputs = append(p.cancelInputs, input)
+	return p.cancelErr
+}
+
+func (p *serviceProvider) PauseSubscription(_ context.Context, input *provider.SubscriptionInput) error {
+	p.subscriptionCalls = append(p.subscriptionCalls, "pause")
+	p.subscriptionInputs = append(p.subscriptionInputs, input)
+	return p.subscriptionErr
+}
+
+func (p *serviceProvider) ResumeSubscription(_ context.Context, input *provider.SubscriptionInput) error {
+	p.subscriptionCalls = append(p.subscriptionCalls, "resume")
+	p.subscriptionInputs = append(p.subscriptionInputs, input)
+	return p.subscriptionErr
+}
+
+func (p *serviceProvider) CancelSubscription(_ context.Context, input *provider.SubscriptionInput) error {
+	p.subscriptionCalls = append(p.subscriptionCalls, "cancel")
+	p.subscriptionInputs = append(p.subscriptionInputs, input)
+	return p.subscriptionErr
+}
+
+func (p *serviceProvider) UpdateSubscription(_ context.Context, input *provider.UpdateSubscriptionInput) error {
+	p.subscriptionCalls = append(p.subscriptionCalls, "update")
+	p.updateInputs = append(p.updateInputs, input)
+	return p.subscriptionErr
+}
+
+type serviceSink struct {
+	err      error
+	messages []*sink.Message
+}
+
+func (s *serviceSink) Schemes() []string {
+	return []string{"test"}
+}
+
+func (s *serviceSink) Deliver(_ context.Context, msg *sink.Message) error {
+	s.messages = append(s.messages, msg)
+	return s.err
+}
+
+func newSinkRegistryForTest(sinks ...sink.Sink) *sink.Registry {
+	sinks = append(sinks, sink.NewHTTPSink(&http.Client{Timeout: time.Second}, "payments-app-key"))
+	return sink.NewRegistry(sinks...)
+}
+
 func newPaymentServiceForTest(repo *servicePaymentRepo, eventRepo *serviceEventRepo, callbackRepo *serviceCallbackRepo, p provider.Provider) *PaymentService {
 	return NewPaymentService(
 		repo,
 		eventRepo,
 		callbackRepo,
+		&serviceRefundRepo{},
+		&serviceChargeRepo{},
+		&serviceProviderEventRepo{},
+		&serviceOutboxRepo{},
+		&serviceUnitOfWork{},
 		provider.NewRegistry(p),
+		newSinkRegistryForTest(),
 		config.PaymentsConfig{
 			CallbackMaxAttempts:   3,
 			CallbackRetryInterval: time.Second,
@@ -242,7 +747,6 @@ func newPaymentServiceForTest(repo *servicePaymentRepo, eventRepo *serviceEventR
 			ReconcileStaleAfter:   time.Minute,
 			JobBatchSize:          100,
 		},
-		"payments-app-key",
 	)
 }
 
@@ -250,7 +754,8 @@ func TestCreatePaymentIdempotentByRequestIDAndCallerService(t *testing.T) {
 	repo := newServicePaymentRepo()
 	eventRepo := &serviceEventRepo{}
 	callbackRepo := &serviceCallbackRepo{}
-	svc := newPaymentServiceForTest(repo, eventRepo, callbackRepo, &serviceProvider{})
+	p := &serviceProvider{}
+	svc := newPaymentServiceForTest(repo, eventRepo, callbackRepo, p)
 
 	first, err := svc.CreatePayment(context.Background(), &types.CreatePaymentRequest{
 		RequestId:         "req-1",
@@ -284,25 +789,267 @@ func TestCreatePaymentIdempotentByRequestIDAndCallerService(t *testing.T) {
 	if second.ID != first.ID {
 		t.Fatalf("expected same payment id for idempotent request, first=%d second=%d", first.ID, second.ID)
 	}
+	if len(p.createInputs) != 1 || p.createInputs[0].IdempotencyKey != "subscriptions-service:req-1:create" {
+		t.Fatalf("expected one provider create with idempotency key, got %+v", p.createInputs)
+	}
 }
 
-func TestCreatePaymentRequiresRequestIDAndCallerService(t *testing.T) {
+func TestCreatePaymentRejectsReusedRequestIDWithDifferentFields(t *testing.T) {
 	repo := newServicePaymentRepo()
-	svc := newPaymentServiceForTest(repo, &serviceEventRepo{}, &serviceCallbackRepo{}, &serviceProvider{})
-
-	_, err := svc.CreatePayment(context.Background(), &types.CreatePaymentRequest{
+	p := &serviceProvider{}
+	svc := newPaymentServiceForTest(repo, &serviceEventRepo{}, &serviceCallbackRepo{}, p)
+	req := &types.CreatePaymentRequest{
+		RequestId:         "req-1",
+		CallerService:     "subscriptions-service",
 		ResourceType:      "subscription",
 		ResourceId:        "sub-1",
 		AmountCents:       1000,
-		Currency:          "USD",
+		Currency:          "usd",
 		PaymentMethod:     types.PaymentMethod_PAYMENT_METHOD_HOSTED_CARD,
 		PaymentType:       types.PaymentType_PAYMENT_TYPE_ONE_TIME,
 		StatusCallbackUrl: "https://caller.example/callback",
-	})
-	if !errors.Is(err, ErrInvalidRequest) {
-		t.Fatalf("expected ErrInvalidRequest, got %v", err)
 	}
-}
+	first, err := svc.CreatePayment(context.Background(), req)
+	if err != nil {
+		t.Fatalf("create payment failed: %v", err)
+	}
+	if first.RequestFingerprint == "" {
+		t.Fatal("expected request fingerprint to be stored")
+	}
+
+	// Normalization differences and non-significant fields are not conflicts.
+	req.Currency = " USD "
+	req.StatusCallbackUrl = "https://caller.example/other-callback"
+	same, err := svc.CreatePayment(context.Background(), req)
+	if err != nil || same.ID != first.ID {
+		t.Fatalf("expected the original payment for an identical request, got %+v err=%v", same, err)
+	}
+
+	req.AmountCents = 2000
+	req.ResourceId = "sub-2"
+	_, err = svc.CreatePayment(context.Background(), req)
+	if !errors.Is(err, ErrIdempotencyConflict) {
+		t.Fatalf("expected ErrIdempotencyConflict, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "different resource_id, amount_cents") {
+		t.Fatalf("expected differing fields in the error, got %v", err)
+	}
+	if len(p.createInputs) != 1 {
+		t.Fatalf("expected no provider call for the conflicting request, got %d", len(p.createInputs))
+	}
+}
+
+func TestCreatePaymentSkipsFingerprintCheckForLegacyPayments(t *testing.T) {
+	repo := newServicePaymentRepo()
+	providerPaymentID := "cs_test_legacy"
+	repo.payments[1] = &entity.Payment{
+		ID:                1,
+		RequestID:         "req-1",
+		CallerService:     "subscriptions-service",
+		AmountCents:       500,
+		Currency:          "USD",
+		Status:            int32(types.PaymentStatus_PAYMENT_STATUS_PENDING),
+		Provider:          int32(types.ProviderType_PROVIDER_TYPE_STRIPE),
+		ProviderPaymentID: &providerPaymentID,
+		Metadata:          map[string]string{},
+	}
+	svc := newPaymentServiceForTest(repo, &serviceEventRepo{}, &serviceCallbackRepo{}, &serviceProvider{})
+
+	payment, err := svc.CreatePayment(context.Background(), &types.CreatePaymentRequest{
+		RequestId:         "req-1",
+		CallerService:     "subscriptions-service",
+		ResourceType:      "subscription",
+		ResourceId:        "sub-1",
+		AmountCents:       1000,
+		Currency:          "USD",
+		PaymentMethod:     types.PaymentMethod_PAYMENT_METHOD_HOSTED_CARD,
+		PaymentType:       types.PaymentType_PAYMENT_TYPE_ONE_TIME,
+		StatusCallbackUrl: "https://caller.example/callback",
+	})
+	if err != nil || payment.ID != 1 {
+		t.Fatalf("expected legacy payment without fingerprint to be returned, got %+v err=%v", payment, err)
+	}
+}
+
+func TestCreatePaymentRequiresRequestIDAndCallerService(t *testing.T) {
+	repo := newServicePaymentRepo()
+	svc := newPaymentServiceForTest(repo, &serviceEventRepo{}, &serviceCallbackRepo{}, &serviceProvider{})
+
+	_, err := svc.CreatePayment(context.Background(), &types.CreatePaymentRequest{
+		ResourceType:      "subscription",
+		ResourceId:        "sub-1",
+		AmountCents:       1000,
+		Currency:          "USD",
+		PaymentMethod:     types.PaymentMethod_PAYMENT_METHOD_HOSTED_CARD,
+		PaymentType:       types.PaymentType_PAYMENT_TYPE_ONE_TIME,
+		StatusCallbackUrl: "https://caller.example/callback",
+	})
+	if !errors.Is(err, ErrInvalidRequest) {
+		t.Fatalf("expected ErrInvalidRequest, got %v", err)
+	}
+}
+
+func TestCreatePaymentRejectsUndeliverableStatusCallbackURL(t *testing.T) {
+	repo := newServicePaymentRepo()
+	p := &serviceProvider{}
+	svc := newPaymentServiceForTest(repo, &serviceEventRepo{}, &serviceCallbackRepo{}, p)
+	svc.sinks = newSinkRegistryForTest(sink.NewBrokerSink("nats", sink.NewNATSPublisher("", time.Second)))
+
+	for i, destination := range []string{"caller.example/callback", "https:///callback", "amqp://payments", "nats://payments status", "nats://payments.status\r\nPUB other 0"} {
+		_, err := svc.CreatePayment(context.Background(), &types.CreatePaymentRequest{
+			RequestId:         fmt.Sprintf("req-%d", i),
+			CallerService:     "subscriptions-service",
+			ResourceType:      "subscription",
+			ResourceId:        "sub-1",
+			AmountCents:       1000,
+			Currency:          "USD",
+			PaymentMethod:     types.PaymentMethod_PAYMENT_METHOD_HOSTED_CARD,
+			PaymentType:       types.PaymentType_PAYMENT_TYPE_ONE_TIME,
+			StatusCallbackUrl: destination,
+		})
+		if !errors.Is(err, ErrInvalidRequest) {
+			t.Fatalf("expected ErrInvalidRequest for %q, got %v", destination, err)
+		}
+	}
+	if len(repo.payments) != 0 || len(p.createInputs) != 0 {
+		t.Fatalf("expected nothing to be stored or created, got payments=%d creates=%d", len(repo.payments), len(p.createInputs))
+	}
+}
+
+func TestCreatePaymentResumesProvisionalPaymentAfterProviderFailure(t *testing.T) {
+	repo := newServicePaymentRepo()
+	eventRepo := &serviceEventRepo{}
+	p := &serviceProvider{createErr: errors.New("stripe request failed: status=502")}
+	svc := newPaymentServiceForTest(repo, eventRepo, &serviceCallbackRepo{}, p)
+	req := &types.CreatePaymentRequest{
+		RequestId:         "req-1",
+		CallerService:     "subscriptions-service",
+		ResourceType:      "subscription",
+		ResourceId:        "sub-1",
+		AmountCents:       1000,
+		Currency:          "USD",
+		PaymentMethod:     types.PaymentMethod_PAYMENT_METHOD_HOSTED_CARD,
+		PaymentType:       types.PaymentType_PAYMENT_TYPE_ONE_TIME,
+		StatusCallbackUrl: "https://caller.example/callback",
+		SuccessUrl:        "https://caller.example/success",
+	}
+
+	if _, err := svc.CreatePayment(context.Background(), req); err == nil {
+		t.Fatal("expected provider error")
+	}
+	if len(repo.payments) != 1 || !isProvisional(repo.payments[1]) {
+		t.Fatalf("expected one provisional payment, got %+v", repo.payments)
+	}
+	if len(eventRepo.events) != 0 {
+		t.Fatalf("expected no events before the provider create, got %+v", eventRepo.events)
+	}
+
+	p.createErr = nil
+	payment, err := svc.CreatePayment(context.Background(), req)
+	if err != nil {
+		t.Fatalf("resumed create failed: %v", err)
+	}
+	if payment.ID != 1 || payment.Status != int32(types.PaymentStatus_PAYMENT_STATUS_PENDING) || payment.ProviderPaymentID == nil {
+		t.Fatalf("expected provisional payment to be completed, got %+v", payment)
+	}
+	if len(p.createInputs) != 2 {
+		t.Fatalf("expected two provider creates, got %d", len(p.createInputs))
+	}
+	first, second := p.createInputs[0], p.createInputs[1]
+	if first.CallbackHash != second.CallbackHash || first.IdempotencyKey != second.IdempotencyKey || second.SuccessURL != "https://caller.example/success" {
+		t.Fatalf("expected the resumed create to repeat the first request, first=%+v second=%+v", first, second)
+	}
+	if len(eventRepo.events) != 1 || eventRepo.events[0].EventType != "payment_created" {
+		t.Fatalf("expected one payment_created event, got %+v", eventRepo.events)
+	}
+}
+
+func TestCreatePaymentFailsPaymentRejectedByProvider(t *testing.T) {
+	repo := newServicePaymentRepo()
+	eventRepo := &serviceEventRepo{}
+	p := &serviceProvider{createErr: fmt.Errorf("%w: stripe request failed: status=400", provider.ErrRequestRejected)}
+	svc := newPaymentServiceForTest(repo, eventRepo, &serviceCallbackRepo{}, p)
+	req := &types.CreatePaymentRequest{
+		RequestId:         "req-1",
+		CallerService:     "subscriptions-service",
+		ResourceType:      "subscription",
+		ResourceId:        "sub-1",
+		AmountCents:       1000,
+		Currency:          "USD",
+		PaymentMethod:     types.PaymentMethod_PAYMENT_METHOD_HOSTED_CARD,
+		PaymentType:       types.PaymentType_PAYMENT_TYPE_ONE_TIME,
+		StatusCallbackUrl: "https://caller.example/callback",
+	}
+
+	if _, err := svc.CreatePayment(context.Background(), req); !errors.Is(err, ErrInvalidRequest) {
+		t.Fatalf("expected ErrInvalidRequest for a rejected create, got %v", err)
+	}
+	if repo.payments[1].Status != int32(types.PaymentStatus_PAYMENT_STATUS_FAILED) || isProvisional(repo.payments[1]) {
+		t.Fatalf("expected rejected payment to be failed, got %+v", repo.payments[1])
+	}
+	if len(eventRepo.events) != 1 || eventRepo.events[0].EventType != "payment_create_rejected" || eventRepo.events[0].PayloadJSON == nil {
+		t.Fatalf("expected one payment_create_rejected event with the error, got %+v", eventRepo.events)
+	}
+	if outbox := svc.outboxRepo.(*serviceOutboxRepo); len(outbox.items) != 0 {
+		t.Fatalf("expected no status callback for a create the caller saw fail, got %+v", outbox.items)
+	}
+
+	payment, err := svc.CreatePayment(context.Background(), req)
+	if err != nil || payment.Status != int32(types.PaymentStatus_PAYMENT_STATUS_FAILED) {
+		t.Fatalf("expected retry to return the failed payment, got %+v err=%v", payment, err)
+	}
+	if len(p.createInputs) != 1 {
+		t.Fatalf("expected the rejected create not to be repeated, got %d creates", len(p.createInputs))
+	}
+}
+
+func TestCreatePaymentContinuesWithRowOfConcurrentDuplicate(t *testing.T) {
+	repo := newServicePaymentRepo()
+	p := &serviceProvider{}
+	svc := newPaymentServiceForTest(repo, &serviceEventRepo{}, &serviceCallbackRepo{}, p)
+	racing := &duplicateInsertRepo{servicePaymentRepo: repo}
+	svc.paymentRepo = racing
+
+	payment, err := svc.CreatePayment(context.Background(), &types.CreatePaymentRequest{
+		RequestId:         "req-1",
+		CallerService:     "subscriptions-service",
+		ResourceType:      "subscription",
+		ResourceId:        "sub-1",
+		AmountCents:       1000,
+		Currency:          "USD",
+		PaymentMethod:     types.PaymentMethod_PAYMENT_METHOD_HOSTED_CARD,
+		PaymentType:       types.PaymentType_PAYMENT_TYPE_ONE_TIME,
+		StatusCallbackUrl: "https://caller.example/callback",
+	})
+	if err != nil {
+		t.Fatalf("create payment failed: %v", err)
+	}
+	if len(repo.payments) != 1 || payment.ID != racing.winnerID {
+		t.Fatalf("expected the concurrent row to be used, got payment=%d rows=%d", payment.ID, len(repo.payments))
+	}
+	if len(p.createInputs) != 1 || p.createInputs[0].CallbackHash != "hash-winner" {
+		t.Fatalf("expected the provider create to use the winner's callback hash, got %+v", p.createInputs)
+	}
+}
+
+// duplicateInsertRepo simulates a concurrent CreatePayment with the same
+// request_id committing its provisional row just before this one.
+type duplicateInsertRepo struct {
+	*servicePaymentRepo
+	winnerID uint64
+}
+
+func (r *duplicateInsertRepo) Create(ctx context.Context, payment *entity.Payment) error {
+	if r.winnerID == 0 {
+		winner := *payment
+		winner.ProviderCallbackHash = "hash-winner"
+		if err := r.servicePaymentRepo.Create(ctx, &winner); err != nil {
+			return err
+		}
+		r.winnerID = winner.ID
+	}
+	return r.servicePaymentRepo.Create(ctx, payment)
+}
 
 func TestCancelPaymentPaidIsInvalidStatus(t *testing.T) {
 	repo := newServicePaymentRepo()
@@ -315,6 +1062,272 @@ func TestCancelPaymentPaidIsInvalidStatus(t *testing.T) {
 	}
 }
 
+func TestPaymentAccessIsScopedToCallerService(t *testing.T) {
+	repo := newServicePaymentRepo()
+	repo.payments[1] = &entity.Payment{ID: 1, CallerService: "subscriptions-service", Status: int32(types.PaymentStatus_PAYMENT_STATUS_PENDING)}
+	repo.payments[2] = &entity.Payment{ID: 2, CallerService: "orders-service", Status: int32(types.PaymentStatus_PAYMENT_STATUS_PENDING)}
+	svc := newPaymentServiceForTest(repo, &serviceEventRepo{}, &serviceCallbackRepo{}, &serviceProvider{})
+	ctx := WithCallerService(context.Background(), "orders-service")
+
+	if _, err := svc.GetPayment(ctx, 1); !errors.Is(err, ErrPaymentNotFound) {
+		t.Fatalf("expected another caller's payment to be not found, got %v", err)
+	}
+	if _, err := svc.CancelPayment(ctx, &types.CancelPaymentRequest{Id: 1, Reason: "probe"}); !errors.Is(err, ErrPaymentNotFound) {
+		t.Fatalf("expected cancel of another caller's payment to be not found, got %v", err)
+	}
+	if repo.payments[1].Status != int32(types.PaymentStatus_PAYMENT_STATUS_PENDING) {
+		t.Fatalf("expected other caller's payment to stay pending, got %d", repo.payments[1].Status)
+	}
+	if payment, err := svc.GetPayment(ctx, 2); err != nil || payment.ID != 2 {
+		t.Fatalf("expected own payment, got %+v err=%v", payment, err)
+	}
+
+	items, _, err := svc.ListPayments(ctx, &types.ListPaymentsRequest{})
+	if err != nil {
+		t.Fatalf("list payments failed: %v", err)
+	}
+	if len(items) != 1 || items[0].ID != 2 {
+		t.Fatalf("expected list limited to own payments, got %+v", items)
+	}
+	if _, _, err := svc.ListPayments(ctx, &types.ListPaymentsRequest{CallerService: "subscriptions-service"}); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden for other caller_service filter, got %v", err)
+	}
+
+	_, err = svc.CreatePayment(ctx, &types.CreatePaymentRequest{
+		RequestId:     "req-9",
+		CallerService: "subscriptions-service",
+		ResourceType:  "subscription",
+		ResourceId:    "sub-9",
+		AmountCents:   1000,
+		Currency:      "USD",
+		PaymentMethod: types.PaymentMethod_PAYMENT_METHOD_HOSTED_CARD,
+		PaymentType:   types.PaymentType_PAYMENT_TYPE_ONE_TIME,
+	})
+	if !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden when creating for another caller, got %v", err)
+	}
+	if len(repo.payments) != 2 {
+		t.Fatalf("expected no payment to be stored, got %d", len(repo.payments))
+	}
+
+	if _, err := svc.ListProviderCallbacks(ctx, &types.ListProviderCallbacksRequest{}); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected provider callback inbox to be admin only, got %v", err)
+	}
+}
+
+func TestAdminServiceSeesEveryCallersPayments(t *testing.T) {
+	repo := newServicePaymentRepo()
+	repo.payments[1] = &entity.Payment{ID: 1, CallerService: "subscriptions-service"}
+	repo.payments[2] = &entity.Payment{ID: 2, CallerService: "orders-service"}
+	svc := newPaymentServiceForTest(repo, &serviceEventRepo{}, &serviceCallbackRepo{}, &serviceProvider{})
+	svc.adminServices = map[string]bool{"payments-gateway": true}
+	ctx := WithCallerService(context.Background(), "payments-gateway")
+
+	if _, err := svc.GetPayment(ctx, 1); err != nil {
+		t.Fatalf("expected admin to read any payment, got %v", err)
+	}
+	items, _, err := svc.ListPayments(ctx, &types.ListPaymentsRequest{})
+	if err != nil {
+		t.Fatalf("list payments failed: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expected admin to list all payments, got %d", len(items))
+	}
+	items, _, err = svc.ListPayments(ctx, &types.ListPaymentsRequest{CallerService: "orders-service"})
+	if err != nil || len(items) != 1 || items[0].ID != 2 {
+		t.Fatalf("expected admin caller_service filter to apply, got %+v err=%v", items, err)
+	}
+	if _, err := svc.ListProviderCallbacks(ctx, &types.ListProviderCallbacksRequest{}); err != nil {
+		t.Fatalf("expected admin to list provider callbacks, got %v", err)
+	}
+}
+
+func TestListPaymentsPagesWithKeysetTokens(t *testing.T) {
+	repo := newServicePaymentRepo()
+	for id := uint64(1); id <= 5; id++ {
+		repo.payments[id] = &entity.Payment{ID: id, CallerService: "subscriptions-service", Currency: "EUR", AmountCents: 1000}
+	}
+	svc := newPaymentServiceForTest(repo, &serviceEventRepo{}, &serviceCallbackRepo{}, &serviceProvider{})
+
+	first, token, err := svc.ListPayments(context.Background(), &types.ListPaymentsRequest{Limit: 2})
+	if err != nil {
+		t.Fatalf("list payments failed: %v", err)
+	}
+	if len(first) != 2 || first[0].ID != 5 || first[1].ID != 4 || token == "" {
+		t.Fatalf("unexpected first page: %+v token=%q", first, token)
+	}
+
+	// A payment created while paging must not shift the next page.
+	repo.payments[6] = &entity.Payment{ID: 6, CallerService: "subscriptions-service", Currency: "EUR", AmountCents: 1000}
+
+	second, token, err := svc.ListPayments(context.Background(), &types.ListPaymentsRequest{Limit: 2, PageToken: token})
+	if err != nil {
+		t.Fatalf("list second page failed: %v", err)
+	}
+	if len(second) != 2 || second[0].ID != 3 || second[1].ID != 2 || token == "" {
+		t.Fatalf("unexpected second page: %+v token=%q", second, token)
+	}
+
+	last, token, err := svc.ListPayments(context.Background(), &types.ListPaymentsRequest{Limit: 2, PageToken: token})
+	if err != nil {
+		t.Fatalf("list last page failed: %v", err)
+	}
+	if len(last) != 1 || last[0].ID != 1 || token != "" {
+		t.Fatalf("unexpected last page: %+v token=%q", last, token)
+	}
+
+	if _, _, err := svc.ListPayments(context.Background(), &types.ListPaymentsRequest{Limit: 2, PageToken: "not-a-token"}); !errors.Is(err, ErrInvalidRequest) {
+		t.Fatalf("expected ErrInvalidRequest for a malformed page token, got %v", err)
+	}
+}
+
+func TestListPaymentsAppliesRangeAndMetadataFilters(t *testing.T) {
+	repo := newServicePaymentRepo()
+	now := time.Now().UTC()
+	repo.payments[1] = &entity.Payment{ID: 1, Status: int32(types.PaymentStatus_PAYMENT_STATUS_PAID), Currency: "EUR", AmountCents: 500, Metadata: map[string]string{"plan": "pro"}, CreatedAt: now}
+	repo.payments[2] = &entity.Payment{ID: 2, Status: int32(types.PaymentStatus_PAYMENT_STATUS_FAILED), Currency: "EUR", AmountCents: 1500, Metadata: map[string]string{"plan": "pro"}, CreatedAt: now}
+	repo.payments[3] = &entity.Payment{ID: 3, Status: int32(types.PaymentStatus_PAYMENT_STATUS_PAID), Currency: "USD", AmountCents: 1500, Metadata: map[string]string{"plan": "pro"}, CreatedAt: now}
+	repo.payments[4] = &entity.Payment{ID: 4, Status: int32(types.PaymentStatus_PAYMENT_STATUS_PENDING), Currency: "EUR", AmountCents: 1500, Metadata: map[string]string{"plan": "pro"}, CreatedAt: now}
+	repo.payments[5] = &entity.Payment{ID: 5, Status: int32(types.PaymentStatus_PAYMENT_STATUS_PAID), Currency: "EUR", AmountCents: 1500, Metadata: map[string]string{"plan": "basic"}, CreatedAt: now}
+	repo.payments[6] = &entity.Payment{ID: 6, Status: int32(types.PaymentStatus_PAYMENT_STATUS_PAID), Currency: "EUR", AmountCents: 1500, Metadata: map[string]string{"plan": "pro"}, CreatedAt: now.Add(-48 * time.Hour)}
+	svc := newPaymentServiceForTest(repo, &serviceEventRepo{}, &serviceCallbackRepo{}, &serviceProvider{})
+
+	items, _, err := svc.ListPayments(context.Background(), &types.ListPaymentsRequest{
+		Statuses:       []types.PaymentStatus{types.PaymentStatus_PAYMENT_STATUS_PAID, types.PaymentStatus_PAYMENT_STATUS_FAILED},
+		Currency:       "eur",
+		MinAmountCents: 1000,
+		MaxAmountCents: 2000,
+		Metadata:       map[string]string{"plan": "pro"},
+		CreatedFrom:    now.Add(-time.Hour).Format(time.RFC3339),
+	})
+	if err != nil {
+		t.Fatalf("list payments failed: %v", err)
+	}
+	if len(items) != 1 || items[0].ID != 2 {
+		t.Fatalf("expected only payment 2 to match, got %+v", items)
+	}
+
+	if _, _, err := svc.ListPayments(context.Background(), &types.ListPaymentsRequest{UpdatedTo: "yesterday"}); !errors.Is(err, ErrInvalidRequest) {
+		t.Fatalf("expected ErrInvalidRequest for a bad updated_to, got %v", err)
+	}
+}
+
+func TestGetPaymentByRequestIDAndProviderRef(t *testing.T) {
+	repo := newServicePaymentRepo()
+	sessionID := "cs_test_1"
+	subscriptionID := "sub_test_1"
+	stripe := int32(types.ProviderType_PROVIDER_TYPE_STRIPE)
+	repo.payments[1] = &entity.Payment{ID: 1, RequestID: "req-1", CallerService: "subscriptions-service", Provider: stripe, ProviderPaymentID: &sessionID}
+	repo.payments[2] = &entity.Payment{ID: 2, RequestID: "req-1", CallerService: "orders-service", Provider: stripe, ProviderSubscriptionID: &subscriptionID}
+	svc := newPaymentServiceForTest(repo, &serviceEventRepo{}, &serviceCallbackRepo{}, &serviceProvider{})
+	ctx := WithCallerService(context.Background(), "orders-service")
+
+	payment, err := svc.GetPaymentByRequestID(ctx, &types.GetPaymentByRequestIDRequest{RequestId: "req-1"})
+	if err != nil || payment.ID != 2 {
+		t.Fatalf("expected the caller's own payment for its request id, got %+v err=%v", payment, err)
+	}
+	if _, err := svc.GetPaymentByRequestID(ctx, &types.GetPaymentByRequestIDRequest{CallerService: "subscriptions-service", RequestId: "req-1"}); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden for another caller's request id, got %v", err)
+	}
+	if _, err := svc.GetPaymentByRequestID(context.Background(), &types.GetPaymentByRequestIDRequest{RequestId: "req-1"}); !errors.Is(err, ErrInvalidRequest) {
+		t.Fatalf("expected ErrInvalidRequest without any caller service, got %v", err)
+	}
+
+	payment, err = svc.GetPaymentByProviderRef(ctx, &types.GetPaymentByProviderRefRequest{
+		Provider:               types.ProviderType_PROVIDER_TYPE_STRIPE,
+		ProviderSubscriptionId: subscriptionID,
+	})
+	if err != nil || payment.ID != 2 {
+		t.Fatalf("expected payment by subscription id, got %+v err=%v", payment, err)
+	}
+	_, err = svc.GetPaymentByProviderRef(ctx, &types.GetPaymentByProviderRefRequest{
+		Provider:          types.ProviderType_PROVIDER_TYPE_STRIPE,
+		ProviderPaymentId: sessionID,
+	})
+	if !errors.Is(err, ErrPaymentNotFound) {
+		t.Fatalf("expected another caller's session to be not found, got %v", err)
+	}
+	payment, err = svc.GetPaymentByProviderRef(context.Background(), &types.GetPaymentByProviderRefRequest{
+		Provider:          types.ProviderType_PROVIDER_TYPE_STRIPE,
+		ProviderPaymentId: sessionID,
+	})
+	if err != nil || payment.ID != 1 {
+		t.Fatalf("expected payment by session id, got %+v err=%v", payment, err)
+	}
+}
+
+func TestWatchPaymentStreamsStatusChangesUntilTerminal(t *testing.T) {
+	repo := newServicePaymentRepo()
+	repo.payments[1] = &entity.Payment{ID: 1, CallerService: "subscriptions-service", Status: int32(types.PaymentStatus_PAYMENT_STATUS_PENDING)}
+	eventRepo := &serviceEventRepo{}
+	_ = eventRepo.Create(context.Background(), &entity.PaymentEvent{PaymentID: 1, EventType: "payment_created", NewStatus: int32(types.PaymentStatus_PAYMENT_STATUS_PENDING)})
+	svc := newPaymentServiceForTest(repo, eventRepo, &serviceCallbackRepo{}, &serviceProvider{})
+	svc.paymentsCfg.WatchPollInterval = time.Millisecond
+
+	// Each send moves the payment on, the way a webhook on another replica would.
+	next := []int32{
+		int32(types.PaymentStatus_PAYMENT_STATUS_PROCESSING),
+		int32(types.PaymentStatus_PAYMENT_STATUS_PAID),
+	}
+	var statuses []int32
+	var eventIDs []uint64
+	err := svc.WatchPayment(context.Background(), 1, func(payment *entity.Payment, eventID uint64) error {
+		statuses = append(statuses, payment.Status)
+		eventIDs = append(eventIDs, eventID)
+		if len(next) > 0 {
+			// An event that does not change the status is not streamed.
+			_ = eventRepo.Create(context.Background(), &entity.PaymentEvent{PaymentID: 1, EventType: "callback_dispatched", NewStatus: payment.Status})
+			_ = eventRepo.Create(context.Background(), &entity.PaymentEvent{PaymentID: 1, EventType: "status_changed", NewStatus: next[0]})
+			repo.payments[1].Status = next[0]
+			next = next[1:]
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("watch payment failed: %v", err)
+	}
+
+	expected := []int32{
+		int32(types.PaymentStatus_PAYMENT_STATUS_PENDING),
+		int32(types.PaymentStatus_PAYMENT_STATUS_PROCESSING),
+		int32(types.PaymentStatus_PAYMENT_STATUS_PAID),
+	}
+	if len(statuses) != len(expected) {
+		t.Fatalf("expected statuses %v, got %v", expected, statuses)
+	}
+	for i := range expected {
+		if statuses[i] != expected[i] {
+			t.Fatalf("expected statuses %v, got %v", expected, statuses)
+		}
+	}
+	if eventIDs[0] != 1 || eventIDs[1] != 3 || eventIDs[2] != 5 {
+		t.Fatalf("unexpected event ids: %v", eventIDs)
+	}
+}
+
+func TestWatchPaymentEndsWithContextAndHidesOtherCallers(t *testing.T) {
+	repo := newServicePaymentRepo()
+	repo.payments[1] = &entity.Payment{ID: 1, CallerService: "subscriptions-service", Status: int32(types.PaymentStatus_PAYMENT_STATUS_PENDING)}
+	svc := newPaymentServiceForTest(repo, &serviceEventRepo{}, &serviceCallbackRepo{}, &serviceProvider{})
+	svc.paymentsCfg.WatchPollInterval = time.Millisecond
+
+	sent := 0
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := svc.WatchPayment(ctx, 1, func(*entity.Payment, uint64) error {
+		sent++
+		return nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) || sent != 1 {
+		t.Fatalf("expected one snapshot and a deadline error, got sent=%d err=%v", sent, err)
+	}
+
+	otherCtx := WithCallerService(context.Background(), "orders-service")
+	if err := svc.WatchPayment(otherCtx, 1, func(*entity.Payment, uint64) error { return nil }); !errors.Is(err, ErrPaymentNotFound) {
+		t.Fatalf("expected another caller's payment to be not found, got %v", err)
+	}
+}
+
 func TestHandleProviderCallbackUpdatesStatusAndStoresCallback(t *testing.T) {
 	repo := newServicePaymentRepo()
 	now := time.Now().UTC().Add(-time.Hour)
@@ -380,13 +1393,19 @@ func TestRunExpirePendingBatchMarksExpired(t *testing.T) {
 		CreatedAt:            now,
 		UpdatedAt:            now,
 	}
+	outboxRepo := &serviceOutboxRepo{}
 	cfgSvc := NewPaymentService(
 		repo,
 		&serviceEventRepo{},
 		&serviceCallbackRepo{},
+		&serviceRefundRepo{},
+		&serviceChargeRepo{},
+		&serviceProviderEventRepo{},
+		outboxRepo,
+		&serviceUnitOfWork{},
 		provider.NewRegistry(&serviceProvider{}),
+		newSinkRegistryForTest(),
 		config.PaymentsConfig{PendingTimeout: time.Minute, CallbackRetryInterval: time.Second, CallbackMaxAttempts: 3, JobBatchSize: 100},
-		"payments-app-key",
 	)
 
 	if err := cfgSvc.RunExpirePendingBatch(context.Background()); err != nil {
@@ -397,95 +1416,506 @@ func TestRunExpirePendingBatchMarksExpired(t *testing.T) {
 	if updated.Status != int32(types.PaymentStatus_PAYMENT_STATUS_EXPIRED) {
 		t.Fatalf("expected expired status, got %d", updated.Status)
 	}
-	if updated.CallbackDeliveryStatus != entity.CallbackDeliveryPending {
-		t.Fatalf("expected callback delivery pending, got %d", updated.CallbackDeliveryStatus)
+	if len(outboxRepo.items) != 1 || outboxRepo.items[0].Status != entity.CallbackDeliveryPending {
+		t.Fatalf("expected one pending callback in the outbox, got %+v", outboxRepo.items)
+	}
+	if outboxRepo.items[0].EventType != callbackEventPaymentStatus || outboxRepo.items[0].Destination != "https://caller.example/status" {
+		t.Fatalf("unexpected queued callback: %+v", outboxRepo.items[0])
 	}
 }
 
-func TestRunReconcileBatchUpdatesTerminalStatus(t *testing.T) {
+func TestRunSweepProvisionalBatchFailsRejectedResume(t *testing.T) {
 	repo := newServicePaymentRepo()
-	now := time.Now().UTC().Add(-2 * time.Hour)
-	providerPaymentID := "cs_test_123"
+	now := time.Now().UTC()
 	repo.payments[1] = &entity.Payment{
 		ID:                   1,
 		RequestID:            "req-1",
 		CallerService:        "subscriptions-service",
-		Status:               int32(types.PaymentStatus_PAYMENT_STATUS_PENDING),
+		AmountCents:          1000,
+		Currency:             "USD",
+		Status:               int32(types.PaymentStatus_PAYMENT_STATUS_CREATED),
+		PaymentMethod:        int32(types.PaymentMethod_PAYMENT_METHOD_HOSTED_CARD),
+		PaymentType:          int32(types.PaymentType_PAYMENT_TYPE_ONE_TIME),
 		Provider:             int32(types.ProviderType_PROVIDER_TYPE_STRIPE),
-		ProviderPaymentID:    &providerPaymentID,
 		ProviderCallbackHash: "hash-1",
-		ProviderCallbackURL:  "https://gateway.example/callback/hash-1",
 		StatusCallbackURL:    "https://caller.example/status",
 		Metadata:             map[string]string{},
-		CreatedAt:            now,
-		UpdatedAt:            now,
+		CreatedAt:            now.Add(-10 * time.Minute),
+		UpdatedAt:            now.Add(-10 * time.Minute),
 	}
-
+	repo.payments[2] = &entity.Payment{
+		ID:                   2,
+		RequestID:            "req-2",
+		CallerService:        "subscriptions-service",
+		AmountCents:          1000,
+		Currency:             "USD",
+		Status:               int32(types.PaymentStatus_PAYMENT_STATUS_CREATED),
+		PaymentMethod:        int32(types.PaymentMethod_PAYMENT_METHOD_HOSTED_CARD),
+		PaymentType:          int32(types.PaymentType_PAYMENT_TYPE_ONE_TIME),
+		Provider:             int32(types.ProviderType_PROVIDER_TYPE_STRIPE),
+		ProviderCallbackHash: "hash-2",
+		StatusCallbackURL:    "https://caller.example/status",
+		Metadata:             map[string]string{},
+		CreatedAt:            now.Add(-10 * time.Minute),
+		UpdatedAt:            now.Add(-10 * time.Minute),
+	}
+	outboxRepo := &serviceOutboxRepo{}
+	p := &rejectingProvider{serviceProvider: &serviceProvider{}, rejectHash: "hash-1"}
 	svc := NewPaymentService(
 		repo,
 		&serviceEventRepo{},
 		&serviceCallbackRepo{},
-		provider.NewRegistry(&serviceProvider{reconcile: int32(types.PaymentStatus_PAYMENT_STATUS_PAID)}),
-		config.PaymentsConfig{ReconcileStaleAfter: time.Minute, CallbackRetryInterval: time.Second, CallbackMaxAttempts: 3, JobBatchSize: 100},
-		"payments-app-key",
+		&serviceRefundRepo{},
+		&serviceChargeRepo{},
+		&serviceProviderEventRepo{},
+		outboxRepo,
+		&serviceUnitOfWork{},
+		provider.NewRegistry(p),
+		newSinkRegistryForTest(),
+		config.PaymentsConfig{
+			ProvisionalStaleAfter:   5 * time.Minute,
+			ProvisionalAbandonAfter: time.Hour,
+			CallbackRetryInterval:   time.Second,
+			CallbackMaxAttempts:     3,
+			JobBatchSize:            100,
+		},
 	)
 
-	if err := svc.RunReconcileBatch(context.Background()); err != nil {
-		t.Fatalf("run reconcile batch failed: %v", err)
+	if err := svc.RunSweepProvisionalBatch(context.Background()); err != nil {
+		t.Fatalf("expected a rejected resume not to fail the sweep, got %v", err)
 	}
-
-	updated, _ := repo.FindByID(context.Background(), 1)
-	if updated.Status != int32(types.PaymentStatus_PAYMENT_STATUS_PAID) {
-		t.Fatalf("expected paid status after reconcile, got %d", updated.Status)
+	if rejected, _ := repo.FindByID(context.Background(), 1); rejected.Status != int32(types.PaymentStatus_PAYMENT_STATUS_FAILED) {
+		t.Fatalf("expected rejected payment to be failed, got %d", rejected.Status)
 	}
-}
-
-func TestRunDispatchCallbacksBatchSuccess(t *testing.T) {
+	if resumed, _ := repo.FindByID(context.Background(), 2); resumed.Status != int32(types.PaymentStatus_PAYMENT_STATUS_PENDING) {
+		t.Fatalf("expected the other payment to be resumed, got %d", resumed.Status)
+	}
+	var rejectedCallbacks int
+	for _, item := range outboxRepo.items {
+		if item.PaymentID == 1 {
+			rejectedCallbacks++
+		}
+	}
+	if rejectedCallbacks != 1 {
+		t.Fatalf("expected a status callback for the payment failed by the sweeper, got %+v", outboxRepo.items)
+	}
+
+	p.rejectHash = ""
+	p.createErr = errors.New("stripe request failed: status=503")
+	if err := svc.RunSweepProvisionalBatch(context.Background()); err != nil {
+		t.Fatalf("expected nothing left to sweep, got %v", err)
+	}
+	if len(p.createInputs) != 2 {
+		t.Fatalf("expected the failed payment not to be resumed again, got %d creates", len(p.createInputs))
+	}
+}
+
+// rejectingProvider rejects the create of one payment.
+type rejectingProvider struct {
+	*serviceProvider
+	rejectHash string
+}
+
+func (p *rejectingProvider) CreatePayment(ctx context.Context, input *provider.CreateInput) (*provider.CreateOutput, error) {
+	if input.CallbackHash == p.rejectHash {
+		p.createInputs = append(p.createInputs, input)
+		return nil, fmt.Errorf("%w: stripe request failed: status=400", provider.ErrRequestRejected)
+	}
+	return p.serviceProvider.CreatePayment(ctx, input)
+}
+
+func TestRunSweepProvisionalBatchResumesAndAbandons(t *testing.T) {
 	repo := newServicePaymentRepo()
 	now := time.Now().UTC()
-	nextAt := now.Add(-time.Second)
+	repo.payments[1] = &entity.Payment{
+		ID:                   1,
+		RequestID:            "req-1",
+		CallerService:        "subscriptions-service",
+		AmountCents:          1000,
+		Currency:             "USD",
+		Status:               int32(types.PaymentStatus_PAYMENT_STATUS_CREATED),
+		PaymentMethod:        int32(types.PaymentMethod_PAYMENT_METHOD_HOSTED_CARD),
+		PaymentType:          int32(types.PaymentType_PAYMENT_TYPE_ONE_TIME),
+		Provider:             int32(types.ProviderType_PROVIDER_TYPE_STRIPE),
+		ProviderCallbackHash: "hash-1",
+		StatusCallbackURL:    "https://caller.example/status",
+		Metadata:             map[string]string{},
+		CreatedAt:            now.Add(-10 * time.Minute),
+		UpdatedAt:            now.Add(-10 * time.Minute),
+	}
+	repo.payments[2] = &entity.Payment{
+		ID:                   2,
+		RequestID:            "req-2",
+		CallerService:        "subscriptions-service",
+		AmountCents:          1000,
+		Currency:             "USD",
+		Status:               int32(types.PaymentStatus_PAYMENT_STATUS_CREATED),
+		PaymentMethod:        int32(types.PaymentMethod_PAYMENT_METHOD_HOSTED_CARD),
+		PaymentType:          int32(types.PaymentType_PAYMENT_TYPE_ONE_TIME),
+		Provider:             int32(types.ProviderType_PROVIDER_TYPE_STRIPE),
+		ProviderCallbackHash: "hash-2",
+		StatusCallbackURL:    "https://caller.example/status",
+		Metadata:             map[string]string{},
+		CreatedAt:            now.Add(-3 * time.Hour),
+		UpdatedAt:            now.Add(-3 * time.Hour),
+	}
+	repo.payments[3] = &entity.Payment{
+		ID:                   3,
+		RequestID:            "req-3",
+		CallerService:        "subscriptions-service",
+		Status:               int32(types.PaymentStatus_PAYMENT_STATUS_CREATED),
+		Provider:             int32(types.ProviderType_PROVIDER_TYPE_STRIPE),
+		ProviderCallbackHash: "hash-3",
+		Metadata:             map[string]string{},
+		CreatedAt:            now,
+		UpdatedAt:            now,
+	}
+	eventRepo := &serviceEventRepo{}
+	outboxRepo := &serviceOutboxRepo{}
+	p := &serviceProvider{}
+	svc := NewPaymentService(
+		repo,
+		eventRepo,
+		&serviceCallbackRepo{},
+		&serviceRefundRepo{},
+		&serviceChargeRepo{},
+		&serviceProviderEventRepo{},
+		outboxRepo,
+		&serviceUnitOfWork{},
+		provider.NewRegistry(p),
+		newSinkRegistryForTest(),
+		config.PaymentsConfig{
+			ProvisionalStaleAfter:   5 * time.Minute,
+			ProvisionalAbandonAfter: time.Hour,
+			CallbackRetryInterval:   time.Second,
+			CallbackMaxAttempts:     3,
+			JobBatchSize:            100,
+		},
+	)
+
+	if err := svc.RunSweepProvisionalBatch(context.Background()); err != nil {
+		t.Fatalf("run sweep provisional batch failed: %v", err)
+	}
+
+	resumed, _ := repo.FindByID(context.Background(), 1)
+	if resumed.Status != int32(types.PaymentStatus_PAYMENT_STATUS_PENDING) || resumed.ProviderPaymentID == nil || resumed.CheckoutURL == nil {
+		t.Fatalf("expected recent provisional payment to be resumed, got %+v", resumed)
+	}
+	if len(p.createInputs) != 1 || p.createInputs[0].CallbackHash != "hash-1" {
+		t.Fatalf("expected one provider create for the recent payment, got %+v", p.createInputs)
+	}
+
+	abandoned, _ := repo.FindByID(context.Background(), 2)
+	if abandoned.Status != int32(types.PaymentStatus_PAYMENT_STATUS_FAILED) {
+		t.Fatalf("expected old provisional payment to be failed, got %d", abandoned.Status)
+	}
+	if len(outboxRepo.items) != 1 || outboxRepo.items[0].PaymentID != 2 {
+		t.Fatalf("expected a status callback for the abandoned payment, got %+v", outboxRepo.items)
+	}
+
+	fresh, _ := repo.FindByID(context.Background(), 3)
+	if fresh.Status != int32(types.PaymentStatus_PAYMENT_STATUS_CREATED) {
+		t.Fatalf("expected in-flight provisional payment to be left alone, got %d", fresh.Status)
+	}
+
+	eventTypes := map[string]uint64{}
+	for _, event := range eventRepo.events {
+		eventTypes[event.EventType] = event.PaymentID
+	}
+	if eventTypes["payment_created"] != 1 || eventTypes["payment_create_abandoned"] != 2 {
+		t.Fatalf("unexpected events: %+v", eventRepo.events)
+	}
+}
+
+func TestRunReconcileBatchUpdatesTerminalStatus(t *testing.T) {
+	repo := newServicePaymentRepo()
+	now := time.Now().UTC().Add(-2 * time.Hour)
+	providerPaymentID := "cs_test_123"
+	repo.payments[1] = &entity.Payment{
+		ID:                   1,
+		RequestID:            "req-1",
+		CallerService:        "subscriptions-service",
+		Status:               int32(types.PaymentStatus_PAYMENT_STATUS_PENDING),
+		Provider:             int32(types.ProviderType_PROVIDER_TYPE_STRIPE),
+		ProviderPaymentID:    &providerPaymentID,
+		ProviderCallbackHash: "hash-1",
+		ProviderCallbackURL:  "https://gateway.example/callback/hash-1",
+		StatusCallbackURL:    "https://caller.example/status",
+		Metadata:             map[string]string{},
+		CreatedAt:            now,
+		UpdatedAt:            now,
+	}
+
+	svc := NewPaymentService(
+		repo,
+		&serviceEventRepo{},
+		&serviceCallbackRepo{},
+		&serviceRefundRepo{},
+		&serviceChargeRepo{},
+		&serviceProviderEventRepo{},
+		&serviceOutboxRepo{},
+		&serviceUnitOfWork{},
+		provider.NewRegistry(&serviceProvider{reconcile: int32(types.PaymentStatus_PAYMENT_STATUS_PAID)}),
+		newSinkRegistryForTest(),
+		config.PaymentsConfig{ReconcileStaleAfter: time.Minute, CallbackRetryInterval: time.Second, CallbackMaxAttempts: 3, JobBatchSize: 100},
+	)
+
+	if err := svc.RunReconcileBatch(context.Background()); err != nil {
+		t.Fatalf("run reconcile batch failed: %v", err)
+	}
+
+	updated, _ := repo.FindByID(context.Background(), 1)
+	if updated.Status != int32(types.PaymentStatus_PAYMENT_STATUS_PAID) {
+		t.Fatalf("expected paid status after reconcile, got %d", updated.Status)
+	}
+}
+
+func TestRunReconcileBatchResolvesSubscriptionPayments(t *testing.T) {
+	repo := newServicePaymentRepo()
+	now := time.Now().UTC().Add(-2 * time.Hour)
+	subscriptionID := "sub_123"
 	repo.payments[1] = &entity.Payment{
 		ID:                     1,
 		RequestID:              "req-1",
 		CallerService:          "subscriptions-service",
-		Status:                 int32(types.PaymentStatus_PAYMENT_STATUS_PAID),
+		Status:                 int32(types.PaymentStatus_PAYMENT_STATUS_PENDING),
+		PaymentMethod:          int32(types.PaymentMethod_PAYMENT_METHOD_PAYMENT_LINK),
+		PaymentType:            int32(types.PaymentType_PAYMENT_TYPE_RECURRING),
 		Provider:               int32(types.ProviderType_PROVIDER_TYPE_STRIPE),
+		ProviderSubscriptionID: &subscriptionID,
 		ProviderCallbackHash:   "hash-1",
 		ProviderCallbackURL:    "https://gateway.example/callback/hash-1",
-		StatusCallbackURL:      "http://localhost/callback",
+		StatusCallbackURL:      "https://caller.example/status",
 		Metadata:               map[string]string{},
-		CallbackDeliveryStatus: entity.CallbackDeliveryPending,
-		CallbackDeliveryNextAt: &nextAt,
-		CreatedAt:              now.Add(-time.Hour),
-		UpdatedAt:              now.Add(-time.Hour),
+		CreatedAt:              now,
+		UpdatedAt:              now,
+	}
+
+	p := &serviceProvider{reconcile: int32(types.PaymentStatus_PAYMENT_STATUS_PAID)}
+	svc := newPaymentServiceForTest(repo, &serviceEventRepo{}, &serviceCallbackRepo{}, p)
+	if err := svc.RunReconcileBatch(context.Background()); err != nil {
+		t.Fatalf("run reconcile batch failed: %v", err)
+	}
+
+	if len(p.statusInputs) != 1 {
+		t.Fatalf("expected one status lookup, got %d", len(p.statusInputs))
+	}
+	input := p.statusInputs[0]
+	if input.ProviderSubscriptionID != "sub_123" || input.ProviderPaymentID != "" {
+		t.Fatalf("unexpected status input: %+v", input)
+	}
+	if input.PaymentMethod != int32(types.PaymentMethod_PAYMENT_METHOD_PAYMENT_LINK) || input.PaymentType != int32(types.PaymentType_PAYMENT_TYPE_RECURRING) {
+		t.Fatalf("expected payment method and type on status input, got %+v", input)
+	}
+
+	updated, _ := repo.FindByID(context.Background(), 1)
+	if updated.Status != int32(types.PaymentStatus_PAYMENT_STATUS_PAID) {
+		t.Fatalf("expected paid status after reconcile, got %d", updated.Status)
+	}
+}
+
+func TestBackfillProviderEventsAppliesUnprocessedEventsOldestFirst(t *testing.T) {
+	repo := newServicePaymentRepo()
+	now := time.Now().UTC().Add(-2 * time.Hour)
+	subscriptionID := "sub_2"
+	for id, hash := range map[uint64]string{1: "hash-1", 2: "hash-2"} {
+		repo.payments[id] = &entity.Payment{
+			ID:                   id,
+			RequestID:            fmt.Sprintf("req-%d", id),
+			CallerService:        "subscriptions-service",
+			Status:               int32(types.PaymentStatus_PAYMENT_STATUS_PENDING),
+			Provider:             int32(types.ProviderType_PROVIDER_TYPE_STRIPE),
+			ProviderCallbackHash: hash,
+			StatusCallbackURL:    "https://caller.example/status",
+			Metadata:             map[string]string{},
+			CreatedAt:            now,
+			UpdatedAt:            now,
+		}
+	}
+	repo.payments[2].PaymentType = int32(types.PaymentType_PAYMENT_TYPE_RECURRING)
+	repo.payments[2].ProviderSubscriptionID = &subscriptionID
+
+	strPtr := func(v string) *string { return &v }
+	paid := int32(types.PaymentStatus_PAYMENT_STATUS_PAID)
+	p := &serviceProvider{
+		eventsPage: 2,
+		events: []provider.ListedEvent{
+			{ID: "evt_4", Payload: []byte("evt_4")},
+			{ID: "evt_3", Payload: []byte("evt_3")},
+			{ID: "evt_2", Payload: []byte("evt_2")},
+			{ID: "evt_1", Payload: []byte("evt_1")},
+		},
+		parsed: map[string]*provider.CallbackEvent{
+			"evt_1": {ProviderEventID: strPtr("evt_1"), EventType: "checkout.session.completed", NewStatus: paid, CallbackHash: strPtr("hash-1")},
+			"evt_2": {ProviderEventID: strPtr("evt_2"), EventType: "checkout.session.completed", NewStatus: paid, CallbackHash: strPtr("hash-1")},
+			"evt_3": {ProviderEventID: strPtr("evt_3"), EventType: "invoice.paid", NewStatus: paid, ProviderSubscriptionID: strPtr("sub_2")},
+			"evt_4": {ProviderEventID: strPtr("evt_4"), EventType: "checkout.session.completed", NewStatus: paid, CallbackHash: strPtr("hash-unknown")},
+		},
+	}
+	providerEventRepo := &serviceProviderEventRepo{events: []*entity.PaymentProviderEvent{
+		{ID: 1, Provider: int32(types.ProviderType_PROVIDER_TYPE_STRIPE), ProviderEventID: "evt_2", PaymentID: 1},
+	}}
+	callbackRepo := &serviceCallbackRepo{}
+	svc := NewPaymentService(
+		repo,
+		&serviceEventRepo{},
+		callbackRepo,
+		&serviceRefundRepo{},
+		&serviceChargeRepo{},
+		providerEventRepo,
+		&serviceOutboxRepo{},
+		&serviceUnitOfWork{},
+		provider.NewRegistry(p),
+		newSinkRegistryForTest(),
+		config.PaymentsConfig{CallbackRetryInterval: time.Second, CallbackMaxAttempts: 3, JobBatchSize: 100},
+	)
+
+	summary, err := svc.BackfillProviderEvents(context.Background(), "stripe", now)
+	if err != nil {
+		t.Fatalf("backfill failed: %v", err)
+	}
+	if summary.Fetched != 4 || summary.Applied != 2 || summary.AlreadyProcessed != 1 || summary.Unmatched != 1 || summary.Failed != 0 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+	if len(summary.Changes) != 2 || summary.Changes[0].PaymentID != 1 || summary.Changes[1].PaymentID != 2 {
+		t.Fatalf("expected changes for payments 1 then 2, got %+v", summary.Changes)
+	}
+	if summary.Changes[1].ProviderEventID != "evt_3" || summary.Changes[1].NewStatus != paid {
+		t.Fatalf("unexpected subscription change: %+v", summary.Changes[1])
+	}
+	for _, id := range []uint64{1, 2} {
+		if repo.payments[id].Status != paid {
+			t.Fatalf("expected payment %d to be paid, got %d", id, repo.payments[id].Status)
+		}
+	}
+	if len(callbackRepo.callbacks) != 2 {
+		t.Fatalf("expected backfilled events in the callback inbox, got %d", len(callbackRepo.callbacks))
+	}
+}
+
+func TestBackfillProviderEventsClaimsListedIDForEventsWithoutID(t *testing.T) {
+	repo := newServicePaymentRepo()
+	now := time.Now().UTC().Add(-2 * time.Hour)
+	repo.payments[1] = &entity.Payment{
+		ID:                   1,
+		RequestID:            "req-1",
+		CallerService:        "subscriptions-service",
+		Status:               int32(types.PaymentStatus_PAYMENT_STATUS_PENDING),
+		Provider:             int32(types.ProviderType_PROVIDER_TYPE_STRIPE),
+		ProviderCallbackHash: "hash-1",
+		StatusCallbackURL:    "https://caller.example/status",
+		Metadata:             map[string]string{},
+		CreatedAt:            now,
+		UpdatedAt:            now,
+	}
+
+	hash := "hash-1"
+	p := &serviceProvider{
+		events: []provider.ListedEvent{{ID: "evt_1", Payload: []byte("evt_1")}},
+		parsed: map[string]*provider.CallbackEvent{
+			"evt_1": {EventType: "checkout.session.completed", NewStatus: int32(types.PaymentStatus_PAYMENT_STATUS_PAID), CallbackHash: &hash},
+		},
+	}
+	providerEventRepo := &serviceProviderEventRepo{}
+	svc := NewPaymentService(
+		repo,
+		&serviceEventRepo{},
+		&serviceCallbackRepo{},
+		&serviceRefundRepo{},
+		&serviceChargeRepo{},
+		providerEventRepo,
+		&serviceOutboxRepo{},
+		&serviceUnitOfWork{},
+		provider.NewRegistry(p),
+		newSinkRegistryForTest(),
+		config.PaymentsConfig{CallbackRetryInterval: time.Second, CallbackMaxAttempts: 3, JobBatchSize: 100},
+	)
+
+	summary, err := svc.BackfillProviderEvents(context.Background(), "stripe", now)
+	if err != nil {
+		t.Fatalf("backfill failed: %v", err)
+	}
+	if summary.Applied != 1 {
+		t.Fatalf("expected the event to be applied, got %+v", summary)
+	}
+	if len(providerEventRepo.events) != 1 || providerEventRepo.events[0].ProviderEventID != "evt_1" {
+		t.Fatalf("expected the listed event ID to be claimed, got %+v", providerEventRepo.events)
+	}
+
+	summary, err = svc.BackfillProviderEvents(context.Background(), "stripe", now)
+	if err != nil {
+		t.Fatalf("second backfill failed: %v", err)
+	}
+	if summary.Applied != 0 || summary.AlreadyProcessed != 1 {
+		t.Fatalf("expected the event to be skipped on the second run, got %+v", summary)
+	}
+}
+
+func TestRunDispatchCallbacksBatchSuccess(t *testing.T) {
+	repo := newServicePaymentRepo()
+	now := time.Now().UTC()
+	nextAt := now.Add(-time.Second)
+	repo.payments[1] = &entity.Payment{
+		ID:                   1,
+		RequestID:            "req-1",
+		CallerService:        "subscriptions-service",
+		Status:               int32(types.PaymentStatus_PAYMENT_STATUS_PAID),
+		Provider:             int32(types.ProviderType_PROVIDER_TYPE_STRIPE),
+		ProviderCallbackHash: "hash-1",
+		ProviderCallbackURL:  "https://gateway.example/callback/hash-1",
+		StatusCallbackURL:    "http://localhost/callback",
+		Metadata:             map[string]string{},
+		CreatedAt:            now.Add(-time.Hour),
+		UpdatedAt:            now.Add(-time.Hour),
 	}
 
 	callbackServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Header.Get("X-Request-ID") != "req-1" {
 			t.Fatalf("expected callback request to include x-request-id=req-1, got %q", r.Header.Get("X-Request-ID"))
 		}
+		if r.Header.Get("X-Callback-Event") != callbackEventPaymentStatus {
+			t.Fatalf("expected callback event header, got %q", r.Header.Get("X-Callback-Event"))
+		}
 		w.WriteHeader(http.StatusOK)
 	}))
 	defer callbackServer.Close()
 
-	repo.payments[1].StatusCallbackURL = callbackServer.URL
+	outboxRepo := &serviceOutboxRepo{items: []*entity.PaymentCallbackOutbox{{
+		ID:            1,
+		PaymentID:     1,
+		EventType:     callbackEventPaymentStatus,
+		RequestID:     "req-1",
+		Destination:   callbackServer.URL,
+		PayloadJSON:   `{"payment":{"id":1}}`,
+		Status:        entity.CallbackDeliveryPending,
+		NextAttemptAt: &nextAt,
+	}}}
+	eventRepo := &serviceEventRepo{}
 
 	svc := NewPaymentService(
 		repo,
-		&serviceEventRepo{},
+		eventRepo,
 		&serviceCallbackRepo{},
+		&serviceRefundRepo{},
+		&serviceChargeRepo{},
+		&serviceProviderEventRepo{},
+		outboxRepo,
+		&serviceUnitOfWork{},
 		provider.NewRegistry(&serviceProvider{}),
+		newSinkRegistryForTest(),
 		config.PaymentsConfig{CallbackRetryInterval: time.Second, CallbackMaxAttempts: 3, JobBatchSize: 100},
-		"payments-app-key",
 	)
 
 	if err := svc.RunDispatchCallbacksBatch(context.Background()); err != nil {
 		t.Fatalf("run dispatch callbacks batch failed: %v", err)
 	}
 
-	updated, _ := repo.FindByID(context.Background(), 1)
-	if updated.CallbackDeliveryStatus != entity.CallbackDeliverySuccess {
-		t.Fatalf("expected callback delivery success, got %d", updated.CallbackDeliveryStatus)
+	delivered := outboxRepo.items[0]
+	if delivered.Status != entity.CallbackDeliverySuccess || delivered.DeliveredAt == nil {
+		t.Fatalf("expected callback delivery success, got %+v", delivered)
+	}
+	if len(eventRepo.events) != 1 || eventRepo.events[0].EventType != "callback_dispatched" {
+		t.Fatalf("expected callback_dispatched event, got %+v", eventRepo.events)
 	}
 }
 
@@ -494,19 +1924,17 @@ func TestRunDispatchCallbacksBatchFailureMarksFailed(t *testing.T) {
 	now := time.Now().UTC()
 	nextAt := now.Add(-time.Second)
 	repo.payments[1] = &entity.Payment{
-		ID:                     1,
-		RequestID:              "req-1",
-		CallerService:          "subscriptions-service",
-		Status:                 int32(types.PaymentStatus_PAYMENT_STATUS_FAILED),
-		Provider:               int32(types.ProviderType_PROVIDER_TYPE_STRIPE),
-		ProviderCallbackHash:   "hash-1",
-		ProviderCallbackURL:    "https://gateway.example/callback/hash-1",
-		StatusCallbackURL:      "http://localhost/callback",
-		Metadata:               map[string]string{},
-		CallbackDeliveryStatus: entity.CallbackDeliveryPending,
-		CallbackDeliveryNextAt: &nextAt,
-		CreatedAt:              now.Add(-time.Hour),
-		UpdatedAt:              now.Add(-time.Hour),
+		ID:                   1,
+		RequestID:            "req-1",
+		CallerService:        "subscriptions-service",
+		Status:               int32(types.PaymentStatus_PAYMENT_STATUS_FAILED),
+		Provider:             int32(types.ProviderType_PROVIDER_TYPE_STRIPE),
+		ProviderCallbackHash: "hash-1",
+		ProviderCallbackURL:  "https://gateway.example/callback/hash-1",
+		StatusCallbackURL:    "http://localhost/callback",
+		Metadata:             map[string]string{},
+		CreatedAt:            now.Add(-time.Hour),
+		UpdatedAt:            now.Add(-time.Hour),
 	}
 
 	callbackServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -514,15 +1942,29 @@ func TestRunDispatchCallbacksBatchFailureMarksFailed(t *testing.T) {
 	}))
 	defer callbackServer.Close()
 
-	repo.payments[1].StatusCallbackURL = callbackServer.URL
+	outboxRepo := &serviceOutboxRepo{items: []*entity.PaymentCallbackOutbox{{
+		ID:            1,
+		PaymentID:     1,
+		EventType:     callbackEventPaymentStatus,
+		RequestID:     "req-1",
+		Destination:   callbackServer.URL,
+		PayloadJSON:   `{"payment":{"id":1}}`,
+		Status:        entity.CallbackDeliveryPending,
+		NextAttemptAt: &nextAt,
+	}}}
 
 	svc := NewPaymentService(
 		repo,
 		&serviceEventRepo{},
 		&serviceCallbackRepo{},
+		&serviceRefundRepo{},
+		&serviceChargeRepo{},
+		&serviceProviderEventRepo{},
+		outboxRepo,
+		&serviceUnitOfWork{},
 		provider.NewRegistry(&serviceProvider{}),
+		newSinkRegistryForTest(),
 		config.PaymentsConfig{CallbackRetryInterval: time.Second, CallbackMaxAttempts: 1, JobBatchSize: 100},
-		"payments-app-key",
 	)
 
 	err := svc.RunDispatchCallbacksBatch(context.Background())
@@ -530,11 +1972,1683 @@ func TestRunDispatchCallbacksBatchFailureMarksFailed(t *testing.T) {
 		t.Fatal("expected dispatch callbacks batch to return error when callback endpoint fails")
 	}
 
-	updated, _ := repo.FindByID(context.Background(), 1)
-	if updated.CallbackDeliveryStatus != entity.CallbackDeliveryFailed {
-		t.Fatalf("expected callback delivery failed, got %d", updated.CallbackDeliveryStatus)
+	updated := outboxRepo.items[0]
+	if updated.Status != entity.CallbackDeliveryFailed {
+		t.Fatalf("expected callback delivery failed, got %d", updated.Status)
+	}
+	if updated.Attempts != 1 {
+		t.Fatalf("expected callback delivery attempts=1, got %d", updated.Attempts)
+	}
+}
+
+func TestRunDispatchCallbacksBatchSignsWithCallerSecrets(t *testing.T) {
+	repo := newServicePaymentRepo()
+	nextAt := time.Now().UTC().Add(-time.Second)
+	repo.payments[1] = &entity.Payment{ID: 1, RequestID: "req-1", CallerService: "subscriptions-service", Status: int32(types.PaymentStatus_PAYMENT_STATUS_PAID)}
+	repo.payments[2] = &entity.Payment{ID: 2, RequestID: "req-2", CallerService: "orders-service", Status: int32(types.PaymentStatus_PAYMENT_STATUS_PAID)}
+
+	testSink := &serviceSink{}
+	outboxRepo := &serviceOutboxRepo{items: []*entity.PaymentCallbackOutbox{
+		{ID: 1, PaymentID: 1, CallerService: "subscriptions-service", EventType: callbackEventPaymentStatus, RequestID: "req-1", Destination: "test://caller", PayloadJSON: `{"payment":{"id":1}}`, Status: entity.CallbackDeliveryPending, NextAttemptAt: &nextAt},
+		{ID: 2, PaymentID: 2, CallerService: "orders-service", EventType: callbackEventPaymentStatus, RequestID: "req-2", Destination: "test://caller", PayloadJSON: `{"payment":{"id":2}}`, Status: entity.CallbackDeliveryPending, NextAttemptAt: &nextAt},
+	}}
+
+	svc := NewPaymentService(
+		repo,
+		&serviceEventRepo{},
+		&serviceCallbackRepo{},
+		&serviceRefundRepo{},
+		&serviceChargeRepo{},
+		&serviceProviderEventRepo{},
+		outboxRepo,
+		&serviceUnitOfWork{},
+		provider.NewRegistry(&serviceProvider{}),
+		newSinkRegistryForTest(testSink),
+		config.PaymentsConfig{
+			CallbackRetryInterval:  time.Second,
+			CallbackMaxAttempts:    3,
+			JobBatchSize:           100,
+			CallbackSigningSecrets: map[string][]string{"subscriptions-service": {"whsec_new", "whsec_old"}},
+		},
+	)
+
+	if err := svc.RunDispatchCallbacksBatch(context.Background()); err != nil {
+		t.Fatalf("run dispatch callbacks batch failed: %v", err)
+	}
+	if len(testSink.messages) != 2 {
+		t.Fatalf("expected two delivered callbacks, got %d", len(testSink.messages))
+	}
+
+	signed := testSink.messages[0]
+	if err := callbacksig.Verify(signed.Body, signed.Signature, time.Minute, "whsec_old"); err != nil {
+		t.Fatalf("expected callback to verify with the previous secret, got %v", err)
+	}
+	if err := callbacksig.Verify(signed.Body, signed.Signature, time.Minute, "whsec_new"); err != nil {
+		t.Fatalf("expected callback to verify with the current secret, got %v", err)
+	}
+	if testSink.messages[1].Signature != "" {
+		t.Fatalf("expected caller without secrets to receive an unsigned callback, got %q", testSink.messages[1].Signature)
+	}
+}
+
+func TestDispatchCallbackSignsWithAttemptTime(t *testing.T) {
+	repo := newServicePaymentRepo()
+	repo.payments[1] = &entity.Payment{ID: 1, RequestID: "req-1", CallerService: "subscriptions-service", Status: int32(types.PaymentStatus_PAYMENT_STATUS_PAID)}
+
+	testSink := &serviceSink{}
+	batchStart := time.Now().UTC().Add(-time.Hour)
+	item := &entity.PaymentCallbackOutbox{ID: 1, PaymentID: 1, CallerService: "subscriptions-service", EventType: callbackEventPaymentStatus, RequestID: "req-1", Destination: "test://caller", PayloadJSON: `{"payment":{"id":1}}`, Status: entity.CallbackDeliveryPending, NextAttemptAt: &batchStart}
+	outboxRepo := &serviceOutboxRepo{items: []*entity.PaymentCallbackOutbox{item}}
+
+	svc := NewPaymentService(
+		repo,
+		&serviceEventRepo{},
+		&serviceCallbackRepo{},
+		&serviceRefundRepo{},
+		&serviceChargeRepo{},
+		&serviceProviderEventRepo{},
+		outboxRepo,
+		&serviceUnitOfWork{},
+		provider.NewRegistry(&serviceProvider{}),
+		newSinkRegistryForTest(testSink),
+		config.PaymentsConfig{
+			CallbackRetryInterval:  time.Second,
+			CallbackMaxAttempts:    3,
+			JobBatchSize:           100,
+			CallbackSigningSecrets: map[string][]string{"subscriptions-service": {"whsec_new"}},
+		},
+	)
+
+	// A batch that started an hour ago must still sign with a fresh timestamp.
+	if err := svc.dispatchCallback(context.Background(), item, batchStart); err != nil {
+		t.Fatalf("dispatch callback failed: %v", err)
+	}
+	if len(testSink.messages) != 1 {
+		t.Fatalf("expected one delivered callback, got %d", len(testSink.messages))
+	}
+	signed := testSink.messages[0]
+	if err := callbacksig.Verify(signed.Body, signed.Signature, callbacksig.DefaultTolerance, "whsec_new"); err != nil {
+		t.Fatalf("expected callback signature within tolerance, got %v", err)
+	}
+}
+
+func TestRunDispatchCallbacksBatchKeepsPerPaymentOrder(t *testing.T) {
+	repo := newServicePaymentRepo()
+	now := time.Now().UTC()
+	nextAt := now.Add(-time.Second)
+	repo.payments[1] = &entity.Payment{ID: 1, RequestID: "req-1", Status: int32(types.PaymentStatus_PAYMENT_STATUS_PAID)}
+	repo.payments[2] = &entity.Payment{ID: 2, RequestID: "req-2", Status: int32(types.PaymentStatus_PAYMENT_STATUS_PAID)}
+
+	testSink := &serviceSink{err: errors.New("caller unavailable")}
+	outboxRepo := &serviceOutboxRepo{items: []*entity.PaymentCallbackOutbox{
+		{ID: 1, PaymentID: 1, EventType: callbackEventPaymentStatus, RequestID: "req-1", Destination: "test://caller", Status: entity.CallbackDeliveryPending, NextAttemptAt: &nextAt},
+		{ID: 2, PaymentID: 1, EventType: callbackEventPaymentRefund, RequestID: "req-1", Destination: "test://caller", Status: entity.CallbackDeliveryPending, NextAttemptAt: &nextAt},
+		{ID: 3, PaymentID: 2, EventType: callbackEventPaymentStatus, RequestID: "req-2", Destination: "test://caller", Status: entity.CallbackDeliveryPending, NextAttemptAt: &nextAt},
+	}}
+
+	svc := NewPaymentService(
+		repo,
+		&serviceEventRepo{},
+		&serviceCallbackRepo{},
+		&serviceRefundRepo{},
+		&serviceChargeRepo{},
+		&serviceProviderEventRepo{},
+		outboxRepo,
+		&serviceUnitOfWork{},
+		provider.NewRegistry(&serviceProvider{}),
+		newSinkRegistryForTest(testSink),
+		config.PaymentsConfig{CallbackRetryInterval: time.Minute, CallbackMaxAttempts: 3, JobBatchSize: 100},
+	)
+
+	if err := svc.RunDispatchCallbacksBatch(context.Background()); err == nil {
+		t.Fatal("expected dispatch error from failing sink")
+	}
+	if len(testSink.messages) != 2 || testSink.messages[0].ID != 1 || testSink.messages[1].ID != 3 {
+		t.Fatalf("expected only the head callback of each payment to be attempted, got %+v", testSink.messages)
+	}
+	if outboxRepo.items[0].Status != entity.CallbackDeliveryPending || outboxRepo.items[0].Attempts != 1 {
+		t.Fatalf("expected failed head callback to stay pending for retry, got %+v", outboxRepo.items[0])
+	}
+
+	// Once the head is delivered the queued refund callback becomes due.
+	testSink.err = nil
+	outboxRepo.items[0].NextAttemptAt = &nextAt
+	outboxRepo.items[2].NextAttemptAt = &nextAt
+	if err := svc.RunDispatchCallbacksBatch(context.Background()); err != nil {
+		t.Fatalf("run dispatch callbacks batch failed: %v", err)
+	}
+	if err := svc.RunDispatchCallbacksBatch(context.Background()); err != nil {
+		t.Fatalf("run dispatch callbacks batch failed: %v", err)
+	}
+	for _, item := range outboxRepo.items {
+		if item.Status != entity.CallbackDeliverySuccess {
+			t.Fatalf("expected all callbacks delivered, got %+v", item)
+		}
+	}
+	if last := testSink.messages[len(testSink.messages)-1]; last.ID != 2 || last.EventType != callbackEventPaymentRefund {
+		t.Fatalf("expected refund callback to be delivered last, got %+v", last)
+	}
+}
+
+func TestRunDispatchCallbacksBatchHoldsBackBehindDeadLetter(t *testing.T) {
+	repo := newServicePaymentRepo()
+	nextAt := time.Now().UTC().Add(-time.Second)
+	repo.payments[1] = &entity.Payment{ID: 1, RequestID: "req-1", Status: int32(types.PaymentStatus_PAYMENT_STATUS_PAID)}
+
+	testSink := &serviceSink{}
+	outboxRepo := &serviceOutboxRepo{items: []*entity.PaymentCallbackOutbox{
+		{ID: 1, PaymentID: 1, EventType: callbackEventPaymentStatus, RequestID: "req-1", Destination: "test://caller", Status: entity.CallbackDeliveryFailed, Attempts: 3},
+		{ID: 2, PaymentID: 1, EventType: callbackEventPaymentRefund, RequestID: "req-1", Destination: "test://caller", Status: entity.CallbackDeliveryPending, NextAttemptAt: &nextAt},
+	}}
+	svc := NewPaymentService(
+		repo,
+		&serviceEventRepo{},
+		&serviceCallbackRepo{},
+		&serviceRefundRepo{},
+		&serviceChargeRepo{},
+		&serviceProviderEventRepo{},
+		outboxRepo,
+		&serviceUnitOfWork{},
+		provider.NewRegistry(&serviceProvider{}),
+		newSinkRegistryForTest(testSink),
+		config.PaymentsConfig{CallbackRetryInterval: time.Minute, CallbackMaxAttempts: 3, JobBatchSize: 100},
+	)
+
+	if err := svc.RunDispatchCallbacksBatch(context.Background()); err != nil {
+		t.Fatalf("run dispatch callbacks batch failed: %v", err)
+	}
+	if len(testSink.messages) != 0 {
+		t.Fatalf("expected the refund callback to wait behind the dead letter, got %+v", testSink.messages)
+	}
+
+	if _, err := svc.RedeliverCallbacks(context.Background(), &types.RedeliverCallbacksRequest{PaymentId: 1}); err != nil {
+		t.Fatalf("redeliver callbacks failed: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := svc.RunDispatchCallbacksBatch(context.Background()); err != nil {
+			t.Fatalf("run dispatch callbacks batch failed: %v", err)
+		}
+	}
+	if len(testSink.messages) != 2 || testSink.messages[0].ID != 1 || testSink.messages[1].ID != 2 {
+		t.Fatalf("expected requeued callback before the refund callback, got %+v", testSink.messages)
+	}
+}
+
+// racingOutboxRepo lets another dispatcher claim every callback between
+// listing and claiming.
+type racingOutboxRepo struct {
+	*serviceOutboxRepo
+}
+
+func (r *racingOutboxRepo) ListDue(ctx context.Context, now time.Time, limit int32) ([]*entity.PaymentCallbackOutbox, error) {
+	items, err := r.serviceOutboxRepo.ListDue(ctx, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		other := *item
+		if _, err := r.serviceOutboxRepo.Claim(ctx, &other, now, now.Add(time.Minute)); err != nil {
+			return nil, err
+		}
+	}
+	return items, nil
+}
+
+func TestRunDispatchCallbacksBatchSkipsCallbacksClaimedElsewhere(t *testing.T) {
+	repo := newServicePaymentRepo()
+	nextAt := time.Now().UTC().Add(-time.Second)
+	repo.payments[1] = &entity.Payment{ID: 1, RequestID: "req-1", Status: int32(types.PaymentStatus_PAYMENT_STATUS_PAID)}
+
+	testSink := &serviceSink{}
+	outboxRepo := &serviceOutboxRepo{items: []*entity.PaymentCallbackOutbox{
+		{ID: 1, PaymentID: 1, EventType: callbackEventPaymentStatus, RequestID: "req-1", Destination: "test://caller", Status: entity.CallbackDeliveryPending, NextAttemptAt: &nextAt},
+	}}
+	svc := NewPaymentService(
+		repo,
+		&serviceEventRepo{},
+		&serviceCallbackRepo{},
+		&serviceRefundRepo{},
+		&serviceChargeRepo{},
+		&serviceProviderEventRepo{},
+		&racingOutboxRepo{serviceOutboxRepo: outboxRepo},
+		&serviceUnitOfWork{},
+		provider.NewRegistry(&serviceProvider{}),
+		newSinkRegistryForTest(testSink),
+		config.PaymentsConfig{CallbackRetryInterval: time.Minute, CallbackMaxAttempts: 3, JobBatchSize: 100},
+	)
+
+	if err := svc.RunDispatchCallbacksBatch(context.Background()); err != nil {
+		t.Fatalf("run dispatch callbacks batch failed: %v", err)
+	}
+	if len(testSink.messages) != 0 {
+		t.Fatalf("expected a callback claimed by another dispatcher to be skipped, got %+v", testSink.messages)
+	}
+	if item := outboxRepo.items[0]; item.Status != entity.CallbackDeliveryPending || item.Attempts != 0 {
+		t.Fatalf("expected the claimed callback to be left to its claimant, got %+v", item)
+	}
+}
+
+func TestRunDispatchCallbacksBatchRejectsUnknownScheme(t *testing.T) {
+	repo := newServicePaymentRepo()
+	nextAt := time.Now().UTC().Add(-time.Second)
+	repo.payments[1] = &entity.Payment{ID: 1, RequestID: "req-1", Status: int32(types.PaymentStatus_PAYMENT_STATUS_PAID)}
+	outboxRepo := &serviceOutboxRepo{items: []*entity.PaymentCallbackOutbox{{
+		ID:            1,
+		PaymentID:     1,
+		EventType:     callbackEventPaymentStatus,
+		RequestID:     "req-1",
+		Destination:   "amqp://queue",
+		Status:        entity.CallbackDeliveryPending,
+		NextAttemptAt: &nextAt,
+	}}}
+
+	svc := NewPaymentService(
+		repo,
+		&serviceEventRepo{},
+		&serviceCallbackRepo{},
+		&serviceRefundRepo{},
+		&serviceChargeRepo{},
+		&serviceProviderEventRepo{},
+		outboxRepo,
+		&serviceUnitOfWork{},
+		provider.NewRegistry(&serviceProvider{}),
+		newSinkRegistryForTest(),
+		config.PaymentsConfig{CallbackRetryInterval: time.Second, CallbackMaxAttempts: 1, JobBatchSize: 100},
+	)
+
+	err := svc.RunDispatchCallbacksBatch(context.Background())
+	if !errors.Is(err, sink.ErrSinkNotConfigured) {
+		t.Fatalf("expected ErrSinkNotConfigured, got %v", err)
+	}
+	if outboxRepo.items[0].Status != entity.CallbackDeliveryFailed {
+		t.Fatalf("expected callback to be marked failed, got %d", outboxRepo.items[0].Status)
+	}
+}
+
+func TestRefundPaymentPartialThenExceeds(t *testing.T) {
+	repo := newServicePaymentRepo()
+	now := time.Now().UTC().Add(-time.Hour)
+	providerPaymentID := "cs_test_123"
+	repo.payments[1] = &entity.Payment{
+		ID:                1,
+		RequestID:         "req-1",
+		CallerService:     "subscriptions-service",
+		AmountCents:       1000,
+		Currency:          "USD",
+		Status:            int32(types.PaymentStatus_PAYMENT_STATUS_PAID),
+		Provider:          int32(types.ProviderType_PROVIDER_TYPE_STRIPE),
+		ProviderPaymentID: &providerPaymentID,
+		RefundableCents:   1000,
+		Metadata:          map[string]string{},
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+	eventRepo := &serviceEventRepo{}
+	refundRepo := &serviceRefundRepo{}
+	p := &serviceProvider{}
+	svc := NewPaymentService(
+		repo,
+		eventRepo,
+		&serviceCallbackRepo{},
+		refundRepo,
+		&serviceChargeRepo{},
+		&serviceProviderEventRepo{},
+		&serviceOutboxRepo{},
+		&serviceUnitOfWork{},
+		provider.NewRegistry(p),
+		newSinkRegistryForTest(),
+		config.PaymentsConfig{CallbackRetryInterval: time.Second, CallbackMaxAttempts: 3, JobBatchSize: 100},
+	)
+
+	payment, refund, err := svc.RefundPayment(context.Background(), &types.RefundPaymentRequest{Id: 1, RequestId: "refund-1", AmountCents: 400, Reason: "requested_by_customer"})
+	if err != nil {
+		t.Fatalf("refund payment failed: %v", err)
+	}
+	if refund.ProviderRefundID == nil || *refund.ProviderRefundID != "re_test_123" {
+		t.Fatalf("expected provider refund id to be stored, got %+v", refund)
+	}
+	if p.refundInputs[0].IdempotencyKey != "subscriptions-service:req-1:refund:refund-1" {
+		t.Fatalf("unexpected refund idempotency key: %s", p.refundInputs[0].IdempotencyKey)
+	}
+	if payment.RefundedCents != 400 || payment.RefundableCents != 600 {
+		t.Fatalf("unexpected refund totals: refunded=%d refundable=%d", payment.RefundedCents, payment.RefundableCents)
+	}
+	if len(eventRepo.events) != 1 || eventRepo.events[0].EventType != "payment_refund_created" {
+		t.Fatalf("expected refund event, got %+v", eventRepo.events)
+	}
+
+	again, _, err := svc.RefundPayment(context.Background(), &types.RefundPaymentRequest{Id: 1, RequestId: "refund-1", AmountCents: 400})
+	if err != nil {
+		t.Fatalf("idempotent refund failed: %v", err)
+	}
+	if again.RefundedCents != 400 || len(p.refundInputs) != 1 {
+		t.Fatalf("expected repeated request_id to be idempotent, refunded=%d provider_calls=%d", again.RefundedCents, len(p.refundInputs))
+	}
+
+	_, _, err = svc.RefundPayment(context.Background(), &types.RefundPaymentRequest{Id: 1, RequestId: "refund-2", AmountCents: 700})
+	if !errors.Is(err, ErrRefundExceedsAmount) {
+		t.Fatalf("expected ErrRefundExceedsAmount, got %v", err)
+	}
+}
+
+func TestRefundPaymentTakesOverRefundStoredByWebhook(t *testing.T) {
+	repo := newServicePaymentRepo()
+	now := time.Now().UTC().Add(-time.Hour)
+	providerPaymentID := "cs_test_123"
+	providerRefundID := "re_test_123"
+	repo.payments[1] = &entity.Payment{
+		ID:                1,
+		RequestID:         "req-1",
+		CallerService:     "subscriptions-service",
+		AmountCents:       1000,
+		Currency:          "USD",
+		Status:            int32(types.PaymentStatus_PAYMENT_STATUS_PAID),
+		Provider:          int32(types.ProviderType_PROVIDER_TYPE_STRIPE),
+		ProviderPaymentID: &providerPaymentID,
+		RefundedCents:     400,
+		RefundableCents:   600,
+		Metadata:          map[string]string{},
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+	// The refund webhook was applied before RefundPayment stored its result.
+	refundRepo := &serviceRefundRepo{refunds: []*entity.PaymentRefund{{
+		ID:               1,
+		PaymentID:        1,
+		RequestID:        providerRefundID,
+		AmountCents:      400,
+		Currency:         "USD",
+		Status:           int32(types.RefundStatus_REFUND_STATUS_SUCCEEDED),
+		ProviderRefundID: &providerRefundID,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}}}
+	svc := NewPaymentService(
+		repo,
+		&serviceEventRepo{},
+		&serviceCallbackRepo{},
+		refundRepo,
+		&serviceChargeRepo{},
+		&serviceProviderEventRepo{},
+		&serviceOutboxRepo{},
+		&serviceUnitOfWork{},
+		provider.NewRegistry(&serviceProvider{}),
+		newSinkRegistryForTest(),
+		config.PaymentsConfig{CallbackRetryInterval: time.Second, CallbackMaxAttempts: 3, JobBatchSize: 100},
+	)
+
+	payment, refund, err := svc.RefundPayment(context.Background(), &types.RefundPaymentRequest{Id: 1, RequestId: "refund-1", AmountCents: 400, Reason: "requested_by_customer"})
+	if err != nil {
+		t.Fatalf("refund payment failed: %v", err)
+	}
+	if len(refundRepo.refunds) != 1 {
+		t.Fatalf("expected the webhook refund to be reused, got %d refunds", len(refundRepo.refunds))
+	}
+	if refund.ID != 1 || refund.RequestID != "refund-1" || refundRepo.refunds[0].RequestID != "refund-1" {
+		t.Fatalf("expected the refund to carry the caller request id, got %+v", refundRepo.refunds[0])
+	}
+	if payment.RefundedCents != 400 || payment.RefundableCents != 600 {
+		t.Fatalf("unexpected refund totals: refunded=%d refundable=%d", payment.RefundedCents, payment.RefundableCents)
+	}
+}
+
+// conflictingRefundProvider changes the payment while the refund is issued,
+// like a webhook applied between the provider call and the local write.
+type conflictingRefundProvider struct {
+	*serviceProvider
+	repo *servicePaymentRepo
+}
+
+func (p *conflictingRefundProvider) Refund(ctx context.Context, input *provider.RefundInput) (*provider.RefundOutput, error) {
+	p.repo.payments[input.PaymentID].Version++
+	return p.serviceProvider.Refund(ctx, input)
+}
+
+func TestRefundPaymentRecordsRefundAfterVersionConflict(t *testing.T) {
+	repo := newServicePaymentRepo()
+	now := time.Now().UTC().Add(-time.Hour)
+	providerPaymentID := "cs_test_123"
+	repo.payments[1] = &entity.Payment{
+		ID:                1,
+		RequestID:         "req-1",
+		CallerService:     "subscriptions-service",
+		AmountCents:       1000,
+		Currency:          "USD",
+		Status:            int32(types.PaymentStatus_PAYMENT_STATUS_PAID),
+		Provider:          int32(types.ProviderType_PROVIDER_TYPE_STRIPE),
+		ProviderPaymentID: &providerPaymentID,
+		RefundableCents:   1000,
+		Metadata:          map[string]string{},
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+	refundRepo := &serviceRefundRepo{}
+	p := &conflictingRefundProvider{serviceProvider: &serviceProvider{}, repo: repo}
+	svc := NewPaymentService(
+		repo,
+		&serviceEventRepo{},
+		&serviceCallbackRepo{},
+		refundRepo,
+		&serviceChargeRepo{},
+		&serviceProviderEventRepo{},
+		&serviceOutboxRepo{},
+		&serviceUnitOfWork{},
+		provider.NewRegistry(p),
+		newSinkRegistryForTest(),
+		config.PaymentsConfig{CallbackRetryInterval: time.Second, CallbackMaxAttempts: 3, JobBatchSize: 100},
+	)
+
+	payment, refund, err := svc.RefundPayment(context.Background(), &types.RefundPaymentRequest{Id: 1, RequestId: "refund-1", AmountCents: 400})
+	if err != nil {
+		t.Fatalf("refund payment failed: %v", err)
+	}
+	if len(p.refundInputs) != 1 {
+		t.Fatalf("expected a single provider refund, got %d", len(p.refundInputs))
+	}
+	if refund == nil || len(refundRepo.refunds) != 1 || refundRepo.refunds[0].RequestID != "refund-1" {
+		t.Fatalf("expected the refund to be recorded, got %+v", refundRepo.refunds)
+	}
+	if payment.RefundedCents != 400 || repo.payments[1].RefundedCents != 400 || repo.payments[1].RefundableCents != 600 {
+		t.Fatalf("unexpected stored refund totals: %+v", repo.payments[1])
+	}
+}
+
+func TestRefundPaymentRequiresPaidStatus(t *testing.T) {
+	repo := newServicePaymentRepo()
+	repo.payments[1] = &entity.Payment{ID: 1, AmountCents: 1000, RefundableCents: 1000, Status: int32(types.PaymentStatus_PAYMENT_STATUS_PENDING)}
+	svc := newPaymentServiceForTest(repo, &serviceEventRepo{}, &serviceCallbackRepo{}, &serviceProvider{})
+
+	_, _, err := svc.RefundPayment(context.Background(), &types.RefundPaymentRequest{Id: 1, RequestId: "refund-1"})
+	if !errors.Is(err, ErrInvalidStatus) {
+		t.Fatalf("expected ErrInvalidStatus, got %v", err)
+	}
+}
+
+func TestHandleProviderCallbackRefundUpdatedAdjustsTotals(t *testing.T) {
+	repo := newServicePaymentRepo()
+	now := time.Now().UTC().Add(-time.Hour)
+	providerRefundID := "re_test_1"
+	repo.payments[1] = &entity.Payment{
+		ID:                   1,
+		RequestID:            "req-1",
+		CallerService:        "subscriptions-service",
+		AmountCents:          1000,
+		Currency:             "USD",
+		Status:               int32(types.PaymentStatus_PAYMENT_STATUS_PAID),
+		Provider:             int32(types.ProviderType_PROVIDER_TYPE_STRIPE),
+		ProviderCallbackHash: "hash-1",
+		RefundableCents:      700,
+		Metadata:             map[string]string{},
+		CreatedAt:            now,
+		UpdatedAt:            now,
+	}
+	refundRepo := &serviceRefundRepo{refunds: []*entity.PaymentRefund{{
+		ID:               1,
+		PaymentID:        1,
+		RequestID:        "refund-1",
+		AmountCents:      300,
+		Currency:         "USD",
+		Status:           int32(types.RefundStatus_REFUND_STATUS_PENDING),
+		ProviderRefundID: &providerRefundID,
+	}}}
+	svc := NewPaymentService(
+		repo,
+		&serviceEventRepo{},
+		&serviceCallbackRepo{},
+		refundRepo,
+		&serviceChargeRepo{},
+		&serviceProviderEventRepo{},
+		&serviceOutboxRepo{},
+		&serviceUnitOfWork{},
+		provider.NewRegistry(&serviceProvider{callbackEvt: &provider.CallbackEvent{
+			EventType: "refund.updated",
+			Refund: &provider.CallbackRefund{
+				ProviderRefundID: providerRefundID,
+				AmountCents:      300,
+				Status:           int32(types.RefundStatus_REFUND_STATUS_FAILED),
+			},
+		}}),
+		newSinkRegistryForTest(),
+		config.PaymentsConfig{CallbackRetryInterval: time.Second, CallbackMaxAttempts: 3, JobBatchSize: 100},
+	)
+
+	payment, err := svc.HandleProviderCallback(context.Background(), &types.HandleProviderCallbackRequest{
+		RequestId:    "cb-1",
+		Provider:     "stripe",
+		CallbackHash: "hash-1",
+		Signature:    "valid-signature",
+		Payload:      `{"id":"evt_1"}`,
+	})
+	if err != nil {
+		t.Fatalf("handle callback failed: %v", err)
+	}
+	if payment.Status != int32(types.PaymentStatus_PAYMENT_STATUS_PAID) {
+		t.Fatalf("expected refund callback to keep paid status, got %d", payment.Status)
+	}
+	if payment.RefundedCents != 0 || payment.RefundableCents != 1000 {
+		t.Fatalf("expected failed refund to release reserved amount, refunded=%d refundable=%d", payment.RefundedCents, payment.RefundableCents)
+	}
+	if refundRepo.refunds[0].Status != int32(types.RefundStatus_REFUND_STATUS_FAILED) {
+		t.Fatalf("expected refund status to be updated, got %d", refundRepo.refunds[0].Status)
+	}
+}
+
+func TestCancelPaymentCancelsAtProvider(t *testing.T) {
+	repo := newServicePaymentRepo()
+	providerPaymentID := "plink_test_1"
+	repo.payments[1] = &entity.Payment{
+		ID:                1,
+		RequestID:         "req-1",
+		CallerService:     "orders-service",
+		Version:           2,
+		Status:            int32(types.PaymentStatus_PAYMENT_STATUS_PENDING),
+		PaymentMethod:     int32(types.PaymentMethod_PAYMENT_METHOD_PAYMENT_LINK),
+		PaymentType:       int32(types.PaymentType_PAYMENT_TYPE_ONE_TIME),
+		Provider:          int32(types.ProviderType_PROVIDER_TYPE_STRIPE),
+		ProviderPaymentID: &providerPaymentID,
+	}
+	p := &serviceProvider{}
+	svc := newPaymentServiceForTest(repo, &serviceEventRepo{}, &serviceCallbackRepo{}, p)
+
+	payment, err := svc.CancelPayment(context.Background(), &types.CancelPaymentRequest{Id: 1, Reason: "duplicate"})
+	if err != nil {
+		t.Fatalf("cancel payment failed: %v", err)
+	}
+	if payment.Status != int32(types.PaymentStatus_PAYMENT_STATUS_CANCELED) {
+		t.Fatalf("expected canceled status, got %d", payment.Status)
+	}
+	if len(p.cancelInputs) != 1 || p.cancelInputs[0].ProviderPaymentID != providerPaymentID {
+		t.Fatalf("expected provider cancel for payment link, got %+v", p.cancelInputs)
+	}
+	if p.cancelInputs[0].IdempotencyKey != "orders-service:req-1:cancel:v2" {
+		t.Fatalf("unexpected cancel idempotency key: %s", p.cancelInputs[0].IdempotencyKey)
+	}
+}
+
+func TestCancelPaymentProviderFailureKeepsStatus(t *testing.T) {
+	repo := newServicePaymentRepo()
+	providerPaymentID := "cs_test_1"
+	repo.payments[1] = &entity.Payment{
+		ID:                1,
+		Status:            int32(types.PaymentStatus_PAYMENT_STATUS_PENDING),
+		PaymentMethod:     int32(types.PaymentMethod_PAYMENT_METHOD_HOSTED_CARD),
+		PaymentType:       int32(types.PaymentType_PAYMENT_TYPE_ONE_TIME),
+		Provider:          int32(types.ProviderType_PROVIDER_TYPE_STRIPE),
+		ProviderPaymentID: &providerPaymentID,
+	}
+	eventRepo := &serviceEventRepo{}
+	svc := newPaymentServiceForTest(repo, eventRepo, &serviceCallbackRepo{}, &serviceProvider{cancelErr: errors.New("stripe unavailable")})
+
+	_, err := svc.CancelPayment(context.Background(), &types.CancelPaymentRequest{Id: 1})
+	if !errors.Is(err, ErrProviderCancelFailed) {
+		t.Fatalf("expected ErrProviderCancelFailed, got %v", err)
+	}
+
+	stored, _ := repo.FindByID(context.Background(), 1)
+	if stored.Status != int32(types.PaymentStatus_PAYMENT_STATUS_PENDING) {
+		t.Fatalf("expected status to stay pending, got %d", stored.Status)
+	}
+	if len(eventRepo.events) != 1 || eventRepo.events[0].EventType != "payment_cancel_failed" {
+		t.Fatalf("expected payment_cancel_failed event, got %+v", eventRepo.events)
+	}
+}
+
+func TestHandleProviderCallbackInvoicePaidRecordsChargeAndKeepsParent(t *testing.T) {
+	repo := newServicePaymentRepo()
+	now := time.Now().UTC().Add(-time.Hour)
+	repo.payments[1] = &entity.Payment{
+		ID:                   1,
+		RequestID:            "req-1",
+		CallerService:        "subscriptions-service",
+		AmountCents:          1999,
+		Currency:             "USD",
+		Status:               int32(types.PaymentStatus_PAYMENT_STATUS_PAID),
+		PaymentType:          int32(types.PaymentType_PAYMENT_TYPE_RECURRING),
+		Provider:             int32(types.ProviderType_PROVIDER_TYPE_STRIPE),
+		ProviderCallbackHash: "hash-1",
+		StatusCallbackURL:    "https://caller.example/status",
+		Metadata:             map[string]string{},
+		CreatedAt:            now,
+		UpdatedAt:            now,
+	}
+	periodStart := now
+	periodEnd := now.AddDate(0, 1, 0)
+	chargeRepo := &serviceChargeRepo{}
+	outboxRepo := &serviceOutboxRepo{}
+	p := &serviceProvider{callbackEvt: &provider.CallbackEvent{
+		EventType: "invoice.payment_failed",
+		NewStatus: int32(types.PaymentStatus_PAYMENT_STATUS_FAILED),
+		Invoice: &provider.CallbackInvoice{
+			ProviderInvoiceID: "in_2",
+			AmountCents:       1999,
+			Currency:          "USD",
+			Status:            int32(types.PaymentStatus_PAYMENT_STATUS_FAILED),
+			PeriodStart:       &periodStart,
+			PeriodEnd:         &periodEnd,
+		},
+	}}
+	svc := NewPaymentService(
+		repo,
+		&serviceEventRepo{},
+		&serviceCallbackRepo{},
+		&serviceRefundRepo{},
+		chargeRepo,
+		&serviceProviderEventRepo{},
+		outboxRepo,
+		&serviceUnitOfWork{},
+		provider.NewRegistry(p),
+		newSinkRegistryForTest(),
+		config.PaymentsConfig{CallbackRetryInterval: time.Second, CallbackMaxAttempts: 3, JobBatchSize: 100},
+	)
+
+	callbackReq := &types.HandleProviderCallbackRequest{
+		RequestId:    "cb-1",
+		Provider:     "stripe",
+		CallbackHash: "hash-1",
+		Signature:    "valid-signature",
+		Payload:      `{"id":"evt_1"}`,
+	}
+	payment, err := svc.HandleProviderCallback(context.Background(), callbackReq)
+	if err != nil {
+		t.Fatalf("handle callback failed: %v", err)
+	}
+	if payment.Status != int32(types.PaymentStatus_PAYMENT_STATUS_PAID) {
+		t.Fatalf("expected renewal failure to keep parent paid, got %d", payment.Status)
+	}
+	if len(chargeRepo.charges) != 1 {
+		t.Fatalf("expected one charge, got %d", len(chargeRepo.charges))
+	}
+	charge := chargeRepo.charges[0]
+	if charge.Status != int32(types.PaymentStatus_PAYMENT_STATUS_FAILED) {
+		t.Fatalf("expected failed charge, got %+v", charge)
+	}
+	if len(outboxRepo.items) != 1 || outboxRepo.items[0].EventType != callbackEventChargeStatus {
+		t.Fatalf("expected only a charge callback to be queued, got %+v", outboxRepo.items)
+	}
+	if outboxRepo.items[0].ChargeID == nil || *outboxRepo.items[0].ChargeID != charge.ID || outboxRepo.items[0].RequestID != "req-1:in_2" {
+		t.Fatalf("unexpected charge callback: %+v", outboxRepo.items[0])
+	}
+
+	p.callbackEvt.EventType = "invoice.paid"
+	p.callbackEvt.NewStatus = int32(types.PaymentStatus_PAYMENT_STATUS_PAID)
+	p.callbackEvt.Invoice.Status = int32(types.PaymentStatus_PAYMENT_STATUS_PAID)
+	if _, err := svc.HandleProviderCallback(context.Background(), callbackReq); err != nil {
+		t.Fatalf("handle retry callback failed: %v", err)
+	}
+
+	charges, err := svc.ListPaymentCharges(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("list charges failed: %v", err)
+	}
+	if len(charges) != 1 || charges[0].Status != int32(types.PaymentStatus_PAYMENT_STATUS_PAID) {
+		t.Fatalf("expected retried invoice to update the same charge, got %+v", charges)
+	}
+	if len(outboxRepo.items) != 2 || outboxRepo.items[1].EventType != callbackEventChargeStatus {
+		t.Fatalf("expected the retried invoice to queue a second charge callback, got %+v", outboxRepo.items)
+	}
+}
+
+func TestRunDispatchCallbacksBatchDispatchesChargeCallbacks(t *testing.T) {
+	repo := newServicePaymentRepo()
+	now := time.Now().UTC()
+	repo.payments[1] = &entity.Payment{
+		ID:            1,
+		RequestID:     "req-1",
+		CallerService: "subscriptions-service",
+		Status:        int32(types.PaymentStatus_PAYMENT_STATUS_PAID),
+		PaymentType:   int32(types.PaymentType_PAYMENT_TYPE_RECURRING),
+		Provider:      int32(types.ProviderType_PROVIDER_TYPE_STRIPE),
+		Metadata:      map[string]string{},
+		CreatedAt:     now.Add(-time.Hour),
+		UpdatedAt:     now.Add(-time.Hour),
+	}
+	charge := &entity.PaymentCharge{
+		ID:                1,
+		PaymentID:         1,
+		ProviderInvoiceID: "in_1",
+		AmountCents:       1999,
+		Currency:          "USD",
+		Status:            int32(types.PaymentStatus_PAYMENT_STATUS_PAID),
+	}
+
+	callbackServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Request-ID") != "req-1:in_1" {
+			t.Fatalf("expected charge callback x-request-id=req-1:in_1, got %q", r.Header.Get("X-Request-ID"))
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer callbackServer.Close()
+	repo.payments[1].StatusCallbackURL = callbackServer.URL
+
+	outboxRepo := &serviceOutboxRepo{}
+	svc := NewPaymentService(
+		repo,
+		&serviceEventRepo{},
+		&serviceCallbackRepo{},
+		&serviceRefundRepo{},
+		&serviceChargeRepo{charges: []*entity.PaymentCharge{charge}},
+		&serviceProviderEventRepo{},
+		outboxRepo,
+		&serviceUnitOfWork{},
+		provider.NewRegistry(&serviceProvider{}),
+		newSinkRegistryForTest(),
+		config.PaymentsConfig{CallbackRetryInterval: time.Second, CallbackMaxAttempts: 3, JobBatchSize: 100},
+	)
+
+	if err := svc.queueCallback(context.Background(), repo.payments[1], charge, callbackEventChargeStatus, now.Add(-time.Second)); err != nil {
+		t.Fatalf("queue charge callback failed: %v", err)
+	}
+	if err := svc.RunDispatchCallbacksBatch(context.Background()); err != nil {
+		t.Fatalf("run dispatch callbacks batch failed: %v", err)
+	}
+	if outboxRepo.items[0].Status != entity.CallbackDeliverySuccess {
+		t.Fatalf("expected charge callback delivery success, got %d", outboxRepo.items[0].Status)
+	}
+}
+
+func TestRefundAfterPaidQueuesSeparateCallbacks(t *testing.T) {
+	repo := newServicePaymentRepo()
+	now := time.Now().UTC().Add(-time.Hour)
+	providerPaymentID := "pi_test_1"
+	repo.payments[1] = &entity.Payment{
+		ID:                   1,
+		RequestID:            "req-1",
+		CallerService:        "subscriptions-service",
+		AmountCents:          1000,
+		Currency:             "USD",
+		Status:               int32(types.PaymentStatus_PAYMENT_STATUS_PENDING),
+		Provider:             int32(types.ProviderType_PROVIDER_TYPE_STRIPE),
+		ProviderPaymentID:    &providerPaymentID,
+		ProviderCallbackHash: "hash-1",
+		StatusCallbackURL:    "test://caller",
+		RefundableCents:      1000,
+		Metadata:             map[string]string{},
+		CreatedAt:            now,
+		UpdatedAt:            now,
+	}
+	testSink := &serviceSink{}
+	outboxRepo := &serviceOutboxRepo{}
+	svc := NewPaymentService(
+		repo,
+		&serviceEventRepo{},
+		&serviceCallbackRepo{},
+		&serviceRefundRepo{},
+		&serviceChargeRepo{},
+		&serviceProviderEventRepo{},
+		outboxRepo,
+		&serviceUnitOfWork{},
+		provider.NewRegistry(&serviceProvider{}),
+		newSinkRegistryForTest(testSink),
+		config.PaymentsConfig{CallbackRetryInterval: time.Second, CallbackMaxAttempts: 3, JobBatchSize: 100},
+	)
+
+	_, err := svc.HandleProviderCallback(context.Background(), &types.HandleProviderCallbackRequest{
+		RequestId:    "cb-1",
+		Provider:     "stripe",
+		CallbackHash: "hash-1",
+		Signature:    "valid-signature",
+		Payload:      `{"id":"evt_1"}`,
+	})
+	if err != nil {
+		t.Fatalf("handle callback failed: %v", err)
+	}
+	if _, _, err := svc.RefundPayment(context.Background(), &types.RefundPaymentRequest{Id: 1, RequestId: "refund-1", AmountCents: 400}); err != nil {
+		t.Fatalf("refund payment failed: %v", err)
+	}
+
+	if len(outboxRepo.items) != 2 {
+		t.Fatalf("expected paid and refund callbacks to be queued separately, got %+v", outboxRepo.items)
+	}
+	if outboxRepo.items[0].EventType != callbackEventPaymentStatus || outboxRepo.items[1].EventType != callbackEventPaymentRefund {
+		t.Fatalf("unexpected callback order: %s, %s", outboxRepo.items[0].EventType, outboxRepo.items[1].EventType)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := svc.RunDispatchCallbacksBatch(context.Background()); err != nil {
+			t.Fatalf("run dispatch callbacks batch failed: %v", err)
+		}
+	}
+	if len(testSink.messages) != 2 {
+		t.Fatalf("expected both callbacks to be delivered, got %d", len(testSink.messages))
+	}
+	var first, second types.PaymentEnvelopeResponse
+	if err := json.Unmarshal(testSink.messages[0].Body, &first); err != nil {
+		t.Fatalf("decode first callback: %v", err)
+	}
+	if err := json.Unmarshal(testSink.messages[1].Body, &second); err != nil {
+		t.Fatalf("decode second callback: %v", err)
+	}
+	if first.GetPayment().GetRefundedCents() != 0 || second.GetPayment().GetRefundedCents() != 400 {
+		t.Fatalf("expected callbacks to carry their own snapshots, got refunded=%d then %d", first.GetPayment().GetRefundedCents(), second.GetPayment().GetRefundedCents())
+	}
+}
+
+func TestListPaymentChargesRejectsOneTimePayment(t *testing.T) {
+	repo := newServicePaymentRepo()
+	repo.payments[1] = &entity.Payment{ID: 1, PaymentType: int32(types.PaymentType_PAYMENT_TYPE_ONE_TIME)}
+	svc := newPaymentServiceForTest(repo, &serviceEventRepo{}, &serviceCallbackRepo{}, &serviceProvider{})
+
+	if _, err := svc.ListPaymentCharges(context.Background(), 1); !errors.Is(err, ErrInvalidRequest) {
+		t.Fatalf("expected ErrInvalidRequest, got %v", err)
+	}
+	if _, err := svc.ListPaymentCharges(context.Background(), 2); !errors.Is(err, ErrPaymentNotFound) {
+		t.Fatalf("expected ErrPaymentNotFound, got %v", err)
+	}
+}
+
+func newSubscriptionPaymentForTest() *entity.Payment {
+	subscriptionID := "sub_test_1"
+	interval := "month"
+	intervalCount := int32(1)
+	return &entity.Payment{
+		ID:                     1,
+		RequestID:              "req-1",
+		CallerService:          "billing-service",
+		AmountCents:            1999,
+		Currency:               "USD",
+		Status:                 int32(types.PaymentStatus_PAYMENT_STATUS_PAID),
+		PaymentType:            int32(types.PaymentType_PAYMENT_TYPE_RECURRING),
+		Provider:               int32(types.ProviderType_PROVIDER_TYPE_STRIPE),
+		RecurringInterval:      &interval,
+		RecurringIntervalCount: &intervalCount,
+		ProviderSubscriptionID: &subscriptionID,
+		Metadata:               map[string]string{},
+	}
+}
+
+func TestPauseAndResumeSubscription(t *testing.T) {
+	repo := newServicePaymentRepo()
+	repo.payments[1] = newSubscriptionPaymentForTest()
+	eventRepo := &serviceEventRepo{}
+	p := &serviceProvider{}
+	svc := newPaymentServiceForTest(repo, eventRepo, &serviceCallbackRepo{}, p)
+
+	payment, err := svc.PauseSubscription(context.Background(), &types.PauseSubscriptionRequest{Id: 1, Reason: "customer request"})
+	if err != nil {
+		t.Fatalf("pause subscription failed: %v", err)
+	}
+	if !payment.SubscriptionPaused {
+		t.Fatal("expected subscription to be paused")
+	}
+
+	if _, err := svc.ResumeSubscription(context.Background(), &types.ResumeSubscriptionRequest{Id: 1}); err != nil {
+		t.Fatalf("resume subscription failed: %v", err)
+	}
+	stored, _ := repo.FindByID(context.Background(), 1)
+	if stored.SubscriptionPaused {
+		t.Fatal("expected subscription to be resumed")
+	}
+	if len(p.subscriptionCalls) != 2 || p.subscriptionInputs[0].ProviderSubscriptionID != "sub_test_1" {
+		t.Fatalf("unexpected provider calls: %v", p.subscriptionCalls)
+	}
+	if len(eventRepo.events) != 2 || eventRepo.events[0].EventType != "subscription_paused" || eventRepo.events[1].EventType != "subscription_resumed" {
+		t.Fatalf("unexpected subscription events: %+v", eventRepo.events)
+	}
+
+	if _, err := svc.ResumeSubscription(context.Background(), &types.ResumeSubscriptionRequest{Id: 1}); !errors.Is(err, ErrInvalidStatus) {
+		t.Fatalf("expected ErrInvalidStatus when resuming an active subscription, got %v", err)
+	}
+}
+
+func TestCancelSubscriptionAtPeriodEndThenImmediately(t *testing.T) {
+	repo := newServicePaymentRepo()
+	repo.payments[1] = newSubscriptionPaymentForTest()
+	p := &serviceProvider{}
+	svc := newPaymentServiceForTest(repo, &serviceEventRepo{}, &serviceCallbackRepo{}, p)
+
+	payment, err := svc.CancelSubscription(context.Background(), &types.CancelSubscriptionRequest{Id: 1, AtPeriodEnd: true})
+	if err != nil {
+		t.Fatalf("cancel at period end failed: %v", err)
+	}
+	if !payment.CancelAtPeriodEnd || payment.Status != int32(types.PaymentStatus_PAYMENT_STATUS_PAID) {
+		t.Fatalf("expected scheduled cancellation on a paid subscription, got %+v", payment)
+	}
+	if !p.subscriptionInputs[0].AtPeriodEnd {
+		t.Fatal("expected provider cancel at period end")
+	}
+
+	payment, err = svc.CancelSubscription(context.Background(), &types.CancelSubscriptionRequest{Id: 1})
+	if err != nil {
+		t.Fatalf("cancel immediately failed: %v", err)
+	}
+	if payment.Status != int32(types.PaymentStatus_PAYMENT_STATUS_CANCELED) {
+		t.Fatalf("expected canceled subscription, got %+v", payment)
+	}
+	outboxRepo := svc.outboxRepo.(*serviceOutboxRepo)
+	if len(outboxRepo.items) != 1 || outboxRepo.items[0].Status != entity.CallbackDeliveryPending {
+		t.Fatalf("expected canceled subscription pending callback delivery, got %+v", outboxRepo.items)
+	}
+
+	if _, err := svc.PauseSubscription(context.Background(), &types.PauseSubscriptionRequest{Id: 1}); !errors.Is(err, ErrInvalidStatus) {
+		t.Fatalf("expected ErrInvalidStatus on canceled subscription, got %v", err)
+	}
+}
+
+func TestUpdateSubscriptionChangesAmountAndInterval(t *testing.T) {
+	repo := newServicePaymentRepo()
+	repo.payments[1] = newSubscriptionPaymentForTest()
+	eventRepo := &serviceEventRepo{}
+	p := &serviceProvider{}
+	svc := newPaymentServiceForTest(repo, eventRepo, &serviceCallbackRepo{}, p)
+
+	payment, err := svc.UpdateSubscription(context.Background(), &types.UpdateSubscriptionRequest{Id: 1, AmountCents: 2999, RecurringInterval: "year"})
+	if err != nil {
+		t.Fatalf("update subscription failed: %v", err)
+	}
+	if payment.AmountCents != 1999 || payment.SubscriptionAmountCents == nil || *payment.SubscriptionAmountCents != 2999 {
+		t.Fatalf("expected the charged amount to stay and the new amount to apply from the next cycle, got %+v", payment)
+	}
+	if derefString(payment.RecurringInterval) != "year" || *payment.RecurringIntervalCount != 1 {
+		t.Fatalf("unexpected updated subscription: %+v", payment)
+	}
+	if len(p.updateInputs) != 1 || p.updateInputs[0].Currency != "USD" || p.updateInputs[0].RecurringIntervalCount != 1 {
+		t.Fatalf("unexpected provider update input: %+v", p.updateInputs)
+	}
+	if len(eventRepo.events) != 1 || eventRepo.events[0].EventType != "subscription_updated" || eventRepo.events[0].PayloadJSON == nil {
+		t.Fatalf("expected subscription_updated event with payload, got %+v", eventRepo.events)
+	}
+}
+
+func TestUpdateSubscriptionKeepsRefundsCappedByChargedAmount(t *testing.T) {
+	repo := newServicePaymentRepo()
+	providerPaymentID := "cs_test_123"
+	repo.payments[1] = newSubscriptionPaymentForTest()
+	repo.payments[1].ProviderPaymentID = &providerPaymentID
+	repo.payments[1].RefundableCents = 1999
+	p := &serviceProvider{}
+	svc := newPaymentServiceForTest(repo, &serviceEventRepo{}, &serviceCallbackRepo{}, p)
+
+	if _, err := svc.UpdateSubscription(context.Background(), &types.UpdateSubscriptionRequest{Id: 1, AmountCents: 2999}); err != nil {
+		t.Fatalf("update subscription failed: %v", err)
+	}
+	payment, refund, err := svc.RefundPayment(context.Background(), &types.RefundPaymentRequest{Id: 1, RequestId: "refund-1"})
+	if err != nil {
+		t.Fatalf("refund payment failed: %v", err)
+	}
+	if refund.AmountCents != 1999 || payment.RefundedCents != 1999 || payment.RefundableCents != 0 {
+		t.Fatalf("expected a full refund of the charged amount, got refund=%d refunded=%d refundable=%d", refund.AmountCents, payment.RefundedCents, payment.RefundableCents)
+	}
+
+	// Going back to the original price is a change of the next-cycle amount.
+	if _, err := svc.UpdateSubscription(context.Background(), &types.UpdateSubscriptionRequest{Id: 1, AmountCents: 1999}); err != nil {
+		t.Fatalf("second update failed: %v", err)
+	}
+	if len(p.updateInputs) != 2 || p.updateInputs[1].AmountCents != 1999 {
+		t.Fatalf("expected the price change back to be sent to the provider, got %+v", p.updateInputs)
+	}
+}
+
+func TestSubscriptionProviderFailureIsRecorded(t *testing.T) {
+	repo := newServicePaymentRepo()
+	repo.payments[1] = newSubscriptionPaymentForTest()
+	eventRepo := &serviceEventRepo{}
+	svc := newPaymentServiceForTest(repo, eventRepo, &serviceCallbackRepo{}, &serviceProvider{subscriptionErr: errors.New("stripe unavailable")})
+
+	_, err := svc.PauseSubscription(context.Background(), &types.PauseSubscriptionRequest{Id: 1})
+	if !errors.Is(err, ErrProviderRequestFailed) {
+		t.Fatalf("expected ErrProviderRequestFailed, got %v", err)
+	}
+	stored, _ := repo.FindByID(context.Background(), 1)
+	if stored.SubscriptionPaused {
+		t.Fatal("expected subscription to stay active")
+	}
+	if len(eventRepo.events) != 1 || eventRepo.events[0].EventType != "subscription_pause_failed" {
+		t.Fatalf("expected subscription_pause_failed event, got %+v", eventRepo.events)
+	}
+}
+
+func TestSubscriptionActionsRejectOneTimePayment(t *testing.T) {
+	repo := newServicePaymentRepo()
+	repo.payments[1] = &entity.Payment{ID: 1, PaymentType: int32(types.PaymentType_PAYMENT_TYPE_ONE_TIME)}
+	svc := newPaymentServiceForTest(repo, &serviceEventRepo{}, &serviceCallbackRepo{}, &serviceProvider{})
+
+	if _, err := svc.CancelSubscription(context.Background(), &types.CancelSubscriptionRequest{Id: 1}); !errors.Is(err, ErrInvalidRequest) {
+		t.Fatalf("expected ErrInvalidRequest, got %v", err)
+	}
+}
+
+// racingPaymentRepo simulates a concurrent writer that bumps the stored version
+// right after the expire job has loaded its batch.
+type racingPaymentRepo struct {
+	*servicePaymentRepo
+}
+
+func (r *racingPaymentRepo) ListExpiredPending(ctx context.Context, cutoff time.Time, limit int32) ([]*entity.Payment, error) {
+	items, err := r.servicePaymentRepo.ListExpiredPending(ctx, cutoff, limit)
+	for _, item := range items {
+		r.payments[item.ID].Version++
+	}
+	return items, err
+}
+
+func TestHandleProviderCallbackRejectsIllegalTransition(t *testing.T) {
+	repo := newServicePaymentRepo()
+	now := time.Now().UTC().Add(-time.Hour)
+	repo.payments[1] = &entity.Payment{
+		ID:                   1,
+		RequestID:            "req-1",
+		CallerService:        "subscriptions-service",
+		Status:               int32(types.PaymentStatus_PAYMENT_STATUS_PAID),
+		Provider:             int32(types.ProviderType_PROVIDER_TYPE_STRIPE),
+		ProviderCallbackHash: "hash-1",
+		Metadata:             map[string]string{},
+		CreatedAt:            now,
+		UpdatedAt:            now,
+	}
+	eventRepo := &serviceEventRepo{}
+	callbackRepo := &serviceCallbackRepo{}
+	svc := newPaymentServiceForTest(repo, eventRepo, callbackRepo, &serviceProvider{
+		callbackEvt: &provider.CallbackEvent{
+			EventType: "checkout.session.expired",
+			NewStatus: int32(types.PaymentStatus_PAYMENT_STATUS_EXPIRED),
+		},
+	})
+
+	payment, err := svc.HandleProviderCallback(context.Background(), &types.HandleProviderCallbackRequest{
+		Provider:     "stripe",
+		CallbackHash: "hash-1",
+		Signature:    "valid-signature",
+		Payload:      `{"id":"evt_late"}`,
+	})
+	if err != nil {
+		t.Fatalf("handle callback failed: %v", err)
+	}
+	if payment.Status != int32(types.PaymentStatus_PAYMENT_STATUS_PAID) {
+		t.Fatalf("expected payment to stay paid, got %d", payment.Status)
+	}
+	if len(callbackRepo.callbacks) != 1 || callbackRepo.callbacks[0].Status != paymentCallbackStatusProcessed {
+		t.Fatalf("expected processed callback record, got %+v", callbackRepo.callbacks)
+	}
+	rejected := false
+	for _, event := range eventRepo.events {
+		if event.EventType == "status_transition_rejected" {
+			rejected = true
+		}
+	}
+	if !rejected {
+		t.Fatal("expected status_transition_rejected event")
+	}
+}
+
+func TestCancelPaymentExpiredIsInvalidTransition(t *testing.T) {
+	repo := newServicePaymentRepo()
+	repo.payments[1] = &entity.Payment{ID: 1, Status: int32(types.PaymentStatus_PAYMENT_STATUS_EXPIRED)}
+	p := &serviceProvider{}
+	svc := newPaymentServiceForTest(repo, &serviceEventRepo{}, &serviceCallbackRepo{}, p)
+
+	_, err := svc.CancelPayment(context.Background(), &types.CancelPaymentRequest{Id: 1})
+	if !errors.Is(err, ErrInvalidTransition) {
+		t.Fatalf("expected ErrInvalidTransition, got %v", err)
+	}
+	if len(p.cancelInputs) != 0 {
+		t.Fatalf("expected no provider cancel call, got %d", len(p.cancelInputs))
+	}
+}
+
+func TestPaymentUpdateBumpsVersion(t *testing.T) {
+	repo := newServicePaymentRepo()
+	repo.payments[1] = &entity.Payment{ID: 1, Status: int32(types.PaymentStatus_PAYMENT_STATUS_PENDING), Version: 1}
+	svc := newPaymentServiceForTest(repo, &serviceEventRepo{}, &serviceCallbackRepo{}, &serviceProvider{})
+
+	payment, err := svc.CancelPayment(context.Background(), &types.CancelPaymentRequest{Id: 1})
+	if err != nil {
+		t.Fatalf("cancel payment failed: %v", err)
+	}
+	if payment.Version != 2 || repo.payments[1].Version != 2 {
+		t.Fatalf("expected version 2, got %d/%d", payment.Version, repo.payments[1].Version)
+	}
+}
+
+func TestRunExpirePendingBatchSkipsConcurrentlyModifiedPayment(t *testing.T) {
+	repo := newServicePaymentRepo()
+	now := time.Now().UTC().Add(-2 * time.Hour)
+	repo.payments[1] = &entity.Payment{
+		ID:                   1,
+		RequestID:            "req-1",
+		CallerService:        "subscriptions-service",
+		Status:               int32(types.PaymentStatus_PAYMENT_STATUS_PENDING),
+		Provider:             int32(types.ProviderType_PROVIDER_TYPE_STRIPE),
+		ProviderCallbackHash: "hash-1",
+		Metadata:             map[string]string{},
+		Version:              1,
+		CreatedAt:            now,
+		UpdatedAt:            now,
+	}
+	eventRepo := &serviceEventRepo{}
+	svc := NewPaymentService(
+		&racingPaymentRepo{servicePaymentRepo: repo},
+		eventRepo,
+		&serviceCallbackRepo{},
+		&serviceRefundRepo{},
+		&serviceChargeRepo{},
+		&serviceProviderEventRepo{},
+		&serviceOutboxRepo{},
+		&serviceUnitOfWork{},
+		provider.NewRegistry(&serviceProvider{}),
+		newSinkRegistryForTest(),
+		config.PaymentsConfig{PendingTimeout: time.Minute, CallbackRetryInterval: time.Second, CallbackMaxAttempts: 3, JobBatchSize: 100},
+	)
+
+	if err := svc.RunExpirePendingBatch(context.Background()); err != nil {
+		t.Fatalf("run expire pending batch failed: %v", err)
+	}
+	if repo.payments[1].Status != int32(types.PaymentStatus_PAYMENT_STATUS_PENDING) {
+		t.Fatalf("expected concurrently modified payment to be left alone, got %d", repo.payments[1].Status)
+	}
+	if len(eventRepo.events) != 0 {
+		t.Fatalf("expected no events, got %d", len(eventRepo.events))
+	}
+}
+
+func TestCanTransition(t *testing.T) {
+	paid := int32(types.PaymentStatus_PAYMENT_STATUS_PAID)
+	pending := int32(types.PaymentStatus_PAYMENT_STATUS_PENDING)
+	expired := int32(types.PaymentStatus_PAYMENT_STATUS_EXPIRED)
+	canceled := int32(types.PaymentStatus_PAYMENT_STATUS_CANCELED)
+
+	cases := []struct {
+		from, to int32
+		want     bool
+	}{
+		{pending, paid, true},
+		{expired, paid, true},
+		{paid, expired, false},
+		{paid, pending, false},
+		{paid, canceled, true},
+		{canceled, paid, false},
+	}
+	for _, tc := range cases {
+		if got := canTransition(tc.from, tc.to); got != tc.want {
+			t.Fatalf("canTransition(%d, %d) = %v, want %v", tc.from, tc.to, got, tc.want)
+		}
+	}
+}
+
+func TestHandleProviderCallbackSkipsDuplicateProviderEvent(t *testing.T) {
+	repo := newServicePaymentRepo()
+	now := time.Now().UTC().Add(-time.Hour)
+	repo.payments[1] = &entity.Payment{
+		ID:                   1,
+		RequestID:            "req-1",
+		CallerService:        "subscriptions-service",
+		Status:               int32(types.PaymentStatus_PAYMENT_STATUS_PENDING),
+		Provider:             int32(types.ProviderType_PROVIDER_TYPE_STRIPE),
+		ProviderCallbackHash: "hash-1",
+		StatusCallbackURL:    "https://caller.example/status",
+		Metadata:             map[string]string{},
+		CreatedAt:            now,
+		UpdatedAt:            now,
+	}
+	eventRepo := &serviceEventRepo{}
+	callbackRepo := &serviceCallbackRepo{}
+	providerEventID := "evt_1"
+	svc := newPaymentServiceForTest(repo, eventRepo, callbackRepo, &serviceProvider{
+		callbackEvt: &provider.CallbackEvent{
+			EventType:       "checkout.session.completed",
+			ProviderEventID: &providerEventID,
+			NewStatus:       int32(types.PaymentStatus_PAYMENT_STATUS_PAID),
+		},
+	})
+	req := &types.HandleProviderCallbackRequest{
+		Provider:     "stripe",
+		CallbackHash: "hash-1",
+		Signature:    "valid-signature",
+		Payload:      `{"id":"evt_1"}`,
+	}
+
+	if _, err := svc.HandleProviderCallback(context.Background(), req); err != nil {
+		t.Fatalf("handle callback failed: %v", err)
+	}
+	processed := *repo.payments[1]
+	eventCount := len(eventRepo.events)
+
+	payment, err := svc.HandleProviderCallback(context.Background(), req)
+	if err != nil {
+		t.Fatalf("handle duplicate callback failed: %v", err)
+	}
+	if payment.Status != int32(types.PaymentStatus_PAYMENT_STATUS_PAID) {
+		t.Fatalf("expected paid status, got %d", payment.Status)
+	}
+	if repo.payments[1].Version != processed.Version || !repo.payments[1].UpdatedAt.Equal(processed.UpdatedAt) {
+		t.Fatal("expected duplicate event to leave the payment untouched")
+	}
+	if len(eventRepo.events) != eventCount {
+		t.Fatalf("expected no new payment events, got %d", len(eventRepo.events)-eventCount)
+	}
+	if len(callbackRepo.callbacks) != 2 {
+		t.Fatalf("expected two callback records, got %d", len(callbackRepo.callbacks))
+	}
+	if callbackRepo.callbacks[0].Status != paymentCallbackStatusProcessed {
+		t.Fatalf("expected first callback processed, got %d", callbackRepo.callbacks[0].Status)
+	}
+	duplicate := callbackRepo.callbacks[1]
+	if duplicate.Status != paymentCallbackStatusDuplicate || duplicate.ProviderEventID == nil || *duplicate.ProviderEventID != "evt_1" {
+		t.Fatalf("expected duplicate callback record for evt_1, got %+v", duplicate)
+	}
+}
+
+func TestHandleProviderCallbackSurfacesEventWriteFailure(t *testing.T) {
+	repo := newServicePaymentRepo()
+	now := time.Now().UTC().Add(-time.Hour)
+	repo.payments[1] = &entity.Payment{
+		ID:                   1,
+		RequestID:            "req-1",
+		CallerService:        "subscriptions-service",
+		Status:               int32(types.PaymentStatus_PAYMENT_STATUS_PENDING),
+		Provider:             int32(types.ProviderType_PROVIDER_TYPE_STRIPE),
+		ProviderCallbackHash: "hash-1",
+		Metadata:             map[string]string{},
+		CreatedAt:            now,
+		UpdatedAt:            now,
+	}
+	writeErr := errors.New("events table unavailable")
+	callbackRepo := &serviceCallbackRepo{}
+	uow := &serviceUnitOfWork{}
+	svc := NewPaymentService(
+		repo,
+		&serviceEventRepo{createErr: writeErr},
+		callbackRepo,
+		&serviceRefundRepo{},
+		&serviceChargeRepo{},
+		&serviceProviderEventRepo{},
+		&serviceOutboxRepo{},
+		uow,
+		provider.NewRegistry(&serviceProvider{
+			callbackEvt: &provider.CallbackEvent{
+				EventType: "checkout.session.completed",
+				NewStatus: int32(types.PaymentStatus_PAYMENT_STATUS_PAID),
+			},
+		}),
+		newSinkRegistryForTest(),
+		config.PaymentsConfig{CallbackMaxAttempts: 3, CallbackRetryInterval: time.Second, JobBatchSize: 100},
+	)
+
+	_, err := svc.HandleProviderCallback(context.Background(), &types.HandleProviderCallbackRequest{
+		Provider:     "stripe",
+		CallbackHash: "hash-1",
+		Signature:    "valid-signature",
+		Payload:      `{"id":"evt_1"}`,
+	})
+	if !errors.Is(err, writeErr) {
+		t.Fatalf("expected event write error, got %v", err)
+	}
+	if uow.rollbacks != 1 || uow.commits != 0 {
+		t.Fatalf("expected one rollback and no commits, got rollbacks=%d commits=%d", uow.rollbacks, uow.commits)
+	}
+	// The webhook is kept as rejected so it can be replayed from the inbox.
+	if len(callbackRepo.callbacks) != 1 || callbackRepo.callbacks[0].Status != paymentCallbackStatusRejected {
+		t.Fatalf("expected only a rejected callback record, got %+v", callbackRepo.callbacks)
+	}
+	if callbackRepo.callbacks[0].Error == nil || !strings.Contains(*callbackRepo.callbacks[0].Error, writeErr.Error()) {
+		t.Fatalf("expected rejected callback to record the failure, got %v", callbackRepo.callbacks[0].Error)
+	}
+}
+
+func TestRunExpirePendingBatchSurfacesEventWriteFailure(t *testing.T) {
+	repo := newServicePaymentRepo()
+	now := time.Now().UTC().Add(-2 * time.Hour)
+	repo.payments[1] = &entity.Payment{
+		ID:        1,
+		Status:    int32(types.PaymentStatus_PAYMENT_STATUS_PENDING),
+		Provider:  int32(types.ProviderType_PROVIDER_TYPE_STRIPE),
+		Metadata:  map[string]string{},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	writeErr := errors.New("events table unavailable")
+	svc := newPaymentServiceForTest(repo, &serviceEventRepo{createErr: writeErr}, &serviceCallbackRepo{}, &serviceProvider{})
+
+	if err := svc.RunExpirePendingBatch(context.Background()); !errors.Is(err, writeErr) {
+		t.Fatalf("expected event write error, got %v", err)
+	}
+}
+
+func TestCallbackRetryDelayBacksOffExponentiallyWithCap(t *testing.T) {
+	svc := &PaymentService{paymentsCfg: config.PaymentsConfig{
+		CallbackRetryInterval:    time.Minute,
+		CallbackRetryMaxInterval: 10 * time.Minute,
+	}}
+
+	expected := []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 8 * time.Minute, 10 * time.Minute, 10 * time.Minute}
+	for idx, want := range expected {
+		if got := svc.callbackRetryDelay(int32(idx + 1)); got != want {
+			t.Fatalf("attempt %d: expected delay %s, got %s", idx+1, want, got)
+		}
+	}
+}
+
+func TestCallbackRetryDelayAppliesJitter(t *testing.T) {
+	svc := &PaymentService{paymentsCfg: config.PaymentsConfig{
+		CallbackRetryInterval:      time.Minute,
+		CallbackRetryMaxInterval:   time.Hour,
+		CallbackRetryJitterPercent: 20,
+	}}
+
+	svc.jitter = func() float64 { return 0 }
+	if got := svc.callbackRetryDelay(3); got != 4*time.Minute-48*time.Second {
+		t.Fatalf("expected lower jitter bound, got %s", got)
+	}
+	svc.jitter = func() float64 { return 0.5 }
+	if got := svc.callbackRetryDelay(3); got != 4*time.Minute {
+		t.Fatalf("expected unshifted delay, got %s", got)
+	}
+	svc.jitter = func() float64 { return 1 }
+	if got := svc.callbackRetryDelay(3); got != 4*time.Minute+48*time.Second {
+		t.Fatalf("expected upper jitter bound, got %s", got)
+	}
+}
+
+func TestDeadLetteredCallbackCanBeRedelivered(t *testing.T) {
+	repo := newServicePaymentRepo()
+	nextAt := time.Now().UTC().Add(-time.Second)
+	repo.payments[1] = &entity.Payment{ID: 1, RequestID: "req-1", CallerService: "subscriptions-service", Status: int32(types.PaymentStatus_PAYMENT_STATUS_PAID)}
+
+	testSink := &serviceSink{err: errors.New("caller unavailable")}
+	outboxRepo := &serviceOutboxRepo{items: []*entity.PaymentCallbackOutbox{{
+		ID:            1,
+		PaymentID:     1,
+		CallerService: "subscriptions-service",
+		EventType:     callbackEventPaymentStatus,
+		RequestID:     "req-1",
+		Destination:   "test://caller",
+		PayloadJSON:   `{"payment":{"id":1}}`,
+		Status:        entity.CallbackDeliveryPending,
+		Attempts:      1,
+		NextAttemptAt: &nextAt,
+	}}}
+
+	svc := NewPaymentService(
+		repo,
+		&serviceEventRepo{},
+		&serviceCallbackRepo{},
+		&serviceRefundRepo{},
+		&serviceChargeRepo{},
+		&serviceProviderEventRepo{},
+		outboxRepo,
+		&serviceUnitOfWork{},
+		provider.NewRegistry(&serviceProvider{}),
+		newSinkRegistryForTest(testSink),
+		config.PaymentsConfig{CallbackRetryInterval: time.Second, CallbackMaxAttempts: 2, JobBatchSize: 100},
+	)
+
+	if err := svc.RunDispatchCallbacksBatch(context.Background()); err == nil {
+		t.Fatal("expected dispatch to report the failed delivery")
+	}
+	if outboxRepo.items[0].Status != entity.CallbackDeliveryFailed {
+		t.Fatalf("expected callback to be dead-lettered, got status %d", outboxRepo.items[0].Status)
+	}
+
+	deadLetters, err := svc.ListDeadLetterCallbacks(context.Background(), &types.ListDeadLetterCallbacksRequest{CallerService: "subscriptions-service"})
+	if err != nil {
+		t.Fatalf("list dead letters failed: %v", err)
+	}
+	if len(deadLetters) != 1 || deadLetters[0].ID != 1 {
+		t.Fatalf("expected the dead-lettered callback, got %+v", deadLetters)
+	}
+
+	if _, err := svc.RedeliverCallbacks(context.Background(), &types.RedeliverCallbacksRequest{}); !errors.Is(err, ErrInvalidRequest) {
+		t.Fatalf("expected ErrInvalidRequest without filter or all, got %v", err)
+	}
+
+	redelivered, err := svc.RedeliverCallbacks(context.Background(), &types.RedeliverCallbacksRequest{PaymentId: 1})
+	if err != nil {
+		t.Fatalf("redeliver failed: %v", err)
+	}
+	if redelivered != 1 {
+		t.Fatalf("expected one redelivered callback, got %d", redelivered)
+	}
+
+	testSink.err = nil
+	if err := svc.RunDispatchCallbacksBatch(context.Background()); err != nil {
+		t.Fatalf("dispatch after redelivery failed: %v", err)
+	}
+	if outboxRepo.items[0].Status != entity.CallbackDeliverySuccess || len(testSink.messages) != 2 {
+		t.Fatalf("expected redelivered callback to be delivered, got %+v", outboxRepo.items[0])
+	}
+}
+
+func TestListPaymentEventsPagesWithCursor(t *testing.T) {
+	repo := newServicePaymentRepo()
+	repo.payments[1] = &entity.Payment{ID: 1, Status: int32(types.PaymentStatus_PAYMENT_STATUS_PAID)}
+	payload := `{"id":"evt_1"}`
+	pending := int32(types.PaymentStatus_PAYMENT_STATUS_PENDING)
+	eventRepo := &serviceEventRepo{events: []*entity.PaymentEvent{
+		{ID: 1, PaymentID: 1, EventType: "payment_created", NewStatus: pending},
+		{ID: 2, PaymentID: 2, EventType: "payment_created", NewStatus: pending},
+		{ID: 3, PaymentID: 1, EventType: "provider_callback", OldStatus: &pending, NewStatus: int32(types.PaymentStatus_PAYMENT_STATUS_PAID), PayloadJSON: &payload},
+		{ID: 4, PaymentID: 1, EventType: "callback_dispatched", NewStatus: int32(types.PaymentStatus_PAYMENT_STATUS_PAID)},
+	}}
+	svc := newPaymentServiceForTest(repo, eventRepo, &serviceCallbackRepo{}, &serviceProvider{})
+
+	page, cursor, err := svc.ListPaymentEvents(context.Background(), &types.ListPaymentEventsRequest{Id: 1, Limit: 2})
+	if err != nil {
+		t.Fatalf("list payment events failed: %v", err)
+	}
+	if len(page) != 2 || page[0].ID != 1 || page[1].ID != 3 || cursor != 3 {
+		t.Fatalf("unexpected first page: events=%+v cursor=%d", page, cursor)
+	}
+	if page[1].PayloadJSON != nil {
+		t.Fatal("expected payload to be omitted unless requested")
+	}
+
+	page, cursor, err = svc.ListPaymentEvents(context.Background(), &types.ListPaymentEventsRequest{Id: 1, Limit: 2, Cursor: cursor})
+	if err != nil {
+		t.Fatalf("list payment events failed: %v", err)
+	}
+	if len(page) != 1 || page[0].ID != 4 || cursor != 0 {
+		t.Fatalf("unexpected last page: events=%+v cursor=%d", page, cursor)
+	}
+
+	page, _, err = svc.ListPaymentEvents(context.Background(), &types.ListPaymentEventsRequest{Id: 1, EventType: "provider_callback", IncludePayload: true})
+	if err != nil {
+		t.Fatalf("list payment events failed: %v", err)
+	}
+	if len(page) != 1 || page[0].PayloadJSON == nil || *page[0].PayloadJSON != payload {
+		t.Fatalf("expected filtered event with payload, got %+v", page)
+	}
+
+	if _, _, err := svc.ListPaymentEvents(context.Background(), &types.ListPaymentEventsRequest{Id: 9}); !errors.Is(err, ErrPaymentNotFound) {
+		t.Fatalf("expected ErrPaymentNotFound, got %v", err)
+	}
+}
+
+func TestReplayProviderCallbackAppliesStoredWebhook(t *testing.T) {
+	repo := newServicePaymentRepo()
+	now := time.Now().UTC()
+	repo.payments[1] = &entity.Payment{
+		ID:                   1,
+		RequestID:            "req-1",
+		Status:               int32(types.PaymentStatus_PAYMENT_STATUS_PENDING),
+		Provider:             int32(types.ProviderType_PROVIDER_TYPE_STRIPE),
+		ProviderCallbackHash: "hash-1",
+		StatusCallbackURL:    "http://localhost/callback",
+		Metadata:             map[string]string{},
+		CreatedAt:            now,
+		UpdatedAt:            now,
+	}
+	failure := "provider callback processing failed: deadlock found"
+	callbackRepo := &serviceCallbackRepo{callbacks: []*entity.PaymentCallback{{
+		ID:           1,
+		Provider:     "stripe",
+		CallbackHash: "hash-1",
+		Signature:    "t=1,v1=expired",
+		PayloadJSON:  `{"id":"evt_1","type":"checkout.session.completed"}`,
+		Status:       paymentCallbackStatusRejected,
+		Error:        &failure,
+		// Processing failed after the signature had been checked.
+		SignatureVerified: true,
+		CreatedAt:         now.Add(-time.Hour),
+	}}}
+	p := &serviceProvider{callbackErr: errors.New("invalid stripe signature")}
+	svc := newPaymentServiceForTest(repo, &serviceEventRepo{}, callbackRepo, p)
+
+	if _, err := svc.ReplayProviderCallback(context.Background(), &types.ReplayProviderCallbackRequest{Id: 1}); !errors.Is(err, ErrCallbackRejected) {
+		t.Fatalf("expected expired signature to be rejected, got %v", err)
+	}
+
+	payment, err := svc.ReplayProviderCallback(context.Background(), &types.ReplayProviderCallbackRequest{Id: 1, SkipSignature: true})
+	if err != nil {
+		t.Fatalf("replay failed: %v", err)
+	}
+	if payment.Status != int32(types.PaymentStatus_PAYMENT_STATUS_PAID) {
+		t.Fatalf("expected replay to mark payment paid, got %d", payment.Status)
+	}
+
+	rejected, err := svc.ListProviderCallbacks(context.Background(), &types.ListProviderCallbacksRequest{
+		HasStatus: true,
+		Status:    types.ProviderCallbackStatus_PROVIDER_CALLBACK_STATUS_REJECTED,
+	})
+	if err != nil {
+		t.Fatalf("list callbacks failed: %v", err)
+	}
+	if len(rejected) != 2 || rejected[1].ID != 1 {
+		t.Fatalf("expected the original and the signature-checked replay as rejected, got %+v", rejected)
+	}
+	processed, err := svc.ListProviderCallbacks(context.Background(), &types.ListProviderCallbacksRequest{
+		CallbackHash: "hash-1",
+		HasStatus:    true,
+		Status:       types.ProviderCallbackStatus_PROVIDER_CALLBACK_STATUS_PROCESSED,
+	})
+	if err != nil {
+		t.Fatalf("list callbacks failed: %v", err)
+	}
+	if len(processed) != 1 || processed[0].PayloadJSON != "" {
+		t.Fatalf("expected one processed callback without payload, got %+v", processed)
+	}
+
+	if _, err := svc.ReplayProviderCallback(context.Background(), &types.ReplayProviderCallbackRequest{Id: 99}); !errors.Is(err, ErrCallbackNotFound) {
+		t.Fatalf("expected ErrCallbackNotFound, got %v", err)
+	}
+	if _, err := svc.ListProviderCallbacks(context.Background(), &types.ListProviderCallbacksRequest{CreatedFrom: "yesterday"}); !errors.Is(err, ErrInvalidRequest) {
+		t.Fatalf("expected ErrInvalidRequest for bad time, got %v", err)
+	}
+}
+
+func TestReplayProviderCallbackRefusesToSkipUnverifiedSignature(t *testing.T) {
+	repo := newServicePaymentRepo()
+	now := time.Now().UTC()
+	repo.payments[1] = &entity.Payment{
+		ID:                   1,
+		RequestID:            "req-1",
+		Status:               int32(types.PaymentStatus_PAYMENT_STATUS_PENDING),
+		Provider:             int32(types.ProviderType_PROVIDER_TYPE_STRIPE),
+		ProviderCallbackHash: "hash-1",
+		StatusCallbackURL:    "http://localhost/callback",
+		Metadata:             map[string]string{},
+		CreatedAt:            now,
+		UpdatedAt:            now,
+	}
+	callbackRepo := &serviceCallbackRepo{}
+	p := &serviceProvider{callbackErr: errors.New("invalid stripe signature")}
+	svc := newPaymentServiceForTest(repo, &serviceEventRepo{}, callbackRepo, p)
+
+	_, err := svc.HandleProviderCallback(context.Background(), &types.HandleProviderCallbackRequest{
+		Provider:     "stripe",
+		CallbackHash: "hash-1",
+		Signature:    "t=1,v1=forged",
+		Payload:      `{"id":"evt_forged","type":"checkout.session.completed"}`,
+	})
+	if !errors.Is(err, ErrCallbackRejected) {
+		t.Fatalf("expected forged webhook to be rejected, got %v", err)
+	}
+	if len(callbackRepo.callbacks) != 1 || callbackRepo.callbacks[0].SignatureVerified {
+		t.Fatalf("expected one unverified rejected callback, got %+v", callbackRepo.callbacks)
+	}
+
+	_, err = svc.ReplayProviderCallback(context.Background(), &types.ReplayProviderCallbackRequest{Id: callbackRepo.callbacks[0].ID, SkipSignature: true})
+	if !errors.Is(err, ErrInvalidRequest) {
+		t.Fatalf("expected ErrInvalidRequest when skipping an unverified signature, got %v", err)
+	}
+	if repo.payments[1].Status != int32(types.PaymentStatus_PAYMENT_STATUS_PENDING) {
+		t.Fatalf("expected payment to stay pending, got %d", repo.payments[1].Status)
+	}
+	if len(callbackRepo.callbacks) != 1 {
+		t.Fatalf("expected no replay record, got %d callbacks", len(callbackRepo.callbacks))
+	}
+}
+
+// newServiceAgainstStripeFake runs the service on the real Stripe provider
+// against stripefake. Webhooks the fake sends are handled by the service.
+func newServiceAgainstStripeFake(t *testing.T) (*PaymentService, *servicePaymentRepo, *stripefake.Server) {
+	t.Helper()
+	var svc *PaymentService
+	webhooks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_, err := svc.HandleProviderCallback(r.Context(), &types.HandleProviderCallbackRequest{
+			Provider:     "stripe",
+			CallbackHash: r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:],
+			Signature:    r.Header.Get("Stripe-Signature"),
+			Payload:      string(body),
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	}))
+	t.Cleanup(webhooks.Close)
+
+	fake, err := stripefake.New(stripefake.Config{SecretKey: "sk_test_fake", WebhookSecret: "whsec_test", WebhookBaseURL: webhooks.URL + "/webhooks"})
+	if err != nil {
+		t.Fatalf("start fake stripe: %v", err)
+	}
+	t.Cleanup(fake.Close)
+
+	repo := newServicePaymentRepo()
+	svc = newPaymentServiceForTest(repo, &serviceEventRepo{}, &serviceCallbackRepo{}, provider.NewStripeProvider(provider.StripeConfig{
+		BaseURL:                 fake.URL,
+		SecretKey:               "sk_test_fake",
+		WebhookSecret:           "whsec_test",
+		ProviderCallbackBaseURL: "https://gateway.example/webhooks/providers/stripe",
+	}))
+	return svc, repo, fake
+}
+
+func TestRefundPaymentLinkPaymentAfterCompletedWebhook(t *testing.T) {
+	svc, repo, fake := newServiceAgainstStripeFake(t)
+	ctx := context.Background()
+
+	payment, err := svc.CreatePayment(ctx, &types.CreatePaymentRequest{
+		RequestId:         "req-link",
+		CallerService:     "orders-service",
+		ResourceType:      "order",
+		ResourceId:        "order-1",
+		AmountCents:       1200,
+		Currency:          "EUR",
+		PaymentMethod:     types.PaymentMethod_PAYMENT_METHOD_PAYMENT_LINK,
+		PaymentType:       types.PaymentType_PAYMENT_TYPE_ONE_TIME,
+		StatusCallbackUrl: "https://caller.example/callback",
+	})
+	if err != nil {
+		t.Fatalf("create payment failed: %v", err)
+	}
+	if payment.ProviderPaymentID == nil || !strings.HasPrefix(*payment.ProviderPaymentID, "plink_") {
+		t.Fatalf("expected payment link id, got %v", payment.ProviderPaymentID)
+	}
+	linkID := *payment.ProviderPaymentID
+
+	_, eventID, err := fake.PayPaymentLink(linkID)
+	if err != nil {
+		t.Fatalf("pay payment link failed: %v", err)
+	}
+	if err := fake.SendWebhook(ctx, eventID); err != nil {
+		t.Fatalf("deliver checkout.session.completed failed: %v", err)
+	}
+
+	stored := repo.payments[payment.ID]
+	if stored.Status != int32(types.PaymentStatus_PAYMENT_STATUS_PAID) {
+		t.Fatalf("expected paid payment after webhook, got %d", stored.Status)
+	}
+	if stored.ProviderPaymentID == nil || *stored.ProviderPaymentID != linkID {
+		t.Fatalf("expected payment link id to be kept, got %v", stored.ProviderPaymentID)
+	}
+
+	refunded, refund, err := svc.RefundPayment(ctx, &types.RefundPaymentRequest{Id: payment.ID, RequestId: "refund-1", AmountCents: 500})
+	if err != nil {
+		t.Fatalf("refund payment link payment failed: %v", err)
+	}
+	if refund.ProviderRefundID == nil || !strings.HasPrefix(*refund.ProviderRefundID, "re_") {
+		t.Fatalf("expected stripe refund id, got %v", refund.ProviderRefundID)
 	}
-	if updated.CallbackDeliveryAttempts != 1 {
-		t.Fatalf("expected callback delivery attempts=1, got %d", updated.CallbackDeliveryAttempts)
+	if refunded.RefundedCents != 500 || refunded.RefundableCents != 700 {
+		t.Fatalf("unexpected refund totals: refunded=%d refundable=%d", refunded.RefundedCents, refunded.RefundableCents)
 	}
 }
@@ -0,0 +1,273 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/vibast-solutions/ms-go-payments/app/entity"
+	"github.com/vibast-solutions/ms-go-payments/app/provider"
+	"github.com/vibast-solutions/ms-go-payments/app/repository"
+	"github.com/vibast-solutions/ms-go-payments/app/types"
+)
+
+type refundPaymentRequest interface {
+	GetId() uint64
+	GetRequestId() string
+	GetAmountCents() int64
+	GetReason() string
+}
+
+func (s *PaymentService) RefundPayment(ctx context.Context, req refundPaymentRequest) (*entity.Payment, *entity.PaymentRefund, error) {
+	requestID := strings.TrimSpace(req.GetRequestId())
+	if requestID == "" || req.GetAmountCents() < 0 {
+		return nil, nil, ErrInvalidRequest
+	}
+
+	payment, err := s.findPaymentForCaller(ctx, req.GetId())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	existing, err := s.refundRepo.FindByPaymentRequestID(ctx, payment.ID, requestID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if existing != nil {
+		return payment, existing, nil
+	}
+
+	if payment.Status != int32(types.PaymentStatus_PAYMENT_STATUS_PAID) {
+		return nil, nil, fmt.Errorf("%w: only paid payments can be refunded", ErrInvalidStatus)
+	}
+	if payment.ProviderPaymentID == nil || strings.TrimSpace(*payment.ProviderPaymentID) == "" {
+		return nil, nil, fmt.Errorf("%w: payment has no provider payment id", ErrInvalidStatus)
+	}
+
+	amount := req.GetAmountCents()
+	if amount == 0 {
+		amount = payment.RefundableCents
+	}
+	if amount <= 0 || amount > payment.RefundableCents {
+		return nil, nil, ErrRefundExceedsAmount
+	}
+
+	providerClient, err := s.providerReg.Get(payment.Provider)
+	if err != nil {
+		if errors.Is(err, provider.ErrProviderNotSupported) {
+			return nil, nil, ErrProviderUnsupported
+		}
+		return nil, nil, err
+	}
+
+	reason := normalizeOptionalString(req.GetReason())
+	providerOutput, err := providerClient.Refund(ctx, &provider.RefundInput{
+		IdempotencyKey:    provider.IdempotencyKey(payment.CallerService, payment.RequestID, "refund:"+requestID),
+		RequestID:         requestID,
+		PaymentID:         payment.ID,
+		ProviderPaymentID: strings.TrimSpace(*payment.ProviderPaymentID),
+		PaymentMethod:     payment.PaymentMethod,
+		PaymentType:       payment.PaymentType,
+		AmountCents:       amount,
+		Reason:            strings.TrimSpace(req.GetReason()),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The money has moved at this point, so the refund is recorded even if a
+	// webhook changed the payment in the meantime.
+	var refund *entity.PaymentRefund
+	for attempt := 1; ; attempt++ {
+		refund, err = s.recordRefund(ctx, payment, requestID, amount, reason, providerOutput)
+		if err == nil {
+			return payment, refund, nil
+		}
+		if errors.Is(err, repository.ErrRefundAlreadyExists) {
+			existing, findErr := s.refundRepo.FindByPaymentRequestID(ctx, payment.ID, requestID)
+			if findErr != nil {
+				return nil, nil, findErr
+			}
+			if existing != nil {
+				return payment, existing, nil
+			}
+		} else if !errors.Is(err, repository.ErrPaymentVersionConflict) {
+			return nil, nil, mapUpdateError(err)
+		}
+		if attempt >= refundRecordAttempts {
+			return nil, nil, mapUpdateError(err)
+		}
+
+		payment, err = s.paymentRepo.FindByID(ctx, payment.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if payment == nil {
+			return nil, nil, ErrPaymentNotFound
+		}
+	}
+}
+
+// refundRecordAttempts bounds how often RefundPayment retries storing a refund
+// the provider has already issued.
+const refundRecordAttempts = 5
+
+// recordRefund stores a refund issued by the provider and updates the payment
+// totals. A refund the provider webhook already stored is taken over under the
+// caller's request ID rather than stored a second time.
+func (s *PaymentService) recordRefund(
+	ctx context.Context,
+	payment *entity.Payment,
+	requestID string,
+	amount int64,
+	reason *string,
+	providerOutput *provider.RefundOutput,
+) (*entity.PaymentRefund, error) {
+	now := time.Now().UTC()
+	providerRefundID := strings.TrimSpace(providerOutput.ProviderRefundID)
+	oldRefundedCents := payment.RefundedCents
+
+	var refund *entity.PaymentRefund
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		var err error
+		if providerRefundID != "" {
+			if refund, err = s.refundRepo.FindByProviderRefundID(ctx, payment.ID, providerRefundID); err != nil {
+				return err
+			}
+		}
+		if refund != nil {
+			refund.RequestID = requestID
+			refund.Reason = reason
+			refund.UpdatedAt = now
+			if err := s.refundRepo.Update(ctx, refund); err != nil {
+				return err
+			}
+		} else {
+			refund = &entity.PaymentRefund{
+				PaymentID:        payment.ID,
+				RequestID:        requestID,
+				AmountCents:      amount,
+				Currency:         payment.Currency,
+				Status:           providerOutput.Status,
+				Reason:           reason,
+				ProviderRefundID: normalizeOptionalString(providerRefundID),
+				CreatedAt:        now,
+				UpdatedAt:        now,
+			}
+			if err := s.refundRepo.Create(ctx, refund); err != nil {
+				return err
+			}
+		}
+
+		if err := s.recalculateRefundTotals(ctx, payment, nil); err != nil {
+			return err
+		}
+		payment.UpdatedAt = now
+		if err := s.paymentRepo.Update(ctx, payment); err != nil {
+			return err
+		}
+		err = s.eventRepo.Create(ctx, &entity.PaymentEvent{
+			PaymentID:       payment.ID,
+			EventType:       "payment_refund_created",
+			NewStatus:       payment.Status,
+			ProviderEventID: refund.ProviderRefundID,
+			CreatedAt:       now,
+		})
+		if err != nil || payment.RefundedCents == oldRefundedCents {
+			return err
+		}
+		return s.queueCallback(ctx, payment, nil, callbackEventPaymentRefund, now)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return refund, nil
+}
+
+// applyRefundCallback stores refund state reported by a provider webhook, keyed
+// by the provider refund ID, and recalculates the refunded/refundable totals
+// of the payment.
+func (s *PaymentService) applyRefundCallback(ctx context.Context, payment *entity.Payment, event *provider.CallbackEvent, now time.Time) error {
+	if event.Refund == nil && event.TotalRefundedCents == nil {
+		return nil
+	}
+
+	if event.Refund != nil {
+		refund, err := s.refundRepo.FindByProviderRefundID(ctx, payment.ID, event.Refund.ProviderRefundID)
+		if err != nil {
+			return err
+		}
+
+		if refund == nil {
+			providerRefundID := event.Refund.ProviderRefundID
+			refund = &entity.PaymentRefund{
+				PaymentID:        payment.ID,
+				RequestID:        providerRefundID,
+				AmountCents:      event.Refund.AmountCents,
+				Currency:         payment.Currency,
+				Status:           event.Refund.Status,
+				ProviderRefundID: &providerRefundID,
+				CreatedAt:        now,
+				UpdatedAt:        now,
+			}
+			// A conflict means RefundPayment stored the refund concurrently. The
+			// error rolls the callback back; the provider's redelivery then
+			// updates the stored refund instead.
+			if err := s.refundRepo.Create(ctx, refund); err != nil {
+				return err
+			}
+		} else if refund.Status != event.Refund.Status || refund.AmountCents != event.Refund.AmountCents {
+			refund.Status = event.Refund.Status
+			if event.Refund.AmountCents > 0 {
+				refund.AmountCents = event.Refund.AmountCents
+			}
+			refund.UpdatedAt = now
+			if err := s.refundRepo.Update(ctx, refund); err != nil {
+				return err
+			}
+		}
+	}
+
+	return s.recalculateRefundTotals(ctx, payment, event.TotalRefundedCents)
+}
+
+// recalculateRefundTotals derives RefundedCents and RefundableCents from the
+// stored refunds. Pending refunds reserve their amount so concurrent requests
+// cannot over-refund; providerTotal, when known, covers refunds issued outside
+// of this service.
+func (s *PaymentService) recalculateRefundTotals(ctx context.Context, payment *entity.Payment, providerTotal *int64) error {
+	refunds, err := s.refundRepo.ListByPaymentID(ctx, payment.ID)
+	if err != nil {
+		return err
+	}
+
+	var succeeded, pending int64
+	for _, refund := range refunds {
+		switch refund.Status {
+		case int32(types.RefundStatus_REFUND_STATUS_SUCCEEDED):
+			succeeded += refund.AmountCents
+		case int32(types.RefundStatus_REFUND_STATUS_PENDING):
+			pending += refund.AmountCents
+		}
+	}
+
+	refunded := succeeded
+	if providerTotal != nil && *providerTotal > refunded {
+		refunded = *providerTotal
+	}
+	if refunded > payment.AmountCents {
+		refunded = payment.AmountCents
+	}
+
+	reserved := refunded + pending
+	if reserved > payment.AmountCents {
+		reserved = payment.AmountCents
+	}
+
+	payment.RefundedCents = refunded
+	payment.RefundableCents = payment.AmountCents - reserved
+	return nil
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/vibast-solutions/ms-go-payments/app/entity"
+	"github.com/vibast-solutions/ms-go-payments/app/repository"
+	"github.com/vibast-solutions/ms-go-payments/app/types"
+)
+
+// statusTransitions lists the statuses a payment may move to from each status.
+// Money confirmed by the provider always wins, so FAILED and EXPIRED payments
+// can still become PAID; CANCELED is final.
+var statusTransitions = map[int32][]int32{
+	int32(types.PaymentStatus_PAYMENT_STATUS_CREATED): {
+		int32(types.PaymentStatus_PAYMENT_STATUS_PENDING),
+		int32(types.PaymentStatus_PAYMENT_STATUS_PROCESSING),
+		int32(types.PaymentStatus_PAYMENT_STATUS_PAID),
+		int32(types.PaymentStatus_PAYMENT_STATUS_FAILED),
+		int32(types.PaymentStatus_PAYMENT_STATUS_CANCELED),
+		int32(types.PaymentStatus_PAYMENT_STATUS_EXPIRED),
+	},
+	int32(types.PaymentStatus_PAYMENT_STATUS_PENDING): {
+		int32(types.PaymentStatus_PAYMENT_STATUS_PROCESSING),
+		int32(types.PaymentStatus_PAYMENT_STATUS_PAID),
+		int32(types.PaymentStatus_PAYMENT_STATUS_FAILED),
+		int32(types.PaymentStatus_PAYMENT_STATUS_CANCELED),
+		int32(types.PaymentStatus_PAYMENT_STATUS_EXPIRED),
+	},
+	int32(types.PaymentStatus_PAYMENT_STATUS_PROCESSING): {
+		int32(types.PaymentStatus_PAYMENT_STATUS_PAID),
+		int32(types.PaymentStatus_PAYMENT_STATUS_FAILED),
+		int32(types.PaymentStatus_PAYMENT_STATUS_CANCELED),
+		int32(types.PaymentStatus_PAYMENT_STATUS_EXPIRED),
+	},
+	int32(types.PaymentStatus_PAYMENT_STATUS_PAID): {
+		int32(types.PaymentStatus_PAYMENT_STATUS_CANCELED),
+	},
+	int32(types.PaymentStatus_PAYMENT_STATUS_FAILED): {
+		int32(types.PaymentStatus_PAYMENT_STATUS_PROCESSING),
+		int32(types.PaymentStatus_PAYMENT_STATUS_PAID),
+		int32(types.PaymentStatus_PAYMENT_STATUS_CANCELED),
+	},
+	int32(types.PaymentStatus_PAYMENT_STATUS_EXPIRED): {
+		int32(types.PaymentStatus_PAYMENT_STATUS_PAID),
+	},
+}
+
+func canTransition(from, to int32) bool {
+	for _, allowed := range statusTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// transitionStatus moves the payment to the given status. Moving to the current
+// status is a no-op and reports changed=false.
+func transitionStatus(payment *entity.Payment, to int32) (bool, error) {
+	if payment.Status == to {
+		return false, nil
+	}
+	if !canTransition(payment.Status, to) {
+		return false, fmt.Errorf(
+			"%w: %s -> %s",
+			ErrInvalidTransition,
+			types.PaymentStatus(payment.Status).String(),
+			types.PaymentStatus(to).String(),
+		)
+	}
+
+	payment.Status = to
+	return true, nil
+}
+
+// mapUpdateError translates repository update errors into service errors.
+func mapUpdateError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrPaymentNotFound):
+		return ErrPaymentNotFound
+	case errors.Is(err, repository.ErrPaymentVersionConflict):
+		return ErrConcurrentUpdate
+	default:
+		return err
+	}
+}
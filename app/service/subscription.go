@@ -0,0 +1,269 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/vibast-solutions/ms-go-payments/app/entity"
+	"github.com/vibast-solutions/ms-go-payments/app/provider"
+	"github.com/vibast-solutions/ms-go-payments/app/types"
+)
+
+type pauseSubscriptionRequest interface {
+	GetId() uint64
+	GetReason() string
+}
+
+type resumeSubscriptionRequest interface {
+	GetId() uint64
+}
+
+type cancelSubscriptionRequest interface {
+	GetId() uint64
+	GetAtPeriodEnd() bool
+	GetReason() string
+}
+
+type updateSubscriptionRequest interface {
+	GetId() uint64
+	GetAmountCents() int64
+	GetRecurringInterval() string
+	GetRecurringIntervalCount() int32
+}
+
+func (s *PaymentService) PauseSubscription(ctx context.Context, req pauseSubscriptionRequest) (*entity.Payment, error) {
+	payment, providerClient, err := s.loadSubscription(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	if payment.Status != int32(types.PaymentStatus_PAYMENT_STATUS_PAID) {
+		return nil, fmt.Errorf("%w: only paid subscriptions can be paused", ErrInvalidStatus)
+	}
+	if payment.SubscriptionPaused {
+		return payment, nil
+	}
+
+	reason := strings.TrimSpace(req.GetReason())
+	err = providerClient.PauseSubscription(ctx, &provider.SubscriptionInput{
+		IdempotencyKey:         lifecycleIdempotencyKey(payment, "subscription_pause"),
+		ProviderSubscriptionID: derefString(payment.ProviderSubscriptionID),
+		Reason:                 reason,
+	})
+	if err != nil {
+		return nil, s.recordSubscriptionFailure(ctx, payment, "subscription_pause_failed", err)
+	}
+
+	payment.SubscriptionPaused = true
+	return s.saveSubscriptionChange(ctx, payment, payment.Status, "subscription_paused", map[string]interface{}{"reason": reason})
+}
+
+func (s *PaymentService) ResumeSubscription(ctx context.Context, req resumeSubscriptionRequest) (*entity.Payment, error) {
+	payment, providerClient, err := s.loadSubscription(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	if !payment.SubscriptionPaused {
+		return nil, fmt.Errorf("%w: subscription is not paused", ErrInvalidStatus)
+	}
+
+	err = providerClient.ResumeSubscription(ctx, &provider.SubscriptionInput{
+		IdempotencyKey:         lifecycleIdempotencyKey(payment, "subscription_resume"),
+		ProviderSubscriptionID: derefString(payment.ProviderSubscriptionID),
+	})
+	if err != nil {
+		return nil, s.recordSubscriptionFailure(ctx, payment, "subscription_resume_failed", err)
+	}
+
+	payment.SubscriptionPaused = false
+	return s.saveSubscriptionChange(ctx, payment, payment.Status, "subscription_resumed", nil)
+}
+
+func (s *PaymentService) CancelSubscription(ctx context.Context, req cancelSubscriptionRequest) (*entity.Payment, error) {
+	payment, providerClient, err := s.loadSubscription(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	if req.GetAtPeriodEnd() && payment.CancelAtPeriodEnd {
+		return payment, nil
+	}
+
+	reason := strings.TrimSpace(req.GetReason())
+	err = providerClient.CancelSubscription(ctx, &provider.SubscriptionInput{
+		IdempotencyKey:         lifecycleIdempotencyKey(payment, "subscription_cancel"),
+		ProviderSubscriptionID: derefString(payment.ProviderSubscriptionID),
+		AtPeriodEnd:            req.GetAtPeriodEnd(),
+		Reason:                 reason,
+	})
+	if err != nil {
+		return nil, s.recordSubscriptionFailure(ctx, payment, "subscription_cancel_failed", err)
+	}
+
+	payload := map[string]interface{}{"reason": reason}
+	if req.GetAtPeriodEnd() {
+		payment.CancelAtPeriodEnd = true
+		return s.saveSubscriptionChange(ctx, payment, payment.Status, "subscription_cancel_scheduled", payload)
+	}
+
+	oldStatus := payment.Status
+	if _, err := transitionStatus(payment, int32(types.PaymentStatus_PAYMENT_STATUS_CANCELED)); err != nil {
+		return nil, err
+	}
+	payment.CancelAtPeriodEnd = false
+	payment.SubscriptionPaused = false
+	return s.saveSubscriptionChange(ctx, payment, oldStatus, "subscription_canceled", payload)
+}
+
+func (s *PaymentService) UpdateSubscription(ctx context.Context, req updateSubscriptionRequest) (*entity.Payment, error) {
+	if req.GetAmountCents() < 0 || req.GetRecurringIntervalCount() < 0 {
+		return nil, ErrInvalidRequest
+	}
+
+	payment, providerClient, err := s.loadSubscription(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	currentAmount := payment.AmountCents
+	if payment.SubscriptionAmountCents != nil {
+		currentAmount = *payment.SubscriptionAmountCents
+	}
+	amount := currentAmount
+	if req.GetAmountCents() > 0 {
+		amount = req.GetAmountCents()
+	}
+	interval := strings.ToLower(strings.TrimSpace(req.GetRecurringInterval()))
+	if interval == "" {
+		interval = derefString(payment.RecurringInterval)
+	}
+	intervalCount := req.GetRecurringIntervalCount()
+	if intervalCount == 0 && payment.RecurringIntervalCount != nil {
+		intervalCount = *payment.RecurringIntervalCount
+	}
+	if interval == "" || intervalCount <= 0 {
+		return nil, fmt.Errorf("%w: recurring interval is required", ErrInvalidRequest)
+	}
+	if amount == currentAmount && interval == derefString(payment.RecurringInterval) && payment.RecurringIntervalCount != nil && intervalCount == *payment.RecurringIntervalCount {
+		return payment, nil
+	}
+
+	err = providerClient.UpdateSubscription(ctx, &provider.UpdateSubscriptionInput{
+		IdempotencyKey:         lifecycleIdempotencyKey(payment, fmt.Sprintf("subscription_update:%d:%s:%d", amount, interval, intervalCount)),
+		ProviderSubscriptionID: derefString(payment.ProviderSubscriptionID),
+		Currency:               payment.Currency,
+		AmountCents:            amount,
+		RecurringInterval:      interval,
+		RecurringIntervalCount: intervalCount,
+	})
+	if err != nil {
+		return nil, s.recordSubscriptionFailure(ctx, payment, "subscription_update_failed", err)
+	}
+
+	payload := map[string]interface{}{
+		"old_amount_cents":             currentAmount,
+		"old_recurring_interval":       derefString(payment.RecurringInterval),
+		"old_recurring_interval_count": derefInt32(payment.RecurringIntervalCount),
+		"amount_cents":                 amount,
+		"recurring_interval":           interval,
+		"recurring_interval_count":     intervalCount,
+	}
+	// AmountCents stays what was charged, so refunds remain capped by it.
+	payment.SubscriptionAmountCents = &amount
+	payment.RecurringInterval = &interval
+	payment.RecurringIntervalCount = &intervalCount
+	return s.saveSubscriptionChange(ctx, payment, payment.Status, "subscription_updated", payload)
+}
+
+// loadSubscription returns a recurring payment that still has a live provider
+// subscription, together with the provider client that owns it.
+func (s *PaymentService) loadSubscription(ctx context.Context, id uint64) (*entity.Payment, provider.Provider, error) {
+	payment, err := s.findPaymentForCaller(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if payment.PaymentType != int32(types.PaymentType_PAYMENT_TYPE_RECURRING) {
+		return nil, nil, fmt.Errorf("%w: payment is not recurring", ErrInvalidRequest)
+	}
+	if strings.TrimSpace(derefString(payment.ProviderSubscriptionID)) == "" {
+		return nil, nil, fmt.Errorf("%w: payment has no provider subscription", ErrInvalidStatus)
+	}
+	if payment.Status == int32(types.PaymentStatus_PAYMENT_STATUS_CANCELED) || payment.Status == int32(types.PaymentStatus_PAYMENT_STATUS_EXPIRED) {
+		return nil, nil, fmt.Errorf("%w: subscription is no longer active", ErrInvalidStatus)
+	}
+
+	providerClient, err := s.providerReg.Get(payment.Provider)
+	if err != nil {
+		if errors.Is(err, provider.ErrProviderNotSupported) {
+			return nil, nil, ErrProviderUnsupported
+		}
+		return nil, nil, err
+	}
+
+	return payment, providerClient, nil
+}
+
+func (s *PaymentService) saveSubscriptionChange(ctx context.Context, payment *entity.Payment, oldStatus int32, eventType string, payload map[string]interface{}) (*entity.Payment, error) {
+	now := time.Now().UTC()
+	payment.UpdatedAt = now
+
+	var oldStatusPtr *int32
+	if oldStatus != payment.Status {
+		oldStatusPtr = &oldStatus
+	}
+	var payloadJSON *string
+	if len(payload) > 0 {
+		raw, _ := json.Marshal(payload)
+		encoded := string(raw)
+		payloadJSON = &encoded
+	}
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		if err := s.paymentRepo.Update(ctx, payment); err != nil {
+			return mapUpdateError(err)
+		}
+		err := s.eventRepo.Create(ctx, &entity.PaymentEvent{
+			PaymentID:   payment.ID,
+			EventType:   eventType,
+			OldStatus:   oldStatusPtr,
+			NewStatus:   payment.Status,
+			PayloadJSON: payloadJSON,
+			CreatedAt:   now,
+		})
+		if err != nil {
+			return err
+		}
+		return s.queueStatusCallback(ctx, payment, oldStatus, now)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return payment, nil
+}
+
+func (s *PaymentService) recordSubscriptionFailure(ctx context.Context, payment *entity.Payment, eventType string, providerErr error) error {
+	payloadJSON, _ := json.Marshal(map[string]string{"error": truncate(providerErr.Error(), 1024)})
+	payload := string(payloadJSON)
+	failure := fmt.Errorf("%w: %v", ErrProviderRequestFailed, providerErr)
+	err := s.eventRepo.Create(ctx, &entity.PaymentEvent{
+		PaymentID:   payment.ID,
+		EventType:   eventType,
+		NewStatus:   payment.Status,
+		PayloadJSON: &payload,
+		CreatedAt:   time.Now().UTC(),
+	})
+	if err != nil {
+		return errors.Join(failure, err)
+	}
+
+	return failure
+}
+
+func derefInt32(v *int32) int32 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
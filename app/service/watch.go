@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/vibast-solutions/ms-go-payments/app/entity"
+	"github.com/vibast-solutions/ms-go-payments/app/repository"
+)
+
+const defaultWatchPollInterval = 2 * time.Second
+
+// WatchPayment sends the current state of the payment and then every status
+// change until the payment reaches a terminal status or ctx ends. Changes are
+// found by tailing payment_events, so a watcher sees updates written by any
+// replica without a broker. send receives the payment and the last event ID
+// reflected in it.
+func (s *PaymentService) WatchPayment(ctx context.Context, id uint64, send func(*entity.Payment, uint64) error) error {
+	payment, err := s.findPaymentForCaller(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// Skip the history first: the payment read afterwards already reflects
+	// every event up to the cursor.
+	cursor, _, err := s.tailPaymentEvents(ctx, payment.ID, 0)
+	if err != nil {
+		return err
+	}
+	if payment, err = s.findPaymentForCaller(ctx, id); err != nil {
+		return err
+	}
+	if err := send(payment, cursor); err != nil {
+		return err
+	}
+	if terminalStatus(payment.Status) {
+		return nil
+	}
+
+	interval := s.paymentsCfg.WatchPollInterval
+	if interval <= 0 {
+		interval = defaultWatchPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	lastStatus := payment.Status
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		next, changed, err := s.tailPaymentEvents(ctx, payment.ID, cursor)
+		if err != nil {
+			return err
+		}
+		if !changed {
+			continue
+		}
+		cursor = next
+
+		current, err := s.paymentRepo.FindByID(ctx, payment.ID)
+		if err != nil {
+			return err
+		}
+		if current == nil {
+			return ErrPaymentNotFound
+		}
+		if current.Status == lastStatus {
+			continue
+		}
+		lastStatus = current.Status
+		if err := send(current, cursor); err != nil {
+			return err
+		}
+		if terminalStatus(current.Status) {
+			return nil
+		}
+	}
+}
+
+// tailPaymentEvents returns the ID of the newest event after cursor and
+// whether there was any.
+func (s *PaymentService) tailPaymentEvents(ctx context.Context, paymentID, cursor uint64) (uint64, bool, error) {
+	changed := false
+	for {
+		events, err := s.eventRepo.ListByPayment(ctx, repository.PaymentEventFilter{
+			PaymentID: paymentID,
+			AfterID:   cursor,
+			Limit:     defaultListLimit,
+		})
+		if err != nil {
+			return cursor, changed, err
+		}
+		if len(events) == 0 {
+			return cursor, changed, nil
+		}
+		cursor = events[len(events)-1].ID
+		changed = true
+		if len(events) < int(defaultListLimit) {
+			return cursor, changed, nil
+		}
+	}
+}
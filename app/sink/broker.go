@@ -0,0 +1,188 @@
+package sink
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Publisher sends one message to a broker topic (NATS subject, Redis channel).
+type Publisher interface {
+	Publish(ctx context.Context, topic string, body []byte) error
+}
+
+// BrokerSink publishes callbacks to the topic named by the destination, e.g.
+// nats://payments.status or redis://payments.status. The published body is the
+// JSON form of PaymentCallbackRequest, so it carries the callback ID and event
+// type that HTTP receivers get as headers.
+type BrokerSink struct {
+	scheme    string
+	publisher Publisher
+}
+
+func NewBrokerSink(scheme string, publisher Publisher) *BrokerSink {
+	return &BrokerSink{scheme: strings.ToLower(scheme), publisher: publisher}
+}
+
+func (s *BrokerSink) Schemes() []string {
+	return []string{s.scheme}
+}
+
+// topicValidator is implemented by publishers whose broker restricts topic
+// names.
+type topicValidator interface {
+	ValidateTopic(topic string) error
+}
+
+func (s *BrokerSink) ValidateDestination(destination string) error {
+	_, topic, err := splitDestination(destination)
+	if err != nil {
+		return err
+	}
+	if validator, ok := s.publisher.(topicValidator); ok {
+		return validator.ValidateTopic(topic)
+	}
+	return nil
+}
+
+func (s *BrokerSink) Deliver(ctx context.Context, msg *Message) error {
+	_, topic, err := splitDestination(msg.Destination)
+	if err != nil {
+		return err
+	}
+
+	req, err := callbackRequest(msg)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	return s.publisher.Publish(ctx, topic, body)
+}
+
+// NATSPublisher speaks the plain NATS text protocol; every publish opens a
+// short-lived connection and waits for the PONG that confirms it was processed.
+type NATSPublisher struct {
+	addr    string
+	timeout time.Duration
+}
+
+func NewNATSPublisher(addr string, timeout time.Duration) *NATSPublisher {
+	return &NATSPublisher{addr: strings.TrimPrefix(strings.TrimSpace(addr), "nats://"), timeout: timeout}
+}
+
+// ValidateTopic rejects subjects that would break the PUB line: whitespace
+// separates its arguments and control characters end it early.
+func (p *NATSPublisher) ValidateTopic(subject string) error {
+	if subject == "" || strings.IndexFunc(subject, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsControl(r)
+	}) >= 0 {
+		return fmt.Errorf("%w: invalid nats subject %q", ErrInvalidDestination, subject)
+	}
+	return nil
+}
+
+func (p *NATSPublisher) Publish(ctx context.Context, subject string, body []byte) error {
+	if err := p.ValidateTopic(subject); err != nil {
+		return err
+	}
+	conn, reader, err := dialBroker(ctx, p.addr, p.timeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(line, "INFO") {
+		return fmt.Errorf("nats: unexpected greeting %q", strings.TrimSpace(line))
+	}
+
+	cmd := fmt.Sprintf("CONNECT {\"verbose\":false,\"pedantic\":false}\r\nPUB %s %d\r\n%s\r\nPING\r\n", subject, len(body), body)
+	if _, err := conn.Write([]byte(cmd)); err != nil {
+		return err
+	}
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			if _, err := conn.Write([]byte("PONG\r\n")); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("nats: %s", line)
+		}
+	}
+}
+
+// RedisPublisher issues PUBLISH over RESP on a short-lived connection.
+type RedisPublisher struct {
+	addr    string
+	timeout time.Duration
+}
+
+func NewRedisPublisher(addr string, timeout time.Duration) *RedisPublisher {
+	return &RedisPublisher{addr: strings.TrimPrefix(strings.TrimSpace(addr), "redis://"), timeout: timeout}
+}
+
+func (p *RedisPublisher) Publish(ctx context.Context, channel string, body []byte) error {
+	conn, reader, err := dialBroker(ctx, p.addr, p.timeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	cmd := fmt.Sprintf("*3\r\n$7\r\nPUBLISH\r\n$%d\r\n%s\r\n$%d\r\n%s\r\n", len(channel), channel, len(body), body)
+	if _, err := conn.Write([]byte(cmd)); err != nil {
+		return err
+	}
+
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, ":") {
+		return fmt.Errorf("redis: %s", line)
+	}
+	return nil
+}
+
+func dialBroker(ctx context.Context, addr string, timeout time.Duration) (net.Conn, *bufio.Reader, error) {
+	if addr == "" {
+		return nil, nil, ErrSinkNotConfigured
+	}
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+
+	dialer := net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, nil, err
+	}
+	deadline := time.Now().Add(timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	_ = conn.SetDeadline(deadline)
+
+	return conn, bufio.NewReader(conn), nil
+}
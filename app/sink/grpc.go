@@ -0,0 +1,102 @@
+package sink
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vibast-solutions/ms-go-payments/app/callbacksig"
+	"github.com/vibast-solutions/ms-go-payments/app/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+// GRPCSink calls PaymentCallbackReceiver.DeliverPaymentCallback on grpc://
+// destinations. Connections are kept open per target; each delivery, including
+// the connection attempt, is bounded by the timeout.
+type GRPCSink struct {
+	apiKey  string
+	timeout time.Duration
+
+	mu    sync.Mutex
+	conns map[string]*grpc.ClientConn
+}
+
+func NewGRPCSink(apiKey string, timeout time.Duration) *GRPCSink {
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return &GRPCSink{
+		apiKey:  strings.TrimSpace(apiKey),
+		timeout: timeout,
+		conns:   make(map[string]*grpc.ClientConn),
+	}
+}
+
+func (s *GRPCSink) Schemes() []string {
+	return []string{"grpc"}
+}
+
+func (s *GRPCSink) Deliver(ctx context.Context, msg *Message) error {
+	_, target, err := splitDestination(msg.Destination)
+	if err != nil {
+		return err
+	}
+
+	req, err := callbackRequest(msg)
+	if err != nil {
+		return err
+	}
+
+	conn, err := s.conn(target)
+	if err != nil {
+		return err
+	}
+
+	// The client connects lazily, so the deadline covers dialing as well as
+	// a receiver that never answers.
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	md := metadata.Pairs("x-request-id", msg.RequestID)
+	if s.apiKey != "" {
+		md.Append("x-api-key", s.apiKey)
+	}
+	if msg.Signature != "" {
+		md.Append(callbacksig.MetadataKey, msg.Signature)
+	}
+	_, err = types.NewPaymentCallbackReceiverClient(conn).DeliverPaymentCallback(metadata.NewOutgoingContext(ctx, md), req)
+	return err
+}
+
+// Close releases every cached connection.
+func (s *GRPCSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var firstErr error
+	for target, conn := range s.conns {
+		if err := conn.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(s.conns, target)
+	}
+	return firstErr
+}
+
+func (s *GRPCSink) conn(target string) (*grpc.ClientConn, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if conn, ok := s.conns[target]; ok {
+		return conn, nil
+	}
+	conn, err := grpc.NewClient(target, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, err
+	}
+	s.conns[target] = conn
+	return conn, nil
+}
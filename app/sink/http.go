@@ -0,0 +1,66 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/vibast-solutions/ms-go-payments/app/callbacksig"
+)
+
+// HTTPSink POSTs the callback body to http(s) destinations.
+type HTTPSink struct {
+	client *http.Client
+	apiKey string
+}
+
+func NewHTTPSink(client *http.Client, apiKey string) *HTTPSink {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &HTTPSink{client: client, apiKey: strings.TrimSpace(apiKey)}
+}
+
+func (s *HTTPSink) Schemes() []string {
+	return []string{"http", "https"}
+}
+
+func (s *HTTPSink) ValidateDestination(destination string) error {
+	parsed, err := url.ParseRequestURI(destination)
+	if err != nil || parsed.Host == "" {
+		return fmt.Errorf("%w: %q is not an absolute URL", ErrInvalidDestination, destination)
+	}
+	return nil
+}
+
+func (s *HTTPSink) Deliver(ctx context.Context, msg *Message) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, msg.Destination, bytes.NewReader(msg.Body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Request-ID", msg.RequestID)
+	req.Header.Set("X-Callback-ID", strconv.FormatUint(msg.ID, 10))
+	req.Header.Set("X-Callback-Event", msg.EventType)
+	if s.apiKey != "" {
+		req.Header.Set("X-API-Key", s.apiKey)
+	}
+	if msg.Signature != "" {
+		req.Header.Set(callbacksig.Header, msg.Signature)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("callback endpoint returned status=%d", resp.StatusCode)
+	}
+	return nil
+}
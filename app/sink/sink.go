@@ -0,0 +1,120 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/vibast-solutions/ms-go-payments/app/types"
+)
+
+var (
+	ErrSinkNotConfigured  = errors.New("callback sink is not configured")
+	ErrInvalidDestination = errors.New("invalid callback destination")
+)
+
+// Message is one outbound status callback. Body is the JSON envelope that was
+// snapshotted when the callback was queued; Signature is the callbacksig header
+// value computed over Body, empty when the caller has no signing secret.
+type Message struct {
+	ID          uint64
+	PaymentID   uint64
+	EventType   string
+	RequestID   string
+	Destination string
+	Body        []byte
+	Signature   string
+}
+
+// Sink delivers callbacks for the destination schemes it declares.
+type Sink interface {
+	Schemes() []string
+	Deliver(ctx context.Context, msg *Message) error
+}
+
+// destinationValidator is implemented by sinks that can tell a malformed
+// destination apart before anything is queued for it.
+type destinationValidator interface {
+	ValidateDestination(destination string) error
+}
+
+type Registry struct {
+	sinks map[string]Sink
+}
+
+func NewRegistry(sinks ...Sink) *Registry {
+	items := make(map[string]Sink)
+	for _, s := range sinks {
+		for _, scheme := range s.Schemes() {
+			items[strings.ToLower(scheme)] = s
+		}
+	}
+	return &Registry{sinks: items}
+}
+
+// Deliver routes the message to the sink registered for its destination scheme.
+func (r *Registry) Deliver(ctx context.Context, msg *Message) error {
+	scheme, _, err := splitDestination(msg.Destination)
+	if err != nil {
+		return err
+	}
+	s, ok := r.sinks[scheme]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrSinkNotConfigured, scheme)
+	}
+	return s.Deliver(ctx, msg)
+}
+
+// Validate checks that a destination has a registered scheme and a target its
+// sink can deliver to, so that a bad status_callback_url is rejected when the
+// payment is created instead of dead-lettering every callback.
+func (r *Registry) Validate(destination string) error {
+	scheme, _, err := splitDestination(destination)
+	if err != nil {
+		return err
+	}
+	if strings.IndexFunc(destination, unicode.IsControl) >= 0 {
+		return fmt.Errorf("%w: %q contains control characters", ErrInvalidDestination, destination)
+	}
+	s, ok := r.sinks[scheme]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrSinkNotConfigured, scheme)
+	}
+	if validator, ok := s.(destinationValidator); ok {
+		return validator.ValidateDestination(strings.TrimSpace(destination))
+	}
+	return nil
+}
+
+// splitDestination returns the lower-cased scheme and the remainder of a
+// destination such as "https://caller/hook", "grpc://host:9090" or
+// "nats://payments.status".
+func splitDestination(destination string) (string, string, error) {
+	destination = strings.TrimSpace(destination)
+	scheme, target, ok := strings.Cut(destination, "://")
+	if !ok || scheme == "" || target == "" {
+		return "", "", fmt.Errorf("%w: %q", ErrInvalidDestination, destination)
+	}
+	return strings.ToLower(scheme), target, nil
+}
+
+// callbackRequest expands the stored envelope into the message used by the
+// non-HTTP sinks.
+func callbackRequest(msg *Message) (*types.PaymentCallbackRequest, error) {
+	var envelope types.ChargeEnvelopeResponse
+	if err := json.Unmarshal(msg.Body, &envelope); err != nil {
+		return nil, err
+	}
+	return &types.PaymentCallbackRequest{
+		CallbackId:  msg.ID,
+		EventType:   msg.EventType,
+		RequestId:   msg.RequestID,
+		Payment:     envelope.Payment,
+		Charge:      envelope.Charge,
+		PayloadJson: string(msg.Body),
+		Signature:   msg.Signature,
+	}, nil
+}
@@ -0,0 +1,304 @@
+package sink
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vibast-solutions/ms-go-payments/app/callbacksig"
+	"github.com/vibast-solutions/ms-go-payments/app/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const testBody = `{"payment":{"id":7,"request_id":"req-7","status":3}}`
+
+func newTestMessage(destination string) *Message {
+	return &Message{
+		ID:          11,
+		PaymentID:   7,
+		EventType:   "payment.status_changed",
+		RequestID:   "req-7",
+		Destination: destination,
+		Body:        []byte(testBody),
+		Signature:   "t=1700000000,v1=abc",
+	}
+}
+
+type recordingPublisher struct {
+	topic string
+	body  []byte
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, topic string, body []byte) error {
+	p.topic = topic
+	p.body = body
+	return nil
+}
+
+func TestRegistryRoutesByScheme(t *testing.T) {
+	nats := &recordingPublisher{}
+	redis := &recordingPublisher{}
+	reg := NewRegistry(NewBrokerSink("nats", nats), NewBrokerSink("redis", redis))
+
+	if err := reg.Deliver(context.Background(), newTestMessage("NATS://payments.status")); err != nil {
+		t.Fatalf("deliver failed: %v", err)
+	}
+	if nats.topic != "payments.status" || redis.topic != "" {
+		t.Fatalf("expected nats sink to receive the message, nats=%q redis=%q", nats.topic, redis.topic)
+	}
+
+	var published types.PaymentCallbackRequest
+	if err := json.Unmarshal(nats.body, &published); err != nil {
+		t.Fatalf("decode published body: %v", err)
+	}
+	if published.CallbackId != 11 || published.EventType != "payment.status_changed" || published.GetPayment().GetId() != 7 {
+		t.Fatalf("unexpected published callback: %+v", &published)
+	}
+	if published.PayloadJson != testBody || published.Signature != "t=1700000000,v1=abc" {
+		t.Fatalf("expected signed payload to be published, got payload=%q signature=%q", published.PayloadJson, published.Signature)
+	}
+
+	if err := reg.Deliver(context.Background(), newTestMessage("https://caller/hook")); !errors.Is(err, ErrSinkNotConfigured) {
+		t.Fatalf("expected ErrSinkNotConfigured, got %v", err)
+	}
+	if err := reg.Deliver(context.Background(), newTestMessage("caller/hook")); !errors.Is(err, ErrInvalidDestination) {
+		t.Fatalf("expected ErrInvalidDestination, got %v", err)
+	}
+}
+
+func TestRegistryValidatesDestinations(t *testing.T) {
+	reg := NewRegistry(
+		NewHTTPSink(nil, ""),
+		NewGRPCSink("", time.Second),
+		NewBrokerSink("nats", NewNATSPublisher("", time.Second)),
+		NewBrokerSink("redis", NewRedisPublisher("", time.Second)),
+	)
+
+	for _, destination := range []string{"https://caller.example/hook", "grpc://caller:9090", "nats://payments.status", "redis://payments status"} {
+		if err := reg.Validate(destination); err != nil {
+			t.Fatalf("expected %q to be valid, got %v", destination, err)
+		}
+	}
+	for _, destination := range []string{"caller/hook", "https:///hook", "nats://payments status", "nats://payments.status\r\nPUB other 0", "redis://payments\x00status"} {
+		if err := reg.Validate(destination); !errors.Is(err, ErrInvalidDestination) {
+			t.Fatalf("expected ErrInvalidDestination for %q, got %v", destination, err)
+		}
+	}
+	if err := reg.Validate("amqp://queue"); !errors.Is(err, ErrSinkNotConfigured) {
+		t.Fatalf("expected ErrSinkNotConfigured, got %v", err)
+	}
+}
+
+func TestHTTPSinkPostsBodyAndHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != testBody {
+			t.Errorf("unexpected body %q", body)
+		}
+		if r.Header.Get("X-Request-ID") != "req-7" || r.Header.Get("X-Callback-ID") != "11" || r.Header.Get("X-API-Key") != "app-key" {
+			t.Errorf("unexpected headers %v", r.Header)
+		}
+		if r.Header.Get(callbacksig.Header) != "t=1700000000,v1=abc" {
+			t.Errorf("unexpected headers %v", r.Header)
+		}
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	s := NewHTTPSink(server.Client(), "app-key")
+	if err := s.Deliver(context.Background(), newTestMessage(server.URL+"/ok")); err != nil {
+		t.Fatalf("deliver failed: %v", err)
+	}
+	err := s.Deliver(context.Background(), newTestMessage(server.URL+"/fail"))
+	if err == nil || !strings.Contains(err.Error(), "status=502") {
+		t.Fatalf("expected status error, got %v", err)
+	}
+}
+
+type testCallbackReceiver struct {
+	types.UnimplementedPaymentCallbackReceiverServer
+	received chan *types.PaymentCallbackRequest
+	apiKeys  chan string
+}
+
+func (r *testCallbackReceiver) DeliverPaymentCallback(ctx context.Context, req *types.PaymentCallbackRequest) (*types.MessageResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	r.apiKeys <- strings.Join(md.Get("x-api-key"), ",")
+	r.received <- req
+	return &types.MessageResponse{Message: "ok"}, nil
+}
+
+func TestGRPCSinkCallsReceiver(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	receiver := &testCallbackReceiver{received: make(chan *types.PaymentCallbackRequest, 1), apiKeys: make(chan string, 1)}
+	server := grpc.NewServer()
+	types.RegisterPaymentCallbackReceiverServer(server, receiver)
+	go func() { _ = server.Serve(lis) }()
+	defer server.Stop()
+
+	s := NewGRPCSink("app-key", 5*time.Second)
+	defer s.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.Deliver(ctx, newTestMessage("grpc://"+lis.Addr().String())); err != nil {
+		t.Fatalf("deliver failed: %v", err)
+	}
+
+	req := <-receiver.received
+	if req.CallbackId != 11 || req.RequestId != "req-7" || req.GetPayment().GetId() != 7 {
+		t.Fatalf("unexpected callback request: %+v", req)
+	}
+	if key := <-receiver.apiKeys; key != "app-key" {
+		t.Fatalf("expected x-api-key metadata, got %q", key)
+	}
+}
+
+type hangingCallbackReceiver struct {
+	types.UnimplementedPaymentCallbackReceiverServer
+}
+
+func (r *hangingCallbackReceiver) DeliverPaymentCallback(ctx context.Context, _ *types.PaymentCallbackRequest) (*types.MessageResponse, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestGRPCSinkTimesOutHangingReceiver(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	server := grpc.NewServer()
+	types.RegisterPaymentCallbackReceiverServer(server, &hangingCallbackReceiver{})
+	go func() { _ = server.Serve(lis) }()
+	defer server.Stop()
+
+	s := NewGRPCSink("", 200*time.Millisecond)
+	defer s.Close()
+
+	started := time.Now()
+	err = s.Deliver(context.Background(), newTestMessage("grpc://"+lis.Addr().String()))
+	if status.Code(err) != codes.DeadlineExceeded {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Fatalf("expected delivery to give up after the timeout, took %s", elapsed)
+	}
+}
+
+// serveOnce accepts a single connection and hands it to handle.
+func serveOnce(t *testing.T, handle func(conn net.Conn, reader *bufio.Reader)) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { _ = lis.Close() })
+
+	go func() {
+		conn, err := lis.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		handle(conn, bufio.NewReader(conn))
+	}()
+
+	return lis.Addr().String()
+}
+
+func TestNATSPublisherPublishes(t *testing.T) {
+	got := make(chan string, 1)
+	addr := serveOnce(t, func(conn net.Conn, reader *bufio.Reader) {
+		_, _ = conn.Write([]byte("INFO {\"server_id\":\"test\"}\r\n"))
+		var pub string
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch {
+			case strings.HasPrefix(line, "PUB "):
+				fields := strings.Fields(line)
+				size, _ := strconv.Atoi(fields[2])
+				payload := make([]byte, size+2)
+				_, _ = io.ReadFull(reader, payload)
+				pub = fields[1] + " " + string(payload[:size])
+			case strings.HasPrefix(line, "PING"):
+				got <- pub
+				_, _ = conn.Write([]byte("PONG\r\n"))
+				return
+			}
+		}
+	})
+
+	p := NewNATSPublisher("nats://"+addr, time.Second)
+	if err := p.Publish(context.Background(), "payments.status", []byte(`{"a":1}`)); err != nil {
+		t.Fatalf("publish failed: %v", err)
+	}
+	if pub := <-got; pub != `payments.status {"a":1}` {
+		t.Fatalf("unexpected publish %q", pub)
+	}
+}
+
+func TestRedisPublisherPublishes(t *testing.T) {
+	got := make(chan []string, 1)
+	addr := serveOnce(t, func(conn net.Conn, reader *bufio.Reader) {
+		header, _ := reader.ReadString('\n')
+		count, _ := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(header, "*")))
+		args := make([]string, 0, count)
+		for i := 0; i < count; i++ {
+			sizeLine, _ := reader.ReadString('\n')
+			size, _ := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(sizeLine, "$")))
+			value := make([]byte, size+2)
+			_, _ = io.ReadFull(reader, value)
+			args = append(args, string(value[:size]))
+		}
+		got <- args
+		_, _ = conn.Write([]byte(":1\r\n"))
+	})
+
+	p := NewRedisPublisher(addr, time.Second)
+	if err := p.Publish(context.Background(), "payments.status", []byte(`{"a":1}`)); err != nil {
+		t.Fatalf("publish failed: %v", err)
+	}
+	args := <-got
+	if fmt.Sprint(args) != `[PUBLISH payments.status {"a":1}]` {
+		t.Fatalf("unexpected redis command %q", args)
+	}
+}
+
+func TestBrokerPublisherRequiresAddress(t *testing.T) {
+	if err := NewRedisPublisher("", time.Second).Publish(context.Background(), "x", nil); !errors.Is(err, ErrSinkNotConfigured) {
+		t.Fatalf("expected ErrSinkNotConfigured, got %v", err)
+	}
+}
+
+func TestNATSPublisherRejectsInvalidSubject(t *testing.T) {
+	p := NewNATSPublisher("127.0.0.1:1", time.Second)
+	for _, subject := range []string{"", "payments status", "payments.status\r\nPUB other 0"} {
+		if err := p.Publish(context.Background(), subject, []byte(`{}`)); !errors.Is(err, ErrInvalidDestination) {
+			t.Fatalf("expected ErrInvalidDestination for %q, got %v", subject, err)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/labstack/echo/v4"
 )
@@ -91,6 +92,123 @@ func (r *GetPaymentRequest) Validate() error {
 	return nil
 }
 
+func NewGetPaymentByRequestIDRequestFromContext(ctx echo.Context) (*GetPaymentByRequestIDRequest, error) {
+	return &GetPaymentByRequestIDRequest{
+		CallerService: strings.TrimSpace(ctx.QueryParam("caller_service")),
+		RequestId:     strings.TrimSpace(ctx.QueryParam("request_id")),
+	}, nil
+}
+
+// Validate leaves caller_service optional: it defaults to the authenticated
+// caller service.
+func (r *GetPaymentByRequestIDRequest) Validate() error {
+	if strings.TrimSpace(r.GetRequestId()) == "" {
+		return errors.New("request_id is required")
+	}
+	return nil
+}
+
+func NewGetPaymentByProviderRefRequestFromContext(ctx echo.Context) (*GetPaymentByProviderRefRequest, error) {
+	req := &GetPaymentByProviderRefRequest{
+		ProviderPaymentId:      strings.TrimSpace(ctx.QueryParam("provider_payment_id")),
+		ProviderSubscriptionId: strings.TrimSpace(ctx.QueryParam("provider_subscription_id")),
+	}
+
+	provider, err := parseProviderQueryParam(ctx.QueryParam("provider"))
+	if err != nil {
+		return nil, err
+	}
+	req.Provider = provider
+
+	return req, nil
+}
+
+func (r *GetPaymentByProviderRefRequest) Validate() error {
+	if r.GetProvider() != ProviderType_PROVIDER_TYPE_STRIPE {
+		return errors.New("invalid provider")
+	}
+	hasPaymentID := strings.TrimSpace(r.GetProviderPaymentId()) != ""
+	hasSubscriptionID := strings.TrimSpace(r.GetProviderSubscriptionId()) != ""
+	if hasPaymentID == hasSubscriptionID {
+		return errors.New("exactly one of provider_payment_id or provider_subscription_id is required")
+	}
+	return nil
+}
+
+func (r *WatchPaymentRequest) Validate() error {
+	if r.GetId() == 0 {
+		return errors.New("invalid payment id")
+	}
+	return nil
+}
+
+func NewListPaymentChargesRequestFromContext(ctx echo.Context) (*ListPaymentChargesRequest, error) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &ListPaymentChargesRequest{Id: id}, nil
+}
+
+func (r *ListPaymentChargesRequest) Validate() error {
+	if r.GetId() == 0 {
+		return errors.New("invalid payment id")
+	}
+	return nil
+}
+
+func NewListPaymentEventsRequestFromContext(ctx echo.Context) (*ListPaymentEventsRequest, error) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &ListPaymentEventsRequest{
+		Id:        id,
+		EventType: strings.TrimSpace(ctx.QueryParam("event_type")),
+		Limit:     100,
+	}
+
+	if cursorRaw := strings.TrimSpace(ctx.QueryParam("cursor")); cursorRaw != "" {
+		cursor, err := strconv.ParseUint(cursorRaw, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		req.Cursor = cursor
+	}
+
+	if limitRaw := strings.TrimSpace(ctx.QueryParam("limit")); limitRaw != "" {
+		limit, err := strconv.ParseInt(limitRaw, 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		req.Limit = int32(limit)
+	}
+
+	if payloadRaw := strings.TrimSpace(ctx.QueryParam("include_payload")); payloadRaw != "" {
+		includePayload, err := strconv.ParseBool(payloadRaw)
+		if err != nil {
+			return nil, err
+		}
+		req.IncludePayload = includePayload
+	}
+
+	return req, nil
+}
+
+func (r *ListPaymentEventsRequest) Validate() error {
+	if r.GetId() == 0 {
+		return errors.New("invalid payment id")
+	}
+	if r.Limit == 0 {
+		r.Limit = 100
+	}
+	if r.GetLimit() <= 0 || r.GetLimit() > 500 {
+		return errors.New("limit must be between 1 and 500")
+	}
+	return nil
+}
+
 func NewListPaymentsRequestFromContext(ctx echo.Context) (*ListPaymentsRequest, error) {
 	req := &ListPaymentsRequest{
 		RequestId:    strings.TrimSpace(ctx.QueryParam("request_id")),
@@ -99,28 +217,73 @@ func NewListPaymentsRequestFromContext(ctx echo.Context) (*ListPaymentsRequest,
 		ResourceId:   strings.TrimSpace(ctx.QueryParam("resource_id")),
 		Limit:        100,
 		Offset:       0,
+		PageToken:    strings.TrimSpace(ctx.QueryParam("page_token")),
+		CreatedFrom:  strings.TrimSpace(ctx.QueryParam("created_from")),
+		CreatedTo:    strings.TrimSpace(ctx.QueryParam("created_to")),
+		UpdatedFrom:  strings.TrimSpace(ctx.QueryParam("updated_from")),
+		UpdatedTo:    strings.TrimSpace(ctx.QueryParam("updated_to")),
+		Currency:     strings.ToUpper(strings.TrimSpace(ctx.QueryParam("currency"))),
+		CustomerRef:  strings.TrimSpace(ctx.QueryParam("customer_ref")),
+	}
+	req.ProviderPaymentId = strings.TrimSpace(ctx.QueryParam("provider_payment_id"))
+	req.ProviderSubscriptionId = strings.TrimSpace(ctx.QueryParam("provider_subscription_id"))
+
+	// status may be repeated or comma-separated; a single value keeps the
+	// has_status/status form.
+	statuses := make([]PaymentStatus, 0)
+	for _, value := range ctx.QueryParams()["status"] {
+		for _, statusRaw := range strings.Split(value, ",") {
+			if statusRaw = strings.TrimSpace(statusRaw); statusRaw == "" {
+				continue
+			}
+			status, err := strconv.ParseInt(statusRaw, 10, 32)
+			if err != nil {
+				return nil, err
+			}
+			statuses = append(statuses, PaymentStatus(status))
+		}
+	}
+	if len(statuses) == 1 {
+		req.HasStatus = true
+		req.Status = statuses[0]
+	} else if len(statuses) > 1 {
+		req.Statuses = statuses
 	}
 
-	statusRaw := strings.TrimSpace(ctx.QueryParam("status"))
-	if statusRaw != "" {
-		status, err := strconv.ParseInt(statusRaw, 10, 32)
+	if minRaw := strings.TrimSpace(ctx.QueryParam("min_amount_cents")); minRaw != "" {
+		minAmount, err := strconv.ParseInt(minRaw, 10, 64)
 		if err != nil {
 			return nil, err
 		}
-		req.HasStatus = true
-		req.Status = PaymentStatus(status)
+		req.MinAmountCents = minAmount
 	}
 
-	providerRaw := strings.TrimSpace(strings.ToLower(ctx.QueryParam("provider")))
-	if providerRaw != "" {
-		switch providerRaw {
-		case "1", "stripe":
-			req.Provider = ProviderType_PROVIDER_TYPE_STRIPE
-		default:
-			return nil, errors.New("invalid provider")
+	if maxRaw := strings.TrimSpace(ctx.QueryParam("max_amount_cents")); maxRaw != "" {
+		maxAmount, err := strconv.ParseInt(maxRaw, 10, 64)
+		if err != nil {
+			return nil, err
 		}
+		req.MaxAmountCents = maxAmount
 	}
 
+	// metadata filters are given as metadata=key:value and may be repeated.
+	for _, pair := range ctx.QueryParams()["metadata"] {
+		key, value, ok := strings.Cut(pair, ":")
+		if !ok {
+			return nil, errors.New("metadata filter must be key:value")
+		}
+		if req.Metadata == nil {
+			req.Metadata = make(map[string]string)
+		}
+		req.Metadata[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+
+	provider, err := parseProviderQueryParam(ctx.QueryParam("provider"))
+	if err != nil {
+		return nil, err
+	}
+	req.Provider = provider
+
 	if limitRaw := strings.TrimSpace(ctx.QueryParam("limit")); limitRaw != "" {
 		limit, err := strconv.ParseInt(limitRaw, 10, 32)
 		if err != nil {
@@ -150,14 +313,42 @@ func (r *ListPaymentsRequest) Validate() error {
 	if r.GetOffset() < 0 {
 		return errors.New("offset must be >= 0")
 	}
+	if r.GetOffset() > 0 && strings.TrimSpace(r.GetPageToken()) != "" {
+		return errors.New("offset cannot be combined with page_token")
+	}
 	if r.GetHasStatus() {
 		if !isValidPaymentStatus(r.GetStatus()) {
 			return errors.New("invalid status")
 		}
 	}
+	for _, status := range r.GetStatuses() {
+		if !isValidPaymentStatus(status) {
+			return errors.New("invalid status")
+		}
+	}
 	if r.GetProvider() != ProviderType_PROVIDER_TYPE_UNSPECIFIED && r.GetProvider() != ProviderType_PROVIDER_TYPE_STRIPE {
 		return errors.New("invalid provider")
 	}
+	if err := validateTimeRange("created_from", r.GetCreatedFrom(), "created_to", r.GetCreatedTo()); err != nil {
+		return err
+	}
+	if err := validateTimeRange("updated_from", r.GetUpdatedFrom(), "updated_to", r.GetUpdatedTo()); err != nil {
+		return err
+	}
+	if r.GetMinAmountCents() < 0 || r.GetMaxAmountCents() < 0 {
+		return errors.New("amount range must be >= 0")
+	}
+	if r.GetMaxAmountCents() > 0 && r.GetMaxAmountCents() < r.GetMinAmountCents() {
+		return errors.New("max_amount_cents must not be below min_amount_cents")
+	}
+	if currency := strings.TrimSpace(r.GetCurrency()); currency != "" && len(currency) != 3 {
+		return errors.New("currency must be 3 letters")
+	}
+	for key := range r.GetMetadata() {
+		if strings.TrimSpace(key) == "" {
+			return errors.New("metadata filter keys must be non-empty")
+		}
+	}
 	return nil
 }
 
@@ -184,42 +375,178 @@ func (r *CancelPaymentRequest) Validate() error {
 	return nil
 }
 
-func NewHandleProviderCallbackRequestFromContext(ctx echo.Context) (*HandleProviderCallbackRequest, error) {
-	provider := strings.TrimSpace(strings.ToLower(ctx.Param("provider")))
-	hash := strings.TrimSpace(ctx.Param("hash"))
-	requestID := strings.TrimSpace(ctx.Request().Header.Get(echo.HeaderXRequestID))
-	signature := strings.TrimSpace(ctx.Request().Header.Get("Stripe-Signature"))
-	if signature == "" {
-		signature = strings.TrimSpace(ctx.Request().Header.Get("X-Provider-Signature"))
+func NewRefundPaymentRequestFromContext(ctx echo.Context) (*RefundPaymentRequest, error) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	var body RefundPaymentRequest
+	if err = ctx.Bind(&body); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	body.Id = id
+	body.RequestId = strings.TrimSpace(body.RequestId)
+	if body.RequestId == "" {
+		body.RequestId = strings.TrimSpace(ctx.Request().Header.Get(echo.HeaderXRequestID))
+	}
+	body.Reason = strings.TrimSpace(body.Reason)
+
+	return &body, nil
+}
+
+func (r *RefundPaymentRequest) Validate() error {
+	if r.GetId() == 0 {
+		return errors.New("invalid payment id")
+	}
+	if strings.TrimSpace(r.GetRequestId()) == "" {
+		return errors.New("request_id is required")
+	}
+	if r.GetAmountCents() < 0 {
+		return errors.New("amount_cents must be >= 0")
+	}
+	return nil
+}
+
+func NewPauseSubscriptionRequestFromContext(ctx echo.Context) (*PauseSubscriptionRequest, error) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	var body PauseSubscriptionRequest
+	if err = ctx.Bind(&body); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	body.Id = id
+	body.Reason = strings.TrimSpace(body.Reason)
+
+	return &body, nil
+}
+
+func (r *PauseSubscriptionRequest) Validate() error {
+	if r.GetId() == 0 {
+		return errors.New("invalid payment id")
+	}
+	return nil
+}
+
+func NewResumeSubscriptionRequestFromContext(ctx echo.Context) (*ResumeSubscriptionRequest, error) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &ResumeSubscriptionRequest{Id: id}, nil
+}
+
+func (r *ResumeSubscriptionRequest) Validate() error {
+	if r.GetId() == 0 {
+		return errors.New("invalid payment id")
+	}
+	return nil
+}
+
+func NewCancelSubscriptionRequestFromContext(ctx echo.Context) (*CancelSubscriptionRequest, error) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return nil, err
 	}
 
+	var body CancelSubscriptionRequest
+	if err = ctx.Bind(&body); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	body.Id = id
+	body.Reason = strings.TrimSpace(body.Reason)
+
+	return &body, nil
+}
+
+func (r *CancelSubscriptionRequest) Validate() error {
+	if r.GetId() == 0 {
+		return errors.New("invalid payment id")
+	}
+	return nil
+}
+
+func NewUpdateSubscriptionRequestFromContext(ctx echo.Context) (*UpdateSubscriptionRequest, error) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	var body UpdateSubscriptionRequest
+	if err = ctx.Bind(&body); err != nil {
+		return nil, err
+	}
+	body.Id = id
+	body.RecurringInterval = strings.ToLower(strings.TrimSpace(body.RecurringInterval))
+
+	return &body, nil
+}
+
+func (r *UpdateSubscriptionRequest) Validate() error {
+	if r.GetId() == 0 {
+		return errors.New("invalid payment id")
+	}
+	if r.GetAmountCents() < 0 {
+		return errors.New("amount_cents must be >= 0")
+	}
+	if r.GetRecurringIntervalCount() < 0 {
+		return errors.New("recurring_interval_count must be >= 0")
+	}
+	if interval := r.GetRecurringInterval(); interval != "" && interval != "day" && interval != "week" && interval != "month" && interval != "year" {
+		return errors.New("recurring_interval must be day, week, month, or year")
+	}
+	if r.GetAmountCents() == 0 && r.GetRecurringInterval() == "" && r.GetRecurringIntervalCount() == 0 {
+		return errors.New("amount_cents, recurring_interval or recurring_interval_count is required")
+	}
+	return nil
+}
+
+// NewHandleProviderCallbackRequestFromContext reads a webhook as the provider
+// sent it: the payload is the raw request body and the signature comes from
+// signatureHeader, as declared by the provider's callback contract.
+func NewHandleProviderCallbackRequestFromContext(ctx echo.Context, signatureHeader string) (*HandleProviderCallbackRequest, error) {
 	rawBody, err := io.ReadAll(ctx.Request().Body)
 	if err != nil {
 		return nil, err
 	}
 
-	req := &HandleProviderCallbackRequest{
-		RequestId:    requestID,
-		Provider:     provider,
-		CallbackHash: hash,
-		Signature:    signature,
+	return &HandleProviderCallbackRequest{
+		RequestId:    strings.TrimSpace(ctx.Request().Header.Get(echo.HeaderXRequestID)),
+		Provider:     strings.TrimSpace(strings.ToLower(ctx.Param("provider"))),
+		CallbackHash: strings.TrimSpace(ctx.Param("hash")),
+		Signature:    strings.TrimSpace(ctx.Request().Header.Get(signatureHeader)),
 		Payload:      string(rawBody),
-	}
+	}, nil
+}
 
-	var body struct {
-		Payload   string `json:"payload"`
-		Signature string `json:"signature"`
+// NewForwardedProviderCallbackRequestFromContext reads a webhook relayed by an
+// internal proxy as a {"payload","signature"} envelope (see
+// provider.BuildForwardPayload). It is only used on the internal forwarding
+// route; the public webhook route never unwraps envelopes.
+func NewForwardedProviderCallbackRequestFromContext(ctx echo.Context) (*HandleProviderCallbackRequest, error) {
+	var envelope struct {
+		Payload   *string `json:"payload"`
+		Signature *string `json:"signature"`
+	}
+	decoder := json.NewDecoder(ctx.Request().Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&envelope); err != nil {
+		return nil, err
 	}
-	if len(rawBody) > 0 && json.Unmarshal(rawBody, &body) == nil {
-		if strings.TrimSpace(body.Payload) != "" {
-			req.Payload = body.Payload
-		}
-		if strings.TrimSpace(body.Signature) != "" {
-			req.Signature = strings.TrimSpace(body.Signature)
-		}
+	if envelope.Payload == nil || envelope.Signature == nil {
+		return nil, errors.New("forwarded callback must contain payload and signature")
 	}
 
-	return req, nil
+	return &HandleProviderCallbackRequest{
+		RequestId:    strings.TrimSpace(ctx.Request().Header.Get(echo.HeaderXRequestID)),
+		Provider:     strings.TrimSpace(strings.ToLower(ctx.Param("provider"))),
+		CallbackHash: strings.TrimSpace(ctx.Param("hash")),
+		Signature:    strings.TrimSpace(*envelope.Signature),
+		Payload:      *envelope.Payload,
+	}, nil
 }
 
 func (r *HandleProviderCallbackRequest) Validate() error {
@@ -241,6 +568,184 @@ func (r *HandleProviderCallbackRequest) Validate() error {
 	return nil
 }
 
+func NewListDeadLetterCallbacksRequestFromContext(ctx echo.Context) (*ListDeadLetterCallbacksRequest, error) {
+	req := &ListDeadLetterCallbacksRequest{
+		CallerService: strings.TrimSpace(ctx.QueryParam("caller_service")),
+		EventType:     strings.TrimSpace(ctx.QueryParam("event_type")),
+		Limit:         100,
+	}
+
+	if paymentIDRaw := strings.TrimSpace(ctx.QueryParam("payment_id")); paymentIDRaw != "" {
+		paymentID, err := strconv.ParseUint(paymentIDRaw, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		req.PaymentId = paymentID
+	}
+
+	if limitRaw := strings.TrimSpace(ctx.QueryParam("limit")); limitRaw != "" {
+		limit, err := strconv.ParseInt(limitRaw, 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		req.Limit = int32(limit)
+	}
+
+	if offsetRaw := strings.TrimSpace(ctx.QueryParam("offset")); offsetRaw != "" {
+		offset, err := strconv.ParseInt(offsetRaw, 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		req.Offset = int32(offset)
+	}
+
+	return req, nil
+}
+
+func (r *ListDeadLetterCallbacksRequest) Validate() error {
+	if r.Limit == 0 {
+		r.Limit = 100
+	}
+	if r.GetLimit() <= 0 || r.GetLimit() > 500 {
+		return errors.New("limit must be between 1 and 500")
+	}
+	if r.GetOffset() < 0 {
+		return errors.New("offset must be >= 0")
+	}
+	return nil
+}
+
+func NewRedeliverCallbacksRequestFromContext(ctx echo.Context) (*RedeliverCallbacksRequest, error) {
+	var body RedeliverCallbacksRequest
+	if err := ctx.Bind(&body); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	body.CallerService = strings.TrimSpace(body.CallerService)
+	body.EventType = strings.TrimSpace(body.EventType)
+
+	return &body, nil
+}
+
+func (r *RedeliverCallbacksRequest) Validate() error {
+	hasFilter := r.GetPaymentId() > 0 || strings.TrimSpace(r.GetCallerService()) != "" || strings.TrimSpace(r.GetEventType()) != ""
+	if !hasFilter && !r.GetAll() {
+		return errors.New("payment_id, caller_service or event_type is required unless all is set")
+	}
+	if hasFilter && r.GetAll() {
+		return errors.New("all cannot be combined with filters")
+	}
+	return nil
+}
+
+func NewListProviderCallbacksRequestFromContext(ctx echo.Context) (*ListProviderCallbacksRequest, error) {
+	req := &ListProviderCallbacksRequest{
+		Provider:     strings.ToLower(strings.TrimSpace(ctx.QueryParam("provider"))),
+		CallbackHash: strings.TrimSpace(ctx.QueryParam("callback_hash")),
+		CreatedFrom:  strings.TrimSpace(ctx.QueryParam("created_from")),
+		CreatedTo:    strings.TrimSpace(ctx.QueryParam("created_to")),
+		Limit:        100,
+	}
+
+	if statusRaw := strings.TrimSpace(ctx.QueryParam("status")); statusRaw != "" {
+		status, err := ParseProviderCallbackStatus(statusRaw)
+		if err != nil {
+			return nil, err
+		}
+		req.HasStatus = true
+		req.Status = status
+	}
+
+	if limitRaw := strings.TrimSpace(ctx.QueryParam("limit")); limitRaw != "" {
+		limit, err := strconv.ParseInt(limitRaw, 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		req.Limit = int32(limit)
+	}
+
+	if offsetRaw := strings.TrimSpace(ctx.QueryParam("offset")); offsetRaw != "" {
+		offset, err := strconv.ParseInt(offsetRaw, 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		req.Offset = int32(offset)
+	}
+
+	if payloadRaw := strings.TrimSpace(ctx.QueryParam("include_payload")); payloadRaw != "" {
+		includePayload, err := strconv.ParseBool(payloadRaw)
+		if err != nil {
+			return nil, err
+		}
+		req.IncludePayload = includePayload
+	}
+
+	return req, nil
+}
+
+func (r *ListProviderCallbacksRequest) Validate() error {
+	if r.Limit == 0 {
+		r.Limit = 100
+	}
+	if r.GetLimit() <= 0 || r.GetLimit() > 500 {
+		return errors.New("limit must be between 1 and 500")
+	}
+	if r.GetOffset() < 0 {
+		return errors.New("offset must be >= 0")
+	}
+	if r.GetHasStatus() && !isValidProviderCallbackStatus(r.GetStatus()) {
+		return errors.New("invalid status")
+	}
+	return validateTimeRange("created_from", r.GetCreatedFrom(), "created_to", r.GetCreatedTo())
+}
+
+func NewReplayProviderCallbackRequestFromContext(ctx echo.Context) (*ReplayProviderCallbackRequest, error) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	var body ReplayProviderCallbackRequest
+	if err = ctx.Bind(&body); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	body.Id = id
+
+	return &body, nil
+}
+
+func (r *ReplayProviderCallbackRequest) Validate() error {
+	if r.GetId() == 0 {
+		return errors.New("invalid callback id")
+	}
+	return nil
+}
+
+// ParseProviderCallbackStatus accepts a status name (processed, rejected,
+// duplicate) or its numeric value.
+func ParseProviderCallbackStatus(raw string) (ProviderCallbackStatus, error) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "processed", "10":
+		return ProviderCallbackStatus_PROVIDER_CALLBACK_STATUS_PROCESSED, nil
+	case "rejected", "20":
+		return ProviderCallbackStatus_PROVIDER_CALLBACK_STATUS_REJECTED, nil
+	case "duplicate", "30":
+		return ProviderCallbackStatus_PROVIDER_CALLBACK_STATUS_DUPLICATE, nil
+	default:
+		return ProviderCallbackStatus_PROVIDER_CALLBACK_STATUS_UNSPECIFIED, errors.New("invalid status")
+	}
+}
+
+func isValidProviderCallbackStatus(status ProviderCallbackStatus) bool {
+	switch status {
+	case ProviderCallbackStatus_PROVIDER_CALLBACK_STATUS_PROCESSED,
+		ProviderCallbackStatus_PROVIDER_CALLBACK_STATUS_REJECTED,
+		ProviderCallbackStatus_PROVIDER_CALLBACK_STATUS_DUPLICATE:
+		return true
+	default:
+		return false
+	}
+}
+
 func isValidPaymentStatus(status PaymentStatus) bool {
 	switch status {
 	case PaymentStatus_PAYMENT_STATUS_CREATED,
@@ -255,3 +760,36 @@ func isValidPaymentStatus(status PaymentStatus) bool {
 		return false
 	}
 }
+
+// parseProviderQueryParam accepts a provider by code or name; empty means
+// unspecified.
+func parseProviderQueryParam(raw string) (ProviderType, error) {
+	switch strings.TrimSpace(strings.ToLower(raw)) {
+	case "":
+		return ProviderType_PROVIDER_TYPE_UNSPECIFIED, nil
+	case "1", "stripe":
+		return ProviderType_PROVIDER_TYPE_STRIPE, nil
+	default:
+		return ProviderType_PROVIDER_TYPE_UNSPECIFIED, errors.New("invalid provider")
+	}
+}
+
+// validateTimeRange checks an optional RFC3339 from/to pair.
+func validateTimeRange(fromName, fromRaw, toName, toRaw string) error {
+	var from, to time.Time
+	var err error
+	if strings.TrimSpace(fromRaw) != "" {
+		if from, err = time.Parse(time.RFC3339, strings.TrimSpace(fromRaw)); err != nil {
+			return errors.New(fromName + " must be an RFC3339 timestamp")
+		}
+	}
+	if strings.TrimSpace(toRaw) != "" {
+		if to, err = time.Parse(time.RFC3339, strings.TrimSpace(toRaw)); err != nil {
+			return errors.New(toName + " must be an RFC3339 timestamp")
+		}
+	}
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		return errors.New(toName + " must not be before " + fromName)
+	}
+	return nil
+}
@@ -1,6 +1,6 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.36.5
+// 	protoc-gen-go v1.36.11
 // 	protoc        v6.33.4
 // source: payments.proto
 
@@ -229,6 +229,113 @@ func (ProviderType) EnumDescriptor() ([]byte, []int) {
 	return file_payments_proto_rawDescGZIP(), []int{3}
 }
 
+type RefundStatus int32
+
+const (
+	RefundStatus_REFUND_STATUS_UNSPECIFIED RefundStatus = 0
+	RefundStatus_REFUND_STATUS_PENDING     RefundStatus = 1
+	RefundStatus_REFUND_STATUS_SUCCEEDED   RefundStatus = 10
+	RefundStatus_REFUND_STATUS_FAILED      RefundStatus = 20
+	RefundStatus_REFUND_STATUS_CANCELED    RefundStatus = 30
+)
+
+// Enum value maps for RefundStatus.
+var (
+	RefundStatus_name = map[int32]string{
+		0:  "REFUND_STATUS_UNSPECIFIED",
+		1:  "REFUND_STATUS_PENDING",
+		10: "REFUND_STATUS_SUCCEEDED",
+		20: "REFUND_STATUS_FAILED",
+		30: "REFUND_STATUS_CANCELED",
+	}
+	RefundStatus_value = map[string]int32{
+		"REFUND_STATUS_UNSPECIFIED": 0,
+		"REFUND_STATUS_PENDING":     1,
+		"REFUND_STATUS_SUCCEEDED":   10,
+		"REFUND_STATUS_FAILED":      20,
+		"REFUND_STATUS_CANCELED":    30,
+	}
+)
+
+func (x RefundStatus) Enum() *RefundStatus {
+	p := new(RefundStatus)
+	*p = x
+	return p
+}
+
+func (x RefundStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RefundStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_payments_proto_enumTypes[4].Descriptor()
+}
+
+func (RefundStatus) Type() protoreflect.EnumType {
+	return &file_payments_proto_enumTypes[4]
+}
+
+func (x RefundStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RefundStatus.Descriptor instead.
+func (RefundStatus) EnumDescriptor() ([]byte, []int) {
+	return file_payments_proto_rawDescGZIP(), []int{4}
+}
+
+type ProviderCallbackStatus int32
+
+const (
+	ProviderCallbackStatus_PROVIDER_CALLBACK_STATUS_UNSPECIFIED ProviderCallbackStatus = 0
+	ProviderCallbackStatus_PROVIDER_CALLBACK_STATUS_PROCESSED   ProviderCallbackStatus = 10
+	ProviderCallbackStatus_PROVIDER_CALLBACK_STATUS_REJECTED    ProviderCallbackStatus = 20
+	ProviderCallbackStatus_PROVIDER_CALLBACK_STATUS_DUPLICATE   ProviderCallbackStatus = 30
+)
+
+// Enum value maps for ProviderCallbackStatus.
+var (
+	ProviderCallbackStatus_name = map[int32]string{
+		0:  "PROVIDER_CALLBACK_STATUS_UNSPECIFIED",
+		10: "PROVIDER_CALLBACK_STATUS_PROCESSED",
+		20: "PROVIDER_CALLBACK_STATUS_REJECTED",
+		30: "PROVIDER_CALLBACK_STATUS_DUPLICATE",
+	}
+	ProviderCallbackStatus_value = map[string]int32{
+		"PROVIDER_CALLBACK_STATUS_UNSPECIFIED": 0,
+		"PROVIDER_CALLBACK_STATUS_PROCESSED":   10,
+		"PROVIDER_CALLBACK_STATUS_REJECTED":    20,
+		"PROVIDER_CALLBACK_STATUS_DUPLICATE":   30,
+	}
+)
+
+func (x ProviderCallbackStatus) Enum() *ProviderCallbackStatus {
+	p := new(ProviderCallbackStatus)
+	*p = x
+	return p
+}
+
+func (x ProviderCallbackStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ProviderCallbackStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_payments_proto_enumTypes[5].Descriptor()
+}
+
+func (ProviderCallbackStatus) Type() protoreflect.EnumType {
+	return &file_payments_proto_enumTypes[5]
+}
+
+func (x ProviderCallbackStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ProviderCallbackStatus.Descriptor instead.
+func (ProviderCallbackStatus) EnumDescriptor() ([]byte, []int) {
+	return file_payments_proto_rawDescGZIP(), []int{5}
+}
+
 type HealthRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
@@ -336,6 +443,8 @@ type Payment struct {
 	Metadata               map[string]string      `protobuf:"bytes,23,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	CreatedAt              string                 `protobuf:"bytes,24,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
 	UpdatedAt              string                 `protobuf:"bytes,25,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	SubscriptionPaused     bool                   `protobuf:"varint,26,opt,name=subscription_paused,json=subscriptionPaused,proto3" json:"subscription_paused,omitempty"`
+	CancelAtPeriodEnd      bool                   `protobuf:"varint,27,opt,name=cancel_at_period_end,json=cancelAtPeriodEnd,proto3" json:"cancel_at_period_end,omitempty"`
 	unknownFields          protoimpl.UnknownFields
 	sizeCache              protoimpl.SizeCache
 }
@@ -545,6 +654,20 @@ func (x *Payment) GetUpdatedAt() string {
 	return ""
 }
 
+func (x *Payment) GetSubscriptionPaused() bool {
+	if x != nil {
+		return x.SubscriptionPaused
+	}
+	return false
+}
+
+func (x *Payment) GetCancelAtPeriodEnd() bool {
+	if x != nil {
+		return x.CancelAtPeriodEnd
+	}
+	return false
+}
+
 type CreatePaymentRequest struct {
 	state                  protoimpl.MessageState `protogen:"open.v1"`
 	RequestId              string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
@@ -753,24 +876,149 @@ func (x *GetPaymentRequest) GetId() uint64 {
 	return 0
 }
 
-type ListPaymentsRequest struct {
+type GetPaymentByRequestIDRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	RequestId     string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
-	CallerService string                 `protobuf:"bytes,2,opt,name=caller_service,json=callerService,proto3" json:"caller_service,omitempty"`
-	ResourceType  string                 `protobuf:"bytes,3,opt,name=resource_type,json=resourceType,proto3" json:"resource_type,omitempty"`
-	ResourceId    string                 `protobuf:"bytes,4,opt,name=resource_id,json=resourceId,proto3" json:"resource_id,omitempty"`
-	HasStatus     bool                   `protobuf:"varint,5,opt,name=has_status,json=hasStatus,proto3" json:"has_status,omitempty"`
-	Status        PaymentStatus          `protobuf:"varint,6,opt,name=status,proto3,enum=payments.PaymentStatus" json:"status,omitempty"`
-	Provider      ProviderType           `protobuf:"varint,7,opt,name=provider,proto3,enum=payments.ProviderType" json:"provider,omitempty"`
-	Limit         int32                  `protobuf:"varint,8,opt,name=limit,proto3" json:"limit,omitempty"`
-	Offset        int32                  `protobuf:"varint,9,opt,name=offset,proto3" json:"offset,omitempty"`
+	CallerService string                 `protobuf:"bytes,1,opt,name=caller_service,json=callerService,proto3" json:"caller_service,omitempty"`
+	RequestId     string                 `protobuf:"bytes,2,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
+func (x *GetPaymentByRequestIDRequest) Reset() {
+	*x = GetPaymentByRequestIDRequest{}
+	mi := &file_payments_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPaymentByRequestIDRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPaymentByRequestIDRequest) ProtoMessage() {}
+
+func (x *GetPaymentByRequestIDRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payments_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPaymentByRequestIDRequest.ProtoReflect.Descriptor instead.
+func (*GetPaymentByRequestIDRequest) Descriptor() ([]byte, []int) {
+	return file_payments_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetPaymentByRequestIDRequest) GetCallerService() string {
+	if x != nil {
+		return x.CallerService
+	}
+	return ""
+}
+
+func (x *GetPaymentByRequestIDRequest) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+type GetPaymentByProviderRefRequest struct {
+	state                  protoimpl.MessageState `protogen:"open.v1"`
+	Provider               ProviderType           `protobuf:"varint,1,opt,name=provider,proto3,enum=payments.ProviderType" json:"provider,omitempty"`
+	ProviderPaymentId      string                 `protobuf:"bytes,2,opt,name=provider_payment_id,json=providerPaymentId,proto3" json:"provider_payment_id,omitempty"`
+	ProviderSubscriptionId string                 `protobuf:"bytes,3,opt,name=provider_subscription_id,json=providerSubscriptionId,proto3" json:"provider_subscription_id,omitempty"`
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *GetPaymentByProviderRefRequest) Reset() {
+	*x = GetPaymentByProviderRefRequest{}
+	mi := &file_payments_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPaymentByProviderRefRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPaymentByProviderRefRequest) ProtoMessage() {}
+
+func (x *GetPaymentByProviderRefRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payments_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPaymentByProviderRefRequest.ProtoReflect.Descriptor instead.
+func (*GetPaymentByProviderRefRequest) Descriptor() ([]byte, []int) {
+	return file_payments_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetPaymentByProviderRefRequest) GetProvider() ProviderType {
+	if x != nil {
+		return x.Provider
+	}
+	return ProviderType_PROVIDER_TYPE_UNSPECIFIED
+}
+
+func (x *GetPaymentByProviderRefRequest) GetProviderPaymentId() string {
+	if x != nil {
+		return x.ProviderPaymentId
+	}
+	return ""
+}
+
+func (x *GetPaymentByProviderRefRequest) GetProviderSubscriptionId() string {
+	if x != nil {
+		return x.ProviderSubscriptionId
+	}
+	return ""
+}
+
+type ListPaymentsRequest struct {
+	state                  protoimpl.MessageState `protogen:"open.v1"`
+	RequestId              string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	CallerService          string                 `protobuf:"bytes,2,opt,name=caller_service,json=callerService,proto3" json:"caller_service,omitempty"`
+	ResourceType           string                 `protobuf:"bytes,3,opt,name=resource_type,json=resourceType,proto3" json:"resource_type,omitempty"`
+	ResourceId             string                 `protobuf:"bytes,4,opt,name=resource_id,json=resourceId,proto3" json:"resource_id,omitempty"`
+	HasStatus              bool                   `protobuf:"varint,5,opt,name=has_status,json=hasStatus,proto3" json:"has_status,omitempty"`
+	Status                 PaymentStatus          `protobuf:"varint,6,opt,name=status,proto3,enum=payments.PaymentStatus" json:"status,omitempty"`
+	Provider               ProviderType           `protobuf:"varint,7,opt,name=provider,proto3,enum=payments.ProviderType" json:"provider,omitempty"`
+	Limit                  int32                  `protobuf:"varint,8,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset                 int32                  `protobuf:"varint,9,opt,name=offset,proto3" json:"offset,omitempty"`
+	PageToken              string                 `protobuf:"bytes,10,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	CreatedFrom            string                 `protobuf:"bytes,11,opt,name=created_from,json=createdFrom,proto3" json:"created_from,omitempty"`
+	CreatedTo              string                 `protobuf:"bytes,12,opt,name=created_to,json=createdTo,proto3" json:"created_to,omitempty"`
+	UpdatedFrom            string                 `protobuf:"bytes,13,opt,name=updated_from,json=updatedFrom,proto3" json:"updated_from,omitempty"`
+	UpdatedTo              string                 `protobuf:"bytes,14,opt,name=updated_to,json=updatedTo,proto3" json:"updated_to,omitempty"`
+	MinAmountCents         int64                  `protobuf:"varint,15,opt,name=min_amount_cents,json=minAmountCents,proto3" json:"min_amount_cents,omitempty"`
+	MaxAmountCents         int64                  `protobuf:"varint,16,opt,name=max_amount_cents,json=maxAmountCents,proto3" json:"max_amount_cents,omitempty"`
+	Currency               string                 `protobuf:"bytes,17,opt,name=currency,proto3" json:"currency,omitempty"`
+	CustomerRef            string                 `protobuf:"bytes,18,opt,name=customer_ref,json=customerRef,proto3" json:"customer_ref,omitempty"`
+	Statuses               []PaymentStatus        `protobuf:"varint,19,rep,packed,name=statuses,proto3,enum=payments.PaymentStatus" json:"statuses,omitempty"`
+	ProviderPaymentId      string                 `protobuf:"bytes,20,opt,name=provider_payment_id,json=providerPaymentId,proto3" json:"provider_payment_id,omitempty"`
+	ProviderSubscriptionId string                 `protobuf:"bytes,21,opt,name=provider_subscription_id,json=providerSubscriptionId,proto3" json:"provider_subscription_id,omitempty"`
+	Metadata               map[string]string      `protobuf:"bytes,22,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
 func (x *ListPaymentsRequest) Reset() {
 	*x = ListPaymentsRequest{}
-	mi := &file_payments_proto_msgTypes[5]
+	mi := &file_payments_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -782,7 +1030,7 @@ func (x *ListPaymentsRequest) String() string {
 func (*ListPaymentsRequest) ProtoMessage() {}
 
 func (x *ListPaymentsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_payments_proto_msgTypes[5]
+	mi := &file_payments_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -795,7 +1043,7 @@ func (x *ListPaymentsRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListPaymentsRequest.ProtoReflect.Descriptor instead.
 func (*ListPaymentsRequest) Descriptor() ([]byte, []int) {
-	return file_payments_proto_rawDescGZIP(), []int{5}
+	return file_payments_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *ListPaymentsRequest) GetRequestId() string {
@@ -861,6 +1109,97 @@ func (x *ListPaymentsRequest) GetOffset() int32 {
 	return 0
 }
 
+func (x *ListPaymentsRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+func (x *ListPaymentsRequest) GetCreatedFrom() string {
+	if x != nil {
+		return x.CreatedFrom
+	}
+	return ""
+}
+
+func (x *ListPaymentsRequest) GetCreatedTo() string {
+	if x != nil {
+		return x.CreatedTo
+	}
+	return ""
+}
+
+func (x *ListPaymentsRequest) GetUpdatedFrom() string {
+	if x != nil {
+		return x.UpdatedFrom
+	}
+	return ""
+}
+
+func (x *ListPaymentsRequest) GetUpdatedTo() string {
+	if x != nil {
+		return x.UpdatedTo
+	}
+	return ""
+}
+
+func (x *ListPaymentsRequest) GetMinAmountCents() int64 {
+	if x != nil {
+		return x.MinAmountCents
+	}
+	return 0
+}
+
+func (x *ListPaymentsRequest) GetMaxAmountCents() int64 {
+	if x != nil {
+		return x.MaxAmountCents
+	}
+	return 0
+}
+
+func (x *ListPaymentsRequest) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *ListPaymentsRequest) GetCustomerRef() string {
+	if x != nil {
+		return x.CustomerRef
+	}
+	return ""
+}
+
+func (x *ListPaymentsRequest) GetStatuses() []PaymentStatus {
+	if x != nil {
+		return x.Statuses
+	}
+	return nil
+}
+
+func (x *ListPaymentsRequest) GetProviderPaymentId() string {
+	if x != nil {
+		return x.ProviderPaymentId
+	}
+	return ""
+}
+
+func (x *ListPaymentsRequest) GetProviderSubscriptionId() string {
+	if x != nil {
+		return x.ProviderSubscriptionId
+	}
+	return ""
+}
+
+func (x *ListPaymentsRequest) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
 type CancelPaymentRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
@@ -871,7 +1210,7 @@ type CancelPaymentRequest struct {
 
 func (x *CancelPaymentRequest) Reset() {
 	*x = CancelPaymentRequest{}
-	mi := &file_payments_proto_msgTypes[6]
+	mi := &file_payments_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -883,7 +1222,7 @@ func (x *CancelPaymentRequest) String() string {
 func (*CancelPaymentRequest) ProtoMessage() {}
 
 func (x *CancelPaymentRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_payments_proto_msgTypes[6]
+	mi := &file_payments_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -896,7 +1235,7 @@ func (x *CancelPaymentRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CancelPaymentRequest.ProtoReflect.Descriptor instead.
 func (*CancelPaymentRequest) Descriptor() ([]byte, []int) {
-	return file_payments_proto_rawDescGZIP(), []int{6}
+	return file_payments_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *CancelPaymentRequest) GetId() uint64 {
@@ -913,32 +1252,1079 @@ func (x *CancelPaymentRequest) GetReason() string {
 	return ""
 }
 
-type HandleProviderCallbackRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	RequestId     string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
-	Provider      string                 `protobuf:"bytes,2,opt,name=provider,proto3" json:"provider,omitempty"`
-	CallbackHash  string                 `protobuf:"bytes,3,opt,name=callback_hash,json=callbackHash,proto3" json:"callback_hash,omitempty"`
-	Signature     string                 `protobuf:"bytes,4,opt,name=signature,proto3" json:"signature,omitempty"`
-	Payload       string                 `protobuf:"bytes,5,opt,name=payload,proto3" json:"payload,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
-}
-
-func (x *HandleProviderCallbackRequest) Reset() {
-	*x = HandleProviderCallbackRequest{}
-	mi := &file_payments_proto_msgTypes[7]
+type Refund struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Id               uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	PaymentId        uint64                 `protobuf:"varint,2,opt,name=payment_id,json=paymentId,proto3" json:"payment_id,omitempty"`
+	RequestId        string                 `protobuf:"bytes,3,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	AmountCents      int64                  `protobuf:"varint,4,opt,name=amount_cents,json=amountCents,proto3" json:"amount_cents,omitempty"`
+	Currency         string                 `protobuf:"bytes,5,opt,name=currency,proto3" json:"currency,omitempty"`
+	Status           RefundStatus           `protobuf:"varint,6,opt,name=status,proto3,enum=payments.RefundStatus" json:"status,omitempty"`
+	Reason           string                 `protobuf:"bytes,7,opt,name=reason,proto3" json:"reason,omitempty"`
+	ProviderRefundId string                 `protobuf:"bytes,8,opt,name=provider_refund_id,json=providerRefundId,proto3" json:"provider_refund_id,omitempty"`
+	CreatedAt        string                 `protobuf:"bytes,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt        string                 `protobuf:"bytes,10,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *Refund) Reset() {
+	*x = Refund{}
+	mi := &file_payments_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *HandleProviderCallbackRequest) String() string {
+func (x *Refund) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HandleProviderCallbackRequest) ProtoMessage() {}
+func (*Refund) ProtoMessage() {}
 
-func (x *HandleProviderCallbackRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_payments_proto_msgTypes[7]
+func (x *Refund) ProtoReflect() protoreflect.Message {
+	mi := &file_payments_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Refund.ProtoReflect.Descriptor instead.
+func (*Refund) Descriptor() ([]byte, []int) {
+	return file_payments_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Refund) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Refund) GetPaymentId() uint64 {
+	if x != nil {
+		return x.PaymentId
+	}
+	return 0
+}
+
+func (x *Refund) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *Refund) GetAmountCents() int64 {
+	if x != nil {
+		return x.AmountCents
+	}
+	return 0
+}
+
+func (x *Refund) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *Refund) GetStatus() RefundStatus {
+	if x != nil {
+		return x.Status
+	}
+	return RefundStatus_REFUND_STATUS_UNSPECIFIED
+}
+
+func (x *Refund) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *Refund) GetProviderRefundId() string {
+	if x != nil {
+		return x.ProviderRefundId
+	}
+	return ""
+}
+
+func (x *Refund) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *Refund) GetUpdatedAt() string {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return ""
+}
+
+type RefundPaymentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	RequestId     string                 `protobuf:"bytes,2,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	AmountCents   int64                  `protobuf:"varint,3,opt,name=amount_cents,json=amountCents,proto3" json:"amount_cents,omitempty"`
+	Reason        string                 `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RefundPaymentRequest) Reset() {
+	*x = RefundPaymentRequest{}
+	mi := &file_payments_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RefundPaymentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RefundPaymentRequest) ProtoMessage() {}
+
+func (x *RefundPaymentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payments_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RefundPaymentRequest.ProtoReflect.Descriptor instead.
+func (*RefundPaymentRequest) Descriptor() ([]byte, []int) {
+	return file_payments_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *RefundPaymentRequest) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *RefundPaymentRequest) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *RefundPaymentRequest) GetAmountCents() int64 {
+	if x != nil {
+		return x.AmountCents
+	}
+	return 0
+}
+
+func (x *RefundPaymentRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type RefundPaymentResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Payment       *Payment               `protobuf:"bytes,1,opt,name=payment,proto3" json:"payment,omitempty"`
+	Refund        *Refund                `protobuf:"bytes,2,opt,name=refund,proto3" json:"refund,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RefundPaymentResponse) Reset() {
+	*x = RefundPaymentResponse{}
+	mi := &file_payments_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RefundPaymentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RefundPaymentResponse) ProtoMessage() {}
+
+func (x *RefundPaymentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_payments_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RefundPaymentResponse.ProtoReflect.Descriptor instead.
+func (*RefundPaymentResponse) Descriptor() ([]byte, []int) {
+	return file_payments_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *RefundPaymentResponse) GetPayment() *Payment {
+	if x != nil {
+		return x.Payment
+	}
+	return nil
+}
+
+func (x *RefundPaymentResponse) GetRefund() *Refund {
+	if x != nil {
+		return x.Refund
+	}
+	return nil
+}
+
+type Charge struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Id                uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	PaymentId         uint64                 `protobuf:"varint,2,opt,name=payment_id,json=paymentId,proto3" json:"payment_id,omitempty"`
+	ProviderInvoiceId string                 `protobuf:"bytes,3,opt,name=provider_invoice_id,json=providerInvoiceId,proto3" json:"provider_invoice_id,omitempty"`
+	AmountCents       int64                  `protobuf:"varint,4,opt,name=amount_cents,json=amountCents,proto3" json:"amount_cents,omitempty"`
+	Currency          string                 `protobuf:"bytes,5,opt,name=currency,proto3" json:"currency,omitempty"`
+	Status            PaymentStatus          `protobuf:"varint,6,opt,name=status,proto3,enum=payments.PaymentStatus" json:"status,omitempty"`
+	PeriodStart       string                 `protobuf:"bytes,7,opt,name=period_start,json=periodStart,proto3" json:"period_start,omitempty"`
+	PeriodEnd         string                 `protobuf:"bytes,8,opt,name=period_end,json=periodEnd,proto3" json:"period_end,omitempty"`
+	CreatedAt         string                 `protobuf:"bytes,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt         string                 `protobuf:"bytes,10,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *Charge) Reset() {
+	*x = Charge{}
+	mi := &file_payments_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Charge) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Charge) ProtoMessage() {}
+
+func (x *Charge) ProtoReflect() protoreflect.Message {
+	mi := &file_payments_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Charge.ProtoReflect.Descriptor instead.
+func (*Charge) Descriptor() ([]byte, []int) {
+	return file_payments_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *Charge) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Charge) GetPaymentId() uint64 {
+	if x != nil {
+		return x.PaymentId
+	}
+	return 0
+}
+
+func (x *Charge) GetProviderInvoiceId() string {
+	if x != nil {
+		return x.ProviderInvoiceId
+	}
+	return ""
+}
+
+func (x *Charge) GetAmountCents() int64 {
+	if x != nil {
+		return x.AmountCents
+	}
+	return 0
+}
+
+func (x *Charge) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *Charge) GetStatus() PaymentStatus {
+	if x != nil {
+		return x.Status
+	}
+	return PaymentStatus_PAYMENT_STATUS_UNSPECIFIED
+}
+
+func (x *Charge) GetPeriodStart() string {
+	if x != nil {
+		return x.PeriodStart
+	}
+	return ""
+}
+
+func (x *Charge) GetPeriodEnd() string {
+	if x != nil {
+		return x.PeriodEnd
+	}
+	return ""
+}
+
+func (x *Charge) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *Charge) GetUpdatedAt() string {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return ""
+}
+
+type ListPaymentChargesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPaymentChargesRequest) Reset() {
+	*x = ListPaymentChargesRequest{}
+	mi := &file_payments_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPaymentChargesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPaymentChargesRequest) ProtoMessage() {}
+
+func (x *ListPaymentChargesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payments_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPaymentChargesRequest.ProtoReflect.Descriptor instead.
+func (*ListPaymentChargesRequest) Descriptor() ([]byte, []int) {
+	return file_payments_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ListPaymentChargesRequest) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type ListPaymentChargesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Charges       []*Charge              `protobuf:"bytes,1,rep,name=charges,proto3" json:"charges,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPaymentChargesResponse) Reset() {
+	*x = ListPaymentChargesResponse{}
+	mi := &file_payments_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPaymentChargesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPaymentChargesResponse) ProtoMessage() {}
+
+func (x *ListPaymentChargesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_payments_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPaymentChargesResponse.ProtoReflect.Descriptor instead.
+func (*ListPaymentChargesResponse) Descriptor() ([]byte, []int) {
+	return file_payments_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ListPaymentChargesResponse) GetCharges() []*Charge {
+	if x != nil {
+		return x.Charges
+	}
+	return nil
+}
+
+// PaymentEvent is one entry of the payment_events audit trail. old_status is
+// unspecified for events that did not start from a known status.
+type PaymentEvent struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Id              uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	PaymentId       uint64                 `protobuf:"varint,2,opt,name=payment_id,json=paymentId,proto3" json:"payment_id,omitempty"`
+	EventType       string                 `protobuf:"bytes,3,opt,name=event_type,json=eventType,proto3" json:"event_type,omitempty"`
+	OldStatus       PaymentStatus          `protobuf:"varint,4,opt,name=old_status,json=oldStatus,proto3,enum=payments.PaymentStatus" json:"old_status,omitempty"`
+	NewStatus       PaymentStatus          `protobuf:"varint,5,opt,name=new_status,json=newStatus,proto3,enum=payments.PaymentStatus" json:"new_status,omitempty"`
+	ProviderEventId string                 `protobuf:"bytes,6,opt,name=provider_event_id,json=providerEventId,proto3" json:"provider_event_id,omitempty"`
+	PayloadJson     string                 `protobuf:"bytes,7,opt,name=payload_json,json=payloadJson,proto3" json:"payload_json,omitempty"`
+	CreatedAt       string                 `protobuf:"bytes,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *PaymentEvent) Reset() {
+	*x = PaymentEvent{}
+	mi := &file_payments_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PaymentEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PaymentEvent) ProtoMessage() {}
+
+func (x *PaymentEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_payments_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PaymentEvent.ProtoReflect.Descriptor instead.
+func (*PaymentEvent) Descriptor() ([]byte, []int) {
+	return file_payments_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *PaymentEvent) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *PaymentEvent) GetPaymentId() uint64 {
+	if x != nil {
+		return x.PaymentId
+	}
+	return 0
+}
+
+func (x *PaymentEvent) GetEventType() string {
+	if x != nil {
+		return x.EventType
+	}
+	return ""
+}
+
+func (x *PaymentEvent) GetOldStatus() PaymentStatus {
+	if x != nil {
+		return x.OldStatus
+	}
+	return PaymentStatus_PAYMENT_STATUS_UNSPECIFIED
+}
+
+func (x *PaymentEvent) GetNewStatus() PaymentStatus {
+	if x != nil {
+		return x.NewStatus
+	}
+	return PaymentStatus_PAYMENT_STATUS_UNSPECIFIED
+}
+
+func (x *PaymentEvent) GetProviderEventId() string {
+	if x != nil {
+		return x.ProviderEventId
+	}
+	return ""
+}
+
+func (x *PaymentEvent) GetPayloadJson() string {
+	if x != nil {
+		return x.PayloadJson
+	}
+	return ""
+}
+
+func (x *PaymentEvent) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+// ListPaymentEventsRequest pages through the history oldest first. cursor is
+// the next_cursor of the previous page; payloads are only returned when
+// include_payload is set.
+type ListPaymentEventsRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	EventType      string                 `protobuf:"bytes,2,opt,name=event_type,json=eventType,proto3" json:"event_type,omitempty"`
+	Cursor         uint64                 `protobuf:"varint,3,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	Limit          int32                  `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
+	IncludePayload bool                   `protobuf:"varint,5,opt,name=include_payload,json=includePayload,proto3" json:"include_payload,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ListPaymentEventsRequest) Reset() {
+	*x = ListPaymentEventsRequest{}
+	mi := &file_payments_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPaymentEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPaymentEventsRequest) ProtoMessage() {}
+
+func (x *ListPaymentEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payments_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPaymentEventsRequest.ProtoReflect.Descriptor instead.
+func (*ListPaymentEventsRequest) Descriptor() ([]byte, []int) {
+	return file_payments_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ListPaymentEventsRequest) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *ListPaymentEventsRequest) GetEventType() string {
+	if x != nil {
+		return x.EventType
+	}
+	return ""
+}
+
+func (x *ListPaymentEventsRequest) GetCursor() uint64 {
+	if x != nil {
+		return x.Cursor
+	}
+	return 0
+}
+
+func (x *ListPaymentEventsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListPaymentEventsRequest) GetIncludePayload() bool {
+	if x != nil {
+		return x.IncludePayload
+	}
+	return false
+}
+
+type ListPaymentEventsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Events        []*PaymentEvent        `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	NextCursor    uint64                 `protobuf:"varint,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPaymentEventsResponse) Reset() {
+	*x = ListPaymentEventsResponse{}
+	mi := &file_payments_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPaymentEventsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPaymentEventsResponse) ProtoMessage() {}
+
+func (x *ListPaymentEventsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_payments_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPaymentEventsResponse.ProtoReflect.Descriptor instead.
+func (*ListPaymentEventsResponse) Descriptor() ([]byte, []int) {
+	return file_payments_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ListPaymentEventsResponse) GetEvents() []*PaymentEvent {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+func (x *ListPaymentEventsResponse) GetNextCursor() uint64 {
+	if x != nil {
+		return x.NextCursor
+	}
+	return 0
+}
+
+type WatchPaymentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchPaymentRequest) Reset() {
+	*x = WatchPaymentRequest{}
+	mi := &file_payments_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchPaymentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchPaymentRequest) ProtoMessage() {}
+
+func (x *WatchPaymentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payments_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchPaymentRequest.ProtoReflect.Descriptor instead.
+func (*WatchPaymentRequest) Descriptor() ([]byte, []int) {
+	return file_payments_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *WatchPaymentRequest) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+// WatchPaymentResponse carries the payment state; event_id is the last
+// payment_events row reflected in it.
+type WatchPaymentResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Payment       *Payment               `protobuf:"bytes,1,opt,name=payment,proto3" json:"payment,omitempty"`
+	EventId       uint64                 `protobuf:"varint,2,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchPaymentResponse) Reset() {
+	*x = WatchPaymentResponse{}
+	mi := &file_payments_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchPaymentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchPaymentResponse) ProtoMessage() {}
+
+func (x *WatchPaymentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_payments_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchPaymentResponse.ProtoReflect.Descriptor instead.
+func (*WatchPaymentResponse) Descriptor() ([]byte, []int) {
+	return file_payments_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *WatchPaymentResponse) GetPayment() *Payment {
+	if x != nil {
+		return x.Payment
+	}
+	return nil
+}
+
+func (x *WatchPaymentResponse) GetEventId() uint64 {
+	if x != nil {
+		return x.EventId
+	}
+	return 0
+}
+
+type ChargeEnvelopeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Payment       *Payment               `protobuf:"bytes,1,opt,name=payment,proto3" json:"payment,omitempty"`
+	Charge        *Charge                `protobuf:"bytes,2,opt,name=charge,proto3" json:"charge,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChargeEnvelopeResponse) Reset() {
+	*x = ChargeEnvelopeResponse{}
+	mi := &file_payments_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChargeEnvelopeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChargeEnvelopeResponse) ProtoMessage() {}
+
+func (x *ChargeEnvelopeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_payments_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChargeEnvelopeResponse.ProtoReflect.Descriptor instead.
+func (*ChargeEnvelopeResponse) Descriptor() ([]byte, []int) {
+	return file_payments_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *ChargeEnvelopeResponse) GetPayment() *Payment {
+	if x != nil {
+		return x.Payment
+	}
+	return nil
+}
+
+func (x *ChargeEnvelopeResponse) GetCharge() *Charge {
+	if x != nil {
+		return x.Charge
+	}
+	return nil
+}
+
+type PauseSubscriptionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PauseSubscriptionRequest) Reset() {
+	*x = PauseSubscriptionRequest{}
+	mi := &file_payments_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PauseSubscriptionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PauseSubscriptionRequest) ProtoMessage() {}
+
+func (x *PauseSubscriptionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payments_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PauseSubscriptionRequest.ProtoReflect.Descriptor instead.
+func (*PauseSubscriptionRequest) Descriptor() ([]byte, []int) {
+	return file_payments_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *PauseSubscriptionRequest) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *PauseSubscriptionRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type ResumeSubscriptionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResumeSubscriptionRequest) Reset() {
+	*x = ResumeSubscriptionRequest{}
+	mi := &file_payments_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResumeSubscriptionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResumeSubscriptionRequest) ProtoMessage() {}
+
+func (x *ResumeSubscriptionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payments_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResumeSubscriptionRequest.ProtoReflect.Descriptor instead.
+func (*ResumeSubscriptionRequest) Descriptor() ([]byte, []int) {
+	return file_payments_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ResumeSubscriptionRequest) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type CancelSubscriptionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	AtPeriodEnd   bool                   `protobuf:"varint,2,opt,name=at_period_end,json=atPeriodEnd,proto3" json:"at_period_end,omitempty"`
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelSubscriptionRequest) Reset() {
+	*x = CancelSubscriptionRequest{}
+	mi := &file_payments_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelSubscriptionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelSubscriptionRequest) ProtoMessage() {}
+
+func (x *CancelSubscriptionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payments_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelSubscriptionRequest.ProtoReflect.Descriptor instead.
+func (*CancelSubscriptionRequest) Descriptor() ([]byte, []int) {
+	return file_payments_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *CancelSubscriptionRequest) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *CancelSubscriptionRequest) GetAtPeriodEnd() bool {
+	if x != nil {
+		return x.AtPeriodEnd
+	}
+	return false
+}
+
+func (x *CancelSubscriptionRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type UpdateSubscriptionRequest struct {
+	state                  protoimpl.MessageState `protogen:"open.v1"`
+	Id                     uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	AmountCents            int64                  `protobuf:"varint,2,opt,name=amount_cents,json=amountCents,proto3" json:"amount_cents,omitempty"`
+	RecurringInterval      string                 `protobuf:"bytes,3,opt,name=recurring_interval,json=recurringInterval,proto3" json:"recurring_interval,omitempty"`
+	RecurringIntervalCount int32                  `protobuf:"varint,4,opt,name=recurring_interval_count,json=recurringIntervalCount,proto3" json:"recurring_interval_count,omitempty"`
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *UpdateSubscriptionRequest) Reset() {
+	*x = UpdateSubscriptionRequest{}
+	mi := &file_payments_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateSubscriptionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateSubscriptionRequest) ProtoMessage() {}
+
+func (x *UpdateSubscriptionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payments_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateSubscriptionRequest.ProtoReflect.Descriptor instead.
+func (*UpdateSubscriptionRequest) Descriptor() ([]byte, []int) {
+	return file_payments_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *UpdateSubscriptionRequest) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *UpdateSubscriptionRequest) GetAmountCents() int64 {
+	if x != nil {
+		return x.AmountCents
+	}
+	return 0
+}
+
+func (x *UpdateSubscriptionRequest) GetRecurringInterval() string {
+	if x != nil {
+		return x.RecurringInterval
+	}
+	return ""
+}
+
+func (x *UpdateSubscriptionRequest) GetRecurringIntervalCount() int32 {
+	if x != nil {
+		return x.RecurringIntervalCount
+	}
+	return 0
+}
+
+type HandleProviderCallbackRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RequestId     string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	Provider      string                 `protobuf:"bytes,2,opt,name=provider,proto3" json:"provider,omitempty"`
+	CallbackHash  string                 `protobuf:"bytes,3,opt,name=callback_hash,json=callbackHash,proto3" json:"callback_hash,omitempty"`
+	Signature     string                 `protobuf:"bytes,4,opt,name=signature,proto3" json:"signature,omitempty"`
+	Payload       string                 `protobuf:"bytes,5,opt,name=payload,proto3" json:"payload,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HandleProviderCallbackRequest) Reset() {
+	*x = HandleProviderCallbackRequest{}
+	mi := &file_payments_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HandleProviderCallbackRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HandleProviderCallbackRequest) ProtoMessage() {}
+
+func (x *HandleProviderCallbackRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payments_proto_msgTypes[25]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -951,66 +2337,712 @@ func (x *HandleProviderCallbackRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HandleProviderCallbackRequest.ProtoReflect.Descriptor instead.
 func (*HandleProviderCallbackRequest) Descriptor() ([]byte, []int) {
-	return file_payments_proto_rawDescGZIP(), []int{7}
+	return file_payments_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *HandleProviderCallbackRequest) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *HandleProviderCallbackRequest) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *HandleProviderCallbackRequest) GetCallbackHash() string {
+	if x != nil {
+		return x.CallbackHash
+	}
+	return ""
+}
+
+func (x *HandleProviderCallbackRequest) GetSignature() string {
+	if x != nil {
+		return x.Signature
+	}
+	return ""
+}
+
+func (x *HandleProviderCallbackRequest) GetPayload() string {
+	if x != nil {
+		return x.Payload
+	}
+	return ""
+}
+
+// ProviderCallback is one inbound webhook stored in payment_callbacks.
+type ProviderCallback struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Id              uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	PaymentId       uint64                 `protobuf:"varint,2,opt,name=payment_id,json=paymentId,proto3" json:"payment_id,omitempty"`
+	Provider        string                 `protobuf:"bytes,3,opt,name=provider,proto3" json:"provider,omitempty"`
+	CallbackHash    string                 `protobuf:"bytes,4,opt,name=callback_hash,json=callbackHash,proto3" json:"callback_hash,omitempty"`
+	ProviderEventId string                 `protobuf:"bytes,5,opt,name=provider_event_id,json=providerEventId,proto3" json:"provider_event_id,omitempty"`
+	Status          ProviderCallbackStatus `protobuf:"varint,6,opt,name=status,proto3,enum=payments.ProviderCallbackStatus" json:"status,omitempty"`
+	Error           string                 `protobuf:"bytes,7,opt,name=error,proto3" json:"error,omitempty"`
+	Payload         string                 `protobuf:"bytes,8,opt,name=payload,proto3" json:"payload,omitempty"`
+	CreatedAt       string                 `protobuf:"bytes,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt       string                 `protobuf:"bytes,10,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ProviderCallback) Reset() {
+	*x = ProviderCallback{}
+	mi := &file_payments_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProviderCallback) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProviderCallback) ProtoMessage() {}
+
+func (x *ProviderCallback) ProtoReflect() protoreflect.Message {
+	mi := &file_payments_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProviderCallback.ProtoReflect.Descriptor instead.
+func (*ProviderCallback) Descriptor() ([]byte, []int) {
+	return file_payments_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *ProviderCallback) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *ProviderCallback) GetPaymentId() uint64 {
+	if x != nil {
+		return x.PaymentId
+	}
+	return 0
+}
+
+func (x *ProviderCallback) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *ProviderCallback) GetCallbackHash() string {
+	if x != nil {
+		return x.CallbackHash
+	}
+	return ""
+}
+
+func (x *ProviderCallback) GetProviderEventId() string {
+	if x != nil {
+		return x.ProviderEventId
+	}
+	return ""
+}
+
+func (x *ProviderCallback) GetStatus() ProviderCallbackStatus {
+	if x != nil {
+		return x.Status
+	}
+	return ProviderCallbackStatus_PROVIDER_CALLBACK_STATUS_UNSPECIFIED
+}
+
+func (x *ProviderCallback) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *ProviderCallback) GetPayload() string {
+	if x != nil {
+		return x.Payload
+	}
+	return ""
+}
+
+func (x *ProviderCallback) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *ProviderCallback) GetUpdatedAt() string {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return ""
+}
+
+// ListProviderCallbacksRequest searches the webhook inbox, newest first.
+// created_from and created_to are RFC3339 timestamps; payloads are only
+// returned when include_payload is set.
+type ListProviderCallbacksRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Provider       string                 `protobuf:"bytes,1,opt,name=provider,proto3" json:"provider,omitempty"`
+	CallbackHash   string                 `protobuf:"bytes,2,opt,name=callback_hash,json=callbackHash,proto3" json:"callback_hash,omitempty"`
+	HasStatus      bool                   `protobuf:"varint,3,opt,name=has_status,json=hasStatus,proto3" json:"has_status,omitempty"`
+	Status         ProviderCallbackStatus `protobuf:"varint,4,opt,name=status,proto3,enum=payments.ProviderCallbackStatus" json:"status,omitempty"`
+	CreatedFrom    string                 `protobuf:"bytes,5,opt,name=created_from,json=createdFrom,proto3" json:"created_from,omitempty"`
+	CreatedTo      string                 `protobuf:"bytes,6,opt,name=created_to,json=createdTo,proto3" json:"created_to,omitempty"`
+	Limit          int32                  `protobuf:"varint,7,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset         int32                  `protobuf:"varint,8,opt,name=offset,proto3" json:"offset,omitempty"`
+	IncludePayload bool                   `protobuf:"varint,9,opt,name=include_payload,json=includePayload,proto3" json:"include_payload,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ListProviderCallbacksRequest) Reset() {
+	*x = ListProviderCallbacksRequest{}
+	mi := &file_payments_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListProviderCallbacksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListProviderCallbacksRequest) ProtoMessage() {}
+
+func (x *ListProviderCallbacksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payments_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListProviderCallbacksRequest.ProtoReflect.Descriptor instead.
+func (*ListProviderCallbacksRequest) Descriptor() ([]byte, []int) {
+	return file_payments_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *ListProviderCallbacksRequest) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *ListProviderCallbacksRequest) GetCallbackHash() string {
+	if x != nil {
+		return x.CallbackHash
+	}
+	return ""
+}
+
+func (x *ListProviderCallbacksRequest) GetHasStatus() bool {
+	if x != nil {
+		return x.HasStatus
+	}
+	return false
+}
+
+func (x *ListProviderCallbacksRequest) GetStatus() ProviderCallbackStatus {
+	if x != nil {
+		return x.Status
+	}
+	return ProviderCallbackStatus_PROVIDER_CALLBACK_STATUS_UNSPECIFIED
+}
+
+func (x *ListProviderCallbacksRequest) GetCreatedFrom() string {
+	if x != nil {
+		return x.CreatedFrom
+	}
+	return ""
+}
+
+func (x *ListProviderCallbacksRequest) GetCreatedTo() string {
+	if x != nil {
+		return x.CreatedTo
+	}
+	return ""
+}
+
+func (x *ListProviderCallbacksRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListProviderCallbacksRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *ListProviderCallbacksRequest) GetIncludePayload() bool {
+	if x != nil {
+		return x.IncludePayload
+	}
+	return false
+}
+
+type ListProviderCallbacksResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Callbacks     []*ProviderCallback    `protobuf:"bytes,1,rep,name=callbacks,proto3" json:"callbacks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListProviderCallbacksResponse) Reset() {
+	*x = ListProviderCallbacksResponse{}
+	mi := &file_payments_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListProviderCallbacksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListProviderCallbacksResponse) ProtoMessage() {}
+
+func (x *ListProviderCallbacksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_payments_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListProviderCallbacksResponse.ProtoReflect.Descriptor instead.
+func (*ListProviderCallbacksResponse) Descriptor() ([]byte, []int) {
+	return file_payments_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *ListProviderCallbacksResponse) GetCallbacks() []*ProviderCallback {
+	if x != nil {
+		return x.Callbacks
+	}
+	return nil
+}
+
+// ReplayProviderCallbackRequest runs a stored webhook through
+// HandleProviderCallback again. skip_signature parses the stored payload
+// without verifying it, for payloads whose signature was checked on receipt
+// but has since expired.
+type ReplayProviderCallbackRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	SkipSignature bool                   `protobuf:"varint,2,opt,name=skip_signature,json=skipSignature,proto3" json:"skip_signature,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReplayProviderCallbackRequest) Reset() {
+	*x = ReplayProviderCallbackRequest{}
+	mi := &file_payments_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplayProviderCallbackRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplayProviderCallbackRequest) ProtoMessage() {}
+
+func (x *ReplayProviderCallbackRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payments_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplayProviderCallbackRequest.ProtoReflect.Descriptor instead.
+func (*ReplayProviderCallbackRequest) Descriptor() ([]byte, []int) {
+	return file_payments_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *ReplayProviderCallbackRequest) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *ReplayProviderCallbackRequest) GetSkipSignature() bool {
+	if x != nil {
+		return x.SkipSignature
+	}
+	return false
+}
+
+type PaymentEnvelopeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Payment       *Payment               `protobuf:"bytes,1,opt,name=payment,proto3" json:"payment,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PaymentEnvelopeResponse) Reset() {
+	*x = PaymentEnvelopeResponse{}
+	mi := &file_payments_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PaymentEnvelopeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PaymentEnvelopeResponse) ProtoMessage() {}
+
+func (x *PaymentEnvelopeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_payments_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PaymentEnvelopeResponse.ProtoReflect.Descriptor instead.
+func (*PaymentEnvelopeResponse) Descriptor() ([]byte, []int) {
+	return file_payments_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *PaymentEnvelopeResponse) GetPayment() *Payment {
+	if x != nil {
+		return x.Payment
+	}
+	return nil
+}
+
+type ListPaymentsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Payments      []*Payment             `protobuf:"bytes,1,rep,name=payments,proto3" json:"payments,omitempty"`
+	NextPageToken string                 `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPaymentsResponse) Reset() {
+	*x = ListPaymentsResponse{}
+	mi := &file_payments_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPaymentsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPaymentsResponse) ProtoMessage() {}
+
+func (x *ListPaymentsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_payments_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPaymentsResponse.ProtoReflect.Descriptor instead.
+func (*ListPaymentsResponse) Descriptor() ([]byte, []int) {
+	return file_payments_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *ListPaymentsResponse) GetPayments() []*Payment {
+	if x != nil {
+		return x.Payments
+	}
+	return nil
+}
+
+func (x *ListPaymentsResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type PaymentCallbackRequest struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	CallbackId uint64                 `protobuf:"varint,1,opt,name=callback_id,json=callbackId,proto3" json:"callback_id,omitempty"`
+	EventType  string                 `protobuf:"bytes,2,opt,name=event_type,json=eventType,proto3" json:"event_type,omitempty"`
+	RequestId  string                 `protobuf:"bytes,3,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	Payment    *Payment               `protobuf:"bytes,4,opt,name=payment,proto3" json:"payment,omitempty"`
+	Charge     *Charge                `protobuf:"bytes,5,opt,name=charge,proto3" json:"charge,omitempty"`
+	// JSON envelope the signature was computed over.
+	PayloadJson string `protobuf:"bytes,6,opt,name=payload_json,json=payloadJson,proto3" json:"payload_json,omitempty"`
+	// Same value as the X-Payments-Signature header on HTTP callbacks.
+	Signature     string `protobuf:"bytes,7,opt,name=signature,proto3" json:"signature,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PaymentCallbackRequest) Reset() {
+	*x = PaymentCallbackRequest{}
+	mi := &file_payments_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PaymentCallbackRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PaymentCallbackRequest) ProtoMessage() {}
+
+func (x *PaymentCallbackRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payments_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
 }
 
-func (x *HandleProviderCallbackRequest) GetRequestId() string {
+// Deprecated: Use PaymentCallbackRequest.ProtoReflect.Descriptor instead.
+func (*PaymentCallbackRequest) Descriptor() ([]byte, []int) {
+	return file_payments_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *PaymentCallbackRequest) GetCallbackId() uint64 {
 	if x != nil {
-		return x.RequestId
+		return x.CallbackId
+	}
+	return 0
+}
+
+func (x *PaymentCallbackRequest) GetEventType() string {
+	if x != nil {
+		return x.EventType
 	}
 	return ""
 }
 
-func (x *HandleProviderCallbackRequest) GetProvider() string {
+func (x *PaymentCallbackRequest) GetRequestId() string {
 	if x != nil {
-		return x.Provider
+		return x.RequestId
 	}
 	return ""
 }
 
-func (x *HandleProviderCallbackRequest) GetCallbackHash() string {
+func (x *PaymentCallbackRequest) GetPayment() *Payment {
 	if x != nil {
-		return x.CallbackHash
+		return x.Payment
+	}
+	return nil
+}
+
+func (x *PaymentCallbackRequest) GetCharge() *Charge {
+	if x != nil {
+		return x.Charge
+	}
+	return nil
+}
+
+func (x *PaymentCallbackRequest) GetPayloadJson() string {
+	if x != nil {
+		return x.PayloadJson
 	}
 	return ""
 }
 
-func (x *HandleProviderCallbackRequest) GetSignature() string {
+func (x *PaymentCallbackRequest) GetSignature() string {
 	if x != nil {
 		return x.Signature
 	}
 	return ""
 }
 
-func (x *HandleProviderCallbackRequest) GetPayload() string {
+type CallbackDelivery struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	PaymentId     uint64                 `protobuf:"varint,2,opt,name=payment_id,json=paymentId,proto3" json:"payment_id,omitempty"`
+	ChargeId      uint64                 `protobuf:"varint,3,opt,name=charge_id,json=chargeId,proto3" json:"charge_id,omitempty"`
+	CallerService string                 `protobuf:"bytes,4,opt,name=caller_service,json=callerService,proto3" json:"caller_service,omitempty"`
+	EventType     string                 `protobuf:"bytes,5,opt,name=event_type,json=eventType,proto3" json:"event_type,omitempty"`
+	RequestId     string                 `protobuf:"bytes,6,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	Destination   string                 `protobuf:"bytes,7,opt,name=destination,proto3" json:"destination,omitempty"`
+	Attempts      int32                  `protobuf:"varint,8,opt,name=attempts,proto3" json:"attempts,omitempty"`
+	LastError     string                 `protobuf:"bytes,9,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+	CreatedAt     string                 `protobuf:"bytes,10,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     string                 `protobuf:"bytes,11,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CallbackDelivery) Reset() {
+	*x = CallbackDelivery{}
+	mi := &file_payments_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CallbackDelivery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CallbackDelivery) ProtoMessage() {}
+
+func (x *CallbackDelivery) ProtoReflect() protoreflect.Message {
+	mi := &file_payments_proto_msgTypes[33]
 	if x != nil {
-		return x.Payload
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CallbackDelivery.ProtoReflect.Descriptor instead.
+func (*CallbackDelivery) Descriptor() ([]byte, []int) {
+	return file_payments_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *CallbackDelivery) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *CallbackDelivery) GetPaymentId() uint64 {
+	if x != nil {
+		return x.PaymentId
+	}
+	return 0
+}
+
+func (x *CallbackDelivery) GetChargeId() uint64 {
+	if x != nil {
+		return x.ChargeId
+	}
+	return 0
+}
+
+func (x *CallbackDelivery) GetCallerService() string {
+	if x != nil {
+		return x.CallerService
 	}
 	return ""
 }
 
-type PaymentEnvelopeResponse struct {
+func (x *CallbackDelivery) GetEventType() string {
+	if x != nil {
+		return x.EventType
+	}
+	return ""
+}
+
+func (x *CallbackDelivery) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *CallbackDelivery) GetDestination() string {
+	if x != nil {
+		return x.Destination
+	}
+	return ""
+}
+
+func (x *CallbackDelivery) GetAttempts() int32 {
+	if x != nil {
+		return x.Attempts
+	}
+	return 0
+}
+
+func (x *CallbackDelivery) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+func (x *CallbackDelivery) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *CallbackDelivery) GetUpdatedAt() string {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return ""
+}
+
+type ListDeadLetterCallbacksRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Payment       *Payment               `protobuf:"bytes,1,opt,name=payment,proto3" json:"payment,omitempty"`
+	PaymentId     uint64                 `protobuf:"varint,1,opt,name=payment_id,json=paymentId,proto3" json:"payment_id,omitempty"`
+	CallerService string                 `protobuf:"bytes,2,opt,name=caller_service,json=callerService,proto3" json:"caller_service,omitempty"`
+	EventType     string                 `protobuf:"bytes,3,opt,name=event_type,json=eventType,proto3" json:"event_type,omitempty"`
+	Limit         int32                  `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset        int32                  `protobuf:"varint,5,opt,name=offset,proto3" json:"offset,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PaymentEnvelopeResponse) Reset() {
-	*x = PaymentEnvelopeResponse{}
-	mi := &file_payments_proto_msgTypes[8]
+func (x *ListDeadLetterCallbacksRequest) Reset() {
+	*x = ListDeadLetterCallbacksRequest{}
+	mi := &file_payments_proto_msgTypes[34]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PaymentEnvelopeResponse) String() string {
+func (x *ListDeadLetterCallbacksRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PaymentEnvelopeResponse) ProtoMessage() {}
+func (*ListDeadLetterCallbacksRequest) ProtoMessage() {}
 
-func (x *PaymentEnvelopeResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_payments_proto_msgTypes[8]
+func (x *ListDeadLetterCallbacksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payments_proto_msgTypes[34]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1021,40 +3053,68 @@ func (x *PaymentEnvelopeResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PaymentEnvelopeResponse.ProtoReflect.Descriptor instead.
-func (*PaymentEnvelopeResponse) Descriptor() ([]byte, []int) {
-	return file_payments_proto_rawDescGZIP(), []int{8}
+// Deprecated: Use ListDeadLetterCallbacksRequest.ProtoReflect.Descriptor instead.
+func (*ListDeadLetterCallbacksRequest) Descriptor() ([]byte, []int) {
+	return file_payments_proto_rawDescGZIP(), []int{34}
 }
 
-func (x *PaymentEnvelopeResponse) GetPayment() *Payment {
+func (x *ListDeadLetterCallbacksRequest) GetPaymentId() uint64 {
 	if x != nil {
-		return x.Payment
+		return x.PaymentId
 	}
-	return nil
+	return 0
 }
 
-type ListPaymentsResponse struct {
+func (x *ListDeadLetterCallbacksRequest) GetCallerService() string {
+	if x != nil {
+		return x.CallerService
+	}
+	return ""
+}
+
+func (x *ListDeadLetterCallbacksRequest) GetEventType() string {
+	if x != nil {
+		return x.EventType
+	}
+	return ""
+}
+
+func (x *ListDeadLetterCallbacksRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListDeadLetterCallbacksRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type ListDeadLetterCallbacksResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Payments      []*Payment             `protobuf:"bytes,1,rep,name=payments,proto3" json:"payments,omitempty"`
+	Callbacks     []*CallbackDelivery    `protobuf:"bytes,1,rep,name=callbacks,proto3" json:"callbacks,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListPaymentsResponse) Reset() {
-	*x = ListPaymentsResponse{}
-	mi := &file_payments_proto_msgTypes[9]
+func (x *ListDeadLetterCallbacksResponse) Reset() {
+	*x = ListDeadLetterCallbacksResponse{}
+	mi := &file_payments_proto_msgTypes[35]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListPaymentsResponse) String() string {
+func (x *ListDeadLetterCallbacksResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListPaymentsResponse) ProtoMessage() {}
+func (*ListDeadLetterCallbacksResponse) ProtoMessage() {}
 
-func (x *ListPaymentsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_payments_proto_msgTypes[9]
+func (x *ListDeadLetterCallbacksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_payments_proto_msgTypes[35]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1065,18 +3125,132 @@ func (x *ListPaymentsResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListPaymentsResponse.ProtoReflect.Descriptor instead.
-func (*ListPaymentsResponse) Descriptor() ([]byte, []int) {
-	return file_payments_proto_rawDescGZIP(), []int{9}
+// Deprecated: Use ListDeadLetterCallbacksResponse.ProtoReflect.Descriptor instead.
+func (*ListDeadLetterCallbacksResponse) Descriptor() ([]byte, []int) {
+	return file_payments_proto_rawDescGZIP(), []int{35}
 }
 
-func (x *ListPaymentsResponse) GetPayments() []*Payment {
+func (x *ListDeadLetterCallbacksResponse) GetCallbacks() []*CallbackDelivery {
 	if x != nil {
-		return x.Payments
+		return x.Callbacks
 	}
 	return nil
 }
 
+// RedeliverCallbacksRequest resets dead-lettered callbacks to pending. At least
+// one filter must be set unless all is true.
+type RedeliverCallbacksRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PaymentId     uint64                 `protobuf:"varint,1,opt,name=payment_id,json=paymentId,proto3" json:"payment_id,omitempty"`
+	CallerService string                 `protobuf:"bytes,2,opt,name=caller_service,json=callerService,proto3" json:"caller_service,omitempty"`
+	EventType     string                 `protobuf:"bytes,3,opt,name=event_type,json=eventType,proto3" json:"event_type,omitempty"`
+	All           bool                   `protobuf:"varint,4,opt,name=all,proto3" json:"all,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RedeliverCallbacksRequest) Reset() {
+	*x = RedeliverCallbacksRequest{}
+	mi := &file_payments_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RedeliverCallbacksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RedeliverCallbacksRequest) ProtoMessage() {}
+
+func (x *RedeliverCallbacksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payments_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RedeliverCallbacksRequest.ProtoReflect.Descriptor instead.
+func (*RedeliverCallbacksRequest) Descriptor() ([]byte, []int) {
+	return file_payments_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *RedeliverCallbacksRequest) GetPaymentId() uint64 {
+	if x != nil {
+		return x.PaymentId
+	}
+	return 0
+}
+
+func (x *RedeliverCallbacksRequest) GetCallerService() string {
+	if x != nil {
+		return x.CallerService
+	}
+	return ""
+}
+
+func (x *RedeliverCallbacksRequest) GetEventType() string {
+	if x != nil {
+		return x.EventType
+	}
+	return ""
+}
+
+func (x *RedeliverCallbacksRequest) GetAll() bool {
+	if x != nil {
+		return x.All
+	}
+	return false
+}
+
+type RedeliverCallbacksResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Redelivered   int64                  `protobuf:"varint,1,opt,name=redelivered,proto3" json:"redelivered,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RedeliverCallbacksResponse) Reset() {
+	*x = RedeliverCallbacksResponse{}
+	mi := &file_payments_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RedeliverCallbacksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RedeliverCallbacksResponse) ProtoMessage() {}
+
+func (x *RedeliverCallbacksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_payments_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RedeliverCallbacksResponse.ProtoReflect.Descriptor instead.
+func (*RedeliverCallbacksResponse) Descriptor() ([]byte, []int) {
+	return file_payments_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *RedeliverCallbacksResponse) GetRedelivered() int64 {
+	if x != nil {
+		return x.Redelivered
+	}
+	return 0
+}
+
 type MessageResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
@@ -1087,7 +3261,7 @@ type MessageResponse struct {
 
 func (x *MessageResponse) Reset() {
 	*x = MessageResponse{}
-	mi := &file_payments_proto_msgTypes[10]
+	mi := &file_payments_proto_msgTypes[38]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1099,7 +3273,7 @@ func (x *MessageResponse) String() string {
 func (*MessageResponse) ProtoMessage() {}
 
 func (x *MessageResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_payments_proto_msgTypes[10]
+	mi := &file_payments_proto_msgTypes[38]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1112,7 +3286,7 @@ func (x *MessageResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use MessageResponse.ProtoReflect.Descriptor instead.
 func (*MessageResponse) Descriptor() ([]byte, []int) {
-	return file_payments_proto_rawDescGZIP(), []int{10}
+	return file_payments_proto_rawDescGZIP(), []int{38}
 }
 
 func (x *MessageResponse) GetMessage() string {
@@ -1138,7 +3312,7 @@ type ErrorResponse struct {
 
 func (x *ErrorResponse) Reset() {
 	*x = ErrorResponse{}
-	mi := &file_payments_proto_msgTypes[11]
+	mi := &file_payments_proto_msgTypes[39]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1150,7 +3324,7 @@ func (x *ErrorResponse) String() string {
 func (*ErrorResponse) ProtoMessage() {}
 
 func (x *ErrorResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_payments_proto_msgTypes[11]
+	mi := &file_payments_proto_msgTypes[39]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1163,7 +3337,7 @@ func (x *ErrorResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ErrorResponse.ProtoReflect.Descriptor instead.
 func (*ErrorResponse) Descriptor() ([]byte, []int) {
-	return file_payments_proto_rawDescGZIP(), []int{11}
+	return file_payments_proto_rawDescGZIP(), []int{39}
 }
 
 func (x *ErrorResponse) GetError() string {
@@ -1175,261 +3349,367 @@ func (x *ErrorResponse) GetError() string {
 
 var File_payments_proto protoreflect.FileDescriptor
 
-var file_payments_proto_rawDesc = string([]byte{
-	0x0a, 0x0e, 0x70, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x12, 0x08, 0x70, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x22, 0x0f, 0x0a, 0x0d, 0x48, 0x65,
-	0x61, 0x6c, 0x74, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x28, 0x0a, 0x0e, 0x48,
-	0x65, 0x61, 0x6c, 0x74, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x16, 0x0a,
-	0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73,
-	0x74, 0x61, 0x74, 0x75, 0x73, 0x22, 0x80, 0x09, 0x0a, 0x07, 0x50, 0x61, 0x79, 0x6d, 0x65, 0x6e,
-	0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x02, 0x69,
-	0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x5f, 0x69, 0x64, 0x18,
-	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x49, 0x64,
-	0x12, 0x25, 0x0a, 0x0e, 0x63, 0x61, 0x6c, 0x6c, 0x65, 0x72, 0x5f, 0x73, 0x65, 0x72, 0x76, 0x69,
-	0x63, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x63, 0x61, 0x6c, 0x6c, 0x65, 0x72,
-	0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x72, 0x65, 0x73, 0x6f, 0x75,
-	0x72, 0x63, 0x65, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c,
-	0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x54, 0x79, 0x70, 0x65, 0x12, 0x1f, 0x0a, 0x0b,
-	0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x0a, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x49, 0x64, 0x12, 0x21, 0x0a,
-	0x0c, 0x63, 0x75, 0x73, 0x74, 0x6f, 0x6d, 0x65, 0x72, 0x5f, 0x72, 0x65, 0x66, 0x18, 0x06, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x0b, 0x63, 0x75, 0x73, 0x74, 0x6f, 0x6d, 0x65, 0x72, 0x52, 0x65, 0x66,
-	0x12, 0x21, 0x0a, 0x0c, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x5f, 0x63, 0x65, 0x6e, 0x74, 0x73,
-	0x18, 0x07, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0b, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x43, 0x65,
-	0x6e, 0x74, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x63, 0x79, 0x18,
-	0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x63, 0x79, 0x12,
-	0x2f, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0e, 0x32,
-	0x17, 0x2e, 0x70, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x2e, 0x50, 0x61, 0x79, 0x6d, 0x65,
-	0x6e, 0x74, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73,
-	0x12, 0x3e, 0x0a, 0x0e, 0x70, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x5f, 0x6d, 0x65, 0x74, 0x68,
-	0x6f, 0x64, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x17, 0x2e, 0x70, 0x61, 0x79, 0x6d, 0x65,
-	0x6e, 0x74, 0x73, 0x2e, 0x50, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x4d, 0x65, 0x74, 0x68, 0x6f,
-	0x64, 0x52, 0x0d, 0x70, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x4d, 0x65, 0x74, 0x68, 0x6f, 0x64,
-	0x12, 0x38, 0x0a, 0x0c, 0x70, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x5f, 0x74, 0x79, 0x70, 0x65,
-	0x18, 0x0b, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x15, 0x2e, 0x70, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74,
-	0x73, 0x2e, 0x50, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x52, 0x0b, 0x70,
-	0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x12, 0x32, 0x0a, 0x08, 0x70, 0x72,
-	0x6f, 0x76, 0x69, 0x64, 0x65, 0x72, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x16, 0x2e, 0x70,
-	0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x2e, 0x50, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x72,
-	0x54, 0x79, 0x70, 0x65, 0x52, 0x08, 0x70, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x72, 0x12, 0x2d,
-	0x0a, 0x12, 0x72, 0x65, 0x63, 0x75, 0x72, 0x72, 0x69, 0x6e, 0x67, 0x5f, 0x69, 0x6e, 0x74, 0x65,
-	0x72, 0x76, 0x61, 0x6c, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x09, 0x52, 0x11, 0x72, 0x65, 0x63, 0x75,
-	0x72, 0x72, 0x69, 0x6e, 0x67, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x12, 0x38, 0x0a,
-	0x18, 0x72, 0x65, 0x63, 0x75, 0x72, 0x72, 0x69, 0x6e, 0x67, 0x5f, 0x69, 0x6e, 0x74, 0x65, 0x72,
-	0x76, 0x61, 0x6c, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x05, 0x52,
-	0x16, 0x72, 0x65, 0x63, 0x75, 0x72, 0x72, 0x69, 0x6e, 0x67, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x76,
-	0x61, 0x6c, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x2e, 0x0a, 0x13, 0x70, 0x72, 0x6f, 0x76, 0x69,
-	0x64, 0x65, 0x72, 0x5f, 0x70, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x0f,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x11, 0x70, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x72, 0x50, 0x61,
-	0x79, 0x6d, 0x65, 0x6e, 0x74, 0x49, 0x64, 0x12, 0x38, 0x0a, 0x18, 0x70, 0x72, 0x6f, 0x76, 0x69,
-	0x64, 0x65, 0x72, 0x5f, 0x73, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e,
-	0x5f, 0x69, 0x64, 0x18, 0x10, 0x20, 0x01, 0x28, 0x09, 0x52, 0x16, 0x70, 0x72, 0x6f, 0x76, 0x69,
-	0x64, 0x65, 0x72, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x49,
-	0x64, 0x12, 0x21, 0x0a, 0x0c, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x6f, 0x75, 0x74, 0x5f, 0x75, 0x72,
-	0x6c, 0x18, 0x11, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x6f, 0x75,
-	0x74, 0x55, 0x72, 0x6c, 0x12, 0x34, 0x0a, 0x16, 0x70, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x72,
-	0x5f, 0x63, 0x61, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x5f, 0x68, 0x61, 0x73, 0x68, 0x18, 0x12,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x14, 0x70, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x72, 0x43, 0x61,
-	0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x48, 0x61, 0x73, 0x68, 0x12, 0x32, 0x0a, 0x15, 0x70, 0x72,
-	0x6f, 0x76, 0x69, 0x64, 0x65, 0x72, 0x5f, 0x63, 0x61, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x5f,
-	0x75, 0x72, 0x6c, 0x18, 0x13, 0x20, 0x01, 0x28, 0x09, 0x52, 0x13, 0x70, 0x72, 0x6f, 0x76, 0x69,
-	0x64, 0x65, 0x72, 0x43, 0x61, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x55, 0x72, 0x6c, 0x12, 0x2e,
-	0x0a, 0x13, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x5f, 0x63, 0x61, 0x6c, 0x6c, 0x62, 0x61, 0x63,
-	0x6b, 0x5f, 0x75, 0x72, 0x6c, 0x18, 0x14, 0x20, 0x01, 0x28, 0x09, 0x52, 0x11, 0x73, 0x74, 0x61,
-	0x74, 0x75, 0x73, 0x43, 0x61, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x55, 0x72, 0x6c, 0x12, 0x25,
-	0x0a, 0x0e, 0x72, 0x65, 0x66, 0x75, 0x6e, 0x64, 0x65, 0x64, 0x5f, 0x63, 0x65, 0x6e, 0x74, 0x73,
-	0x18, 0x15, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0d, 0x72, 0x65, 0x66, 0x75, 0x6e, 0x64, 0x65, 0x64,
-	0x43, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x29, 0x0a, 0x10, 0x72, 0x65, 0x66, 0x75, 0x6e, 0x64, 0x61,
-	0x62, 0x6c, 0x65, 0x5f, 0x63, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x16, 0x20, 0x01, 0x28, 0x03, 0x52,
-	0x0f, 0x72, 0x65, 0x66, 0x75, 0x6e, 0x64, 0x61, 0x62, 0x6c, 0x65, 0x43, 0x65, 0x6e, 0x74, 0x73,
-	0x12, 0x3b, 0x0a, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x18, 0x17, 0x20, 0x03,
-	0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x70, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x2e, 0x50, 0x61,
-	0x79, 0x6d, 0x65, 0x6e, 0x74, 0x2e, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x45, 0x6e,
-	0x74, 0x72, 0x79, 0x52, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x12, 0x1d, 0x0a,
-	0x0a, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x18, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x12, 0x1d, 0x0a, 0x0a,
-	0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x19, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x09, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x1a, 0x3b, 0x0a, 0x0d, 0x4d,
-	0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03,
-	0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14,
-	0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76,
-	0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x92, 0x06, 0x0a, 0x14, 0x43, 0x72, 0x65,
-	0x61, 0x74, 0x65, 0x50, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x5f, 0x69, 0x64, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x49, 0x64,
-	0x12, 0x25, 0x0a, 0x0e, 0x63, 0x61, 0x6c, 0x6c, 0x65, 0x72, 0x5f, 0x73, 0x65, 0x72, 0x76, 0x69,
-	0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x63, 0x61, 0x6c, 0x6c, 0x65, 0x72,
-	0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x72, 0x65, 0x73, 0x6f, 0x75,
-	0x72, 0x63, 0x65, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c,
-	0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x54, 0x79, 0x70, 0x65, 0x12, 0x1f, 0x0a, 0x0b,
-	0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x0a, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x49, 0x64, 0x12, 0x21, 0x0a,
-	0x0c, 0x63, 0x75, 0x73, 0x74, 0x6f, 0x6d, 0x65, 0x72, 0x5f, 0x72, 0x65, 0x66, 0x18, 0x05, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x0b, 0x63, 0x75, 0x73, 0x74, 0x6f, 0x6d, 0x65, 0x72, 0x52, 0x65, 0x66,
-	0x12, 0x21, 0x0a, 0x0c, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x5f, 0x63, 0x65, 0x6e, 0x74, 0x73,
-	0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0b, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x43, 0x65,
-	0x6e, 0x74, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x63, 0x79, 0x18,
-	0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x63, 0x79, 0x12,
-	0x3e, 0x0a, 0x0e, 0x70, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x5f, 0x6d, 0x65, 0x74, 0x68, 0x6f,
-	0x64, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x17, 0x2e, 0x70, 0x61, 0x79, 0x6d, 0x65, 0x6e,
-	0x74, 0x73, 0x2e, 0x50, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x4d, 0x65, 0x74, 0x68, 0x6f, 0x64,
-	0x52, 0x0d, 0x70, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x4d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x12,
-	0x38, 0x0a, 0x0c, 0x70, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18,
-	0x09, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x15, 0x2e, 0x70, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x73,
-	0x2e, 0x50, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x52, 0x0b, 0x70, 0x61,
-	0x79, 0x6d, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x12, 0x32, 0x0a, 0x08, 0x70, 0x72, 0x6f,
-	0x76, 0x69, 0x64, 0x65, 0x72, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x16, 0x2e, 0x70, 0x61,
-	0x79, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x2e, 0x50, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x72, 0x54,
-	0x79, 0x70, 0x65, 0x52, 0x08, 0x70, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x72, 0x12, 0x2d, 0x0a,
-	0x12, 0x72, 0x65, 0x63, 0x75, 0x72, 0x72, 0x69, 0x6e, 0x67, 0x5f, 0x69, 0x6e, 0x74, 0x65, 0x72,
-	0x76, 0x61, 0x6c, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x11, 0x72, 0x65, 0x63, 0x75, 0x72,
-	0x72, 0x69, 0x6e, 0x67, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x12, 0x38, 0x0a, 0x18,
-	0x72, 0x65, 0x63, 0x75, 0x72, 0x72, 0x69, 0x6e, 0x67, 0x5f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x76,
-	0x61, 0x6c, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x05, 0x52, 0x16,
-	0x72, 0x65, 0x63, 0x75, 0x72, 0x72, 0x69, 0x6e, 0x67, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61,
-	0x6c, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x2e, 0x0a, 0x13, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73,
-	0x5f, 0x63, 0x61, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x5f, 0x75, 0x72, 0x6c, 0x18, 0x0d, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x11, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x43, 0x61, 0x6c, 0x6c, 0x62,
-	0x61, 0x63, 0x6b, 0x55, 0x72, 0x6c, 0x12, 0x1f, 0x0a, 0x0b, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73,
-	0x73, 0x5f, 0x75, 0x72, 0x6c, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x73, 0x75, 0x63,
-	0x63, 0x65, 0x73, 0x73, 0x55, 0x72, 0x6c, 0x12, 0x1d, 0x0a, 0x0a, 0x63, 0x61, 0x6e, 0x63, 0x65,
-	0x6c, 0x5f, 0x75, 0x72, 0x6c, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x61, 0x6e,
-	0x63, 0x65, 0x6c, 0x55, 0x72, 0x6c, 0x12, 0x48, 0x0a, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61,
-	0x74, 0x61, 0x18, 0x10, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2c, 0x2e, 0x70, 0x61, 0x79, 0x6d, 0x65,
-	0x6e, 0x74, 0x73, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x50, 0x61, 0x79, 0x6d, 0x65, 0x6e,
-	0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74,
-	0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61,
-	0x1a, 0x3b, 0x0a, 0x0d, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72,
-	0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03,
-	0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x23, 0x0a,
-	0x11, 0x47, 0x65, 0x74, 0x50, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65,
-	0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x02,
-	0x69, 0x64, 0x22, 0xd3, 0x02, 0x0a, 0x13, 0x4c, 0x69, 0x73, 0x74, 0x50, 0x61, 0x79, 0x6d, 0x65,
-	0x6e, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09,
-	0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x49, 0x64, 0x12, 0x25, 0x0a, 0x0e, 0x63, 0x61, 0x6c,
-	0x6c, 0x65, 0x72, 0x5f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x0d, 0x63, 0x61, 0x6c, 0x6c, 0x65, 0x72, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65,
-	0x12, 0x23, 0x0a, 0x0d, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x5f, 0x74, 0x79, 0x70,
-	0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63,
-	0x65, 0x54, 0x79, 0x70, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63,
-	0x65, 0x5f, 0x69, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x72, 0x65, 0x73, 0x6f,
-	0x75, 0x72, 0x63, 0x65, 0x49, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x68, 0x61, 0x73, 0x5f, 0x73, 0x74,
-	0x61, 0x74, 0x75, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x68, 0x61, 0x73, 0x53,
-	0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x2f, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18,
-	0x06, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x17, 0x2e, 0x70, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x73,
-	0x2e, 0x50, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06,
-	0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x32, 0x0a, 0x08, 0x70, 0x72, 0x6f, 0x76, 0x69, 0x64,
-	0x65, 0x72, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x16, 0x2e, 0x70, 0x61, 0x79, 0x6d, 0x65,
-	0x6e, 0x74, 0x73, 0x2e, 0x50, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x72, 0x54, 0x79, 0x70, 0x65,
-	0x52, 0x08, 0x70, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69,
-	0x6d, 0x69, 0x74, 0x18, 0x08, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74,
-	0x12, 0x16, 0x0a, 0x06, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x18, 0x09, 0x20, 0x01, 0x28, 0x05,
-	0x52, 0x06, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x22, 0x3e, 0x0a, 0x14, 0x43, 0x61, 0x6e, 0x63,
-	0x65, 0x6c, 0x50, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x02, 0x69, 0x64,
-	0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x22, 0xb7, 0x01, 0x0a, 0x1d, 0x48, 0x61, 0x6e,
-	0x64, 0x6c, 0x65, 0x50, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x72, 0x43, 0x61, 0x6c, 0x6c, 0x62,
-	0x61, 0x63, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09,
-	0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x49, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x72, 0x6f,
-	0x76, 0x69, 0x64, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x72, 0x6f,
-	0x76, 0x69, 0x64, 0x65, 0x72, 0x12, 0x23, 0x0a, 0x0d, 0x63, 0x61, 0x6c, 0x6c, 0x62, 0x61, 0x63,
-	0x6b, 0x5f, 0x68, 0x61, 0x73, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x63, 0x61,
-	0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x48, 0x61, 0x73, 0x68, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x69,
-	0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73,
-	0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x79, 0x6c,
-	0x6f, 0x61, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f,
-	0x61, 0x64, 0x22, 0x46, 0x0a, 0x17, 0x50, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x45, 0x6e, 0x76,
-	0x65, 0x6c, 0x6f, 0x70, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2b, 0x0a,
-	0x07, 0x70, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11,
-	0x2e, 0x70, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x2e, 0x50, 0x61, 0x79, 0x6d, 0x65, 0x6e,
-	0x74, 0x52, 0x07, 0x70, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x22, 0x45, 0x0a, 0x14, 0x4c, 0x69,
-	0x73, 0x74, 0x50, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
-	0x73, 0x65, 0x12, 0x2d, 0x0a, 0x08, 0x70, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x01,
-	0x20, 0x03, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x70, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x2e,
-	0x50, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x08, 0x70, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74,
-	0x73, 0x22, 0x58, 0x0a, 0x0f, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x52, 0x65, 0x73, 0x70,
-	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x2b,
-	0x0a, 0x07, 0x70, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
-	0x11, 0x2e, 0x70, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x2e, 0x50, 0x61, 0x79, 0x6d, 0x65,
-	0x6e, 0x74, 0x52, 0x07, 0x70, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x22, 0x25, 0x0a, 0x0d, 0x45,
-	0x72, 0x72, 0x6f, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x14, 0x0a, 0x05,
-	0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72,
-	0x6f, 0x72, 0x2a, 0xf3, 0x01, 0x0a, 0x0d, 0x50, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x53, 0x74,
-	0x61, 0x74, 0x75, 0x73, 0x12, 0x1e, 0x0a, 0x1a, 0x50, 0x41, 0x59, 0x4d, 0x45, 0x4e, 0x54, 0x5f,
-	0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49,
-	0x45, 0x44, 0x10, 0x00, 0x12, 0x1a, 0x0a, 0x16, 0x50, 0x41, 0x59, 0x4d, 0x45, 0x4e, 0x54, 0x5f,
-	0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x43, 0x52, 0x45, 0x41, 0x54, 0x45, 0x44, 0x10, 0x01,
-	0x12, 0x1a, 0x0a, 0x16, 0x50, 0x41, 0x59, 0x4d, 0x45, 0x4e, 0x54, 0x5f, 0x53, 0x54, 0x41, 0x54,
-	0x55, 0x53, 0x5f, 0x50, 0x45, 0x4e, 0x44, 0x49, 0x4e, 0x47, 0x10, 0x02, 0x12, 0x1d, 0x0a, 0x19,
-	0x50, 0x41, 0x59, 0x4d, 0x45, 0x4e, 0x54, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x50,
-	0x52, 0x4f, 0x43, 0x45, 0x53, 0x53, 0x49, 0x4e, 0x47, 0x10, 0x03, 0x12, 0x17, 0x0a, 0x13, 0x50,
-	0x41, 0x59, 0x4d, 0x45, 0x4e, 0x54, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x50, 0x41,
-	0x49, 0x44, 0x10, 0x0a, 0x12, 0x19, 0x0a, 0x15, 0x50, 0x41, 0x59, 0x4d, 0x45, 0x4e, 0x54, 0x5f,
-	0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x46, 0x41, 0x49, 0x4c, 0x45, 0x44, 0x10, 0x14, 0x12,
-	0x1b, 0x0a, 0x17, 0x50, 0x41, 0x59, 0x4d, 0x45, 0x4e, 0x54, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55,
-	0x53, 0x5f, 0x43, 0x41, 0x4e, 0x43, 0x45, 0x4c, 0x45, 0x44, 0x10, 0x1e, 0x12, 0x1a, 0x0a, 0x16,
-	0x50, 0x41, 0x59, 0x4d, 0x45, 0x4e, 0x54, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x45,
-	0x58, 0x50, 0x49, 0x52, 0x45, 0x44, 0x10, 0x28, 0x2a, 0x70, 0x0a, 0x0d, 0x50, 0x61, 0x79, 0x6d,
-	0x65, 0x6e, 0x74, 0x4d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x12, 0x1e, 0x0a, 0x1a, 0x50, 0x41, 0x59,
-	0x4d, 0x45, 0x4e, 0x54, 0x5f, 0x4d, 0x45, 0x54, 0x48, 0x4f, 0x44, 0x5f, 0x55, 0x4e, 0x53, 0x50,
-	0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x1e, 0x0a, 0x1a, 0x50, 0x41, 0x59,
-	0x4d, 0x45, 0x4e, 0x54, 0x5f, 0x4d, 0x45, 0x54, 0x48, 0x4f, 0x44, 0x5f, 0x48, 0x4f, 0x53, 0x54,
-	0x45, 0x44, 0x5f, 0x43, 0x41, 0x52, 0x44, 0x10, 0x01, 0x12, 0x1f, 0x0a, 0x1b, 0x50, 0x41, 0x59,
-	0x4d, 0x45, 0x4e, 0x54, 0x5f, 0x4d, 0x45, 0x54, 0x48, 0x4f, 0x44, 0x5f, 0x50, 0x41, 0x59, 0x4d,
-	0x45, 0x4e, 0x54, 0x5f, 0x4c, 0x49, 0x4e, 0x4b, 0x10, 0x02, 0x2a, 0x62, 0x0a, 0x0b, 0x50, 0x61,
-	0x79, 0x6d, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x12, 0x1c, 0x0a, 0x18, 0x50, 0x41, 0x59,
-	0x4d, 0x45, 0x4e, 0x54, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43,
-	0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x19, 0x0a, 0x15, 0x50, 0x41, 0x59, 0x4d, 0x45,
-	0x4e, 0x54, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x4f, 0x4e, 0x45, 0x5f, 0x54, 0x49, 0x4d, 0x45,
-	0x10, 0x01, 0x12, 0x1a, 0x0a, 0x16, 0x50, 0x41, 0x59, 0x4d, 0x45, 0x4e, 0x54, 0x5f, 0x54, 0x59,
-	0x50, 0x45, 0x5f, 0x52, 0x45, 0x43, 0x55, 0x52, 0x52, 0x49, 0x4e, 0x47, 0x10, 0x02, 0x2a, 0x47,
-	0x0a, 0x0c, 0x50, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x72, 0x54, 0x79, 0x70, 0x65, 0x12, 0x1d,
-	0x0a, 0x19, 0x50, 0x52, 0x4f, 0x56, 0x49, 0x44, 0x45, 0x52, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f,
-	0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x18, 0x0a,
-	0x14, 0x50, 0x52, 0x4f, 0x56, 0x49, 0x44, 0x45, 0x52, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x53,
-	0x54, 0x52, 0x49, 0x50, 0x45, 0x10, 0x01, 0x32, 0xf1, 0x03, 0x0a, 0x0f, 0x50, 0x61, 0x79, 0x6d,
-	0x65, 0x6e, 0x74, 0x73, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x3b, 0x0a, 0x06, 0x48,
-	0x65, 0x61, 0x6c, 0x74, 0x68, 0x12, 0x17, 0x2e, 0x70, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x73,
-	0x2e, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18,
-	0x2e, 0x70, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x2e, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68,
-	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x52, 0x0a, 0x0d, 0x43, 0x72, 0x65, 0x61,
-	0x74, 0x65, 0x50, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x1e, 0x2e, 0x70, 0x61, 0x79, 0x6d,
-	0x65, 0x6e, 0x74, 0x73, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x50, 0x61, 0x79, 0x6d, 0x65,
-	0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x21, 0x2e, 0x70, 0x61, 0x79, 0x6d,
-	0x65, 0x6e, 0x74, 0x73, 0x2e, 0x50, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x45, 0x6e, 0x76, 0x65,
-	0x6c, 0x6f, 0x70, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4c, 0x0a, 0x0a,
-	0x47, 0x65, 0x74, 0x50, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x1b, 0x2e, 0x70, 0x61, 0x79,
-	0x6d, 0x65, 0x6e, 0x74, 0x73, 0x2e, 0x47, 0x65, 0x74, 0x50, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74,
-	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x21, 0x2e, 0x70, 0x61, 0x79, 0x6d, 0x65, 0x6e,
-	0x74, 0x73, 0x2e, 0x50, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x45, 0x6e, 0x76, 0x65, 0x6c, 0x6f,
-	0x70, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4d, 0x0a, 0x0c, 0x4c, 0x69,
-	0x73, 0x74, 0x50, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x1d, 0x2e, 0x70, 0x61, 0x79,
-	0x6d, 0x65, 0x6e, 0x74, 0x73, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x50, 0x61, 0x79, 0x6d, 0x65, 0x6e,
-	0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x70, 0x61, 0x79, 0x6d,
-	0x65, 0x6e, 0x74, 0x73, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x50, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74,
-	0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x52, 0x0a, 0x0d, 0x43, 0x61, 0x6e,
-	0x63, 0x65, 0x6c, 0x50, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x1e, 0x2e, 0x70, 0x61, 0x79,
-	0x6d, 0x65, 0x6e, 0x74, 0x73, 0x2e, 0x43, 0x61, 0x6e, 0x63, 0x65, 0x6c, 0x50, 0x61, 0x79, 0x6d,
-	0x65, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x21, 0x2e, 0x70, 0x61, 0x79,
-	0x6d, 0x65, 0x6e, 0x74, 0x73, 0x2e, 0x50, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x45, 0x6e, 0x76,
-	0x65, 0x6c, 0x6f, 0x70, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x5c, 0x0a,
-	0x16, 0x48, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x50, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x72, 0x43,
-	0x61, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x12, 0x27, 0x2e, 0x70, 0x61, 0x79, 0x6d, 0x65, 0x6e,
-	0x74, 0x73, 0x2e, 0x48, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x50, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65,
-	0x72, 0x43, 0x61, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x1a, 0x19, 0x2e, 0x70, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x2e, 0x4d, 0x65, 0x73, 0x73,
-	0x61, 0x67, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x3c, 0x5a, 0x3a, 0x67,
-	0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x76, 0x69, 0x62, 0x61, 0x73, 0x74,
-	0x2d, 0x73, 0x6f, 0x6c, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2f, 0x6d, 0x73, 0x2d, 0x67, 0x6f,
-	0x2d, 0x70, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x2f, 0x61, 0x70, 0x70, 0x2f, 0x74, 0x79,
-	0x70, 0x65, 0x73, 0x3b, 0x74, 0x79, 0x70, 0x65, 0x73, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x33,
-})
+const file_payments_proto_rawDesc = "" +
+	"\n" +
+	"\x0epayments.proto\x12\bpayments\"\x0f\n" +
+	"\rHealthRequest\"(\n" +
+	"\x0eHealthResponse\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\"\xe2\t\n" +
+	"\aPayment\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x02 \x01(\tR\trequestId\x12%\n" +
+	"\x0ecaller_service\x18\x03 \x01(\tR\rcallerService\x12#\n" +
+	"\rresource_type\x18\x04 \x01(\tR\fresourceType\x12\x1f\n" +
+	"\vresource_id\x18\x05 \x01(\tR\n" +
+	"resourceId\x12!\n" +
+	"\fcustomer_ref\x18\x06 \x01(\tR\vcustomerRef\x12!\n" +
+	"\famount_cents\x18\a \x01(\x03R\vamountCents\x12\x1a\n" +
+	"\bcurrency\x18\b \x01(\tR\bcurrency\x12/\n" +
+	"\x06status\x18\t \x01(\x0e2\x17.payments.PaymentStatusR\x06status\x12>\n" +
+	"\x0epayment_method\x18\n" +
+	" \x01(\x0e2\x17.payments.PaymentMethodR\rpaymentMethod\x128\n" +
+	"\fpayment_type\x18\v \x01(\x0e2\x15.payments.PaymentTypeR\vpaymentType\x122\n" +
+	"\bprovider\x18\f \x01(\x0e2\x16.payments.ProviderTypeR\bprovider\x12-\n" +
+	"\x12recurring_interval\x18\r \x01(\tR\x11recurringInterval\x128\n" +
+	"\x18recurring_interval_count\x18\x0e \x01(\x05R\x16recurringIntervalCount\x12.\n" +
+	"\x13provider_payment_id\x18\x0f \x01(\tR\x11providerPaymentId\x128\n" +
+	"\x18provider_subscription_id\x18\x10 \x01(\tR\x16providerSubscriptionId\x12!\n" +
+	"\fcheckout_url\x18\x11 \x01(\tR\vcheckoutUrl\x124\n" +
+	"\x16provider_callback_hash\x18\x12 \x01(\tR\x14providerCallbackHash\x122\n" +
+	"\x15provider_callback_url\x18\x13 \x01(\tR\x13providerCallbackUrl\x12.\n" +
+	"\x13status_callback_url\x18\x14 \x01(\tR\x11statusCallbackUrl\x12%\n" +
+	"\x0erefunded_cents\x18\x15 \x01(\x03R\rrefundedCents\x12)\n" +
+	"\x10refundable_cents\x18\x16 \x01(\x03R\x0frefundableCents\x12;\n" +
+	"\bmetadata\x18\x17 \x03(\v2\x1f.payments.Payment.MetadataEntryR\bmetadata\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x18 \x01(\tR\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\x19 \x01(\tR\tupdatedAt\x12/\n" +
+	"\x13subscription_paused\x18\x1a \x01(\bR\x12subscriptionPaused\x12/\n" +
+	"\x14cancel_at_period_end\x18\x1b \x01(\bR\x11cancelAtPeriodEnd\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x92\x06\n" +
+	"\x14CreatePaymentRequest\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x01 \x01(\tR\trequestId\x12%\n" +
+	"\x0ecaller_service\x18\x02 \x01(\tR\rcallerService\x12#\n" +
+	"\rresource_type\x18\x03 \x01(\tR\fresourceType\x12\x1f\n" +
+	"\vresource_id\x18\x04 \x01(\tR\n" +
+	"resourceId\x12!\n" +
+	"\fcustomer_ref\x18\x05 \x01(\tR\vcustomerRef\x12!\n" +
+	"\famount_cents\x18\x06 \x01(\x03R\vamountCents\x12\x1a\n" +
+	"\bcurrency\x18\a \x01(\tR\bcurrency\x12>\n" +
+	"\x0epayment_method\x18\b \x01(\x0e2\x17.payments.PaymentMethodR\rpaymentMethod\x128\n" +
+	"\fpayment_type\x18\t \x01(\x0e2\x15.payments.PaymentTypeR\vpaymentType\x122\n" +
+	"\bprovider\x18\n" +
+	" \x01(\x0e2\x16.payments.ProviderTypeR\bprovider\x12-\n" +
+	"\x12recurring_interval\x18\v \x01(\tR\x11recurringInterval\x128\n" +
+	"\x18recurring_interval_count\x18\f \x01(\x05R\x16recurringIntervalCount\x12.\n" +
+	"\x13status_callback_url\x18\r \x01(\tR\x11statusCallbackUrl\x12\x1f\n" +
+	"\vsuccess_url\x18\x0e \x01(\tR\n" +
+	"successUrl\x12\x1d\n" +
+	"\n" +
+	"cancel_url\x18\x0f \x01(\tR\tcancelUrl\x12H\n" +
+	"\bmetadata\x18\x10 \x03(\v2,.payments.CreatePaymentRequest.MetadataEntryR\bmetadata\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"#\n" +
+	"\x11GetPaymentRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\"d\n" +
+	"\x1cGetPaymentByRequestIDRequest\x12%\n" +
+	"\x0ecaller_service\x18\x01 \x01(\tR\rcallerService\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x02 \x01(\tR\trequestId\"\xbe\x01\n" +
+	"\x1eGetPaymentByProviderRefRequest\x122\n" +
+	"\bprovider\x18\x01 \x01(\x0e2\x16.payments.ProviderTypeR\bprovider\x12.\n" +
+	"\x13provider_payment_id\x18\x02 \x01(\tR\x11providerPaymentId\x128\n" +
+	"\x18provider_subscription_id\x18\x03 \x01(\tR\x16providerSubscriptionId\"\xae\a\n" +
+	"\x13ListPaymentsRequest\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x01 \x01(\tR\trequestId\x12%\n" +
+	"\x0ecaller_service\x18\x02 \x01(\tR\rcallerService\x12#\n" +
+	"\rresource_type\x18\x03 \x01(\tR\fresourceType\x12\x1f\n" +
+	"\vresource_id\x18\x04 \x01(\tR\n" +
+	"resourceId\x12\x1d\n" +
+	"\n" +
+	"has_status\x18\x05 \x01(\bR\thasStatus\x12/\n" +
+	"\x06status\x18\x06 \x01(\x0e2\x17.payments.PaymentStatusR\x06status\x122\n" +
+	"\bprovider\x18\a \x01(\x0e2\x16.payments.ProviderTypeR\bprovider\x12\x14\n" +
+	"\x05limit\x18\b \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06offset\x18\t \x01(\x05R\x06offset\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\n" +
+	" \x01(\tR\tpageToken\x12!\n" +
+	"\fcreated_from\x18\v \x01(\tR\vcreatedFrom\x12\x1d\n" +
+	"\n" +
+	"created_to\x18\f \x01(\tR\tcreatedTo\x12!\n" +
+	"\fupdated_from\x18\r \x01(\tR\vupdatedFrom\x12\x1d\n" +
+	"\n" +
+	"updated_to\x18\x0e \x01(\tR\tupdatedTo\x12(\n" +
+	"\x10min_amount_cents\x18\x0f \x01(\x03R\x0eminAmountCents\x12(\n" +
+	"\x10max_amount_cents\x18\x10 \x01(\x03R\x0emaxAmountCents\x12\x1a\n" +
+	"\bcurrency\x18\x11 \x01(\tR\bcurrency\x12!\n" +
+	"\fcustomer_ref\x18\x12 \x01(\tR\vcustomerRef\x123\n" +
+	"\bstatuses\x18\x13 \x03(\x0e2\x17.payments.PaymentStatusR\bstatuses\x12.\n" +
+	"\x13provider_payment_id\x18\x14 \x01(\tR\x11providerPaymentId\x128\n" +
+	"\x18provider_subscription_id\x18\x15 \x01(\tR\x16providerSubscriptionId\x12G\n" +
+	"\bmetadata\x18\x16 \x03(\v2+.payments.ListPaymentsRequest.MetadataEntryR\bmetadata\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\">\n" +
+	"\x14CancelPaymentRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\"\xc9\x02\n" +
+	"\x06Refund\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x1d\n" +
+	"\n" +
+	"payment_id\x18\x02 \x01(\x04R\tpaymentId\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x03 \x01(\tR\trequestId\x12!\n" +
+	"\famount_cents\x18\x04 \x01(\x03R\vamountCents\x12\x1a\n" +
+	"\bcurrency\x18\x05 \x01(\tR\bcurrency\x12.\n" +
+	"\x06status\x18\x06 \x01(\x0e2\x16.payments.RefundStatusR\x06status\x12\x16\n" +
+	"\x06reason\x18\a \x01(\tR\x06reason\x12,\n" +
+	"\x12provider_refund_id\x18\b \x01(\tR\x10providerRefundId\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\t \x01(\tR\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\n" +
+	" \x01(\tR\tupdatedAt\"\x80\x01\n" +
+	"\x14RefundPaymentRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x02 \x01(\tR\trequestId\x12!\n" +
+	"\famount_cents\x18\x03 \x01(\x03R\vamountCents\x12\x16\n" +
+	"\x06reason\x18\x04 \x01(\tR\x06reason\"n\n" +
+	"\x15RefundPaymentResponse\x12+\n" +
+	"\apayment\x18\x01 \x01(\v2\x11.payments.PaymentR\apayment\x12(\n" +
+	"\x06refund\x18\x02 \x01(\v2\x10.payments.RefundR\x06refund\"\xd7\x02\n" +
+	"\x06Charge\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x1d\n" +
+	"\n" +
+	"payment_id\x18\x02 \x01(\x04R\tpaymentId\x12.\n" +
+	"\x13provider_invoice_id\x18\x03 \x01(\tR\x11providerInvoiceId\x12!\n" +
+	"\famount_cents\x18\x04 \x01(\x03R\vamountCents\x12\x1a\n" +
+	"\bcurrency\x18\x05 \x01(\tR\bcurrency\x12/\n" +
+	"\x06status\x18\x06 \x01(\x0e2\x17.payments.PaymentStatusR\x06status\x12!\n" +
+	"\fperiod_start\x18\a \x01(\tR\vperiodStart\x12\x1d\n" +
+	"\n" +
+	"period_end\x18\b \x01(\tR\tperiodEnd\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\t \x01(\tR\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\n" +
+	" \x01(\tR\tupdatedAt\"+\n" +
+	"\x19ListPaymentChargesRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\"H\n" +
+	"\x1aListPaymentChargesResponse\x12*\n" +
+	"\acharges\x18\x01 \x03(\v2\x10.payments.ChargeR\acharges\"\xba\x02\n" +
+	"\fPaymentEvent\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x1d\n" +
+	"\n" +
+	"payment_id\x18\x02 \x01(\x04R\tpaymentId\x12\x1d\n" +
+	"\n" +
+	"event_type\x18\x03 \x01(\tR\teventType\x126\n" +
+	"\n" +
+	"old_status\x18\x04 \x01(\x0e2\x17.payments.PaymentStatusR\toldStatus\x126\n" +
+	"\n" +
+	"new_status\x18\x05 \x01(\x0e2\x17.payments.PaymentStatusR\tnewStatus\x12*\n" +
+	"\x11provider_event_id\x18\x06 \x01(\tR\x0fproviderEventId\x12!\n" +
+	"\fpayload_json\x18\a \x01(\tR\vpayloadJson\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\b \x01(\tR\tcreatedAt\"\xa0\x01\n" +
+	"\x18ListPaymentEventsRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x1d\n" +
+	"\n" +
+	"event_type\x18\x02 \x01(\tR\teventType\x12\x16\n" +
+	"\x06cursor\x18\x03 \x01(\x04R\x06cursor\x12\x14\n" +
+	"\x05limit\x18\x04 \x01(\x05R\x05limit\x12'\n" +
+	"\x0finclude_payload\x18\x05 \x01(\bR\x0eincludePayload\"l\n" +
+	"\x19ListPaymentEventsResponse\x12.\n" +
+	"\x06events\x18\x01 \x03(\v2\x16.payments.PaymentEventR\x06events\x12\x1f\n" +
+	"\vnext_cursor\x18\x02 \x01(\x04R\n" +
+	"nextCursor\"%\n" +
+	"\x13WatchPaymentRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\"^\n" +
+	"\x14WatchPaymentResponse\x12+\n" +
+	"\apayment\x18\x01 \x01(\v2\x11.payments.PaymentR\apayment\x12\x19\n" +
+	"\bevent_id\x18\x02 \x01(\x04R\aeventId\"o\n" +
+	"\x16ChargeEnvelopeResponse\x12+\n" +
+	"\apayment\x18\x01 \x01(\v2\x11.payments.PaymentR\apayment\x12(\n" +
+	"\x06charge\x18\x02 \x01(\v2\x10.payments.ChargeR\x06charge\"B\n" +
+	"\x18PauseSubscriptionRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\"+\n" +
+	"\x19ResumeSubscriptionRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\"g\n" +
+	"\x19CancelSubscriptionRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\"\n" +
+	"\rat_period_end\x18\x02 \x01(\bR\vatPeriodEnd\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\"\xb7\x01\n" +
+	"\x19UpdateSubscriptionRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12!\n" +
+	"\famount_cents\x18\x02 \x01(\x03R\vamountCents\x12-\n" +
+	"\x12recurring_interval\x18\x03 \x01(\tR\x11recurringInterval\x128\n" +
+	"\x18recurring_interval_count\x18\x04 \x01(\x05R\x16recurringIntervalCount\"\xb7\x01\n" +
+	"\x1dHandleProviderCallbackRequest\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x01 \x01(\tR\trequestId\x12\x1a\n" +
+	"\bprovider\x18\x02 \x01(\tR\bprovider\x12#\n" +
+	"\rcallback_hash\x18\x03 \x01(\tR\fcallbackHash\x12\x1c\n" +
+	"\tsignature\x18\x04 \x01(\tR\tsignature\x12\x18\n" +
+	"\apayload\x18\x05 \x01(\tR\apayload\"\xd6\x02\n" +
+	"\x10ProviderCallback\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x1d\n" +
+	"\n" +
+	"payment_id\x18\x02 \x01(\x04R\tpaymentId\x12\x1a\n" +
+	"\bprovider\x18\x03 \x01(\tR\bprovider\x12#\n" +
+	"\rcallback_hash\x18\x04 \x01(\tR\fcallbackHash\x12*\n" +
+	"\x11provider_event_id\x18\x05 \x01(\tR\x0fproviderEventId\x128\n" +
+	"\x06status\x18\x06 \x01(\x0e2 .payments.ProviderCallbackStatusR\x06status\x12\x14\n" +
+	"\x05error\x18\a \x01(\tR\x05error\x12\x18\n" +
+	"\apayload\x18\b \x01(\tR\apayload\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\t \x01(\tR\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\n" +
+	" \x01(\tR\tupdatedAt\"\xd1\x02\n" +
+	"\x1cListProviderCallbacksRequest\x12\x1a\n" +
+	"\bprovider\x18\x01 \x01(\tR\bprovider\x12#\n" +
+	"\rcallback_hash\x18\x02 \x01(\tR\fcallbackHash\x12\x1d\n" +
+	"\n" +
+	"has_status\x18\x03 \x01(\bR\thasStatus\x128\n" +
+	"\x06status\x18\x04 \x01(\x0e2 .payments.ProviderCallbackStatusR\x06status\x12!\n" +
+	"\fcreated_from\x18\x05 \x01(\tR\vcreatedFrom\x12\x1d\n" +
+	"\n" +
+	"created_to\x18\x06 \x01(\tR\tcreatedTo\x12\x14\n" +
+	"\x05limit\x18\a \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06offset\x18\b \x01(\x05R\x06offset\x12'\n" +
+	"\x0finclude_payload\x18\t \x01(\bR\x0eincludePayload\"Y\n" +
+	"\x1dListProviderCallbacksResponse\x128\n" +
+	"\tcallbacks\x18\x01 \x03(\v2\x1a.payments.ProviderCallbackR\tcallbacks\"V\n" +
+	"\x1dReplayProviderCallbackRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12%\n" +
+	"\x0eskip_signature\x18\x02 \x01(\bR\rskipSignature\"F\n" +
+	"\x17PaymentEnvelopeResponse\x12+\n" +
+	"\apayment\x18\x01 \x01(\v2\x11.payments.PaymentR\apayment\"m\n" +
+	"\x14ListPaymentsResponse\x12-\n" +
+	"\bpayments\x18\x01 \x03(\v2\x11.payments.PaymentR\bpayments\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"\x8f\x02\n" +
+	"\x16PaymentCallbackRequest\x12\x1f\n" +
+	"\vcallback_id\x18\x01 \x01(\x04R\n" +
+	"callbackId\x12\x1d\n" +
+	"\n" +
+	"event_type\x18\x02 \x01(\tR\teventType\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x03 \x01(\tR\trequestId\x12+\n" +
+	"\apayment\x18\x04 \x01(\v2\x11.payments.PaymentR\apayment\x12(\n" +
+	"\x06charge\x18\x05 \x01(\v2\x10.payments.ChargeR\x06charge\x12!\n" +
+	"\fpayload_json\x18\x06 \x01(\tR\vpayloadJson\x12\x1c\n" +
+	"\tsignature\x18\a \x01(\tR\tsignature\"\xde\x02\n" +
+	"\x10CallbackDelivery\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x1d\n" +
+	"\n" +
+	"payment_id\x18\x02 \x01(\x04R\tpaymentId\x12\x1b\n" +
+	"\tcharge_id\x18\x03 \x01(\x04R\bchargeId\x12%\n" +
+	"\x0ecaller_service\x18\x04 \x01(\tR\rcallerService\x12\x1d\n" +
+	"\n" +
+	"event_type\x18\x05 \x01(\tR\teventType\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x06 \x01(\tR\trequestId\x12 \n" +
+	"\vdestination\x18\a \x01(\tR\vdestination\x12\x1a\n" +
+	"\battempts\x18\b \x01(\x05R\battempts\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\t \x01(\tR\tlastError\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\n" +
+	" \x01(\tR\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\v \x01(\tR\tupdatedAt\"\xb3\x01\n" +
+	"\x1eListDeadLetterCallbacksRequest\x12\x1d\n" +
+	"\n" +
+	"payment_id\x18\x01 \x01(\x04R\tpaymentId\x12%\n" +
+	"\x0ecaller_service\x18\x02 \x01(\tR\rcallerService\x12\x1d\n" +
+	"\n" +
+	"event_type\x18\x03 \x01(\tR\teventType\x12\x14\n" +
+	"\x05limit\x18\x04 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06offset\x18\x05 \x01(\x05R\x06offset\"[\n" +
+	"\x1fListDeadLetterCallbacksResponse\x128\n" +
+	"\tcallbacks\x18\x01 \x03(\v2\x1a.payments.CallbackDeliveryR\tcallbacks\"\x92\x01\n" +
+	"\x19RedeliverCallbacksRequest\x12\x1d\n" +
+	"\n" +
+	"payment_id\x18\x01 \x01(\x04R\tpaymentId\x12%\n" +
+	"\x0ecaller_service\x18\x02 \x01(\tR\rcallerService\x12\x1d\n" +
+	"\n" +
+	"event_type\x18\x03 \x01(\tR\teventType\x12\x10\n" +
+	"\x03all\x18\x04 \x01(\bR\x03all\">\n" +
+	"\x1aRedeliverCallbacksResponse\x12 \n" +
+	"\vredelivered\x18\x01 \x01(\x03R\vredelivered\"X\n" +
+	"\x0fMessageResponse\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\x12+\n" +
+	"\apayment\x18\x02 \x01(\v2\x11.payments.PaymentR\apayment\"%\n" +
+	"\rErrorResponse\x12\x14\n" +
+	"\x05error\x18\x01 \x01(\tR\x05error*\xf3\x01\n" +
+	"\rPaymentStatus\x12\x1e\n" +
+	"\x1aPAYMENT_STATUS_UNSPECIFIED\x10\x00\x12\x1a\n" +
+	"\x16PAYMENT_STATUS_CREATED\x10\x01\x12\x1a\n" +
+	"\x16PAYMENT_STATUS_PENDING\x10\x02\x12\x1d\n" +
+	"\x19PAYMENT_STATUS_PROCESSING\x10\x03\x12\x17\n" +
+	"\x13PAYMENT_STATUS_PAID\x10\n" +
+	"\x12\x19\n" +
+	"\x15PAYMENT_STATUS_FAILED\x10\x14\x12\x1b\n" +
+	"\x17PAYMENT_STATUS_CANCELED\x10\x1e\x12\x1a\n" +
+	"\x16PAYMENT_STATUS_EXPIRED\x10(*p\n" +
+	"\rPaymentMethod\x12\x1e\n" +
+	"\x1aPAYMENT_METHOD_UNSPECIFIED\x10\x00\x12\x1e\n" +
+	"\x1aPAYMENT_METHOD_HOSTED_CARD\x10\x01\x12\x1f\n" +
+	"\x1bPAYMENT_METHOD_PAYMENT_LINK\x10\x02*b\n" +
+	"\vPaymentType\x12\x1c\n" +
+	"\x18PAYMENT_TYPE_UNSPECIFIED\x10\x00\x12\x19\n" +
+	"\x15PAYMENT_TYPE_ONE_TIME\x10\x01\x12\x1a\n" +
+	"\x16PAYMENT_TYPE_RECURRING\x10\x02*G\n" +
+	"\fProviderType\x12\x1d\n" +
+	"\x19PROVIDER_TYPE_UNSPECIFIED\x10\x00\x12\x18\n" +
+	"\x14PROVIDER_TYPE_STRIPE\x10\x01*\x9b\x01\n" +
+	"\fRefundStatus\x12\x1d\n" +
+	"\x19REFUND_STATUS_UNSPECIFIED\x10\x00\x12\x19\n" +
+	"\x15REFUND_STATUS_PENDING\x10\x01\x12\x1b\n" +
+	"\x17REFUND_STATUS_SUCCEEDED\x10\n" +
+	"\x12\x18\n" +
+	"\x14REFUND_STATUS_FAILED\x10\x14\x12\x1a\n" +
+	"\x16REFUND_STATUS_CANCELED\x10\x1e*\xb9\x01\n" +
+	"\x16ProviderCallbackStatus\x12(\n" +
+	"$PROVIDER_CALLBACK_STATUS_UNSPECIFIED\x10\x00\x12&\n" +
+	"\"PROVIDER_CALLBACK_STATUS_PROCESSED\x10\n" +
+	"\x12%\n" +
+	"!PROVIDER_CALLBACK_STATUS_REJECTED\x10\x14\x12&\n" +
+	"\"PROVIDER_CALLBACK_STATUS_DUPLICATE\x10\x1e2\xb6\x0e\n" +
+	"\x0fPaymentsService\x12;\n" +
+	"\x06Health\x12\x17.payments.HealthRequest\x1a\x18.payments.HealthResponse\x12R\n" +
+	"\rCreatePayment\x12\x1e.payments.CreatePaymentRequest\x1a!.payments.PaymentEnvelopeResponse\x12L\n" +
+	"\n" +
+	"GetPayment\x12\x1b.payments.GetPaymentRequest\x1a!.payments.PaymentEnvelopeResponse\x12b\n" +
+	"\x15GetPaymentByRequestID\x12&.payments.GetPaymentByRequestIDRequest\x1a!.payments.PaymentEnvelopeResponse\x12f\n" +
+	"\x17GetPaymentByProviderRef\x12(.payments.GetPaymentByProviderRefRequest\x1a!.payments.PaymentEnvelopeResponse\x12M\n" +
+	"\fListPayments\x12\x1d.payments.ListPaymentsRequest\x1a\x1e.payments.ListPaymentsResponse\x12R\n" +
+	"\rCancelPayment\x12\x1e.payments.CancelPaymentRequest\x1a!.payments.PaymentEnvelopeResponse\x12P\n" +
+	"\rRefundPayment\x12\x1e.payments.RefundPaymentRequest\x1a\x1f.payments.RefundPaymentResponse\x12_\n" +
+	"\x12ListPaymentCharges\x12#.payments.ListPaymentChargesRequest\x1a$.payments.ListPaymentChargesResponse\x12\\\n" +
+	"\x11ListPaymentEvents\x12\".payments.ListPaymentEventsRequest\x1a#.payments.ListPaymentEventsResponse\x12O\n" +
+	"\fWatchPayment\x12\x1d.payments.WatchPaymentRequest\x1a\x1e.payments.WatchPaymentResponse0\x01\x12Z\n" +
+	"\x11PauseSubscription\x12\".payments.PauseSubscriptionRequest\x1a!.payments.PaymentEnvelopeResponse\x12\\\n" +
+	"\x12ResumeSubscription\x12#.payments.ResumeSubscriptionRequest\x1a!.payments.PaymentEnvelopeResponse\x12\\\n" +
+	"\x12CancelSubscription\x12#.payments.CancelSubscriptionRequest\x1a!.payments.PaymentEnvelopeResponse\x12\\\n" +
+	"\x12UpdateSubscription\x12#.payments.UpdateSubscriptionRequest\x1a!.payments.PaymentEnvelopeResponse\x12\\\n" +
+	"\x16HandleProviderCallback\x12'.payments.HandleProviderCallbackRequest\x1a\x19.payments.MessageResponse\x12n\n" +
+	"\x17ListDeadLetterCallbacks\x12(.payments.ListDeadLetterCallbacksRequest\x1a).payments.ListDeadLetterCallbacksResponse\x12_\n" +
+	"\x12RedeliverCallbacks\x12#.payments.RedeliverCallbacksRequest\x1a$.payments.RedeliverCallbacksResponse\x12h\n" +
+	"\x15ListProviderCallbacks\x12&.payments.ListProviderCallbacksRequest\x1a'.payments.ListProviderCallbacksResponse\x12d\n" +
+	"\x16ReplayProviderCallback\x12'.payments.ReplayProviderCallbackRequest\x1a!.payments.PaymentEnvelopeResponse2p\n" +
+	"\x17PaymentCallbackReceiver\x12U\n" +
+	"\x16DeliverPaymentCallback\x12 .payments.PaymentCallbackRequest\x1a\x19.payments.MessageResponseB<Z:github.com/vibast-solutions/ms-go-payments/app/types;typesb\x06proto3"
 
 var (
 	file_payments_proto_rawDescOnce sync.Once
@@ -1443,60 +3723,141 @@ func file_payments_proto_rawDescGZIP() []byte {
 	return file_payments_proto_rawDescData
 }
 
-var file_payments_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
-var file_payments_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_payments_proto_enumTypes = make([]protoimpl.EnumInfo, 6)
+var file_payments_proto_msgTypes = make([]protoimpl.MessageInfo, 43)
 var file_payments_proto_goTypes = []any{
-	(PaymentStatus)(0),                    // 0: payments.PaymentStatus
-	(PaymentMethod)(0),                    // 1: payments.PaymentMethod
-	(PaymentType)(0),                      // 2: payments.PaymentType
-	(ProviderType)(0),                     // 3: payments.ProviderType
-	(*HealthRequest)(nil),                 // 4: payments.HealthRequest
-	(*HealthResponse)(nil),                // 5: payments.HealthResponse
-	(*Payment)(nil),                       // 6: payments.Payment
-	(*CreatePaymentRequest)(nil),          // 7: payments.CreatePaymentRequest
-	(*GetPaymentRequest)(nil),             // 8: payments.GetPaymentRequest
-	(*ListPaymentsRequest)(nil),           // 9: payments.ListPaymentsRequest
-	(*CancelPaymentRequest)(nil),          // 10: payments.CancelPaymentRequest
-	(*HandleProviderCallbackRequest)(nil), // 11: payments.HandleProviderCallbackRequest
-	(*PaymentEnvelopeResponse)(nil),       // 12: payments.PaymentEnvelopeResponse
-	(*ListPaymentsResponse)(nil),          // 13: payments.ListPaymentsResponse
-	(*MessageResponse)(nil),               // 14: payments.MessageResponse
-	(*ErrorResponse)(nil),                 // 15: payments.ErrorResponse
-	nil,                                   // 16: payments.Payment.MetadataEntry
-	nil,                                   // 17: payments.CreatePaymentRequest.MetadataEntry
+	(PaymentStatus)(0),                      // 0: payments.PaymentStatus
+	(PaymentMethod)(0),                      // 1: payments.PaymentMethod
+	(PaymentType)(0),                        // 2: payments.PaymentType
+	(ProviderType)(0),                       // 3: payments.ProviderType
+	(RefundStatus)(0),                       // 4: payments.RefundStatus
+	(ProviderCallbackStatus)(0),             // 5: payments.ProviderCallbackStatus
+	(*HealthRequest)(nil),                   // 6: payments.HealthRequest
+	(*HealthResponse)(nil),                  // 7: payments.HealthResponse
+	(*Payment)(nil),                         // 8: payments.Payment
+	(*CreatePaymentRequest)(nil),            // 9: payments.CreatePaymentRequest
+	(*GetPaymentRequest)(nil),               // 10: payments.GetPaymentRequest
+	(*GetPaymentByRequestIDRequest)(nil),    // 11: payments.GetPaymentByRequestIDRequest
+	(*GetPaymentByProviderRefRequest)(nil),  // 12: payments.GetPaymentByProviderRefRequest
+	(*ListPaymentsRequest)(nil),             // 13: payments.ListPaymentsRequest
+	(*CancelPaymentRequest)(nil),            // 14: payments.CancelPaymentRequest
+	(*Refund)(nil),                          // 15: payments.Refund
+	(*RefundPaymentRequest)(nil),            // 16: payments.RefundPaymentRequest
+	(*RefundPaymentResponse)(nil),           // 17: payments.RefundPaymentResponse
+	(*Charge)(nil),                          // 18: payments.Charge
+	(*ListPaymentChargesRequest)(nil),       // 19: payments.ListPaymentChargesRequest
+	(*ListPaymentChargesResponse)(nil),      // 20: payments.ListPaymentChargesResponse
+	(*PaymentEvent)(nil),                    // 21: payments.PaymentEvent
+	(*ListPaymentEventsRequest)(nil),        // 22: payments.ListPaymentEventsRequest
+	(*ListPaymentEventsResponse)(nil),       // 23: payments.ListPaymentEventsResponse
+	(*WatchPaymentRequest)(nil),             // 24: payments.WatchPaymentRequest
+	(*WatchPaymentResponse)(nil),            // 25: payments.WatchPaymentResponse
+	(*ChargeEnvelopeResponse)(nil),          // 26: payments.ChargeEnvelopeResponse
+	(*PauseSubscriptionRequest)(nil),        // 27: payments.PauseSubscriptionRequest
+	(*ResumeSubscriptionRequest)(nil),       // 28: payments.ResumeSubscriptionRequest
+	(*CancelSubscriptionRequest)(nil),       // 29: payments.CancelSubscriptionRequest
+	(*UpdateSubscriptionRequest)(nil),       // 30: payments.UpdateSubscriptionRequest
+	(*HandleProviderCallbackRequest)(nil),   // 31: payments.HandleProviderCallbackRequest
+	(*ProviderCallback)(nil),                // 32: payments.ProviderCallback
+	(*ListProviderCallbacksRequest)(nil),    // 33: payments.ListProviderCallbacksRequest
+	(*ListProviderCallbacksResponse)(nil),   // 34: payments.ListProviderCallbacksResponse
+	(*ReplayProviderCallbackRequest)(nil),   // 35: payments.ReplayProviderCallbackRequest
+	(*PaymentEnvelopeResponse)(nil),         // 36: payments.PaymentEnvelopeResponse
+	(*ListPaymentsResponse)(nil),            // 37: payments.ListPaymentsResponse
+	(*PaymentCallbackRequest)(nil),          // 38: payments.PaymentCallbackRequest
+	(*CallbackDelivery)(nil),                // 39: payments.CallbackDelivery
+	(*ListDeadLetterCallbacksRequest)(nil),  // 40: payments.ListDeadLetterCallbacksRequest
+	(*ListDeadLetterCallbacksResponse)(nil), // 41: payments.ListDeadLetterCallbacksResponse
+	(*RedeliverCallbacksRequest)(nil),       // 42: payments.RedeliverCallbacksRequest
+	(*RedeliverCallbacksResponse)(nil),      // 43: payments.RedeliverCallbacksResponse
+	(*MessageResponse)(nil),                 // 44: payments.MessageResponse
+	(*ErrorResponse)(nil),                   // 45: payments.ErrorResponse
+	nil,                                     // 46: payments.Payment.MetadataEntry
+	nil,                                     // 47: payments.CreatePaymentRequest.MetadataEntry
+	nil,                                     // 48: payments.ListPaymentsRequest.MetadataEntry
 }
 var file_payments_proto_depIdxs = []int32{
 	0,  // 0: payments.Payment.status:type_name -> payments.PaymentStatus
 	1,  // 1: payments.Payment.payment_method:type_name -> payments.PaymentMethod
 	2,  // 2: payments.Payment.payment_type:type_name -> payments.PaymentType
 	3,  // 3: payments.Payment.provider:type_name -> payments.ProviderType
-	16, // 4: payments.Payment.metadata:type_name -> payments.Payment.MetadataEntry
+	46, // 4: payments.Payment.metadata:type_name -> payments.Payment.MetadataEntry
 	1,  // 5: payments.CreatePaymentRequest.payment_method:type_name -> payments.PaymentMethod
 	2,  // 6: payments.CreatePaymentRequest.payment_type:type_name -> payments.PaymentType
 	3,  // 7: payments.CreatePaymentRequest.provider:type_name -> payments.ProviderType
-	17, // 8: payments.CreatePaymentRequest.metadata:type_name -> payments.CreatePaymentRequest.MetadataEntry
-	0,  // 9: payments.ListPaymentsRequest.status:type_name -> payments.PaymentStatus
-	3,  // 10: payments.ListPaymentsRequest.provider:type_name -> payments.ProviderType
-	6,  // 11: payments.PaymentEnvelopeResponse.payment:type_name -> payments.Payment
-	6,  // 12: payments.ListPaymentsResponse.payments:type_name -> payments.Payment
-	6,  // 13: payments.MessageResponse.payment:type_name -> payments.Payment
-	4,  // 14: payments.PaymentsService.Health:input_type -> payments.HealthRequest
-	7,  // 15: payments.PaymentsService.CreatePayment:input_type -> payments.CreatePaymentRequest
-	8,  // 16: payments.PaymentsService.GetPayment:input_type -> payments.GetPaymentRequest
-	9,  // 17: payments.PaymentsService.ListPayments:input_type -> payments.ListPaymentsRequest
-	10, // 18: payments.PaymentsService.CancelPayment:input_type -> payments.CancelPaymentRequest
-	11, // 19: payments.PaymentsService.HandleProviderCallback:input_type -> payments.HandleProviderCallbackRequest
-	5,  // 20: payments.PaymentsService.Health:output_type -> payments.HealthResponse
-	12, // 21: payments.PaymentsService.CreatePayment:output_type -> payments.PaymentEnvelopeResponse
-	12, // 22: payments.PaymentsService.GetPayment:output_type -> payments.PaymentEnvelopeResponse
-	13, // 23: payments.PaymentsService.ListPayments:output_type -> payments.ListPaymentsResponse
-	12, // 24: payments.PaymentsService.CancelPayment:output_type -> payments.PaymentEnvelopeResponse
-	14, // 25: payments.PaymentsService.HandleProviderCallback:output_type -> payments.MessageResponse
-	20, // [20:26] is the sub-list for method output_type
-	14, // [14:20] is the sub-list for method input_type
-	14, // [14:14] is the sub-list for extension type_name
-	14, // [14:14] is the sub-list for extension extendee
-	0,  // [0:14] is the sub-list for field type_name
+	47, // 8: payments.CreatePaymentRequest.metadata:type_name -> payments.CreatePaymentRequest.MetadataEntry
+	3,  // 9: payments.GetPaymentByProviderRefRequest.provider:type_name -> payments.ProviderType
+	0,  // 10: payments.ListPaymentsRequest.status:type_name -> payments.PaymentStatus
+	3,  // 11: payments.ListPaymentsRequest.provider:type_name -> payments.ProviderType
+	0,  // 12: payments.ListPaymentsRequest.statuses:type_name -> payments.PaymentStatus
+	48, // 13: payments.ListPaymentsRequest.metadata:type_name -> payments.ListPaymentsRequest.MetadataEntry
+	4,  // 14: payments.Refund.status:type_name -> payments.RefundStatus
+	8,  // 15: payments.RefundPaymentResponse.payment:type_name -> payments.Payment
+	15, // 16: payments.RefundPaymentResponse.refund:type_name -> payments.Refund
+	0,  // 17: payments.Charge.status:type_name -> payments.PaymentStatus
+	18, // 18: payments.ListPaymentChargesResponse.charges:type_name -> payments.Charge
+	0,  // 19: payments.PaymentEvent.old_status:type_name -> payments.PaymentStatus
+	0,  // 20: payments.PaymentEvent.new_status:type_name -> payments.PaymentStatus
+	21, // 21: payments.ListPaymentEventsResponse.events:type_name -> payments.PaymentEvent
+	8,  // 22: payments.WatchPaymentResponse.payment:type_name -> payments.Payment
+	8,  // 23: payments.ChargeEnvelopeResponse.payment:type_name -> payments.Payment
+	18, // 24: payments.ChargeEnvelopeResponse.charge:type_name -> payments.Charge
+	5,  // 25: payments.ProviderCallback.status:type_name -> payments.ProviderCallbackStatus
+	5,  // 26: payments.ListProviderCallbacksRequest.status:type_name -> payments.ProviderCallbackStatus
+	32, // 27: payments.ListProviderCallbacksResponse.callbacks:type_name -> payments.ProviderCallback
+	8,  // 28: payments.PaymentEnvelopeResponse.payment:type_name -> payments.Payment
+	8,  // 29: payments.ListPaymentsResponse.payments:type_name -> payments.Payment
+	8,  // 30: payments.PaymentCallbackRequest.payment:type_name -> payments.Payment
+	18, // 31: payments.PaymentCallbackRequest.charge:type_name -> payments.Charge
+	39, // 32: payments.ListDeadLetterCallbacksResponse.callbacks:type_name -> payments.CallbackDelivery
+	8,  // 33: payments.MessageResponse.payment:type_name -> payments.Payment
+	6,  // 34: payments.PaymentsService.Health:input_type -> payments.HealthRequest
+	9,  // 35: payments.PaymentsService.CreatePayment:input_type -> payments.CreatePaymentRequest
+	10, // 36: payments.PaymentsService.GetPayment:input_type -> payments.GetPaymentRequest
+	11, // 37: payments.PaymentsService.GetPaymentByRequestID:input_type -> payments.GetPaymentByRequestIDRequest
+	12, // 38: payments.PaymentsService.GetPaymentByProviderRef:input_type -> payments.GetPaymentByProviderRefRequest
+	13, // 39: payments.PaymentsService.ListPayments:input_type -> payments.ListPaymentsRequest
+	14, // 40: payments.PaymentsService.CancelPayment:input_type -> payments.CancelPaymentRequest
+	16, // 41: payments.PaymentsService.RefundPayment:input_type -> payments.RefundPaymentRequest
+	19, // 42: payments.PaymentsService.ListPaymentCharges:input_type -> payments.ListPaymentChargesRequest
+	22, // 43: payments.PaymentsService.ListPaymentEvents:input_type -> payments.ListPaymentEventsRequest
+	24, // 44: payments.PaymentsService.WatchPayment:input_type -> payments.WatchPaymentRequest
+	27, // 45: payments.PaymentsService.PauseSubscription:input_type -> payments.PauseSubscriptionRequest
+	28, // 46: payments.PaymentsService.ResumeSubscription:input_type -> payments.ResumeSubscriptionRequest
+	29, // 47: payments.PaymentsService.CancelSubscription:input_type -> payments.CancelSubscriptionRequest
+	30, // 48: payments.PaymentsService.UpdateSubscription:input_type -> payments.UpdateSubscriptionRequest
+	31, // 49: payments.PaymentsService.HandleProviderCallback:input_type -> payments.HandleProviderCallbackRequest
+	40, // 50: payments.PaymentsService.ListDeadLetterCallbacks:input_type -> payments.ListDeadLetterCallbacksRequest
+	42, // 51: payments.PaymentsService.RedeliverCallbacks:input_type -> payments.RedeliverCallbacksRequest
+	33, // 52: payments.PaymentsService.ListProviderCallbacks:input_type -> payments.ListProviderCallbacksRequest
+	35, // 53: payments.PaymentsService.ReplayProviderCallback:input_type -> payments.ReplayProviderCallbackRequest
+	38, // 54: payments.PaymentCallbackReceiver.DeliverPaymentCallback:input_type -> payments.PaymentCallbackRequest
+	7,  // 55: payments.PaymentsService.Health:output_type -> payments.HealthResponse
+	36, // 56: payments.PaymentsService.CreatePayment:output_type -> payments.PaymentEnvelopeResponse
+	36, // 57: payments.PaymentsService.GetPayment:output_type -> payments.PaymentEnvelopeResponse
+	36, // 58: payments.PaymentsService.GetPaymentByRequestID:output_type -> payments.PaymentEnvelopeResponse
+	36, // 59: payments.PaymentsService.GetPaymentByProviderRef:output_type -> payments.PaymentEnvelopeResponse
+	37, // 60: payments.PaymentsService.ListPayments:output_type -> payments.ListPaymentsResponse
+	36, // 61: payments.PaymentsService.CancelPayment:output_type -> payments.PaymentEnvelopeResponse
+	17, // 62: payments.PaymentsService.RefundPayment:output_type -> payments.RefundPaymentResponse
+	20, // 63: payments.PaymentsService.ListPaymentCharges:output_type -> payments.ListPaymentChargesResponse
+	23, // 64: payments.PaymentsService.ListPaymentEvents:output_type -> payments.ListPaymentEventsResponse
+	25, // 65: payments.PaymentsService.WatchPayment:output_type -> payments.WatchPaymentResponse
+	36, // 66: payments.PaymentsService.PauseSubscription:output_type -> payments.PaymentEnvelopeResponse
+	36, // 67: payments.PaymentsService.ResumeSubscription:output_type -> payments.PaymentEnvelopeResponse
+	36, // 68: payments.PaymentsService.CancelSubscription:output_type -> payments.PaymentEnvelopeResponse
+	36, // 69: payments.PaymentsService.UpdateSubscription:output_type -> payments.PaymentEnvelopeResponse
+	44, // 70: payments.PaymentsService.HandleProviderCallback:output_type -> payments.MessageResponse
+	41, // 71: payments.PaymentsService.ListDeadLetterCallbacks:output_type -> payments.ListDeadLetterCallbacksResponse
+	43, // 72: payments.PaymentsService.RedeliverCallbacks:output_type -> payments.RedeliverCallbacksResponse
+	34, // 73: payments.PaymentsService.ListProviderCallbacks:output_type -> payments.ListProviderCallbacksResponse
+	36, // 74: payments.PaymentsService.ReplayProviderCallback:output_type -> payments.PaymentEnvelopeResponse
+	44, // 75: payments.PaymentCallbackReceiver.DeliverPaymentCallback:output_type -> payments.MessageResponse
+	55, // [55:76] is the sub-list for method output_type
+	34, // [34:55] is the sub-list for method input_type
+	34, // [34:34] is the sub-list for extension type_name
+	34, // [34:34] is the sub-list for extension extendee
+	0,  // [0:34] is the sub-list for field type_name
 }
 
 func init() { file_payments_proto_init() }
@@ -1509,10 +3870,10 @@ func file_payments_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_payments_proto_rawDesc), len(file_payments_proto_rawDesc)),
-			NumEnums:      4,
-			NumMessages:   14,
+			NumEnums:      6,
+			NumMessages:   43,
 			NumExtensions: 0,
-			NumServices:   1,
+			NumServices:   2,
 		},
 		GoTypes:           file_payments_proto_goTypes,
 		DependencyIndexes: file_payments_proto_depIdxs,
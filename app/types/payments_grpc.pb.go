@@ -19,12 +19,26 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	PaymentsService_Health_FullMethodName                 = "/payments.PaymentsService/Health"
-	PaymentsService_CreatePayment_FullMethodName          = "/payments.PaymentsService/CreatePayment"
-	PaymentsService_GetPayment_FullMethodName             = "/payments.PaymentsService/GetPayment"
-	PaymentsService_ListPayments_FullMethodName           = "/payments.PaymentsService/ListPayments"
-	PaymentsService_CancelPayment_FullMethodName          = "/payments.PaymentsService/CancelPayment"
-	PaymentsService_HandleProviderCallback_FullMethodName = "/payments.PaymentsService/HandleProviderCallback"
+	PaymentsService_Health_FullMethodName                  = "/payments.PaymentsService/Health"
+	PaymentsService_CreatePayment_FullMethodName           = "/payments.PaymentsService/CreatePayment"
+	PaymentsService_GetPayment_FullMethodName              = "/payments.PaymentsService/GetPayment"
+	PaymentsService_GetPaymentByRequestID_FullMethodName   = "/payments.PaymentsService/GetPaymentByRequestID"
+	PaymentsService_GetPaymentByProviderRef_FullMethodName = "/payments.PaymentsService/GetPaymentByProviderRef"
+	PaymentsService_ListPayments_FullMethodName            = "/payments.PaymentsService/ListPayments"
+	PaymentsService_CancelPayment_FullMethodName           = "/payments.PaymentsService/CancelPayment"
+	PaymentsService_RefundPayment_FullMethodName           = "/payments.PaymentsService/RefundPayment"
+	PaymentsService_ListPaymentCharges_FullMethodName      = "/payments.PaymentsService/ListPaymentCharges"
+	PaymentsService_ListPaymentEvents_FullMethodName       = "/payments.PaymentsService/ListPaymentEvents"
+	PaymentsService_WatchPayment_FullMethodName            = "/payments.PaymentsService/WatchPayment"
+	PaymentsService_PauseSubscription_FullMethodName       = "/payments.PaymentsService/PauseSubscription"
+	PaymentsService_ResumeSubscription_FullMethodName      = "/payments.PaymentsService/ResumeSubscription"
+	PaymentsService_CancelSubscription_FullMethodName      = "/payments.PaymentsService/CancelSubscription"
+	PaymentsService_UpdateSubscription_FullMethodName      = "/payments.PaymentsService/UpdateSubscription"
+	PaymentsService_HandleProviderCallback_FullMethodName  = "/payments.PaymentsService/HandleProviderCallback"
+	PaymentsService_ListDeadLetterCallbacks_FullMethodName = "/payments.PaymentsService/ListDeadLetterCallbacks"
+	PaymentsService_RedeliverCallbacks_FullMethodName      = "/payments.PaymentsService/RedeliverCallbacks"
+	PaymentsService_ListProviderCallbacks_FullMethodName   = "/payments.PaymentsService/ListProviderCallbacks"
+	PaymentsService_ReplayProviderCallback_FullMethodName  = "/payments.PaymentsService/ReplayProviderCallback"
 )
 
 // PaymentsServiceClient is the client API for PaymentsService service.
@@ -34,9 +48,23 @@ type PaymentsServiceClient interface {
 	Health(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error)
 	CreatePayment(ctx context.Context, in *CreatePaymentRequest, opts ...grpc.CallOption) (*PaymentEnvelopeResponse, error)
 	GetPayment(ctx context.Context, in *GetPaymentRequest, opts ...grpc.CallOption) (*PaymentEnvelopeResponse, error)
+	GetPaymentByRequestID(ctx context.Context, in *GetPaymentByRequestIDRequest, opts ...grpc.CallOption) (*PaymentEnvelopeResponse, error)
+	GetPaymentByProviderRef(ctx context.Context, in *GetPaymentByProviderRefRequest, opts ...grpc.CallOption) (*PaymentEnvelopeResponse, error)
 	ListPayments(ctx context.Context, in *ListPaymentsRequest, opts ...grpc.CallOption) (*ListPaymentsResponse, error)
 	CancelPayment(ctx context.Context, in *CancelPaymentRequest, opts ...grpc.CallOption) (*PaymentEnvelopeResponse, error)
+	RefundPayment(ctx context.Context, in *RefundPaymentRequest, opts ...grpc.CallOption) (*RefundPaymentResponse, error)
+	ListPaymentCharges(ctx context.Context, in *ListPaymentChargesRequest, opts ...grpc.CallOption) (*ListPaymentChargesResponse, error)
+	ListPaymentEvents(ctx context.Context, in *ListPaymentEventsRequest, opts ...grpc.CallOption) (*ListPaymentEventsResponse, error)
+	WatchPayment(ctx context.Context, in *WatchPaymentRequest, opts ...grpc.CallOption) (PaymentsService_WatchPaymentClient, error)
+	PauseSubscription(ctx context.Context, in *PauseSubscriptionRequest, opts ...grpc.CallOption) (*PaymentEnvelopeResponse, error)
+	ResumeSubscription(ctx context.Context, in *ResumeSubscriptionRequest, opts ...grpc.CallOption) (*PaymentEnvelopeResponse, error)
+	CancelSubscription(ctx context.Context, in *CancelSubscriptionRequest, opts ...grpc.CallOption) (*PaymentEnvelopeResponse, error)
+	UpdateSubscription(ctx context.Context, in *UpdateSubscriptionRequest, opts ...grpc.CallOption) (*PaymentEnvelopeResponse, error)
 	HandleProviderCallback(ctx context.Context, in *HandleProviderCallbackRequest, opts ...grpc.CallOption) (*MessageResponse, error)
+	ListDeadLetterCallbacks(ctx context.Context, in *ListDeadLetterCallbacksRequest, opts ...grpc.CallOption) (*ListDeadLetterCallbacksResponse, error)
+	RedeliverCallbacks(ctx context.Context, in *RedeliverCallbacksRequest, opts ...grpc.CallOption) (*RedeliverCallbacksResponse, error)
+	ListProviderCallbacks(ctx context.Context, in *ListProviderCallbacksRequest, opts ...grpc.CallOption) (*ListProviderCallbacksResponse, error)
+	ReplayProviderCallback(ctx context.Context, in *ReplayProviderCallbackRequest, opts ...grpc.CallOption) (*PaymentEnvelopeResponse, error)
 }
 
 type paymentsServiceClient struct {
@@ -74,6 +102,24 @@ func (c *paymentsServiceClient) GetPayment(ctx context.Context, in *GetPaymentRe
 	return out, nil
 }
 
+func (c *paymentsServiceClient) GetPaymentByRequestID(ctx context.Context, in *GetPaymentByRequestIDRequest, opts ...grpc.CallOption) (*PaymentEnvelopeResponse, error) {
+	out := new(PaymentEnvelopeResponse)
+	err := c.cc.Invoke(ctx, PaymentsService_GetPaymentByRequestID_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentsServiceClient) GetPaymentByProviderRef(ctx context.Context, in *GetPaymentByProviderRefRequest, opts ...grpc.CallOption) (*PaymentEnvelopeResponse, error) {
+	out := new(PaymentEnvelopeResponse)
+	err := c.cc.Invoke(ctx, PaymentsService_GetPaymentByProviderRef_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *paymentsServiceClient) ListPayments(ctx context.Context, in *ListPaymentsRequest, opts ...grpc.CallOption) (*ListPaymentsResponse, error) {
 	out := new(ListPaymentsResponse)
 	err := c.cc.Invoke(ctx, PaymentsService_ListPayments_FullMethodName, in, out, opts...)
@@ -92,6 +138,101 @@ func (c *paymentsServiceClient) CancelPayment(ctx context.Context, in *CancelPay
 	return out, nil
 }
 
+func (c *paymentsServiceClient) RefundPayment(ctx context.Context, in *RefundPaymentRequest, opts ...grpc.CallOption) (*RefundPaymentResponse, error) {
+	out := new(RefundPaymentResponse)
+	err := c.cc.Invoke(ctx, PaymentsService_RefundPayment_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentsServiceClient) ListPaymentCharges(ctx context.Context, in *ListPaymentChargesRequest, opts ...grpc.CallOption) (*ListPaymentChargesResponse, error) {
+	out := new(ListPaymentChargesResponse)
+	err := c.cc.Invoke(ctx, PaymentsService_ListPaymentCharges_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentsServiceClient) ListPaymentEvents(ctx context.Context, in *ListPaymentEventsRequest, opts ...grpc.CallOption) (*ListPaymentEventsResponse, error) {
+	out := new(ListPaymentEventsResponse)
+	err := c.cc.Invoke(ctx, PaymentsService_ListPaymentEvents_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentsServiceClient) WatchPayment(ctx context.Context, in *WatchPaymentRequest, opts ...grpc.CallOption) (PaymentsService_WatchPaymentClient, error) {
+	stream, err := c.cc.NewStream(ctx, &PaymentsService_ServiceDesc.Streams[0], PaymentsService_WatchPayment_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &paymentsServiceWatchPaymentClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type PaymentsService_WatchPaymentClient interface {
+	Recv() (*WatchPaymentResponse, error)
+	grpc.ClientStream
+}
+
+type paymentsServiceWatchPaymentClient struct {
+	grpc.ClientStream
+}
+
+func (x *paymentsServiceWatchPaymentClient) Recv() (*WatchPaymentResponse, error) {
+	m := new(WatchPaymentResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *paymentsServiceClient) PauseSubscription(ctx context.Context, in *PauseSubscriptionRequest, opts ...grpc.CallOption) (*PaymentEnvelopeResponse, error) {
+	out := new(PaymentEnvelopeResponse)
+	err := c.cc.Invoke(ctx, PaymentsService_PauseSubscription_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentsServiceClient) ResumeSubscription(ctx context.Context, in *ResumeSubscriptionRequest, opts ...grpc.CallOption) (*PaymentEnvelopeResponse, error) {
+	out := new(PaymentEnvelopeResponse)
+	err := c.cc.Invoke(ctx, PaymentsService_ResumeSubscription_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentsServiceClient) CancelSubscription(ctx context.Context, in *CancelSubscriptionRequest, opts ...grpc.CallOption) (*PaymentEnvelopeResponse, error) {
+	out := new(PaymentEnvelopeResponse)
+	err := c.cc.Invoke(ctx, PaymentsService_CancelSubscription_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentsServiceClient) UpdateSubscription(ctx context.Context, in *UpdateSubscriptionRequest, opts ...grpc.CallOption) (*PaymentEnvelopeResponse, error) {
+	out := new(PaymentEnvelopeResponse)
+	err := c.cc.Invoke(ctx, PaymentsService_UpdateSubscription_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *paymentsServiceClient) HandleProviderCallback(ctx context.Context, in *HandleProviderCallbackRequest, opts ...grpc.CallOption) (*MessageResponse, error) {
 	out := new(MessageResponse)
 	err := c.cc.Invoke(ctx, PaymentsService_HandleProviderCallback_FullMethodName, in, out, opts...)
@@ -101,6 +242,42 @@ func (c *paymentsServiceClient) HandleProviderCallback(ctx context.Context, in *
 	return out, nil
 }
 
+func (c *paymentsServiceClient) ListDeadLetterCallbacks(ctx context.Context, in *ListDeadLetterCallbacksRequest, opts ...grpc.CallOption) (*ListDeadLetterCallbacksResponse, error) {
+	out := new(ListDeadLetterCallbacksResponse)
+	err := c.cc.Invoke(ctx, PaymentsService_ListDeadLetterCallbacks_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentsServiceClient) RedeliverCallbacks(ctx context.Context, in *RedeliverCallbacksRequest, opts ...grpc.CallOption) (*RedeliverCallbacksResponse, error) {
+	out := new(RedeliverCallbacksResponse)
+	err := c.cc.Invoke(ctx, PaymentsService_RedeliverCallbacks_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentsServiceClient) ListProviderCallbacks(ctx context.Context, in *ListProviderCallbacksRequest, opts ...grpc.CallOption) (*ListProviderCallbacksResponse, error) {
+	out := new(ListProviderCallbacksResponse)
+	err := c.cc.Invoke(ctx, PaymentsService_ListProviderCallbacks_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentsServiceClient) ReplayProviderCallback(ctx context.Context, in *ReplayProviderCallbackRequest, opts ...grpc.CallOption) (*PaymentEnvelopeResponse, error) {
+	out := new(PaymentEnvelopeResponse)
+	err := c.cc.Invoke(ctx, PaymentsService_ReplayProviderCallback_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // PaymentsServiceServer is the server API for PaymentsService service.
 // All implementations must embed UnimplementedPaymentsServiceServer
 // for forward compatibility
@@ -108,9 +285,23 @@ type PaymentsServiceServer interface {
 	Health(context.Context, *HealthRequest) (*HealthResponse, error)
 	CreatePayment(context.Context, *CreatePaymentRequest) (*PaymentEnvelopeResponse, error)
 	GetPayment(context.Context, *GetPaymentRequest) (*PaymentEnvelopeResponse, error)
+	GetPaymentByRequestID(context.Context, *GetPaymentByRequestIDRequest) (*PaymentEnvelopeResponse, error)
+	GetPaymentByProviderRef(context.Context, *GetPaymentByProviderRefRequest) (*PaymentEnvelopeResponse, error)
 	ListPayments(context.Context, *ListPaymentsRequest) (*ListPaymentsResponse, error)
 	CancelPayment(context.Context, *CancelPaymentRequest) (*PaymentEnvelopeResponse, error)
+	RefundPayment(context.Context, *RefundPaymentRequest) (*RefundPaymentResponse, error)
+	ListPaymentCharges(context.Context, *ListPaymentChargesRequest) (*ListPaymentChargesResponse, error)
+	ListPaymentEvents(context.Context, *ListPaymentEventsRequest) (*ListPaymentEventsResponse, error)
+	WatchPayment(*WatchPaymentRequest, PaymentsService_WatchPaymentServer) error
+	PauseSubscription(context.Context, *PauseSubscriptionRequest) (*PaymentEnvelopeResponse, error)
+	ResumeSubscription(context.Context, *ResumeSubscriptionRequest) (*PaymentEnvelopeResponse, error)
+	CancelSubscription(context.Context, *CancelSubscriptionRequest) (*PaymentEnvelopeResponse, error)
+	UpdateSubscription(context.Context, *UpdateSubscriptionRequest) (*PaymentEnvelopeResponse, error)
 	HandleProviderCallback(context.Context, *HandleProviderCallbackRequest) (*MessageResponse, error)
+	ListDeadLetterCallbacks(context.Context, *ListDeadLetterCallbacksRequest) (*ListDeadLetterCallbacksResponse, error)
+	RedeliverCallbacks(context.Context, *RedeliverCallbacksRequest) (*RedeliverCallbacksResponse, error)
+	ListProviderCallbacks(context.Context, *ListProviderCallbacksRequest) (*ListProviderCallbacksResponse, error)
+	ReplayProviderCallback(context.Context, *ReplayProviderCallbackRequest) (*PaymentEnvelopeResponse, error)
 	mustEmbedUnimplementedPaymentsServiceServer()
 }
 
@@ -127,15 +318,57 @@ func (UnimplementedPaymentsServiceServer) CreatePayment(context.Context, *Create
 func (UnimplementedPaymentsServiceServer) GetPayment(context.Context, *GetPaymentRequest) (*PaymentEnvelopeResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetPayment not implemented")
 }
+func (UnimplementedPaymentsServiceServer) GetPaymentByRequestID(context.Context, *GetPaymentByRequestIDRequest) (*PaymentEnvelopeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPaymentByRequestID not implemented")
+}
+func (UnimplementedPaymentsServiceServer) GetPaymentByProviderRef(context.Context, *GetPaymentByProviderRefRequest) (*PaymentEnvelopeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPaymentByProviderRef not implemented")
+}
 func (UnimplementedPaymentsServiceServer) ListPayments(context.Context, *ListPaymentsRequest) (*ListPaymentsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListPayments not implemented")
 }
 func (UnimplementedPaymentsServiceServer) CancelPayment(context.Context, *CancelPaymentRequest) (*PaymentEnvelopeResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CancelPayment not implemented")
 }
+func (UnimplementedPaymentsServiceServer) RefundPayment(context.Context, *RefundPaymentRequest) (*RefundPaymentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RefundPayment not implemented")
+}
+func (UnimplementedPaymentsServiceServer) ListPaymentCharges(context.Context, *ListPaymentChargesRequest) (*ListPaymentChargesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPaymentCharges not implemented")
+}
+func (UnimplementedPaymentsServiceServer) ListPaymentEvents(context.Context, *ListPaymentEventsRequest) (*ListPaymentEventsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPaymentEvents not implemented")
+}
+func (UnimplementedPaymentsServiceServer) WatchPayment(*WatchPaymentRequest, PaymentsService_WatchPaymentServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchPayment not implemented")
+}
+func (UnimplementedPaymentsServiceServer) PauseSubscription(context.Context, *PauseSubscriptionRequest) (*PaymentEnvelopeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PauseSubscription not implemented")
+}
+func (UnimplementedPaymentsServiceServer) ResumeSubscription(context.Context, *ResumeSubscriptionRequest) (*PaymentEnvelopeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResumeSubscription not implemented")
+}
+func (UnimplementedPaymentsServiceServer) CancelSubscription(context.Context, *CancelSubscriptionRequest) (*PaymentEnvelopeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelSubscription not implemented")
+}
+func (UnimplementedPaymentsServiceServer) UpdateSubscription(context.Context, *UpdateSubscriptionRequest) (*PaymentEnvelopeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateSubscription not implemented")
+}
 func (UnimplementedPaymentsServiceServer) HandleProviderCallback(context.Context, *HandleProviderCallbackRequest) (*MessageResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method HandleProviderCallback not implemented")
 }
+func (UnimplementedPaymentsServiceServer) ListDeadLetterCallbacks(context.Context, *ListDeadLetterCallbacksRequest) (*ListDeadLetterCallbacksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListDeadLetterCallbacks not implemented")
+}
+func (UnimplementedPaymentsServiceServer) RedeliverCallbacks(context.Context, *RedeliverCallbacksRequest) (*RedeliverCallbacksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RedeliverCallbacks not implemented")
+}
+func (UnimplementedPaymentsServiceServer) ListProviderCallbacks(context.Context, *ListProviderCallbacksRequest) (*ListProviderCallbacksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListProviderCallbacks not implemented")
+}
+func (UnimplementedPaymentsServiceServer) ReplayProviderCallback(context.Context, *ReplayProviderCallbackRequest) (*PaymentEnvelopeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReplayProviderCallback not implemented")
+}
 func (UnimplementedPaymentsServiceServer) mustEmbedUnimplementedPaymentsServiceServer() {}
 
 // UnsafePaymentsServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -203,6 +436,42 @@ func _PaymentsService_GetPayment_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _PaymentsService_GetPaymentByRequestID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPaymentByRequestIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentsServiceServer).GetPaymentByRequestID(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentsService_GetPaymentByRequestID_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentsServiceServer).GetPaymentByRequestID(ctx, req.(*GetPaymentByRequestIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentsService_GetPaymentByProviderRef_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPaymentByProviderRefRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentsServiceServer).GetPaymentByProviderRef(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentsService_GetPaymentByProviderRef_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentsServiceServer).GetPaymentByProviderRef(ctx, req.(*GetPaymentByProviderRefRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _PaymentsService_ListPayments_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ListPaymentsRequest)
 	if err := dec(in); err != nil {
@@ -239,6 +508,153 @@ func _PaymentsService_CancelPayment_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _PaymentsService_RefundPayment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RefundPaymentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentsServiceServer).RefundPayment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentsService_RefundPayment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentsServiceServer).RefundPayment(ctx, req.(*RefundPaymentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentsService_ListPaymentCharges_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPaymentChargesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentsServiceServer).ListPaymentCharges(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentsService_ListPaymentCharges_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentsServiceServer).ListPaymentCharges(ctx, req.(*ListPaymentChargesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentsService_ListPaymentEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPaymentEventsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentsServiceServer).ListPaymentEvents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentsService_ListPaymentEvents_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentsServiceServer).ListPaymentEvents(ctx, req.(*ListPaymentEventsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentsService_WatchPayment_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchPaymentRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PaymentsServiceServer).WatchPayment(m, &paymentsServiceWatchPaymentServer{stream})
+}
+
+type PaymentsService_WatchPaymentServer interface {
+	Send(*WatchPaymentResponse) error
+	grpc.ServerStream
+}
+
+type paymentsServiceWatchPaymentServer struct {
+	grpc.ServerStream
+}
+
+func (x *paymentsServiceWatchPaymentServer) Send(m *WatchPaymentResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _PaymentsService_PauseSubscription_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PauseSubscriptionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentsServiceServer).PauseSubscription(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentsService_PauseSubscription_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentsServiceServer).PauseSubscription(ctx, req.(*PauseSubscriptionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentsService_ResumeSubscription_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResumeSubscriptionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentsServiceServer).ResumeSubscription(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentsService_ResumeSubscription_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentsServiceServer).ResumeSubscription(ctx, req.(*ResumeSubscriptionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentsService_CancelSubscription_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelSubscriptionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentsServiceServer).CancelSubscription(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentsService_CancelSubscription_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentsServiceServer).CancelSubscription(ctx, req.(*CancelSubscriptionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentsService_UpdateSubscription_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateSubscriptionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentsServiceServer).UpdateSubscription(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentsService_UpdateSubscription_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentsServiceServer).UpdateSubscription(ctx, req.(*UpdateSubscriptionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _PaymentsService_HandleProviderCallback_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(HandleProviderCallbackRequest)
 	if err := dec(in); err != nil {
@@ -257,6 +673,78 @@ func _PaymentsService_HandleProviderCallback_Handler(srv interface{}, ctx contex
 	return interceptor(ctx, in, info, handler)
 }
 
+func _PaymentsService_ListDeadLetterCallbacks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDeadLetterCallbacksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentsServiceServer).ListDeadLetterCallbacks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentsService_ListDeadLetterCallbacks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentsServiceServer).ListDeadLetterCallbacks(ctx, req.(*ListDeadLetterCallbacksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentsService_RedeliverCallbacks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RedeliverCallbacksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentsServiceServer).RedeliverCallbacks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentsService_RedeliverCallbacks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentsServiceServer).RedeliverCallbacks(ctx, req.(*RedeliverCallbacksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentsService_ListProviderCallbacks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListProviderCallbacksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentsServiceServer).ListProviderCallbacks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentsService_ListProviderCallbacks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentsServiceServer).ListProviderCallbacks(ctx, req.(*ListProviderCallbacksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PaymentsService_ReplayProviderCallback_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReplayProviderCallbackRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentsServiceServer).ReplayProviderCallback(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentsService_ReplayProviderCallback_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentsServiceServer).ReplayProviderCallback(ctx, req.(*ReplayProviderCallbackRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // PaymentsService_ServiceDesc is the grpc.ServiceDesc for PaymentsService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -276,6 +764,14 @@ var PaymentsService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetPayment",
 			Handler:    _PaymentsService_GetPayment_Handler,
 		},
+		{
+			MethodName: "GetPaymentByRequestID",
+			Handler:    _PaymentsService_GetPaymentByRequestID_Handler,
+		},
+		{
+			MethodName: "GetPaymentByProviderRef",
+			Handler:    _PaymentsService_GetPaymentByProviderRef_Handler,
+		},
 		{
 			MethodName: "ListPayments",
 			Handler:    _PaymentsService_ListPayments_Handler,
@@ -284,10 +780,151 @@ var PaymentsService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CancelPayment",
 			Handler:    _PaymentsService_CancelPayment_Handler,
 		},
+		{
+			MethodName: "RefundPayment",
+			Handler:    _PaymentsService_RefundPayment_Handler,
+		},
+		{
+			MethodName: "ListPaymentCharges",
+			Handler:    _PaymentsService_ListPaymentCharges_Handler,
+		},
+		{
+			MethodName: "ListPaymentEvents",
+			Handler:    _PaymentsService_ListPaymentEvents_Handler,
+		},
+		{
+			MethodName: "PauseSubscription",
+			Handler:    _PaymentsService_PauseSubscription_Handler,
+		},
+		{
+			MethodName: "ResumeSubscription",
+			Handler:    _PaymentsService_ResumeSubscription_Handler,
+		},
+		{
+			MethodName: "CancelSubscription",
+			Handler:    _PaymentsService_CancelSubscription_Handler,
+		},
+		{
+			MethodName: "UpdateSubscription",
+			Handler:    _PaymentsService_UpdateSubscription_Handler,
+		},
 		{
 			MethodName: "HandleProviderCallback",
 			Handler:    _PaymentsService_HandleProviderCallback_Handler,
 		},
+		{
+			MethodName: "ListDeadLetterCallbacks",
+			Handler:    _PaymentsService_ListDeadLetterCallbacks_Handler,
+		},
+		{
+			MethodName: "RedeliverCallbacks",
+			Handler:    _PaymentsService_RedeliverCallbacks_Handler,
+		},
+		{
+			MethodName: "ListProviderCallbacks",
+			Handler:    _PaymentsService_ListProviderCallbacks_Handler,
+		},
+		{
+			MethodName: "ReplayProviderCallback",
+			Handler:    _PaymentsService_ReplayProviderCallback_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchPayment",
+			Handler:       _PaymentsService_WatchPayment_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "payments.proto",
+}
+
+const (
+	PaymentCallbackReceiver_DeliverPaymentCallback_FullMethodName = "/payments.PaymentCallbackReceiver/DeliverPaymentCallback"
+)
+
+// PaymentCallbackReceiverClient is the client API for PaymentCallbackReceiver service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type PaymentCallbackReceiverClient interface {
+	DeliverPaymentCallback(ctx context.Context, in *PaymentCallbackRequest, opts ...grpc.CallOption) (*MessageResponse, error)
+}
+
+type paymentCallbackReceiverClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPaymentCallbackReceiverClient(cc grpc.ClientConnInterface) PaymentCallbackReceiverClient {
+	return &paymentCallbackReceiverClient{cc}
+}
+
+func (c *paymentCallbackReceiverClient) DeliverPaymentCallback(ctx context.Context, in *PaymentCallbackRequest, opts ...grpc.CallOption) (*MessageResponse, error) {
+	out := new(MessageResponse)
+	err := c.cc.Invoke(ctx, PaymentCallbackReceiver_DeliverPaymentCallback_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PaymentCallbackReceiverServer is the server API for PaymentCallbackReceiver service.
+// All implementations must embed UnimplementedPaymentCallbackReceiverServer
+// for forward compatibility
+type PaymentCallbackReceiverServer interface {
+	DeliverPaymentCallback(context.Context, *PaymentCallbackRequest) (*MessageResponse, error)
+	mustEmbedUnimplementedPaymentCallbackReceiverServer()
+}
+
+// UnimplementedPaymentCallbackReceiverServer must be embedded to have forward compatible implementations.
+type UnimplementedPaymentCallbackReceiverServer struct {
+}
+
+func (UnimplementedPaymentCallbackReceiverServer) DeliverPaymentCallback(context.Context, *PaymentCallbackRequest) (*MessageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeliverPaymentCallback not implemented")
+}
+func (UnimplementedPaymentCallbackReceiverServer) mustEmbedUnimplementedPaymentCallbackReceiverServer() {
+}
+
+// UnsafePaymentCallbackReceiverServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PaymentCallbackReceiverServer will
+// result in compilation errors.
+type UnsafePaymentCallbackReceiverServer interface {
+	mustEmbedUnimplementedPaymentCallbackReceiverServer()
+}
+
+func RegisterPaymentCallbackReceiverServer(s grpc.ServiceRegistrar, srv PaymentCallbackReceiverServer) {
+	s.RegisterService(&PaymentCallbackReceiver_ServiceDesc, srv)
+}
+
+func _PaymentCallbackReceiver_DeliverPaymentCallback_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PaymentCallbackRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentCallbackReceiverServer).DeliverPaymentCallback(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PaymentCallbackReceiver_DeliverPaymentCallback_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentCallbackReceiverServer).DeliverPaymentCallback(ctx, req.(*PaymentCallbackRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// PaymentCallbackReceiver_ServiceDesc is the grpc.ServiceDesc for PaymentCallbackReceiver service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PaymentCallbackReceiver_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "payments.PaymentCallbackReceiver",
+	HandlerType: (*PaymentCallbackReceiverServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "DeliverPaymentCallback",
+			Handler:    _PaymentCallbackReceiver_DeliverPaymentCallback_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "payments.proto",
@@ -77,6 +77,61 @@ func TestNewListPaymentsRequestFromContextAndValidate(t *testing.T) {
 	}
 }
 
+func TestNewListPaymentsRequestFromContextParsesRichFilters(t *testing.T) {
+	e := echo.New()
+	query := "/payments?status=10,20&status=30&currency=eur&min_amount_cents=100&max_amount_cents=900" +
+		"&created_from=2026-01-01T00:00:00Z&created_to=2026-02-01T00:00:00Z&updated_from=2026-01-15T00:00:00Z" +
+		"&customer_ref=cus-1&provider_payment_id=cs_1&provider_subscription_id=sub_1" +
+		"&metadata=plan:pro&metadata=region:eu&page_token=abc"
+	req := httptest.NewRequest("GET", query, nil)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+
+	parsed, err := NewListPaymentsRequestFromContext(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if parsed.GetHasStatus() || len(parsed.GetStatuses()) != 3 || parsed.GetStatuses()[1] != PaymentStatus_PAYMENT_STATUS_FAILED {
+		t.Fatalf("unexpected statuses parse: %+v", parsed.GetStatuses())
+	}
+	if parsed.GetCurrency() != "EUR" || parsed.GetMinAmountCents() != 100 || parsed.GetMaxAmountCents() != 900 {
+		t.Fatalf("unexpected currency/amount parse: %+v", parsed)
+	}
+	if parsed.GetCustomerRef() != "cus-1" || parsed.GetProviderPaymentId() != "cs_1" || parsed.GetProviderSubscriptionId() != "sub_1" {
+		t.Fatalf("unexpected reference parse: %+v", parsed)
+	}
+	if parsed.GetMetadata()["plan"] != "pro" || parsed.GetMetadata()["region"] != "eu" || parsed.GetPageToken() != "abc" {
+		t.Fatalf("unexpected metadata/page token parse: %+v", parsed)
+	}
+	if err := parsed.Validate(); err != nil {
+		t.Fatalf("expected valid list request, got %v", err)
+	}
+}
+
+func TestListPaymentsValidateRejectsInvalidFilters(t *testing.T) {
+	cases := map[string]*ListPaymentsRequest{
+		"offset with page token": {Offset: 10, PageToken: "abc"},
+		"inverted amount range":  {MinAmountCents: 500, MaxAmountCents: 100},
+		"inverted updated range": {UpdatedFrom: "2026-02-01T00:00:00Z", UpdatedTo: "2026-01-01T00:00:00Z"},
+		"bad created_from":       {CreatedFrom: "yesterday"},
+		"bad currency":           {Currency: "EURO"},
+		"bad status":             {Statuses: []PaymentStatus{PaymentStatus(99)}},
+		"empty metadata key":     {Metadata: map[string]string{" ": "pro"}},
+	}
+	for name, req := range cases {
+		if err := req.Validate(); err == nil {
+			t.Fatalf("%s: expected validation error", name)
+		}
+	}
+}
+
+func TestListPaymentsValidateAcceptsQuotedMetadataKeys(t *testing.T) {
+	req := &ListPaymentsRequest{Metadata: map[string]string{`pl"an\tier`: "pro"}}
+	if err := req.Validate(); err != nil {
+		t.Fatalf("expected metadata keys with quotes and backslashes to be accepted, got %v", err)
+	}
+}
+
 func TestListPaymentsValidateDefaultLimit(t *testing.T) {
 	req := &ListPaymentsRequest{}
 	if err := req.Validate(); err != nil {
@@ -106,16 +161,21 @@ func TestNewCancelPaymentRequestFromContext(t *testing.T) {
 }
 
 func TestNewHandleProviderCallbackRequestFromContext(t *testing.T) {
+	// A body that looks like a forwarding envelope is still taken verbatim:
+	// only the declared header may supply the signature.
+	body := `{"payload":"{\"id\":\"evt_1\"}","signature":"sig-from-body"}`
 	e := echo.New()
-	req := httptest.NewRequest("POST", "/webhooks/providers/stripe/hash-1", bytes.NewBufferString(`{"payload":"{\"id\":\"evt_1\"}","signature":"sig-value"}`))
+	req := httptest.NewRequest("POST", "/webhooks/providers/stripe/hash-1", bytes.NewBufferString(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	req.Header.Set(echo.HeaderXRequestID, "callback-req-1")
+	req.Header.Set("Stripe-Signature", "sig-from-header")
+	req.Header.Set("X-Provider-Signature", "sig-other")
 	rec := httptest.NewRecorder()
 	ctx := e.NewContext(req, rec)
 	ctx.SetParamNames("provider", "hash")
 	ctx.SetParamValues("stripe", "hash-1")
 
-	parsed, err := NewHandleProviderCallbackRequestFromContext(ctx)
+	parsed, err := NewHandleProviderCallbackRequestFromContext(ctx, "Stripe-Signature")
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -125,10 +185,151 @@ func TestNewHandleProviderCallbackRequestFromContext(t *testing.T) {
 	if parsed.GetProvider() != "stripe" || parsed.GetCallbackHash() != "hash-1" {
 		t.Fatalf("unexpected callback route params: %+v", parsed)
 	}
-	if parsed.GetSignature() != "sig-value" {
-		t.Fatalf("expected signature from body override, got %q", parsed.GetSignature())
+	if parsed.GetSignature() != "sig-from-header" {
+		t.Fatalf("expected signature from the declared header, got %q", parsed.GetSignature())
+	}
+	if parsed.GetPayload() != body {
+		t.Fatalf("expected raw body as payload, got %q", parsed.GetPayload())
 	}
 	if err := parsed.Validate(); err != nil {
 		t.Fatalf("expected valid callback request, got %v", err)
 	}
 }
+
+func TestNewForwardedProviderCallbackRequestFromContext(t *testing.T) {
+	newCtx := func(body string) echo.Context {
+		e := echo.New()
+		req := httptest.NewRequest("POST", "/webhooks/forwarded/stripe/hash-1", bytes.NewBufferString(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(echo.HeaderXRequestID, "callback-req-1")
+		ctx := e.NewContext(req, httptest.NewRecorder())
+		ctx.SetParamNames("provider", "hash")
+		ctx.SetParamValues("stripe", "hash-1")
+		return ctx
+	}
+
+	parsed, err := NewForwardedProviderCallbackRequestFromContext(newCtx(`{"payload":"{\"id\":\"evt_1\"}","signature":"sig-value"}`))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if parsed.GetPayload() != `{"id":"evt_1"}` || parsed.GetSignature() != "sig-value" {
+		t.Fatalf("unexpected forwarded callback: %+v", parsed)
+	}
+
+	if _, err := NewForwardedProviderCallbackRequestFromContext(newCtx(`{"id":"evt_1"}`)); err == nil {
+		t.Fatal("expected a raw provider body to be rejected on the forwarding route")
+	}
+	if _, err := NewForwardedProviderCallbackRequestFromContext(newCtx(`{"payload":"{}"}`)); err == nil {
+		t.Fatal("expected an envelope without signature to be rejected")
+	}
+}
+
+func TestNewRefundPaymentRequestFromContextUsesHeaderRequestID(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest("POST", "/payments/12/refunds", bytes.NewBufferString(`{"amount_cents":500,"reason":" requested_by_customer "}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(echo.HeaderXRequestID, "refund-req-1")
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("12")
+
+	parsed, err := NewRefundPaymentRequestFromContext(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if parsed.GetId() != 12 || parsed.GetRequestId() != "refund-req-1" || parsed.GetAmountCents() != 500 {
+		t.Fatalf("unexpected parsed refund request: %+v", parsed)
+	}
+	if parsed.GetReason() != "requested_by_customer" {
+		t.Fatalf("expected trimmed reason, got %q", parsed.GetReason())
+	}
+	if err := parsed.Validate(); err != nil {
+		t.Fatalf("expected valid refund request, got %v", err)
+	}
+}
+
+func TestUpdateSubscriptionValidate(t *testing.T) {
+	req := &UpdateSubscriptionRequest{Id: 3}
+	if err := req.Validate(); err == nil {
+		t.Fatal("expected error when nothing is changed")
+	}
+
+	req.RecurringInterval = "fortnight"
+	if err := req.Validate(); err == nil {
+		t.Fatal("expected recurring_interval validation error")
+	}
+
+	req.RecurringInterval = "year"
+	req.AmountCents = 2999
+	if err := req.Validate(); err != nil {
+		t.Fatalf("expected valid update request, got %v", err)
+	}
+}
+
+func TestRedeliverCallbacksValidate(t *testing.T) {
+	if err := (&RedeliverCallbacksRequest{}).Validate(); err == nil {
+		t.Fatal("expected error without filter or all")
+	}
+	if err := (&RedeliverCallbacksRequest{All: true, PaymentId: 7}).Validate(); err == nil {
+		t.Fatal("expected error when all is combined with a filter")
+	}
+	if err := (&RedeliverCallbacksRequest{CallerService: "subscriptions-service"}).Validate(); err != nil {
+		t.Fatalf("expected filtered request to be valid, got %v", err)
+	}
+	if err := (&RedeliverCallbacksRequest{All: true}).Validate(); err != nil {
+		t.Fatalf("expected all request to be valid, got %v", err)
+	}
+}
+
+func TestNewListPaymentEventsRequestFromContext(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest("GET", "/payments/7/events?event_type=provider_callback&cursor=12&limit=50&include_payload=true", nil)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("7")
+
+	parsed, err := NewListPaymentEventsRequestFromContext(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if parsed.GetId() != 7 || parsed.GetEventType() != "provider_callback" || parsed.GetCursor() != 12 || parsed.GetLimit() != 50 || !parsed.GetIncludePayload() {
+		t.Fatalf("unexpected parsed request: %+v", parsed)
+	}
+	if err := parsed.Validate(); err != nil {
+		t.Fatalf("expected valid request, got %v", err)
+	}
+
+	parsed.Limit = 501
+	if err := parsed.Validate(); err == nil {
+		t.Fatal("expected limit validation error")
+	}
+}
+
+func TestNewListProviderCallbacksRequestFromContext(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest("GET", "/callbacks/inbox?provider=Stripe&status=rejected&created_from=2026-01-01T00:00:00Z&created_to=2026-01-02T00:00:00Z", nil)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+
+	parsed, err := NewListProviderCallbacksRequestFromContext(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if parsed.GetProvider() != "stripe" || !parsed.GetHasStatus() || parsed.GetStatus() != ProviderCallbackStatus_PROVIDER_CALLBACK_STATUS_REJECTED {
+		t.Fatalf("unexpected parsed request: %+v", parsed)
+	}
+	if err := parsed.Validate(); err != nil {
+		t.Fatalf("expected valid request, got %v", err)
+	}
+
+	parsed.CreatedTo = "2025-12-31T00:00:00Z"
+	if err := parsed.Validate(); err == nil {
+		t.Fatal("expected inverted time range to be rejected")
+	}
+	parsed.CreatedTo = "tomorrow"
+	if err := parsed.Validate(); err == nil {
+		t.Fatal("expected non-RFC3339 time to be rejected")
+	}
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/vibast-solutions/ms-go-payments/app/mapper"
+	"github.com/vibast-solutions/ms-go-payments/app/types"
+)
+
+var (
+	inboxProvider       string
+	inboxCallbackHash   string
+	inboxStatus         string
+	inboxCreatedFrom    string
+	inboxCreatedTo      string
+	inboxLimit          int32
+	inboxOffset         int32
+	inboxIncludePayload bool
+
+	inboxSkipSignature bool
+)
+
+var callbacksInboxCmd = &cobra.Command{
+	Use:   "inbox",
+	Short: "Inspect and replay inbound provider callbacks",
+}
+
+var callbacksInboxListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List stored provider callbacks, newest first",
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		req := &types.ListProviderCallbacksRequest{
+			Provider:       strings.ToLower(strings.TrimSpace(inboxProvider)),
+			CallbackHash:   strings.TrimSpace(inboxCallbackHash),
+			CreatedFrom:    strings.TrimSpace(inboxCreatedFrom),
+			CreatedTo:      strings.TrimSpace(inboxCreatedTo),
+			Limit:          inboxLimit,
+			Offset:         inboxOffset,
+			IncludePayload: inboxIncludePayload,
+		}
+		if strings.TrimSpace(inboxStatus) != "" {
+			status, err := types.ParseProviderCallbackStatus(inboxStatus)
+			if err != nil {
+				return err
+			}
+			req.HasStatus = true
+			req.Status = status
+		}
+		if err := req.Validate(); err != nil {
+			return err
+		}
+
+		_, paymentService, cleanup := mustCreatePaymentService()
+		defer cleanup()
+
+		items, err := paymentService.ListProviderCallbacks(context.Background(), req)
+		if err != nil {
+			return err
+		}
+
+		encoder := json.NewEncoder(cmd.OutOrStdout())
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(&types.ListProviderCallbacksResponse{Callbacks: mapper.ProviderCallbacksToProto(items)})
+	},
+}
+
+var callbacksInboxReplayCmd = &cobra.Command{
+	Use:   "replay <id>",
+	Short: "Run a stored provider callback through callback handling again",
+	Long:  "Run a stored provider callback through callback handling again. Use --skip-signature for payloads whose signature was verified on receipt but has expired since.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		id, err := strconv.ParseUint(strings.TrimSpace(args[0]), 10, 64)
+		if err != nil {
+			return err
+		}
+		req := &types.ReplayProviderCallbackRequest{Id: id, SkipSignature: inboxSkipSignature}
+		if err := req.Validate(); err != nil {
+			return err
+		}
+
+		_, paymentService, cleanup := mustCreatePaymentService()
+		defer cleanup()
+
+		payment, err := paymentService.ReplayProviderCallback(context.Background(), req)
+		if err != nil {
+			return err
+		}
+		logrus.WithFields(logrus.Fields{
+			"job":         "callbacks_inbox_replay",
+			"callback_id": id,
+			"payment_id":  payment.ID,
+			"status":      types.PaymentStatus(payment.Status).String(),
+		}).Info("provider_callback_replayed")
+		return nil
+	},
+}
+
+func init() {
+	callbacksCmd.AddCommand(callbacksInboxCmd)
+	callbacksInboxCmd.AddCommand(callbacksInboxListCmd)
+	callbacksInboxCmd.AddCommand(callbacksInboxReplayCmd)
+
+	callbacksInboxListCmd.Flags().StringVar(&inboxProvider, "provider", "", "Only callbacks of this provider (e.g. stripe)")
+	callbacksInboxListCmd.Flags().StringVar(&inboxCallbackHash, "callback-hash", "", "Only callbacks received on this callback hash")
+	callbacksInboxListCmd.Flags().StringVar(&inboxStatus, "status", "", "Only callbacks with this status (processed, rejected, duplicate)")
+	callbacksInboxListCmd.Flags().StringVar(&inboxCreatedFrom, "from", "", "Only callbacks received at or after this RFC3339 time")
+	callbacksInboxListCmd.Flags().StringVar(&inboxCreatedTo, "to", "", "Only callbacks received at or before this RFC3339 time")
+	callbacksInboxListCmd.Flags().Int32Var(&inboxLimit, "limit", 100, "Maximum number of callbacks to list")
+	callbacksInboxListCmd.Flags().Int32Var(&inboxOffset, "offset", 0, "Number of callbacks to skip")
+	callbacksInboxListCmd.Flags().BoolVar(&inboxIncludePayload, "include-payload", false, "Include raw callback payloads")
+
+	callbacksInboxReplayCmd.Flags().BoolVar(&inboxSkipSignature, "skip-signature", false, "Parse the stored payload without verifying its signature")
+}
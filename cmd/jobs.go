@@ -2,19 +2,30 @@ package cmd
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/vibast-solutions/ms-go-payments/app/service"
+	"github.com/vibast-solutions/ms-go-payments/app/types"
 	"github.com/vibast-solutions/ms-go-payments/config"
 )
 
 var (
 	workerMode bool
+
+	redeliverPaymentID     uint64
+	redeliverCallerService string
+	redeliverEventType     string
+	redeliverAll           bool
+
+	backfillSince    string
+	backfillProvider string
 )
 
 var reconcileCmd = &cobra.Command{
@@ -31,6 +42,52 @@ var reconcileCmd = &cobra.Command{
 	},
 }
 
+var reconcileEventsCmd = &cobra.Command{
+	Use:   "events",
+	Short: "Apply provider events missed by the webhook endpoint",
+	Long:  "Page through the provider's event list since --since and apply every event that was not processed yet through provider callback handling. Prints a summary of the payments that changed.",
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		since, err := time.Parse(time.RFC3339, strings.TrimSpace(backfillSince))
+		if err != nil {
+			return fmt.Errorf("--since must be an RFC3339 timestamp: %w", err)
+		}
+
+		_, paymentService, cleanup := mustCreatePaymentService()
+		defer cleanup()
+
+		summary, err := paymentService.BackfillProviderEvents(context.Background(), backfillProvider, since)
+		if summary != nil {
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "fetched=%d applied=%d already_processed=%d unmatched=%d failed=%d changed=%d\n",
+				summary.Fetched, summary.Applied, summary.AlreadyProcessed, summary.Unmatched, summary.Failed, len(summary.Changes))
+			for _, change := range summary.Changes {
+				fmt.Fprintf(out, "payment=%d event=%s type=%s status=%s->%s\n",
+					change.PaymentID,
+					change.ProviderEventID,
+					change.EventType,
+					types.PaymentStatus(change.OldStatus).String(),
+					types.PaymentStatus(change.NewStatus).String(),
+				)
+			}
+		}
+		return err
+	},
+}
+
+var reconcileProvisionalCmd = &cobra.Command{
+	Use:   "provisional",
+	Short: "Resume or abandon payments whose provider create was interrupted",
+	Run: func(_ *cobra.Command, _ []string) {
+		runCommand(
+			"reconcile_provisional",
+			func(cfg *config.Config) time.Duration { return cfg.Jobs.ProvisionalSweepInterval },
+			func(s *service.PaymentService, ctx context.Context) error {
+				return s.RunSweepProvisionalBatch(ctx)
+			},
+		)
+	},
+}
+
 var callbacksCmd = &cobra.Command{
 	Use:   "callbacks",
 	Short: "Run status callback related commands",
@@ -50,6 +107,33 @@ var callbacksDispatchCmd = &cobra.Command{
 	},
 }
 
+var callbacksRedeliverCmd = &cobra.Command{
+	Use:   "redeliver",
+	Short: "Reset dead-lettered callbacks back to pending",
+	Long:  "Reset dead-lettered callbacks back to pending. Select them with --payment-id, --caller-service and --event-type, or pass --all.",
+	RunE: func(_ *cobra.Command, _ []string) error {
+		req := &types.RedeliverCallbacksRequest{
+			PaymentId:     redeliverPaymentID,
+			CallerService: strings.TrimSpace(redeliverCallerService),
+			EventType:     strings.TrimSpace(redeliverEventType),
+			All:           redeliverAll,
+		}
+		if err := req.Validate(); err != nil {
+			return err
+		}
+
+		_, paymentService, cleanup := mustCreatePaymentService()
+		defer cleanup()
+
+		count, err := paymentService.RedeliverCallbacks(context.Background(), req)
+		if err != nil {
+			return err
+		}
+		logrus.WithField("job", "callbacks_redeliver").WithField("redelivered", count).Info("callbacks_redelivered")
+		return nil
+	},
+}
+
 var expireCmd = &cobra.Command{
 	Use:   "expire",
 	Short: "Run expiration-related commands",
@@ -71,12 +155,24 @@ var expirePendingCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(reconcileCmd)
+	reconcileCmd.AddCommand(reconcileEventsCmd)
+	reconcileCmd.AddCommand(reconcileProvisionalCmd)
 	rootCmd.AddCommand(callbacksCmd)
 	rootCmd.AddCommand(expireCmd)
 	callbacksCmd.AddCommand(callbacksDispatchCmd)
+	callbacksCmd.AddCommand(callbacksRedeliverCmd)
 	expireCmd.AddCommand(expirePendingCmd)
 
 	rootCmd.PersistentFlags().BoolVar(&workerMode, "worker", false, "Run continuously using configured interval")
+
+	reconcileEventsCmd.Flags().StringVar(&backfillSince, "since", "", "Apply events created at or after this RFC3339 time")
+	reconcileEventsCmd.Flags().StringVar(&backfillProvider, "provider", "stripe", "Provider to read events from")
+	_ = reconcileEventsCmd.MarkFlagRequired("since")
+
+	callbacksRedeliverCmd.Flags().Uint64Var(&redeliverPaymentID, "payment-id", 0, "Redeliver dead-lettered callbacks of this payment")
+	callbacksRedeliverCmd.Flags().StringVar(&redeliverCallerService, "caller-service", "", "Redeliver dead-lettered callbacks of this caller service")
+	callbacksRedeliverCmd.Flags().StringVar(&redeliverEventType, "event-type", "", "Redeliver dead-lettered callbacks of this event type")
+	callbacksRedeliverCmd.Flags().BoolVar(&redeliverAll, "all", false, "Redeliver every dead-lettered callback")
 }
 
 func runCommand(
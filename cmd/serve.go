@@ -19,10 +19,13 @@ import (
 	"github.com/vibast-solutions/ms-go-payments/app/provider"
 	"github.com/vibast-solutions/ms-go-payments/app/repository"
 	"github.com/vibast-solutions/ms-go-payments/app/service"
+	"github.com/vibast-solutions/ms-go-payments/app/sink"
 	"github.com/vibast-solutions/ms-go-payments/app/types"
 	"github.com/vibast-solutions/ms-go-payments/config"
 
 	_ "github.com/go-sql-driver/mysql"
+	"github.com/google/uuid"
+	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/labstack/echo/v4"
 	echomiddleware "github.com/labstack/echo/v4/middleware"
 	"github.com/sirupsen/logrus"
@@ -59,6 +62,7 @@ func runServe(_ *cobra.Command, _ []string) {
 	grpcInternalAuthMiddleware := authmiddleware.NewGRPCInternalAuthMiddleware(internalAuthService)
 
 	e := setupHTTPServer(paymentController, echoInternalAuthMiddleware, cfg.App.ServiceName)
+	webhookServer := setupWebhookServer(paymentController, cfg.Webhook.BodyLimit)
 	grpcSrv, lis := setupGRPCServer(cfg, grpcPaymentServer, grpcInternalAuthMiddleware, cfg.App.ServiceName)
 
 	go func() {
@@ -69,6 +73,14 @@ func runServe(_ *cobra.Command, _ []string) {
 		}
 	}()
 
+	go func() {
+		webhookAddr := net.JoinHostPort(cfg.Webhook.Host, cfg.Webhook.Port)
+		logrus.WithField("addr", webhookAddr).Info("Starting webhook HTTP server")
+		if err := webhookServer.Start(webhookAddr); err != nil && err != http.ErrServerClosed {
+			logrus.WithError(err).Fatal("Webhook HTTP server error")
+		}
+	}()
+
 	go func() {
 		logrus.WithField("addr", lis.Addr().String()).Info("Starting gRPC server")
 		if err := grpcSrv.Serve(lis); err != nil {
@@ -87,6 +99,9 @@ func runServe(_ *cobra.Command, _ []string) {
 	if err := e.Shutdown(shutdownCtx); err != nil {
 		logrus.WithError(err).Warn("HTTP shutdown error")
 	}
+	if err := webhookServer.Shutdown(shutdownCtx); err != nil {
+		logrus.WithError(err).Warn("Webhook HTTP shutdown error")
+	}
 	grpcSrv.GracefulStop()
 
 	logrus.Info("Server stopped")
@@ -100,7 +115,67 @@ func setupHTTPServer(
 	e := echo.New()
 	e.HideBanner = true
 
-	e.Use(echomiddleware.RequestLoggerWithConfig(echomiddleware.RequestLoggerConfig{
+	e.Use(requestLogger())
+	e.Use(echomiddleware.Recover())
+	e.Use(echomiddleware.CORS())
+	e.Use(requireRequestID())
+	e.Use(internalAuthMiddleware.RequireInternalAccess(appServiceName))
+	e.Use(carryCallerService())
+
+	e.GET("/health", paymentController.Health)
+
+	payments := e.Group("/payments")
+	payments.POST("", paymentController.CreatePayment)
+	payments.GET("", paymentController.ListPayments)
+	payments.GET("/by-request-id", paymentController.GetPaymentByRequestID)
+	payments.GET("/by-provider-ref", paymentController.GetPaymentByProviderRef)
+	payments.GET("/:id", paymentController.GetPayment)
+	payments.POST("/:id/cancel", paymentController.CancelPayment)
+	payments.POST("/:id/refunds", paymentController.RefundPayment)
+	payments.GET("/:id/charges", paymentController.ListPaymentCharges)
+	payments.GET("/:id/events", paymentController.ListPaymentEvents)
+	payments.POST("/:id/subscription/pause", paymentController.PauseSubscription)
+	payments.POST("/:id/subscription/resume", paymentController.ResumeSubscription)
+	payments.POST("/:id/subscription/cancel", paymentController.CancelSubscription)
+	payments.PATCH("/:id/subscription", paymentController.UpdateSubscription)
+
+	callbacks := e.Group("/callbacks")
+	callbacks.GET("/dead-letters", paymentController.ListDeadLetterCallbacks)
+	callbacks.POST("/redeliver", paymentController.RedeliverCallbacks)
+	callbacks.GET("/inbox", paymentController.ListProviderCallbacks)
+	callbacks.POST("/inbox/:id/replay", paymentController.ReplayProviderCallback)
+
+	// Internal relays post {"payload","signature"} envelopes here; the public
+	// webhook listener only accepts raw provider bodies.
+	forwarded := e.Group("/webhooks/forwarded")
+	forwarded.POST("/:provider/:hash", paymentController.ForwardProviderCallback)
+
+	return e
+}
+
+// setupWebhookServer builds the public listener that receives provider
+// webhooks. Providers cannot send our API key or a request ID, so the routes
+// here are authenticated only by the provider signature and the unguessable
+// callback hash, and a request ID is generated when none is supplied.
+func setupWebhookServer(paymentController *controller.PaymentController, bodyLimit string) *echo.Echo {
+	e := echo.New()
+	e.HideBanner = true
+
+	e.Use(requestLogger())
+	e.Use(echomiddleware.Recover())
+	e.Use(echomiddleware.BodyLimit(bodyLimit))
+	e.Use(ensureRequestID())
+
+	e.GET("/health", paymentController.Health)
+
+	webhooks := e.Group("/webhooks/providers")
+	webhooks.POST("/:provider/:hash", paymentController.HandleProviderCallback)
+
+	return e
+}
+
+func requestLogger() echo.MiddlewareFunc {
+	return echomiddleware.RequestLoggerWithConfig(echomiddleware.RequestLoggerConfig{
 		LogURI:       true,
 		LogStatus:    true,
 		LogMethod:    true,
@@ -128,24 +203,7 @@ func setupHTTPServer(
 			entry.Info("http_request")
 			return nil
 		},
-	}))
-	e.Use(echomiddleware.Recover())
-	e.Use(echomiddleware.CORS())
-	e.Use(requireRequestID())
-	e.Use(internalAuthMiddleware.RequireInternalAccess(appServiceName))
-
-	e.GET("/health", paymentController.Health)
-
-	payments := e.Group("/payments")
-	payments.POST("", paymentController.CreatePayment)
-	payments.GET("", paymentController.ListPayments)
-	payments.GET("/:id", paymentController.GetPayment)
-	payments.POST("/:id/cancel", paymentController.CancelPayment)
-
-	webhooks := e.Group("/webhooks/providers")
-	webhooks.POST("/:provider/:hash", paymentController.HandleProviderCallback)
-
-	return e
+	})
 }
 
 func requireRequestID() echo.MiddlewareFunc {
@@ -161,6 +219,35 @@ func requireRequestID() echo.MiddlewareFunc {
 	}
 }
 
+func ensureRequestID() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			requestID := strings.TrimSpace(ctx.Request().Header.Get(echo.HeaderXRequestID))
+			if requestID == "" {
+				requestID = uuid.NewString()
+				ctx.Request().Header.Set(echo.HeaderXRequestID, requestID)
+			}
+			ctx.Response().Header().Set(echo.HeaderXRequestID, requestID)
+			return next(ctx)
+		}
+	}
+}
+
+// carryCallerService copies the caller service resolved by the internal auth
+// middleware into the request context, where the service layer scopes payment
+// access to it.
+func carryCallerService() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			if callerService, err := authmiddleware.CallerServiceFromContext(ctx); err == nil {
+				req := ctx.Request()
+				ctx.SetRequest(req.WithContext(service.WithCallerService(req.Context(), callerService)))
+			}
+			return next(ctx)
+		}
+	}
+}
+
 func setupGRPCServer(
 	cfg *config.Config,
 	paymentServer *paymentgrpc.Server,
@@ -179,6 +266,14 @@ func setupGRPCServer(
 			paymentgrpc.RequestIDInterceptor(),
 			paymentgrpc.LoggingInterceptor(),
 			internalAuthMiddleware.UnaryRequireInternalAccess(appServiceName),
+			paymentgrpc.CallerInterceptor(),
+		),
+		grpc.ChainStreamInterceptor(
+			paymentgrpc.StreamRecoveryInterceptor(),
+			paymentgrpc.StreamRequestIDInterceptor(),
+			paymentgrpc.StreamLoggingInterceptor(),
+			internalAuthMiddleware.StreamRequireInternalAccess(appServiceName),
+			paymentgrpc.StreamCallerInterceptor(),
 		),
 	)
 	types.RegisterPaymentsServiceServer(grpcSrv, paymentServer)
@@ -195,43 +290,72 @@ func mustCreatePaymentService() (*config.Config, *service.PaymentService, func()
 		logrus.WithError(err).Fatal("Failed to configure logging")
 	}
 
-	db, err := sql.Open("mysql", cfg.MySQL.DSN)
+	dialect, err := repository.NewDialect(cfg.Database.Driver)
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to select database dialect")
+	}
+
+	db, err := sql.Open(dialect.DriverName(), cfg.Database.DSN)
 	if err != nil {
 		logrus.WithError(err).Fatal("Failed to connect to database")
 	}
 
-	db.SetMaxOpenConns(cfg.MySQL.MaxOpenConns)
-	db.SetMaxIdleConns(cfg.MySQL.MaxIdleConns)
-	db.SetConnMaxLifetime(cfg.MySQL.ConnMaxLifetime)
+	db.SetMaxOpenConns(cfg.Database.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.Database.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.Database.ConnMaxLifetime)
 
 	if err := db.Ping(); err != nil {
 		_ = db.Close()
 		logrus.WithError(err).Fatal("Failed to ping database")
 	}
 
-	paymentRepo := repository.NewPaymentRepository(db)
-	eventRepo := repository.NewPaymentEventRepository(db)
-	callbackRepo := repository.NewPaymentCallbackRepository(db)
+	paymentRepo := repository.NewPaymentRepository(db, dialect)
+	eventRepo := repository.NewPaymentEventRepository(db, dialect)
+	callbackRepo := repository.NewPaymentCallbackRepository(db, dialect)
+	refundRepo := repository.NewPaymentRefundRepository(db, dialect)
+	chargeRepo := repository.NewPaymentChargeRepository(db, dialect)
+	providerEventRepo := repository.NewPaymentProviderEventRepository(db, dialect)
+	outboxRepo := repository.NewPaymentCallbackOutboxRepository(db, dialect)
+	uow := repository.NewUnitOfWork(db)
 
 	stripeProvider := provider.NewStripeProvider(provider.StripeConfig{
+		BaseURL:                   cfg.Stripe.APIBaseURL,
 		SecretKey:                 cfg.Stripe.SecretKey,
 		WebhookSecret:             cfg.Stripe.WebhookSecret,
 		ProviderCallbackBaseURL:   cfg.Stripe.ProviderCallbackBaseURL,
 		SignatureToleranceSeconds: cfg.Stripe.SignatureToleranceSeconds,
 		HTTPTimeout:               cfg.Stripe.HTTPTimeout,
+		MaxNetworkRetries:         cfg.Stripe.MaxNetworkRetries,
 	})
 
 	providerRegistry := provider.NewRegistry(stripeProvider)
+
+	grpcSink := sink.NewGRPCSink(cfg.App.APIKey, cfg.Payments.CallbackHTTPTimeout)
+	sinkRegistry := sink.NewRegistry(
+		sink.NewHTTPSink(&http.Client{Timeout: cfg.Payments.CallbackHTTPTimeout}, cfg.App.APIKey),
+		grpcSink,
+		sink.NewBrokerSink("nats", sink.NewNATSPublisher(cfg.Payments.CallbackNATSAddr, cfg.Payments.CallbackHTTPTimeout)),
+		sink.NewBrokerSink("redis", sink.NewRedisPublisher(cfg.Payments.CallbackRedisAddr, cfg.Payments.CallbackHTTPTimeout)),
+	)
+
 	paymentService := service.NewPaymentService(
 		paymentRepo,
 		eventRepo,
 		callbackRepo,
+		refundRepo,
+		chargeRepo,
+		providerEventRepo,
+		outboxRepo,
+		uow,
 		providerRegistry,
+		sinkRegistry,
 		cfg.Payments,
-		cfg.App.APIKey,
 	)
 
 	cleanup := func() {
+		if err := grpcSink.Close(); err != nil {
+			logrus.WithError(err).Warn("Failed to close callback connections")
+		}
 		if err := db.Close(); err != nil {
 			logrus.WithError(err).Warn("Failed to close database")
 		}
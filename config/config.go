@@ -2,8 +2,10 @@ package config
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -13,7 +15,8 @@ type Config struct {
 	App               AppConfig
 	HTTP              ServerConfig
 	GRPC              ServerConfig
-	MySQL             MySQLConfig
+	Webhook           WebhookConfig
+	Database          DatabaseConfig
 	Log               LogConfig
 	InternalEndpoints InternalEndpointsConfig
 	Stripe            StripeConfig
@@ -31,7 +34,20 @@ type ServerConfig struct {
 	Port string
 }
 
-type MySQLConfig struct {
+// WebhookConfig is the public listener for provider webhooks. It sits outside
+// the internal API-key middleware; requests are authenticated by the provider
+// signature and the callback hash only.
+type WebhookConfig struct {
+	Host string
+	Port string
+	// BodyLimit uses the echo size format, e.g. "256K" or "1M".
+	BodyLimit string
+}
+
+// DatabaseConfig selects the SQL backend. Driver is "mysql" or "postgres";
+// the DSN uses that driver's format.
+type DatabaseConfig struct {
+	Driver          string
 	DSN             string
 	MaxOpenConns    int
 	MaxIdleConns    int
@@ -47,34 +63,65 @@ type InternalEndpointsConfig struct {
 }
 
 type StripeConfig struct {
+	APIBaseURL                string
 	SecretKey                 string
 	WebhookSecret             string
 	ProviderCallbackBaseURL   string
 	SignatureToleranceSeconds int64
 	HTTPTimeout               time.Duration
+	MaxNetworkRetries         int
 }
 
 type PaymentsConfig struct {
 	CallbackMaxAttempts   int32
 	CallbackRetryInterval time.Duration
-	CallbackHTTPTimeout   time.Duration
-	PendingTimeout        time.Duration
-	ReconcileStaleAfter   time.Duration
-	JobBatchSize          int32
+	// CallbackRetryMaxInterval caps the exponential retry backoff.
+	CallbackRetryMaxInterval   time.Duration
+	CallbackRetryJitterPercent int
+	CallbackHTTPTimeout        time.Duration
+	CallbackNATSAddr           string
+	CallbackRedisAddr          string
+	// CallbackSigningSecrets maps a caller service to its active callback
+	// signing secrets, newest first; at most two are active during a rotation.
+	CallbackSigningSecrets map[string][]string
+	PendingTimeout         time.Duration
+	ReconcileStaleAfter    time.Duration
+	// ProvisionalStaleAfter is how long a payment may stay provisional (stored
+	// but not yet created at the provider) before the sweeper picks it up;
+	// after ProvisionalAbandonAfter the sweeper fails it instead of resuming.
+	ProvisionalStaleAfter   time.Duration
+	ProvisionalAbandonAfter time.Duration
+	JobBatchSize            int32
+	// WatchPollInterval is how often WatchPayment streams poll payment_events
+	// for new changes.
+	WatchPollInterval time.Duration
+	// AdminServices may read and manage every caller's payments; any other
+	// caller only sees the payments created under its own service name.
+	AdminServices []string
 }
 
 type JobsConfig struct {
 	ReconcileInterval       time.Duration
 	CallbackDispatchInterval time.Duration
 	ExpirePendingInterval    time.Duration
+	ProvisionalSweepInterval time.Duration
 }
 
 func Load() (*Config, error) {
 	_ = godotenv.Load()
 
-	mysqlDSN := os.Getenv("MYSQL_DSN")
-	if mysqlDSN == "" {
-		return nil, errors.New("MYSQL_DSN environment variable is required")
+	dbDriver := getEnv("DB_DRIVER", "mysql")
+	if dbDriver != "mysql" && dbDriver != "postgres" {
+		return nil, fmt.Errorf("DB_DRIVER: unsupported driver %q", dbDriver)
+	}
+	dbDSN := os.Getenv(dbEnv("DSN"))
+	if dbDSN == "" {
+		return nil, errors.New("DB_DSN environment variable is required")
+	}
+
+	signingSecrets, err := parseSigningSecrets(os.Getenv("PAYMENTS_CALLBACK_SIGNING_SECRETS"))
+	if err != nil {
+		return nil, err
 	}
 
 	return &Config{
@@ -90,11 +137,17 @@ func Load() (*Config, error) {
 			Host: getEnv("GRPC_HOST", "0.0.0.0"),
 			Port: getEnv("GRPC_PORT", "9090"),
 		},
-		MySQL: MySQLConfig{
-			DSN:             mysqlDSN,
-			MaxOpenConns:    getIntEnv("MYSQL_MAX_OPEN_CONNS", 10),
-			MaxIdleConns:    getIntEnv("MYSQL_MAX_IDLE_CONNS", 5),
-			ConnMaxLifetime: getMinutesEnv("MYSQL_CONN_MAX_LIFETIME_MINUTES", 30*time.Minute),
+		Webhook: WebhookConfig{
+			Host:      getEnv("WEBHOOK_HTTP_HOST", "0.0.0.0"),
+			Port:      getEnv("WEBHOOK_HTTP_PORT", "8081"),
+			BodyLimit: getEnv("WEBHOOK_BODY_LIMIT", "256K"),
+		},
+		Database: DatabaseConfig{
+			Driver:          dbDriver,
+			DSN:             dbDSN,
+			MaxOpenConns:    getIntEnv(dbEnv("MAX_OPEN_CONNS"), 10),
+			MaxIdleConns:    getIntEnv(dbEnv("MAX_IDLE_CONNS"), 5),
+			ConnMaxLifetime: getMinutesEnv(dbEnv("CONN_MAX_LIFETIME_MINUTES"), 30*time.Minute),
 		},
 		Log: LogConfig{
 			Level: getEnv("LOG_LEVEL", "info"),
@@ -103,24 +156,36 @@ func Load() (*Config, error) {
 			AuthGRPCAddr: getEnv("AUTH_SERVICE_GRPC_ADDR", "localhost:9090"),
 		},
 		Stripe: StripeConfig{
+			APIBaseURL:                getEnv("STRIPE_API_BASE_URL", "https://api.stripe.com"),
 			SecretKey:                 getEnv("STRIPE_SECRET_KEY", ""),
 			WebhookSecret:             getEnv("STRIPE_WEBHOOK_SECRET", ""),
 			ProviderCallbackBaseURL:   getEnv("PAYMENTS_PROVIDER_CALLBACK_BASE_URL", ""),
 			SignatureToleranceSeconds: int64(getIntEnv("STRIPE_SIGNATURE_TOLERANCE_SECONDS", 300)),
 			HTTPTimeout:               getSecondsEnv("STRIPE_HTTP_TIMEOUT_SECONDS", 10*time.Second),
+			MaxNetworkRetries:         getIntEnv("STRIPE_MAX_NETWORK_RETRIES", 2),
 		},
 		Payments: PaymentsConfig{
-			CallbackMaxAttempts:   int32(getIntEnv("PAYMENTS_CALLBACK_MAX_ATTEMPTS", 10)),
-			CallbackRetryInterval: getMinutesEnv("PAYMENTS_CALLBACK_RETRY_INTERVAL_MINUTES", 5*time.Minute),
-			CallbackHTTPTimeout:   getSecondsEnv("PAYMENTS_CALLBACK_HTTP_TIMEOUT_SECONDS", 10*time.Second),
-			PendingTimeout:        getMinutesEnv("PAYMENTS_PENDING_TIMEOUT_MINUTES", 60*time.Minute),
-			ReconcileStaleAfter:   getMinutesEnv("PAYMENTS_RECONCILE_STALE_AFTER_MINUTES", 15*time.Minute),
-			JobBatchSize:          int32(getIntEnv("PAYMENTS_JOB_BATCH_SIZE", 100)),
+			CallbackMaxAttempts:        int32(getIntEnv("PAYMENTS_CALLBACK_MAX_ATTEMPTS", 10)),
+			CallbackRetryInterval:      getMinutesEnv("PAYMENTS_CALLBACK_RETRY_INTERVAL_MINUTES", 5*time.Minute),
+			CallbackRetryMaxInterval:   getMinutesEnv("PAYMENTS_CALLBACK_RETRY_MAX_INTERVAL_MINUTES", 6*time.Hour),
+			CallbackRetryJitterPercent: getIntEnv("PAYMENTS_CALLBACK_RETRY_JITTER_PERCENT", 20),
+			CallbackHTTPTimeout:        getSecondsEnv("PAYMENTS_CALLBACK_HTTP_TIMEOUT_SECONDS", 10*time.Second),
+			CallbackNATSAddr:           getEnv("PAYMENTS_CALLBACK_NATS_ADDR", ""),
+			CallbackRedisAddr:          getEnv("PAYMENTS_CALLBACK_REDIS_ADDR", ""),
+			CallbackSigningSecrets:     signingSecrets,
+			PendingTimeout:             getMinutesEnv("PAYMENTS_PENDING_TIMEOUT_MINUTES", 60*time.Minute),
+			ReconcileStaleAfter:        getMinutesEnv("PAYMENTS_RECONCILE_STALE_AFTER_MINUTES", 15*time.Minute),
+			ProvisionalStaleAfter:      getMinutesEnv("PAYMENTS_PROVISIONAL_STALE_AFTER_MINUTES", 5*time.Minute),
+			ProvisionalAbandonAfter:    getMinutesEnv("PAYMENTS_PROVISIONAL_ABANDON_AFTER_MINUTES", 60*time.Minute),
+			JobBatchSize:               int32(getIntEnv("PAYMENTS_JOB_BATCH_SIZE", 100)),
+			WatchPollInterval:          getSecondsEnv("PAYMENTS_WATCH_POLL_INTERVAL_SECONDS", 2*time.Second),
+			AdminServices:              getListEnv("PAYMENTS_ADMIN_SERVICES"),
 		},
 		Jobs: JobsConfig{
 			ReconcileInterval:        getMinutesEnv("PAYMENTS_RECONCILE_INTERVAL_MINUTES", 2*time.Minute),
 			CallbackDispatchInterval: getMinutesEnv("PAYMENTS_CALLBACK_DISPATCH_INTERVAL_MINUTES", time.Minute),
 			ExpirePendingInterval:    getMinutesEnv("PAYMENTS_EXPIRE_PENDING_INTERVAL_MINUTES", 5*time.Minute),
+			ProvisionalSweepInterval: getMinutesEnv("PAYMENTS_PROVISIONAL_SWEEP_INTERVAL_MINUTES", 5*time.Minute),
 		},
 	}, nil
 }
@@ -141,6 +206,25 @@ func getIntEnv(key string, defaultValue int) int {
 	return defaultValue
 }
 
+// dbEnv names a database variable: DB_<suffix> when it is set, otherwise the
+// MYSQL_<suffix> name older deployments still use.
+func dbEnv(suffix string) string {
+	if _, ok := os.LookupEnv("DB_" + suffix); ok {
+		return "DB_" + suffix
+	}
+	return "MYSQL_" + suffix
+}
+
+func getListEnv(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
 func getMinutesEnv(key string, defaultValue time.Duration) time.Duration {
 	if value := os.Getenv(key); value != "" {
 		if minutes, err := strconv.Atoi(value); err == nil {
@@ -158,3 +242,30 @@ func getSecondsEnv(key string, defaultValue time.Duration) time.Duration {
 	}
 	return defaultValue
 }
+
+// parseSigningSecrets reads "caller-a=new|old,caller-b=secret".
+func parseSigningSecrets(value string) (map[string][]string, error) {
+	result := make(map[string][]string)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		service, rawSecrets, ok := strings.Cut(entry, "=")
+		service = strings.TrimSpace(service)
+		if !ok || service == "" {
+			return nil, fmt.Errorf("PAYMENTS_CALLBACK_SIGNING_SECRETS: invalid entry %q", entry)
+		}
+		secrets := make([]string, 0, 2)
+		for _, secret := range strings.Split(rawSecrets, "|") {
+			if secret = strings.TrimSpace(secret); secret != "" {
+				secrets = append(secrets, secret)
+			}
+		}
+		if len(secrets) == 0 || len(secrets) > 2 {
+			return nil, fmt.Errorf("PAYMENTS_CALLBACK_SIGNING_SECRETS: %s must have one or two secrets", service)
+		}
+		result[service] = secrets
+	}
+	return result, nil
+}
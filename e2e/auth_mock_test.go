@@ -12,6 +12,7 @@ import (
 	"testing"
 
 	authpb "github.com/vibast-solutions/ms-go-auth/app/types"
+	"github.com/vibast-solutions/ms-go-payments/app/provider/stripefake"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
@@ -21,10 +22,18 @@ import (
 const (
 	defaultPaymentsCallerAPIKey   = "payments-caller-key"
 	defaultPaymentsNoAccessAPIKey = "payments-no-access-key"
+	defaultPaymentsOrdersAPIKey   = "payments-orders-key"
 	defaultPaymentsAppAPIKey      = "payments-app-api-key"
 	paymentsAuthMockAddr          = "0.0.0.0:38084"
+	paymentsStripeMockAddr        = "0.0.0.0:38085"
+	paymentsStripeSecretKey       = "sk_test_e2e"
+	paymentsStripeWebhookSecret   = "whsec_e2e"
 )
 
+// fakeStripe serves the Stripe API to the service container and sends signed
+// webhooks to its public webhook listener.
+var fakeStripe *stripefake.Server
+
 func paymentsCallerAPIKey() string {
 	if value := strings.TrimSpace(os.Getenv("PAYMENTS_CALLER_API_KEY")); value != "" {
 		return value
@@ -39,6 +48,15 @@ func paymentsNoAccessAPIKey() string {
 	return defaultPaymentsNoAccessAPIKey
 }
 
+// paymentsOrdersAPIKey identifies a non-admin caller that may only see the
+// payments it created itself.
+func paymentsOrdersAPIKey() string {
+	if value := strings.TrimSpace(os.Getenv("PAYMENTS_ORDERS_API_KEY")); value != "" {
+		return value
+	}
+	return defaultPaymentsOrdersAPIKey
+}
+
 func paymentsAppAPIKey() string {
 	if value := strings.TrimSpace(os.Getenv("PAYMENTS_APP_API_KEY")); value != "" {
 		return value
@@ -62,6 +80,11 @@ func (s *paymentsAuthGRPCServer) ValidateInternalAccess(ctx context.Context, req
 			ServiceName:   "payments-gateway",
 			AllowedAccess: []string{"payments-service", "subscriptions-service", "notifications-service", "profile-service"},
 		}, nil
+	case paymentsOrdersAPIKey():
+		return &authpb.ValidateInternalAccessResponse{
+			ServiceName:   "orders-service",
+			AllowedAccess: []string{"payments-service"},
+		}, nil
 	case paymentsNoAccessAPIKey():
 		return &authpb.ValidateInternalAccessResponse{
 			ServiceName:   "payments-gateway",
@@ -91,6 +114,9 @@ func TestMain(m *testing.M) {
 	if os.Getenv("PAYMENTS_NO_ACCESS_API_KEY") == "" {
 		_ = os.Setenv("PAYMENTS_NO_ACCESS_API_KEY", defaultPaymentsNoAccessAPIKey)
 	}
+	if os.Getenv("PAYMENTS_ORDERS_API_KEY") == "" {
+		_ = os.Setenv("PAYMENTS_ORDERS_API_KEY", defaultPaymentsOrdersAPIKey)
+	}
 	if os.Getenv("PAYMENTS_APP_API_KEY") == "" {
 		_ = os.Setenv("PAYMENTS_APP_API_KEY", defaultPaymentsAppAPIKey)
 	}
@@ -101,6 +127,21 @@ func TestMain(m *testing.M) {
 		os.Exit(1)
 	}
 
+	webhookBase := os.Getenv("PAYMENTS_WEBHOOK_URL")
+	if webhookBase == "" {
+		webhookBase = defaultPaymentsWebhookBase
+	}
+	fakeStripe, err = stripefake.New(stripefake.Config{
+		Addr:           paymentsStripeMockAddr,
+		SecretKey:      paymentsStripeSecretKey,
+		WebhookSecret:  paymentsStripeWebhookSecret,
+		WebhookBaseURL: webhookBase + "/webhooks/providers/stripe",
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to start payments stripe mock: %v\n", err)
+		os.Exit(1)
+	}
+
 	grpcServer := grpc.NewServer()
 	authpb.RegisterAuthServiceServer(grpcServer, &paymentsAuthGRPCServer{})
 
@@ -112,6 +153,7 @@ func TestMain(m *testing.M) {
 
 	grpcServer.GracefulStop()
 	_ = listener.Close()
+	fakeStripe.Close()
 
 	os.Exit(exitCode)
 }
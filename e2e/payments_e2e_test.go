@@ -13,6 +13,7 @@ import (
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -25,8 +26,9 @@ import (
 )
 
 const (
-	defaultPaymentsHTTPBase = "http://localhost:48080"
-	defaultPaymentsGRPCAddr = "localhost:49090"
+	defaultPaymentsHTTPBase    = "http://localhost:48080"
+	defaultPaymentsGRPCAddr    = "localhost:49090"
+	defaultPaymentsWebhookBase = "http://localhost:48081"
 )
 
 type httpClient struct {
@@ -165,6 +167,10 @@ func TestPaymentsE2E(t *testing.T) {
 	if grpcAddr == "" {
 		grpcAddr = defaultPaymentsGRPCAddr
 	}
+	webhookBase := os.Getenv("PAYMENTS_WEBHOOK_URL")
+	if webhookBase == "" {
+		webhookBase = defaultPaymentsWebhookBase
+	}
 
 	if err := waitForHTTP(httpBase, 30*time.Second); err != nil {
 		t.Fatalf("http not ready: %v", err)
@@ -174,6 +180,7 @@ func TestPaymentsE2E(t *testing.T) {
 	}
 
 	client := newHTTPClient(httpBase)
+	webhookClient := newHTTPClient(webhookBase)
 
 	conn := dialPaymentsGRPC(t, grpcAddr)
 	defer conn.Close()
@@ -270,12 +277,219 @@ func TestPaymentsE2E(t *testing.T) {
 
 	t.Run("HTTPCallbackValidation", func(t *testing.T) {
 		path := "/webhooks/providers/stripe/test-hash"
-		resp, body := client.doJSON(t, http.MethodPost, path, map[string]any{"payload": "{}"})
+		resp, body := webhookClient.doJSON(t, http.MethodPost, path, map[string]any{"payload": "{}"})
 		if resp.StatusCode != http.StatusBadRequest {
 			t.Fatalf("expected 400, got %d body=%s", resp.StatusCode, string(body))
 		}
 	})
 
+	t.Run("HTTPWebhookWithoutAPIKey", func(t *testing.T) {
+		// The public webhook listener never asks for the internal API key.
+		path := "/webhooks/providers/stripe/test-hash"
+		resp, body := webhookClient.doJSONWithAPIKey(t, http.MethodPost, path, map[string]any{"id": "evt_1"}, "")
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Fatalf("expected 400 for unsigned webhook, got %d body=%s", resp.StatusCode, string(body))
+		}
+		if resp.Header.Get("X-Request-ID") == "" {
+			t.Fatal("expected webhook response to carry a request id")
+		}
+	})
+
+	t.Run("HTTPWebhookNotOnInternalListener", func(t *testing.T) {
+		path := "/webhooks/providers/stripe/test-hash"
+		resp, body := client.doJSON(t, http.MethodPost, path, map[string]any{"payload": "{}"})
+		if resp.StatusCode != http.StatusNotFound {
+			t.Fatalf("expected 404, got %d body=%s", resp.StatusCode, string(body))
+		}
+	})
+
+	t.Run("GRPCHostedCardPaidByStripeWebhook", func(t *testing.T) {
+		created, err := grpcClient.CreatePayment(context.Background(), &types.CreatePaymentRequest{
+			RequestId:         fmt.Sprintf("e2e-stripe-%d", time.Now().UnixNano()),
+			CallerService:     "subscriptions-service",
+			ResourceType:      "order",
+			ResourceId:        "e2e-1",
+			AmountCents:       1500,
+			Currency:          "EUR",
+			PaymentMethod:     types.PaymentMethod_PAYMENT_METHOD_HOSTED_CARD,
+			PaymentType:       types.PaymentType_PAYMENT_TYPE_ONE_TIME,
+			StatusCallbackUrl: "http://localhost:1/status",
+		})
+		if err != nil {
+			t.Fatalf("grpc create payment failed: %v", err)
+		}
+		payment := created.GetPayment()
+		if payment.GetStatus() != types.PaymentStatus_PAYMENT_STATUS_PENDING || payment.GetProviderPaymentId() == "" {
+			t.Fatalf("expected pending payment with a checkout session, got %+v", payment)
+		}
+
+		eventID, err := fakeStripe.CompleteCheckoutSession(payment.GetProviderPaymentId())
+		if err != nil {
+			t.Fatalf("complete checkout session failed: %v", err)
+		}
+		if err := fakeStripe.SendWebhook(context.Background(), eventID); err != nil {
+			t.Fatalf("send webhook failed: %v", err)
+		}
+
+		res, err := grpcClient.GetPayment(context.Background(), &types.GetPaymentRequest{Id: payment.GetId()})
+		if err != nil {
+			t.Fatalf("grpc get payment failed: %v", err)
+		}
+		if res.GetPayment().GetStatus() != types.PaymentStatus_PAYMENT_STATUS_PAID {
+			t.Fatalf("expected paid after webhook, got %s", res.GetPayment().GetStatus())
+		}
+	})
+
+	t.Run("GRPCWatchPaymentUntilPaid", func(t *testing.T) {
+		created, err := grpcClient.CreatePayment(context.Background(), &types.CreatePaymentRequest{
+			RequestId:         fmt.Sprintf("e2e-watch-%d", time.Now().UnixNano()),
+			CallerService:     "subscriptions-service",
+			ResourceType:      "order",
+			ResourceId:        "e2e-5",
+			AmountCents:       1300,
+			Currency:          "EUR",
+			PaymentMethod:     types.PaymentMethod_PAYMENT_METHOD_HOSTED_CARD,
+			PaymentType:       types.PaymentType_PAYMENT_TYPE_ONE_TIME,
+			StatusCallbackUrl: "http://localhost:1/status",
+		})
+		if err != nil {
+			t.Fatalf("grpc create payment failed: %v", err)
+		}
+		payment := created.GetPayment()
+
+		ctx, cancel := context.WithTimeout(grpcContextWithHeaders(paymentsCallerAPIKey(), fmt.Sprintf("e2e-grpc-watch-%d", time.Now().UnixNano())), 30*time.Second)
+		defer cancel()
+		stream, err := rawGRPCClient.WatchPayment(ctx, &types.WatchPaymentRequest{Id: payment.GetId()})
+		if err != nil {
+			t.Fatalf("grpc watch payment failed: %v", err)
+		}
+		first, err := stream.Recv()
+		if err != nil || first.GetPayment().GetStatus() != types.PaymentStatus_PAYMENT_STATUS_PENDING {
+			t.Fatalf("expected pending snapshot, got %+v err=%v", first, err)
+		}
+
+		eventID, err := fakeStripe.CompleteCheckoutSession(payment.GetProviderPaymentId())
+		if err != nil {
+			t.Fatalf("complete checkout session failed: %v", err)
+		}
+		if err := fakeStripe.SendWebhook(context.Background(), eventID); err != nil {
+			t.Fatalf("send webhook failed: %v", err)
+		}
+
+		update, err := stream.Recv()
+		if err != nil || update.GetPayment().GetStatus() != types.PaymentStatus_PAYMENT_STATUS_PAID || update.GetEventId() <= first.GetEventId() {
+			t.Fatalf("expected paid update after the webhook, got %+v err=%v", update, err)
+		}
+		if _, err := stream.Recv(); err != io.EOF {
+			t.Fatalf("expected the stream to end after a terminal status, got %v", err)
+		}
+	})
+
+	t.Run("GRPCCreateIdempotencyConflict", func(t *testing.T) {
+		req := &types.CreatePaymentRequest{
+			RequestId:         fmt.Sprintf("e2e-idem-%d", time.Now().UnixNano()),
+			CallerService:     "subscriptions-service",
+			ResourceType:      "order",
+			ResourceId:        "e2e-2",
+			AmountCents:       900,
+			Currency:          "EUR",
+			PaymentMethod:     types.PaymentMethod_PAYMENT_METHOD_HOSTED_CARD,
+			PaymentType:       types.PaymentType_PAYMENT_TYPE_ONE_TIME,
+			StatusCallbackUrl: "http://localhost:1/status",
+		}
+		first, err := grpcClient.CreatePayment(context.Background(), req)
+		if err != nil {
+			t.Fatalf("grpc create payment failed: %v", err)
+		}
+		again, err := grpcClient.CreatePayment(context.Background(), req)
+		if err != nil || again.GetPayment().GetId() != first.GetPayment().GetId() {
+			t.Fatalf("expected the original payment for a repeated request, got %+v err=%v", again.GetPayment(), err)
+		}
+
+		req.AmountCents = 1900
+		_, err = grpcClient.CreatePayment(context.Background(), req)
+		if status.Code(err) != codes.AlreadyExists || !strings.Contains(status.Convert(err).Message(), "amount_cents") {
+			t.Fatalf("expected AlreadyExists naming amount_cents, got %v", err)
+		}
+	})
+
+	t.Run("GRPCLookupByRequestIDAndProviderRef", func(t *testing.T) {
+		requestID := fmt.Sprintf("e2e-lookup-%d", time.Now().UnixNano())
+		created, err := grpcClient.CreatePayment(context.Background(), &types.CreatePaymentRequest{
+			RequestId:         requestID,
+			CallerService:     "subscriptions-service",
+			ResourceType:      "order",
+			ResourceId:        "e2e-4",
+			AmountCents:       1100,
+			Currency:          "EUR",
+			PaymentMethod:     types.PaymentMethod_PAYMENT_METHOD_HOSTED_CARD,
+			PaymentType:       types.PaymentType_PAYMENT_TYPE_ONE_TIME,
+			StatusCallbackUrl: "http://localhost:1/status",
+		})
+		if err != nil {
+			t.Fatalf("grpc create payment failed: %v", err)
+		}
+		payment := created.GetPayment()
+
+		byRequest, err := grpcClient.GetPaymentByRequestID(context.Background(), &types.GetPaymentByRequestIDRequest{
+			CallerService: "subscriptions-service",
+			RequestId:     requestID,
+		})
+		if err != nil || byRequest.GetPayment().GetId() != payment.GetId() {
+			t.Fatalf("expected lookup by request id to find payment %d, got %+v err=%v", payment.GetId(), byRequest.GetPayment(), err)
+		}
+
+		byRef, err := grpcClient.GetPaymentByProviderRef(context.Background(), &types.GetPaymentByProviderRefRequest{
+			Provider:          types.ProviderType_PROVIDER_TYPE_STRIPE,
+			ProviderPaymentId: payment.GetProviderPaymentId(),
+		})
+		if err != nil || byRef.GetPayment().GetId() != payment.GetId() {
+			t.Fatalf("expected lookup by provider ref to find payment %d, got %+v err=%v", payment.GetId(), byRef.GetPayment(), err)
+		}
+
+		resp, body := client.doJSON(t, http.MethodGet, "/payments/by-provider-ref?provider=stripe&provider_payment_id=cs_missing", nil)
+		if resp.StatusCode != http.StatusNotFound {
+			t.Fatalf("expected 404, got %d body=%s", resp.StatusCode, string(body))
+		}
+	})
+
+	t.Run("GRPCCallerScopedAccess", func(t *testing.T) {
+		created, err := grpcClient.CreatePayment(context.Background(), &types.CreatePaymentRequest{
+			RequestId:         fmt.Sprintf("e2e-scope-%d", time.Now().UnixNano()),
+			CallerService:     "subscriptions-service",
+			ResourceType:      "order",
+			ResourceId:        "e2e-3",
+			AmountCents:       700,
+			Currency:          "EUR",
+			PaymentMethod:     types.PaymentMethod_PAYMENT_METHOD_HOSTED_CARD,
+			PaymentType:       types.PaymentType_PAYMENT_TYPE_ONE_TIME,
+			StatusCallbackUrl: "http://localhost:1/status",
+		})
+		if err != nil {
+			t.Fatalf("grpc create payment failed: %v", err)
+		}
+
+		ordersCtx := grpcContextWithHeaders(paymentsOrdersAPIKey(), fmt.Sprintf("e2e-grpc-scope-%d", time.Now().UnixNano()))
+		_, err = rawGRPCClient.GetPayment(ordersCtx, &types.GetPaymentRequest{Id: created.GetPayment().GetId()})
+		if status.Code(err) != codes.NotFound {
+			t.Fatalf("expected NotFound for another caller's payment, got %v", err)
+		}
+		_, err = rawGRPCClient.CreatePayment(ordersCtx, &types.CreatePaymentRequest{
+			RequestId:         fmt.Sprintf("e2e-scope-other-%d", time.Now().UnixNano()),
+			CallerService:     "subscriptions-service",
+			ResourceType:      "order",
+			ResourceId:        "e2e-3",
+			AmountCents:       700,
+			Currency:          "EUR",
+			PaymentMethod:     types.PaymentMethod_PAYMENT_METHOD_HOSTED_CARD,
+			PaymentType:       types.PaymentType_PAYMENT_TYPE_ONE_TIME,
+			StatusCallbackUrl: "http://localhost:1/status",
+		})
+		if status.Code(err) != codes.PermissionDenied {
+			t.Fatalf("expected PermissionDenied creating for another caller, got %v", err)
+		}
+	})
+
 	t.Run("GRPCGetNotFound", func(t *testing.T) {
 		_, err := grpcClient.GetPayment(context.Background(), &types.GetPaymentRequest{Id: 999999})
 		if status.Code(err) != codes.NotFound {
@@ -296,6 +510,33 @@ func TestPaymentsE2E(t *testing.T) {
 		}
 	})
 
+	t.Run("GRPCListPaymentsPageToken", func(t *testing.T) {
+		first, err := grpcClient.ListPayments(context.Background(), &types.ListPaymentsRequest{
+			CallerService: "subscriptions-service",
+			Currency:      "EUR",
+			Limit:         1,
+		})
+		if err != nil {
+			t.Fatalf("grpc list payments failed: %v", err)
+		}
+		if len(first.GetPayments()) != 1 || first.GetNextPageToken() == "" {
+			t.Fatalf("expected one payment and a next page token, got %+v", first)
+		}
+
+		second, err := grpcClient.ListPayments(context.Background(), &types.ListPaymentsRequest{
+			CallerService: "subscriptions-service",
+			Currency:      "EUR",
+			Limit:         1,
+			PageToken:     first.GetNextPageToken(),
+		})
+		if err != nil {
+			t.Fatalf("grpc list second page failed: %v", err)
+		}
+		if len(second.GetPayments()) != 1 || second.GetPayments()[0].GetId() >= first.GetPayments()[0].GetId() {
+			t.Fatalf("expected an older payment on the second page, got %+v", second.GetPayments())
+		}
+	})
+
 	t.Run("GRPCCancelNotFound", func(t *testing.T) {
 		_, err := grpcClient.CancelPayment(context.Background(), &types.CancelPaymentRequest{Id: 999999, Reason: "e2e"})
 		if status.Code(err) != codes.NotFound {
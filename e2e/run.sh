@@ -3,9 +3,18 @@ set -euo pipefail
 
 SCRIPT_DIR="$(cd "$(dirname "${BASH_SOURCE[0]}")" && pwd)"
 ROOT_DIR="$(cd "$SCRIPT_DIR/.." && pwd)"
-COMPOSE_FILE="$SCRIPT_DIR/docker-compose.yml"
+DB="${1:-mysql}"
 
-echo "Starting docker compose..."
+case "$DB" in
+  mysql) COMPOSE_FILE="$SCRIPT_DIR/docker-compose.yml" ;;
+  postgres) COMPOSE_FILE="$SCRIPT_DIR/docker-compose.postgres.yml" ;;
+  *)
+    echo "usage: $0 [mysql|postgres]" >&2
+    exit 2
+    ;;
+esac
+
+echo "Starting docker compose ($DB)..."
 docker compose -f "$COMPOSE_FILE" up -d --build
 
 cleanup() {
@@ -18,4 +27,5 @@ echo "Running E2E tests..."
 cd "$ROOT_DIR"
 PAYMENTS_HTTP_URL="${PAYMENTS_HTTP_URL:-http://localhost:48080}" \
 PAYMENTS_GRPC_ADDR="${PAYMENTS_GRPC_ADDR:-localhost:49090}" \
+PAYMENTS_WEBHOOK_URL="${PAYMENTS_WEBHOOK_URL:-http://localhost:48081}" \
 go test ./e2e -v -tags e2e
@@ -0,0 +1,158 @@
+-- PostgreSQL schema, equivalent to schema.sql. Apply it to an existing
+-- payments database (DB_DRIVER=postgres).
+
+CREATE TABLE payments (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    request_id VARCHAR(128) NOT NULL,
+    caller_service VARCHAR(128) NOT NULL,
+    resource_type VARCHAR(128) NOT NULL,
+    resource_id VARCHAR(255) NOT NULL,
+    customer_ref VARCHAR(255) NULL,
+    amount_cents BIGINT NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    status SMALLINT NOT NULL DEFAULT 1,
+    payment_method SMALLINT NOT NULL,
+    payment_type SMALLINT NOT NULL,
+    provider SMALLINT NOT NULL,
+    recurring_interval VARCHAR(16) NULL,
+    recurring_interval_count INT NULL,
+    subscription_amount_cents BIGINT NULL,
+    subscription_paused BOOLEAN NOT NULL DEFAULT FALSE,
+    cancel_at_period_end BOOLEAN NOT NULL DEFAULT FALSE,
+    provider_payment_id VARCHAR(255) NULL,
+    provider_subscription_id VARCHAR(255) NULL,
+    checkout_url TEXT NULL,
+    provider_callback_hash VARCHAR(128) NOT NULL,
+    provider_callback_url VARCHAR(1024) NOT NULL,
+    status_callback_url VARCHAR(1024) NOT NULL,
+    success_url VARCHAR(1024) NOT NULL DEFAULT '',
+    cancel_url VARCHAR(1024) NOT NULL DEFAULT '',
+    request_fingerprint CHAR(64) NOT NULL DEFAULT '',
+    refunded_cents BIGINT NOT NULL DEFAULT 0,
+    refundable_cents BIGINT NOT NULL DEFAULT 0,
+    metadata_json JSONB NOT NULL,
+    version BIGINT NOT NULL DEFAULT 1,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_payments_caller_request_id ON payments (caller_service, request_id);
+CREATE UNIQUE INDEX idx_payments_provider_callback_hash ON payments (provider, provider_callback_hash);
+CREATE INDEX idx_payments_status ON payments (status);
+CREATE INDEX idx_payments_provider ON payments (provider);
+CREATE INDEX idx_payments_resource ON payments (resource_type, resource_id);
+CREATE INDEX idx_payments_provider_subscription ON payments (provider, provider_subscription_id);
+CREATE INDEX idx_payments_provider_payment ON payments (provider, provider_payment_id);
+CREATE INDEX idx_payments_updated_at ON payments (updated_at);
+CREATE INDEX idx_payments_created_at ON payments (created_at);
+-- Serves ListPayments metadata filters (metadata_json @> ...).
+CREATE INDEX idx_payments_metadata ON payments USING GIN (metadata_json jsonb_path_ops);
+
+-- Raw payloads stay TEXT so provider bodies are stored byte for byte.
+CREATE TABLE payment_events (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    payment_id BIGINT NOT NULL,
+    event_type VARCHAR(128) NOT NULL,
+    old_status SMALLINT NULL,
+    new_status SMALLINT NOT NULL,
+    provider_event_id VARCHAR(255) NULL,
+    payload_json TEXT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_payment_events_payment_id FOREIGN KEY (payment_id) REFERENCES payments(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_payment_events_payment_id ON payment_events (payment_id);
+CREATE INDEX idx_payment_events_payment_type ON payment_events (payment_id, event_type);
+CREATE INDEX idx_payment_events_created_at ON payment_events (created_at);
+
+CREATE TABLE payment_callbacks (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    payment_id BIGINT NULL,
+    provider VARCHAR(32) NOT NULL,
+    callback_hash VARCHAR(128) NOT NULL,
+    provider_event_id VARCHAR(255) NULL,
+    signature VARCHAR(1024) NOT NULL,
+    payload_json TEXT NOT NULL,
+    status SMALLINT NOT NULL,
+    error VARCHAR(1024) NULL,
+    signature_verified BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_payment_callbacks_payment_id FOREIGN KEY (payment_id) REFERENCES payments(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_payment_callbacks_payment_id ON payment_callbacks (payment_id);
+CREATE INDEX idx_payment_callbacks_provider_hash ON payment_callbacks (provider, callback_hash);
+CREATE INDEX idx_payment_callbacks_provider_event_id ON payment_callbacks (provider, provider_event_id);
+CREATE INDEX idx_payment_callbacks_status ON payment_callbacks (status);
+CREATE INDEX idx_payment_callbacks_created_at ON payment_callbacks (created_at);
+
+CREATE TABLE payment_provider_events (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    provider SMALLINT NOT NULL,
+    provider_event_id VARCHAR(255) NOT NULL,
+    payment_id BIGINT NOT NULL,
+    event_type VARCHAR(128) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_payment_provider_events_payment_id FOREIGN KEY (payment_id) REFERENCES payments(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_payment_provider_events_provider_event_id ON payment_provider_events (provider, provider_event_id);
+CREATE INDEX idx_payment_provider_events_payment_id ON payment_provider_events (payment_id);
+
+CREATE TABLE payment_callback_outbox (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    payment_id BIGINT NOT NULL,
+    charge_id BIGINT NULL,
+    caller_service VARCHAR(128) NOT NULL,
+    event_type VARCHAR(64) NOT NULL,
+    request_id VARCHAR(255) NOT NULL,
+    destination VARCHAR(1024) NOT NULL,
+    payload_json TEXT NOT NULL,
+    status SMALLINT NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NULL,
+    last_error VARCHAR(1024) NULL,
+    delivered_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_payment_callback_outbox_payment_id FOREIGN KEY (payment_id) REFERENCES payments(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_payment_callback_outbox_due ON payment_callback_outbox (status, next_attempt_at);
+CREATE INDEX idx_payment_callback_outbox_payment ON payment_callback_outbox (payment_id, status, id);
+CREATE INDEX idx_payment_callback_outbox_caller ON payment_callback_outbox (caller_service, status, id);
+
+CREATE TABLE payment_refunds (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    payment_id BIGINT NOT NULL,
+    request_id VARCHAR(128) NOT NULL,
+    amount_cents BIGINT NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    status SMALLINT NOT NULL,
+    reason VARCHAR(1024) NULL,
+    provider_refund_id VARCHAR(255) NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_payment_refunds_payment_id FOREIGN KEY (payment_id) REFERENCES payments(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_payment_refunds_payment_request_id ON payment_refunds (payment_id, request_id);
+CREATE UNIQUE INDEX idx_payment_refunds_provider_refund_id ON payment_refunds (payment_id, provider_refund_id);
+CREATE INDEX idx_payment_refunds_status ON payment_refunds (status);
+
+CREATE TABLE payment_charges (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    payment_id BIGINT NOT NULL,
+    provider_invoice_id VARCHAR(255) NOT NULL,
+    amount_cents BIGINT NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    status SMALLINT NOT NULL,
+    period_start TIMESTAMPTZ NULL,
+    period_end TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_payment_charges_payment_id FOREIGN KEY (payment_id) REFERENCES payments(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_payment_charges_payment_invoice ON payment_charges (payment_id, provider_invoice_id);
@@ -16,19 +16,22 @@ CREATE TABLE payments (
     provider SMALLINT NOT NULL,
     recurring_interval VARCHAR(16) NULL,
     recurring_interval_count INT NULL,
+    subscription_amount_cents BIGINT NULL,
+    subscription_paused BOOLEAN NOT NULL DEFAULT FALSE,
+    cancel_at_period_end BOOLEAN NOT NULL DEFAULT FALSE,
     provider_payment_id VARCHAR(255) NULL,
     provider_subscription_id VARCHAR(255) NULL,
     checkout_url TEXT NULL,
     provider_callback_hash VARCHAR(128) NOT NULL,
     provider_callback_url VARCHAR(1024) NOT NULL,
     status_callback_url VARCHAR(1024) NOT NULL,
+    success_url VARCHAR(1024) NOT NULL DEFAULT '',
+    cancel_url VARCHAR(1024) NOT NULL DEFAULT '',
+    request_fingerprint CHAR(64) NOT NULL DEFAULT '',
     refunded_cents BIGINT NOT NULL DEFAULT 0,
     refundable_cents BIGINT NOT NULL DEFAULT 0,
     metadata_json JSON NOT NULL,
-    callback_delivery_status SMALLINT NOT NULL DEFAULT 0,
-    callback_delivery_attempts INT NOT NULL DEFAULT 0,
-    callback_delivery_next_at DATETIME NULL,
-    callback_delivery_last_error VARCHAR(1024) NULL,
+    version BIGINT UNSIGNED NOT NULL DEFAULT 1,
     created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
     updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
     UNIQUE INDEX idx_payments_caller_request_id (caller_service, request_id),
@@ -36,7 +39,8 @@ CREATE TABLE payments (
     INDEX idx_payments_status (status),
     INDEX idx_payments_provider (provider),
     INDEX idx_payments_resource (resource_type, resource_id),
-    INDEX idx_payments_callback_delivery (callback_delivery_status, callback_delivery_next_at),
+    INDEX idx_payments_provider_subscription (provider, provider_subscription_id),
+    INDEX idx_payments_provider_payment (provider, provider_payment_id),
     INDEX idx_payments_updated_at (updated_at),
     INDEX idx_payments_created_at (created_at)
 );
@@ -52,6 +56,7 @@ CREATE TABLE payment_events (
     created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
     CONSTRAINT fk_payment_events_payment_id FOREIGN KEY (payment_id) REFERENCES payments(id) ON DELETE CASCADE,
     INDEX idx_payment_events_payment_id (payment_id),
+    INDEX idx_payment_events_payment_type (payment_id, event_type),
     INDEX idx_payment_events_created_at (created_at)
 );
 
@@ -60,15 +65,84 @@ CREATE TABLE payment_callbacks (
     payment_id BIGINT UNSIGNED NULL,
     provider VARCHAR(32) NOT NULL,
     callback_hash VARCHAR(128) NOT NULL,
+    provider_event_id VARCHAR(255) NULL,
     signature VARCHAR(1024) NOT NULL,
     payload_json LONGTEXT NOT NULL,
     status SMALLINT NOT NULL,
     error VARCHAR(1024) NULL,
+    signature_verified BOOLEAN NOT NULL DEFAULT FALSE,
     created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
     updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
     CONSTRAINT fk_payment_callbacks_payment_id FOREIGN KEY (payment_id) REFERENCES payments(id) ON DELETE SET NULL,
     INDEX idx_payment_callbacks_payment_id (payment_id),
     INDEX idx_payment_callbacks_provider_hash (provider, callback_hash),
+    INDEX idx_payment_callbacks_provider_event_id (provider, provider_event_id),
     INDEX idx_payment_callbacks_status (status),
     INDEX idx_payment_callbacks_created_at (created_at)
 );
+
+CREATE TABLE payment_provider_events (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    provider SMALLINT NOT NULL,
+    provider_event_id VARCHAR(255) NOT NULL,
+    payment_id BIGINT UNSIGNED NOT NULL,
+    event_type VARCHAR(128) NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_payment_provider_events_payment_id FOREIGN KEY (payment_id) REFERENCES payments(id) ON DELETE CASCADE,
+    UNIQUE INDEX idx_payment_provider_events_provider_event_id (provider, provider_event_id),
+    INDEX idx_payment_provider_events_payment_id (payment_id)
+);
+
+CREATE TABLE payment_callback_outbox (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    payment_id BIGINT UNSIGNED NOT NULL,
+    charge_id BIGINT UNSIGNED NULL,
+    caller_service VARCHAR(128) NOT NULL,
+    event_type VARCHAR(64) NOT NULL,
+    request_id VARCHAR(255) NOT NULL,
+    destination VARCHAR(1024) NOT NULL,
+    payload_json LONGTEXT NOT NULL,
+    status SMALLINT NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at DATETIME NULL,
+    last_error VARCHAR(1024) NULL,
+    delivered_at DATETIME NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    CONSTRAINT fk_payment_callback_outbox_payment_id FOREIGN KEY (payment_id) REFERENCES payments(id) ON DELETE CASCADE,
+    INDEX idx_payment_callback_outbox_due (status, next_attempt_at),
+    INDEX idx_payment_callback_outbox_payment (payment_id, status, id),
+    INDEX idx_payment_callback_outbox_caller (caller_service, status, id)
+);
+
+CREATE TABLE payment_refunds (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    payment_id BIGINT UNSIGNED NOT NULL,
+    request_id VARCHAR(128) NOT NULL,
+    amount_cents BIGINT NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    status SMALLINT NOT NULL,
+    reason VARCHAR(1024) NULL,
+    provider_refund_id VARCHAR(255) NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    CONSTRAINT fk_payment_refunds_payment_id FOREIGN KEY (payment_id) REFERENCES payments(id) ON DELETE CASCADE,
+    UNIQUE INDEX idx_payment_refunds_payment_request_id (payment_id, request_id),
+    UNIQUE INDEX idx_payment_refunds_provider_refund_id (payment_id, provider_refund_id),
+    INDEX idx_payment_refunds_status (status)
+);
+
+CREATE TABLE payment_charges (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    payment_id BIGINT UNSIGNED NOT NULL,
+    provider_invoice_id VARCHAR(255) NOT NULL,
+    amount_cents BIGINT NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    status SMALLINT NOT NULL,
+    period_start DATETIME NULL,
+    period_end DATETIME NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    CONSTRAINT fk_payment_charges_payment_id FOREIGN KEY (payment_id) REFERENCES payments(id) ON DELETE CASCADE,
+    UNIQUE INDEX idx_payment_charges_payment_invoice (payment_id, provider_invoice_id)
+);